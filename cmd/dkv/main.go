@@ -1,27 +1,18 @@
 package main
 
 import (
-	"context"
-	"crypto/tls"
-	"distributed-kv/gen/dkv/v1/dkvv1connect"
-	"distributed-kv/internal/api"
-	"distributed-kv/internal/store/distributed"
-	"distributed-kv/internal/store/persisted"
-	internaltls "distributed-kv/internal/tls"
-	"fmt"
+	"distributed-kv/pkg/server"
 	"log"
 	"log/slog"
-	"net"
-	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hashicorp/raft"
 	"github.com/joho/godotenv"
-	"github.com/urfave/cli/v3"
-	"golang.org/x/net/http2"
-	"golang.org/x/net/http2/h2c"
+	"github.com/urfave/cli/v2"
 )
 
 var (
@@ -30,19 +21,50 @@ var (
 	name                string
 	listenPeerAddress   string
 	listenClientAddress string
+	listenRedisAddress  string
+	multiplexPeer       bool
 	initialCluster      cli.StringSlice
 	initialClusterState string
+	initialClusterToken string
+	forceNewCluster     bool
 	advertiseNodes      cli.StringSlice
 
+	discoveryMode        string
+	discoverySRV         string
+	discoveryDNSPattern  string
+	discoveryDNSMaxPeers int
+	discoveryRemoveAfter time.Duration
+	discoveryTrustPeers  bool
+
+	deadMemberTimeout       time.Duration
+	minQuorum               int
+	serverStabilizationTime time.Duration
+	zone                    string
+	maxReadLag              uint64
+
 	peerCertFile      string
 	peerKeyFile       string
 	peerTrustedCAFile string
+	peerAllowedIDs    cli.StringSlice
 
 	certFile      string
 	keyFile       string
 	trustedCAFile string
 
 	dataDir string
+
+	auditLogFile       string
+	auditLogMaxSize    int
+	auditLogMaxBackups int
+
+	backupDir       string
+	backupInterval  time.Duration
+	backupRetention int
+
+	enableFaultInjection bool
+	faultInjectionSeed   uint64
+
+	restoreFrom string
 )
 
 var app = &cli.App{
@@ -79,6 +101,18 @@ var app = &cli.App{
 			Value:       ":3000",
 			Destination: &listenClientAddress,
 		},
+		&cli.StringFlag{
+			Name:        "listen-redis-address",
+			Usage:       "Address listen on for Redis clients, with the client TLS configuration. Disabled if empty",
+			EnvVars:     []string{"DKV_LISTEN_REDIS_ADDRESS"},
+			Destination: &listenRedisAddress,
+		},
+		&cli.BoolFlag{
+			Name:        "multiplex-peer-traffic",
+			Usage:       "Tunnel peer traffic through the client address and TLS configuration instead of the peer address",
+			EnvVars:     []string{"DKV_MULTIPLEX_PEER_TRAFFIC"},
+			Destination: &multiplexPeer,
+		},
 		&cli.StringSliceFlag{
 			Name:        "initial-cluster",
 			Usage:       "Initial cluster configuration for bootstrapping",
@@ -93,6 +127,87 @@ var app = &cli.App{
 			Required:    true,
 			Destination: &initialClusterState,
 		},
+		&cli.StringFlag{
+			Name:        "discovery",
+			Usage:       "Discovery of the peers in addition to the initial cluster (static, srv, dns)",
+			EnvVars:     []string{"DKV_DISCOVERY"},
+			Value:       "static",
+			Destination: &discoveryMode,
+		},
+		&cli.StringFlag{
+			Name:        "discovery-srv",
+			Usage:       "Domain name of the SRV records of the peers (e.g. _peer._tcp.dkv.default.svc.cluster.local)",
+			EnvVars:     []string{"DKV_DISCOVERY_SRV"},
+			Destination: &discoverySRV,
+		},
+		&cli.StringFlag{
+			Name:        "discovery-dns-pattern",
+			Usage:       "Peer address pattern where {i} is the ordinal of the peer (e.g. dkv-{i}.dkv.default.svc.cluster.local:2380)",
+			EnvVars:     []string{"DKV_DISCOVERY_DNS_PATTERN"},
+			Destination: &discoveryDNSPattern,
+		},
+		&cli.IntFlag{
+			Name:        "discovery-dns-max-peers",
+			Usage:       "Number of ordinals resolved by the dns discovery",
+			EnvVars:     []string{"DKV_DISCOVERY_DNS_MAX_PEERS"},
+			Value:       16,
+			Destination: &discoveryDNSMaxPeers,
+		},
+		&cli.DurationFlag{
+			Name:        "discovery-remove-vanished-after",
+			Usage:       "Remove the members which have not been discovered for this duration. Disabled if 0",
+			EnvVars:     []string{"DKV_DISCOVERY_REMOVE_VANISHED_AFTER"},
+			Destination: &discoveryRemoveAfter,
+		},
+		&cli.BoolFlag{
+			Name:        "discovery-trust-peers",
+			Usage:       "Accept the peer identities of the discovered peers, which trusts the DNS answers. Otherwise, the peers which are not members must be in --peer-allowed-identities",
+			EnvVars:     []string{"DKV_DISCOVERY_TRUST_PEERS"},
+			Destination: &discoveryTrustPeers,
+		},
+		&cli.DurationFlag{
+			Name:        "dead-member-timeout",
+			Usage:       "Remove the members which have been unreachable from the leader for this duration. Disabled if 0",
+			EnvVars:     []string{"DKV_DEAD_MEMBER_TIMEOUT"},
+			Destination: &deadMemberTimeout,
+		},
+		&cli.IntFlag{
+			Name:        "min-quorum",
+			Usage:       "Minimum number of voters kept when removing dead or vanished members",
+			EnvVars:     []string{"DKV_MIN_QUORUM"},
+			Value:       3,
+			Destination: &minQuorum,
+		},
+		&cli.DurationFlag{
+			Name:        "server-stabilization-time",
+			Usage:       "Join new servers as non-voters, promoted once healthy for this duration. Disabled if 0",
+			EnvVars:     []string{"DKV_SERVER_STABILIZATION_TIME"},
+			Destination: &serverStabilizationTime,
+		},
+		&cli.StringFlag{
+			Name:        "zone",
+			Usage:       "Zone of the node. The leader keeps one voter per zone, the other nodes of the zone are hot standbys",
+			EnvVars:     []string{"DKV_ZONE"},
+			Destination: &zone,
+		},
+		&cli.Uint64Flag{
+			Name:        "max-read-lag",
+			Usage:       "Maximum number of committed entries not applied by a follower serving a read with a maximum staleness",
+			EnvVars:     []string{"DKV_MAX_READ_LAG"},
+			Destination: &maxReadLag,
+		},
+		&cli.StringFlag{
+			Name:        "initial-cluster-token",
+			Usage:       "Token identifying the cluster. Persisted on first start, peers with another token are rejected",
+			EnvVars:     []string{"DKV_INITIAL_CLUSTER_TOKEN"},
+			Destination: &initialClusterToken,
+		},
+		&cli.BoolFlag{
+			Name:        "force-new-cluster",
+			Usage:       "Recover from a permanent loss of quorum by forcing a new cluster with this node as the only member. Other nodes must rejoin with an empty data directory",
+			EnvVars:     []string{"DKV_FORCE_NEW_CLUSTER"},
+			Destination: &forceNewCluster,
+		},
 		&cli.StringFlag{
 			Name:        "peer-cert-file",
 			Usage:       "Path to the peer server TLS certificate file",
@@ -111,6 +226,12 @@ var app = &cli.App{
 			EnvVars:     []string{"DKV_PEER_TRUSTED_CA_FILE"},
 			Destination: &peerTrustedCAFile,
 		},
+		&cli.StringSliceFlag{
+			Name:        "peer-allowed-identities",
+			Usage:       "Additional peer identities (CN or SAN) to accept",
+			EnvVars:     []string{"DKV_PEER_ALLOWED_IDENTITIES"},
+			Destination: &peerAllowedIDs,
+		},
 		&cli.StringFlag{
 			Name:        "cert-file",
 			Usage:       "Path to the client server TLS certificate file",
@@ -136,182 +257,164 @@ var app = &cli.App{
 			Value:       "data",
 			Destination: &dataDir,
 		},
+		&cli.StringFlag{
+			Name:        "audit-log-file",
+			Usage:       "Path to the audit log file (JSON lines). Disabled if empty",
+			EnvVars:     []string{"DKV_AUDIT_LOG_FILE"},
+			Destination: &auditLogFile,
+		},
+		&cli.IntFlag{
+			Name:        "audit-log-max-size",
+			Usage:       "Maximum size in megabytes of the audit log file before rotation",
+			EnvVars:     []string{"DKV_AUDIT_LOG_MAX_SIZE"},
+			Value:       100,
+			Destination: &auditLogMaxSize,
+		},
+		&cli.IntFlag{
+			Name:        "audit-log-max-backups",
+			Usage:       "Maximum number of rotated audit log files to keep",
+			EnvVars:     []string{"DKV_AUDIT_LOG_MAX_BACKUPS"},
+			Value:       10,
+			Destination: &auditLogMaxBackups,
+		},
+		&cli.StringFlag{
+			Name:        "backup-dir",
+			Usage:       "Directory where the leader stores scheduled snapshots. Disabled if empty",
+			EnvVars:     []string{"DKV_BACKUP_DIR"},
+			Destination: &backupDir,
+		},
+		&cli.DurationFlag{
+			Name:        "backup-interval",
+			Usage:       "Interval between scheduled snapshots",
+			EnvVars:     []string{"DKV_BACKUP_INTERVAL"},
+			Value:       time.Hour,
+			Destination: &backupInterval,
+		},
+		&cli.IntFlag{
+			Name:        "backup-retention",
+			Usage:       "Number of scheduled snapshots to keep (0 keeps all snapshots)",
+			EnvVars:     []string{"DKV_BACKUP_RETENTION"},
+			Value:       24,
+			Destination: &backupRetention,
+		},
+		&cli.BoolFlag{
+			Name:        "enable-fault-injection",
+			Usage:       "Enable the debug RPCs injecting network faults in the peer traffic. Do not enable in production",
+			EnvVars:     []string{"DKV_ENABLE_FAULT_INJECTION"},
+			Destination: &enableFaultInjection,
+		},
+		&cli.Uint64Flag{
+			Name:        "fault-injection-seed",
+			Usage:       "Seed of the random injected faults, so that they can be reproduced. A random seed is used and logged if 0",
+			EnvVars:     []string{"DKV_FAULT_INJECTION_SEED"},
+			Destination: &faultInjectionSeed,
+		},
 	},
-	Action: func(c *cli.Context) (err error) {
-		ctx := c.Context
-		// TLS configurations
-		storeOpts := []distributed.StoreOption{}
-		if peerCertFile != "" && peerKeyFile != "" {
-			peerTLSConfig, err := internaltls.SetupServerTLSConfig(
-				peerCertFile,
-				peerKeyFile,
-				peerTrustedCAFile,
-			)
-			if err != nil {
-				return err
-			}
-			storeOpts = append(storeOpts, distributed.WithServerTLSConfig(peerTLSConfig))
-		}
-
-		if (peerCertFile != "" && peerKeyFile != "") || peerTrustedCAFile != "" {
-			peerClientTLSConfig, err := internaltls.SetupClientTLSConfig(
-				peerCertFile,
-				peerKeyFile,
-				peerTrustedCAFile,
-			)
-			if err != nil {
-				return err
-			}
-			storeOpts = append(storeOpts, distributed.WithClientTLSConfig(peerClientTLSConfig))
-		}
-
-		var tlsConfig *tls.Config
-		if certFile != "" && keyFile != "" {
-			tlsConfig, err = internaltls.SetupServerTLSConfig(certFile, keyFile, trustedCAFile)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Store configuration
-		store := persisted.New(dataDir)
-		defer func() {
-			_ = store.Close()
-		}()
-		dstore, err := bootstrapDStore(store, storeOpts)
+	Commands: []*cli.Command{
+		{
+			Name:  "restore",
+			Usage: "Restore the data directory of this node from a snapshot file",
+			UsageText: "dkv --name=NAME --initial-cluster=NAME=ADDRESS,... --initial-cluster-state=new " +
+				"--data-dir=DIR restore --from=FILE",
+			Description: "The node is restored as the single member of a new cluster. " +
+				"The other nodes of the initial cluster must be started with an empty data directory.\n\n" +
+				"The node is configured by the global options, which must be set before the command " +
+				"or by their environment variables.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:        "from",
+					Usage:       "Path to the snapshot file",
+					Required:    true,
+					Destination: &restoreFrom,
+				},
+			},
+			Action: func(*cli.Context) error {
+				config, err := newConfig()
+				if err != nil {
+					return err
+				}
+				return server.Restore(config, restoreFrom)
+			},
+		},
+	},
+	Action: func(c *cli.Context) error {
+		config, err := newConfig()
 		if err != nil {
 			return err
 		}
-		defer func() {
-			err := dstore.Shutdown()
-			if err != nil {
-				slog.Error("failed to shutdown store", "error", err)
-			}
-			slog.Warn("store shutdown")
-		}()
-
-		// Routes
-		r := http.NewServeMux()
-		r.Handle(dkvv1connect.NewDkvAPIHandler(&api.DkvAPIHandler{
-			Store: dstore,
-		}))
+		ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
-		nodes := make(map[raft.ServerID]string)
-		for _, node := range advertiseNodes.Value() {
-			id, addr, ok := strings.Cut(node, "=")
-			if !ok {
-				slog.Error("invalid initial cluster configuration", "node", node)
-				continue
-			}
-			nodes[raft.ServerID(id)] = addr
-		}
-		r.Handle(dkvv1connect.NewMembershipAPIHandler(&api.MembershipAPIHandler{
-			AdvertiseNodes: nodes,
-			Store:          dstore,
-		}))
-
-		// Start the server
-		l, err := net.Listen("tcp", listenClientAddress)
-		if err != nil {
+		srv := server.New(config)
+		if err := srv.Start(ctx); err != nil {
 			return err
 		}
-		if tlsConfig != nil {
-			l = tls.NewListener(l, tlsConfig)
-		}
-		slog.Info("server listening", "address", listenClientAddress)
-		srv := &http.Server{
-			BaseContext: func(_ net.Listener) context.Context { return ctx },
-			Handler:     h2c.NewHandler(r, &http2.Server{}),
-		}
 		defer func() {
-			_ = srv.Shutdown(ctx)
-			_ = l.Close()
-			slog.Warn("server shutdown")
+			_ = srv.Stop()
 		}()
-		return srv.Serve(l)
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-srv.Err():
+			return err
+		}
 	},
 }
 
-func bootstrapDStore(
-	storer distributed.Storer,
-	storeOpts []distributed.StoreOption,
-) (dstore *distributed.Store, err error) {
-	// Bootstrap
-	nodes := initialCluster.Value()
-	if len(nodes) == 0 {
-		return nil, fmt.Errorf("invalid initial cluster configuration (no nodes): %s", nodes)
+// newConfig returns the configuration of the server from the flags.
+func newConfig() (server.Config, error) {
+	peers, err := server.ParsePeers(initialCluster.Value())
+	if err != nil {
+		return server.Config{}, err
 	}
-	bootstrapNode, _, ok := strings.Cut(nodes[0], "=")
-	if !ok {
-		return nil, fmt.Errorf("invalid initial cluster configuration: %s", nodes)
-	}
-	advertizedPeers := make(map[raft.ServerID]raft.ServerAddress)
-	for _, node := range nodes {
+	nodes := make(map[raft.ServerID]string)
+	for _, node := range advertiseNodes.Value() {
 		id, addr, ok := strings.Cut(node, "=")
 		if !ok {
-			return nil, fmt.Errorf("invalid initial cluster configuration: %s", node)
+			slog.Error("invalid initial cluster configuration", "node", node)
+			continue
 		}
-		advertizedPeers[raft.ServerID(id)] = raft.ServerAddress(addr)
+		nodes[raft.ServerID(id)] = addr
 	}
-
-	dstore = distributed.NewStore(
-		dataDir,
-		listenPeerAddress,
-		name,
-		advertizedPeers[raft.ServerID(name)],
-		storer,
-		storeOpts...,
-	)
-
-	bootstrap := initialClusterState == "new" && bootstrapNode == name
-	if err := dstore.Open(bootstrap); err != nil {
-		return nil, err
-	}
-	// Periodically try to join the peers
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-dstore.ShutdownCh():
-				slog.Error("stopped joining peers due to store shutdown")
-				return
-			case <-ticker.C:
-				leaderAddr, leaderID := dstore.GetLeader()
-				if leaderAddr == "" {
-					slog.Error("no leader")
-					continue
-				}
-				// Not leader
-				if leaderID != raft.ServerID(name) {
-					continue
-				}
-				members, err := dstore.GetServers()
-				if err != nil {
-					slog.Error("failed to get servers", "error", err)
-					continue
-				}
-			peers:
-				for id, addr := range advertizedPeers {
-					// Ignore self
-					if id == raft.ServerID(name) {
-						continue
-					}
-					// Ignore if already member
-					for _, member := range members {
-						if member.ID == id {
-							continue peers
-						}
-					}
-					slog.Info("request peer to join", "id", id, "addr", addr)
-					if err := dstore.Join(id, addr); err != nil {
-						slog.Error("failed to join peer", "id", id, "addr", addr, "error", err)
-					}
-				}
-			}
-		}
-	}()
-	return dstore, nil
+	return server.Config{
+		Name:                    name,
+		ListenPeerAddress:       listenPeerAddress,
+		ListenClientAddress:     listenClientAddress,
+		ListenRedisAddress:      listenRedisAddress,
+		MultiplexPeer:           multiplexPeer,
+		AdvertiseNodes:          nodes,
+		InitialCluster:          peers,
+		InitialClusterState:     initialClusterState,
+		InitialClusterToken:     initialClusterToken,
+		ForceNewCluster:         forceNewCluster,
+		DiscoveryMode:           discoveryMode,
+		DiscoverySRV:            discoverySRV,
+		DiscoveryDNSPattern:     discoveryDNSPattern,
+		DiscoveryDNSMaxPeers:    discoveryDNSMaxPeers,
+		DiscoveryRemoveAfter:    discoveryRemoveAfter,
+		DiscoveryTrustPeers:     discoveryTrustPeers,
+		DeadMemberTimeout:       deadMemberTimeout,
+		MinQuorum:               minQuorum,
+		ServerStabilizationTime: serverStabilizationTime,
+		Zone:                    zone,
+		MaxReadLag:              maxReadLag,
+		PeerCertFile:            peerCertFile,
+		PeerKeyFile:             peerKeyFile,
+		PeerTrustedCAFile:       peerTrustedCAFile,
+		PeerAllowedIdentities:   peerAllowedIDs.Value(),
+		CertFile:                certFile,
+		KeyFile:                 keyFile,
+		TrustedCAFile:           trustedCAFile,
+		DataDir:                 dataDir,
+		AuditLogFile:            auditLogFile,
+		AuditLogMaxSize:         int64(auditLogMaxSize) * 1024 * 1024,
+		AuditLogMaxBackups:      auditLogMaxBackups,
+		BackupDir:               backupDir,
+		BackupInterval:          backupInterval,
+		BackupRetention:         backupRetention,
+		FaultInjection:          enableFaultInjection,
+		FaultInjectionSeed:      faultInjectionSeed,
+	}, nil
 }
 
 func main() {
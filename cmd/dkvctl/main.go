@@ -3,22 +3,23 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
-	"math/rand"
-	"net"
-	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	dkvv1 "distributed-kv/gen/dkv/v1"
-	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/backup"
 	internaltls "distributed-kv/internal/tls"
+	"distributed-kv/pkg/client"
 
-	"connectrpc.com/connect"
 	"github.com/joho/godotenv"
-	"github.com/urfave/cli/v3"
-	"golang.org/x/net/http2"
+	"github.com/urfave/cli/v2"
 )
 
 var (
@@ -27,15 +28,10 @@ var (
 	certFile      string
 	keyFile       string
 	trustedCAFile string
-	endpoint      string
+	endpoints     cli.StringSlice
 )
 
-var (
-	dkvClient              dkvv1connect.DkvAPIClient
-	leaderDkvClient        dkvv1connect.DkvAPIClient
-	membershipClient       dkvv1connect.MembershipAPIClient
-	leaderMembershipClient dkvv1connect.MembershipAPIClient
-)
+var dkv *client.Client
 
 var app = &cli.App{
 	Name:                 "dkvctl",
@@ -62,11 +58,11 @@ var app = &cli.App{
 			EnvVars:     []string{"DKVCTL_CACERT"},
 			Destination: &trustedCAFile,
 		},
-		&cli.StringFlag{
+		&cli.StringSliceFlag{
 			Name:        "endpoint",
-			Usage:       "Server endpoint",
+			Usage:       "Server endpoints. The other members are discovered",
 			EnvVars:     []string{"DKVCTL_ENDPOINT"},
-			Destination: &endpoint,
+			Destination: &endpoints,
 			Required:    true,
 		},
 	},
@@ -80,72 +76,43 @@ var app = &cli.App{
 			}
 		}
 
-		http := &http.Client{
-			Transport: &http2.Transport{
-				AllowHTTP: true,
-				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
-					var d net.Dialer
-					conn, err := d.DialContext(ctx, network, addr)
-					if tlsConfig != nil {
-						serverName, _, serr := net.SplitHostPort(addr)
-						if serr != nil {
-							serverName = addr
-						}
-						tlsConfig := tlsConfig.Clone()
-						tlsConfig.ServerName = serverName
-						return tls.Client(conn, tlsConfig), err
-					}
-					return conn, err
-				},
-			},
-		}
-		scheme := "http://"
-		if tlsConfig != nil {
-			scheme = "https://"
-		}
-		dkvClient = dkvv1connect.NewDkvAPIClient(http, scheme+endpoint, connect.WithGRPC())
-		membershipClient = dkvv1connect.NewMembershipAPIClient(
-			http,
-			scheme+endpoint,
-			connect.WithGRPC(),
-		)
-		leaderEndpoint := findEndpoint(c.Context)
-		if leaderEndpoint == "" {
-			leaderEndpoint = endpoint
-		}
-		leaderDkvClient = dkvv1connect.NewDkvAPIClient(
-			http,
-			scheme+leaderEndpoint,
-			connect.WithGRPC(),
-		)
-		leaderMembershipClient = dkvv1connect.NewMembershipAPIClient(
-			http,
-			scheme+leaderEndpoint,
-			connect.WithGRPC(),
-		)
-		return nil
+		dkv, err = client.New(endpoints.Value(), client.WithTLSConfig(tlsConfig))
+		return err
 	},
-	// get, set, delete, member-join, member-leave, member-list
+	// get, set, delete, incr, lock, queue, member-join, member-leave, member-list, member-health, snapshot
 	Commands: []*cli.Command{
 		{
 			Name:      "get",
 			Usage:     "Get the value of a key",
 			ArgsUsage: "KEY",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "max-staleness",
+					Usage: "Maximum staleness of the value read from a follower. Unbounded if 0",
+				},
+				&cli.BoolFlag{
+					Name:  "linearizable",
+					Usage: "Read on the leader once it confirmed its leadership",
+				},
+			},
 			Action: func(c *cli.Context) error {
 				ctx := c.Context
 				key := c.Args().First()
 				if key == "" {
 					return cli.ShowCommandHelp(c, "get")
 				}
-				resp, err := dkvClient.Get(ctx, &connect.Request[dkvv1.GetRequest]{
-					Msg: &dkvv1.GetRequest{
-						Key: key,
-					},
-				})
+				var opts []client.ReadOption
+				if maxStaleness := c.Duration("max-staleness"); maxStaleness > 0 {
+					opts = append(opts, client.WithMaxStaleness(maxStaleness))
+				}
+				if c.Bool("linearizable") {
+					opts = append(opts, client.WithLinearizable())
+				}
+				value, err := dkv.Get(ctx, key, opts...)
 				if err != nil {
 					return err
 				}
-				fmt.Println(resp.Msg.GetValue())
+				fmt.Println(value)
 				return nil
 			},
 		},
@@ -160,12 +127,7 @@ var app = &cli.App{
 				if key == "" || value == "" {
 					return cli.ShowCommandHelp(c, "set")
 				}
-				_, err := leaderDkvClient.Set(ctx, &connect.Request[dkvv1.SetRequest]{
-					Msg: &dkvv1.SetRequest{
-						Key:   key,
-						Value: value,
-					},
-				})
+				_, err := dkv.Set(ctx, key, value)
 				return err
 			},
 		},
@@ -179,18 +141,218 @@ var app = &cli.App{
 				if key == "" {
 					return cli.ShowCommandHelp(c, "delete")
 				}
-				_, err := leaderDkvClient.Delete(ctx, &connect.Request[dkvv1.DeleteRequest]{
-					Msg: &dkvv1.DeleteRequest{
-						Key: key,
-					},
-				})
+				_, err := dkv.Delete(ctx, key)
 				return err
 			},
 		},
+		{
+			Name:      "incr",
+			Usage:     "Add a delta to the integer value of a key, and print the new value",
+			ArgsUsage: "KEY",
+			Flags: []cli.Flag{
+				&cli.Int64Flag{
+					Name:  "delta",
+					Usage: "Delta added to the value",
+					Value: 1,
+				},
+				&cli.Int64Flag{
+					Name:  "initial",
+					Usage: "Value of a missing key, before the delta is added",
+				},
+				&cli.Int64Flag{
+					Name:  "min",
+					Usage: "Fail if the new value is lower",
+				},
+				&cli.Int64Flag{
+					Name:  "max",
+					Usage: "Fail if the new value is greater",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				ctx := c.Context
+				key := c.Args().First()
+				if key == "" {
+					return cli.ShowCommandHelp(c, "incr")
+				}
+				opts := []client.IncrementOption{client.WithInitial(c.Int64("initial"))}
+				if c.IsSet("min") {
+					opts = append(opts, client.WithMin(c.Int64("min")))
+				}
+				if c.IsSet("max") {
+					opts = append(opts, client.WithMax(c.Int64("max")))
+				}
+				value, err := dkv.Increment(ctx, key, c.Int64("delta"), opts...)
+				if err != nil {
+					return err
+				}
+				fmt.Println(value)
+				return nil
+			},
+		},
+		{
+			Name:      "lock",
+			Usage:     "Run a command while holding a lock",
+			ArgsUsage: "NAME -- COMMAND [ARGS...]",
+			Description: "The waiters are served in FIFO order. The command is killed if the lock is lost.\n" +
+				"The key and the fencing token of the lock are in the DKV_LOCK_KEY and DKV_LOCK_REVISION\n" +
+				"environment variables of the command.",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "ttl",
+					Usage: "TTL of the lock, which is kept alive while the command runs",
+					Value: client.DefaultLeaseTTL,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				name := c.Args().First()
+				command := c.Args().Tail()
+				// The separator is kept after the positional arguments.
+				if len(command) > 0 && command[0] == "--" {
+					command = command[1:]
+				}
+				if name == "" || len(command) == 0 {
+					return cli.ShowCommandHelp(c, "lock")
+				}
+				ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				lease, err := dkv.Lock(ctx, name, c.Duration("ttl"))
+				if err != nil {
+					return err
+				}
+				return runLocked(ctx, lease, command)
+			},
+		},
+		{
+			Name:  "queue",
+			Usage: "Manage the queues",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "enqueue",
+					Usage:     "Append a message to a queue, and print its ID",
+					ArgsUsage: "QUEUE BODY",
+					Action: func(c *cli.Context) error {
+						ctx := c.Context
+						queue := c.Args().Get(0)
+						body := c.Args().Get(1)
+						if queue == "" || body == "" {
+							return cli.ShowSubcommandHelp(c)
+						}
+						id, err := dkv.Enqueue(ctx, queue, body)
+						if err != nil {
+							return err
+						}
+						fmt.Println(id)
+						return nil
+					},
+				},
+				{
+					Name:      "dequeue",
+					Usage:     "Claim the first message of a queue, and print its ID, claim, deliveries and body",
+					ArgsUsage: "QUEUE",
+					Description: "The message is delivered again if it is not acknowledged before the visibility timeout.\n" +
+						"Fails if the queue is still empty after the wait.",
+					Flags: []cli.Flag{
+						&cli.DurationFlag{
+							Name:  "visibility-timeout",
+							Usage: "Duration of the claim",
+							Value: 30 * time.Second,
+						},
+						&cli.DurationFlag{
+							Name:  "wait",
+							Usage: "Maximum duration to wait for a message if the queue is empty",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						ctx := c.Context
+						queue := c.Args().First()
+						if queue == "" {
+							return cli.ShowSubcommandHelp(c)
+						}
+						message, err := dkv.Dequeue(ctx, queue, c.Duration("visibility-timeout"), c.Duration("wait"))
+						if err != nil {
+							return err
+						}
+						if message == nil {
+							return errors.New("queue is empty")
+						}
+						fmt.Printf(
+							"%d\t%d\t%d\t%s\n",
+							message.GetId(),
+							message.GetClaim(),
+							message.GetDeliveries(),
+							message.GetBody(),
+						)
+						return nil
+					},
+				},
+				{
+					Name:      "ack",
+					Usage:     "Acknowledge a claimed message, which deletes it",
+					ArgsUsage: "QUEUE ID CLAIM",
+					Action: func(c *cli.Context) error {
+						queue, message, err := parseClaim(c)
+						if err != nil || message == nil {
+							return err
+						}
+						_, err = dkv.Ack(c.Context, queue, message)
+						return err
+					},
+				},
+				{
+					Name:      "nack",
+					Usage:     "Requeue a claimed message at its position",
+					ArgsUsage: "QUEUE ID CLAIM",
+					Action: func(c *cli.Context) error {
+						queue, message, err := parseClaim(c)
+						if err != nil || message == nil {
+							return err
+						}
+						_, err = dkv.Nack(c.Context, queue, message)
+						return err
+					},
+				},
+				{
+					Name:      "peek",
+					Usage:     "List the first messages of a queue without claiming them",
+					ArgsUsage: "QUEUE",
+					Flags: []cli.Flag{
+						&cli.IntFlag{
+							Name:  "limit",
+							Usage: "Maximum number of messages",
+							Value: 10,
+						},
+					},
+					Action: func(c *cli.Context) error {
+						ctx := c.Context
+						queue := c.Args().First()
+						if queue == "" {
+							return cli.ShowSubcommandHelp(c)
+						}
+						messages, ready, claimed, err := dkv.Peek(ctx, queue, c.Int("limit"))
+						if err != nil {
+							return err
+						}
+						fmt.Printf("Ready: %d\n", ready)
+						fmt.Printf("Claimed: %d\n", claimed)
+						fmt.Println("ID\t| Deliveries\t| Body")
+						for _, message := range messages {
+							fmt.Printf("%d\t| %d\t| %s\n", message.GetId(), message.GetDeliveries(), message.GetBody())
+						}
+						return nil
+					},
+				},
+			},
+		},
 		{
 			Name:      "member-join",
 			Usage:     "Join the cluster",
 			ArgsUsage: "ID ADDRESS",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "cluster-token",
+					Usage: "Expected token of the cluster",
+				},
+			},
 			Action: func(c *cli.Context) error {
 				ctx := c.Context
 				id := c.Args().Get(0)
@@ -198,16 +360,7 @@ var app = &cli.App{
 				if id == "" || address == "" {
 					return cli.ShowCommandHelp(c, "member-join")
 				}
-				_, err := leaderMembershipClient.JoinServer(
-					ctx,
-					&connect.Request[dkvv1.JoinServerRequest]{
-						Msg: &dkvv1.JoinServerRequest{
-							Id:      id,
-							Address: address,
-						},
-					},
-				)
-				return err
+				return dkv.JoinServer(ctx, id, address, c.String("cluster-token"))
 			},
 		},
 		{
@@ -220,15 +373,7 @@ var app = &cli.App{
 				if id == "" {
 					return cli.ShowCommandHelp(c, "member-leave")
 				}
-				_, err := leaderMembershipClient.LeaveServer(
-					ctx,
-					&connect.Request[dkvv1.LeaveServerRequest]{
-						Msg: &dkvv1.LeaveServerRequest{
-							Id: id,
-						},
-					},
-				)
-				return err
+				return dkv.LeaveServer(ctx, id)
 			},
 		},
 		{
@@ -236,64 +381,188 @@ var app = &cli.App{
 			Usage: "List the cluster members",
 			Action: func(c *cli.Context) error {
 				ctx := c.Context
-				resp, err := membershipClient.GetServers(
-					ctx,
-					&connect.Request[dkvv1.GetServersRequest]{
-						Msg: &dkvv1.GetServersRequest{},
-					},
-				)
+				resp, err := dkv.GetServers(ctx)
 				if err != nil {
 					return err
 				}
-				fmt.Println("ID\t| Raft Address\t| RPC Address\t| Leader")
-				for _, server := range resp.Msg.GetServers() {
+				if token := resp.GetClusterToken(); token != "" {
+					fmt.Printf("Cluster token: %s\n", token)
+				}
+				fmt.Println("ID\t| Raft Address\t| RPC Address\t| Leader\t| Voter\t| Zone")
+				for _, server := range resp.GetServers() {
 					fmt.Printf(
-						"%s\t| %s\t| %s\t| %s\n",
+						"%s\t| %s\t| %s\t| %s\t| %s\t| %s\n",
 						server.GetId(),
 						server.GetRaftAddress(),
 						server.GetRpcAddress(),
 						strconv.FormatBool(server.GetIsLeader()),
+						strconv.FormatBool(server.GetIsVoter()),
+						server.GetZone(),
 					)
 				}
 				return nil
 			},
 		},
+		{
+			Name:  "member-health",
+			Usage: "Show the health of the cluster members, as seen by the leader",
+			Action: func(c *cli.Context) error {
+				ctx := c.Context
+				resp, err := dkv.GetClusterHealth(ctx)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Healthy: %t\n", resp.GetHealthy())
+				fmt.Printf("Failure tolerance: %d\n", resp.GetFailureTolerance())
+				fmt.Println("ID\t| Raft Address\t| Leader\t| Voter\t| Last Contact\t| Last Index\t| Term\t| Healthy\t| Stable Since")
+				for _, server := range resp.GetServers() {
+					stableSince := "-"
+					if server.GetStableSince() != nil {
+						stableSince = server.GetStableSince().AsTime().Format(time.RFC3339)
+					}
+					fmt.Printf(
+						"%s\t| %s\t| %t\t| %t\t| %s\t| %d\t| %d\t| %t\t| %s\n",
+						server.GetId(),
+						server.GetRaftAddress(),
+						server.GetIsLeader(),
+						server.GetIsVoter(),
+						server.GetLastContact().AsDuration().Round(time.Millisecond),
+						server.GetLastIndex(),
+						server.GetLastTerm(),
+						server.GetHealthy(),
+						stableSince,
+					)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "snapshot",
+			Usage: "Manage the snapshots of the store",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "save",
+					Usage:     "Save a snapshot of the store to a file",
+					ArgsUsage: "FILE",
+					Action: func(c *cli.Context) error {
+						ctx := c.Context
+						path := c.Args().First()
+						if path == "" {
+							return cli.ShowSubcommandHelp(c)
+						}
+						info, err := saveSnapshot(ctx, path)
+						if err != nil {
+							return err
+						}
+						printSnapshotInfo(info)
+						return nil
+					},
+				},
+				{
+					Name:      "status",
+					Usage:     "Verify and describe a snapshot file",
+					ArgsUsage: "FILE",
+					Action: func(c *cli.Context) error {
+						path := c.Args().First()
+						if path == "" {
+							return cli.ShowSubcommandHelp(c)
+						}
+						info, snapshot, err := backup.Open(path)
+						if err != nil {
+							return err
+						}
+						_ = snapshot.Close()
+						printSnapshotInfo(info)
+						return nil
+					},
+				},
+			},
+		},
 	},
 }
 
-func findEndpoint(ctx context.Context) (addr string) {
-	servers, err := membershipClient.GetServers(ctx, &connect.Request[dkvv1.GetServersRequest]{
-		Msg: &dkvv1.GetServersRequest{},
-	})
+// parseClaim parses the QUEUE ID CLAIM arguments of ack and nack. The message
+// is nil if the help was shown.
+func parseClaim(c *cli.Context) (string, *dkvv1.QueueMessage, error) {
+	queue := c.Args().Get(0)
+	if queue == "" || c.Args().Len() != 3 {
+		return "", nil, cli.ShowSubcommandHelp(c)
+	}
+	id, err := strconv.ParseInt(c.Args().Get(1), 10, 64)
 	if err != nil {
-		return ""
+		return "", nil, fmt.Errorf("invalid ID: %w", err)
 	}
-	// No server? Use the RPC that was provided.
-	if len(servers.Msg.GetServers()) == 0 {
-		return ""
+	claim, err := strconv.ParseInt(c.Args().Get(2), 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid claim: %w", err)
 	}
-	// Filter the server and only get the servers with RPC address
-	advertisedServers := make([]*dkvv1.Server, len(servers.Msg.GetServers()))
-	for _, server := range servers.Msg.GetServers() {
-		if server.GetRpcAddress() != "" {
-			advertisedServers = append(advertisedServers, server)
+	return queue, &dkvv1.QueueMessage{Id: id, Claim: claim}, nil
+}
+
+// runLocked runs a command while the lease of a lock is held, and releases the
+// lock once the command exits. The command is killed if the lease is lost.
+func runLocked(ctx context.Context, lease *client.Lease, command []string) error {
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lease.Done():
+			cancel()
+		case <-cmdCtx.Done():
 		}
+	}()
+	cmd := exec.CommandContext(cmdCtx, command[0], command[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(
+		os.Environ(),
+		"DKV_LOCK_KEY="+lease.Key,
+		"DKV_LOCK_REVISION="+strconv.FormatInt(lease.Revision, 10),
+	)
+	runErr := cmd.Run()
+	// The lock is released even if the command was interrupted.
+	if err := dkv.Unlock(context.WithoutCancel(ctx), lease); err != nil {
+		return errors.Join(runErr, err)
 	}
-	// No advertised server? Use the RPC that was provided.
-	if len(advertisedServers) == 0 {
-		return ""
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		return cli.Exit("", exitErr.ExitCode())
 	}
-	// Find the leader
-	for _, server := range advertisedServers {
-		// Request the first leader.
-		if server.GetIsLeader() {
-			return server.GetRpcAddress()
-		}
+	return runErr
+}
+
+// saveSnapshot streams a snapshot to a temporary file, verifies it and renames
+// it to path.
+func saveSnapshot(ctx context.Context, path string) (*backup.Info, error) {
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+	}()
+	if err := dkv.Snapshot(ctx, f); err != nil {
+		return nil, err
 	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	info, snapshot, err := backup.Open(tmp)
+	if err != nil {
+		return nil, err
+	}
+	_ = snapshot.Close()
+	return info, os.Rename(tmp, path)
+}
 
-	// No leader? Request random server.
-	idx := rand.Intn(len(advertisedServers))
-	return advertisedServers[idx].GetRpcAddress()
+func printSnapshotInfo(info *backup.Info) {
+	fmt.Printf("Index:\t%d\n", info.Index)
+	fmt.Printf("Term:\t%d\n", info.Term)
+	fmt.Printf("Keys:\t%d\n", info.Keys)
+	fmt.Printf("Size:\t%d\n", info.Size)
+	fmt.Printf("SHA256:\t%s\n", info.SHA256)
 }
 
 func main() {
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.31.0
+// 	protoc-gen-go v1.36.6
 // 	protoc        (unknown)
 // source: dkv/v1/dkv.proto
 
@@ -9,8 +9,11 @@ package dkvv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,37 +23,4467 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// CollectionType is the type of a collection.
+type CollectionType int32
+
+const (
+	CollectionType_COLLECTION_TYPE_UNSPECIFIED CollectionType = 0
+	CollectionType_COLLECTION_TYPE_HASH        CollectionType = 1
+	CollectionType_COLLECTION_TYPE_SET         CollectionType = 2
+	CollectionType_COLLECTION_TYPE_SORTED_SET  CollectionType = 3
+)
+
+// Enum value maps for CollectionType.
+var (
+	CollectionType_name = map[int32]string{
+		0: "COLLECTION_TYPE_UNSPECIFIED",
+		1: "COLLECTION_TYPE_HASH",
+		2: "COLLECTION_TYPE_SET",
+		3: "COLLECTION_TYPE_SORTED_SET",
+	}
+	CollectionType_value = map[string]int32{
+		"COLLECTION_TYPE_UNSPECIFIED": 0,
+		"COLLECTION_TYPE_HASH":        1,
+		"COLLECTION_TYPE_SET":         2,
+		"COLLECTION_TYPE_SORTED_SET":  3,
+	}
+)
+
+func (x CollectionType) Enum() *CollectionType {
+	p := new(CollectionType)
+	*p = x
+	return p
+}
+
+func (x CollectionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CollectionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[0].Descriptor()
+}
+
+func (CollectionType) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[0]
+}
+
+func (x CollectionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CollectionType.Descriptor instead.
+func (CollectionType) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{0}
+}
+
+type PutCommand_Condition int32
+
+const (
+	PutCommand_CONDITION_UNSPECIFIED PutCommand_Condition = 0
+	// None of the keys exists.
+	PutCommand_CONDITION_ABSENT PutCommand_Condition = 1
+	// All the keys exist.
+	PutCommand_CONDITION_EXISTS PutCommand_Condition = 2
+)
+
+// Enum value maps for PutCommand_Condition.
+var (
+	PutCommand_Condition_name = map[int32]string{
+		0: "CONDITION_UNSPECIFIED",
+		1: "CONDITION_ABSENT",
+		2: "CONDITION_EXISTS",
+	}
+	PutCommand_Condition_value = map[string]int32{
+		"CONDITION_UNSPECIFIED": 0,
+		"CONDITION_ABSENT":      1,
+		"CONDITION_EXISTS":      2,
+	}
+)
+
+func (x PutCommand_Condition) Enum() *PutCommand_Condition {
+	p := new(PutCommand_Condition)
+	*p = x
+	return p
+}
+
+func (x PutCommand_Condition) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PutCommand_Condition) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[1].Descriptor()
+}
+
+func (PutCommand_Condition) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[1]
+}
+
+func (x PutCommand_Condition) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PutCommand_Condition.Descriptor instead.
+func (PutCommand_Condition) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type Comparison_Target int32
+
+const (
+	Comparison_TARGET_VERSION         Comparison_Target = 0
+	Comparison_TARGET_CREATE_REVISION Comparison_Target = 1
+	Comparison_TARGET_MOD_REVISION    Comparison_Target = 2
+	Comparison_TARGET_VALUE           Comparison_Target = 3
+)
+
+// Enum value maps for Comparison_Target.
+var (
+	Comparison_Target_name = map[int32]string{
+		0: "TARGET_VERSION",
+		1: "TARGET_CREATE_REVISION",
+		2: "TARGET_MOD_REVISION",
+		3: "TARGET_VALUE",
+	}
+	Comparison_Target_value = map[string]int32{
+		"TARGET_VERSION":         0,
+		"TARGET_CREATE_REVISION": 1,
+		"TARGET_MOD_REVISION":    2,
+		"TARGET_VALUE":           3,
+	}
+)
+
+func (x Comparison_Target) Enum() *Comparison_Target {
+	p := new(Comparison_Target)
+	*p = x
+	return p
+}
+
+func (x Comparison_Target) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Comparison_Target) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[2].Descriptor()
+}
+
+func (Comparison_Target) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[2]
+}
+
+func (x Comparison_Target) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Comparison_Target.Descriptor instead.
+func (Comparison_Target) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{17, 0}
+}
+
+type Comparison_Result int32
+
+const (
+	Comparison_RESULT_EQUAL     Comparison_Result = 0
+	Comparison_RESULT_GREATER   Comparison_Result = 1
+	Comparison_RESULT_LESS      Comparison_Result = 2
+	Comparison_RESULT_NOT_EQUAL Comparison_Result = 3
+)
+
+// Enum value maps for Comparison_Result.
+var (
+	Comparison_Result_name = map[int32]string{
+		0: "RESULT_EQUAL",
+		1: "RESULT_GREATER",
+		2: "RESULT_LESS",
+		3: "RESULT_NOT_EQUAL",
+	}
+	Comparison_Result_value = map[string]int32{
+		"RESULT_EQUAL":     0,
+		"RESULT_GREATER":   1,
+		"RESULT_LESS":      2,
+		"RESULT_NOT_EQUAL": 3,
+	}
+)
+
+func (x Comparison_Result) Enum() *Comparison_Result {
+	p := new(Comparison_Result)
+	*p = x
+	return p
+}
+
+func (x Comparison_Result) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Comparison_Result) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[3].Descriptor()
+}
+
+func (Comparison_Result) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[3]
+}
+
+func (x Comparison_Result) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Comparison_Result.Descriptor instead.
+func (Comparison_Result) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{17, 1}
+}
+
+type RangeOperation_SortOrder int32
+
+const (
+	RangeOperation_SORT_ORDER_NONE    RangeOperation_SortOrder = 0
+	RangeOperation_SORT_ORDER_ASCEND  RangeOperation_SortOrder = 1
+	RangeOperation_SORT_ORDER_DESCEND RangeOperation_SortOrder = 2
+)
+
+// Enum value maps for RangeOperation_SortOrder.
+var (
+	RangeOperation_SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_NONE",
+		1: "SORT_ORDER_ASCEND",
+		2: "SORT_ORDER_DESCEND",
+	}
+	RangeOperation_SortOrder_value = map[string]int32{
+		"SORT_ORDER_NONE":    0,
+		"SORT_ORDER_ASCEND":  1,
+		"SORT_ORDER_DESCEND": 2,
+	}
+)
+
+func (x RangeOperation_SortOrder) Enum() *RangeOperation_SortOrder {
+	p := new(RangeOperation_SortOrder)
+	*p = x
+	return p
+}
+
+func (x RangeOperation_SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RangeOperation_SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[4].Descriptor()
+}
+
+func (RangeOperation_SortOrder) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[4]
+}
+
+func (x RangeOperation_SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RangeOperation_SortOrder.Descriptor instead.
+func (RangeOperation_SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{19, 0}
+}
+
+type RangeOperation_SortTarget int32
+
+const (
+	RangeOperation_SORT_TARGET_KEY             RangeOperation_SortTarget = 0
+	RangeOperation_SORT_TARGET_VERSION         RangeOperation_SortTarget = 1
+	RangeOperation_SORT_TARGET_CREATE_REVISION RangeOperation_SortTarget = 2
+	RangeOperation_SORT_TARGET_MOD_REVISION    RangeOperation_SortTarget = 3
+	RangeOperation_SORT_TARGET_VALUE           RangeOperation_SortTarget = 4
+)
+
+// Enum value maps for RangeOperation_SortTarget.
+var (
+	RangeOperation_SortTarget_name = map[int32]string{
+		0: "SORT_TARGET_KEY",
+		1: "SORT_TARGET_VERSION",
+		2: "SORT_TARGET_CREATE_REVISION",
+		3: "SORT_TARGET_MOD_REVISION",
+		4: "SORT_TARGET_VALUE",
+	}
+	RangeOperation_SortTarget_value = map[string]int32{
+		"SORT_TARGET_KEY":             0,
+		"SORT_TARGET_VERSION":         1,
+		"SORT_TARGET_CREATE_REVISION": 2,
+		"SORT_TARGET_MOD_REVISION":    3,
+		"SORT_TARGET_VALUE":           4,
+	}
+)
+
+func (x RangeOperation_SortTarget) Enum() *RangeOperation_SortTarget {
+	p := new(RangeOperation_SortTarget)
+	*p = x
+	return p
+}
+
+func (x RangeOperation_SortTarget) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RangeOperation_SortTarget) Descriptor() protoreflect.EnumDescriptor {
+	return file_dkv_v1_dkv_proto_enumTypes[5].Descriptor()
+}
+
+func (RangeOperation_SortTarget) Type() protoreflect.EnumType {
+	return &file_dkv_v1_dkv_proto_enumTypes[5]
+}
+
+func (x RangeOperation_SortTarget) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RangeOperation_SortTarget.Descriptor instead.
+func (RangeOperation_SortTarget) EnumDescriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{19, 1}
+}
+
 // Command is a message used in Raft to replicate log entries.
 type Command struct {
-	state         protoimpl.MessageState
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Command:
+	//
+	//	*Command_Set
+	//	*Command_Delete
+	//	*Command_ServerMetadata
+	//	*Command_Put
+	//	*Command_DeleteKeys
+	//	*Command_Increment
+	//	*Command_Barrier
+	//	*Command_Txn
+	//	*Command_KeepAlive
+	//	*Command_Enqueue
+	//	*Command_Dequeue
+	//	*Command_Ack
+	//	*Command_Nack
+	//	*Command_HashSet
+	//	*Command_SetAdd
+	//	*Command_SortedSetAdd
+	//	*Command_RemoveMembers
+	Command isCommand_Command `protobuf_oneof:"command"`
+	// Time of the command on the node proposing it, in Unix nanoseconds. The
+	// expirations are evaluated at this time, so that the nodes agree on them.
+	Time          int64 `protobuf:"varint,8,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Command) GetCommand() isCommand_Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Command) GetSet() *SetRequest {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Set); ok {
+			return x.Set
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetDelete() *DeleteRequest {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Delete); ok {
+			return x.Delete
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetServerMetadata() *ServerMetadata {
+	if x != nil {
+		if x, ok := x.Command.(*Command_ServerMetadata); ok {
+			return x.ServerMetadata
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetPut() *PutCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Put); ok {
+			return x.Put
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetDeleteKeys() *DeleteKeysCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_DeleteKeys); ok {
+			return x.DeleteKeys
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetIncrement() *IncrementCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Increment); ok {
+			return x.Increment
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetBarrier() *BarrierCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Barrier); ok {
+			return x.Barrier
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetTxn() *TxnCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Txn); ok {
+			return x.Txn
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetKeepAlive() *KeepAliveCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_KeepAlive); ok {
+			return x.KeepAlive
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetEnqueue() *EnqueueCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Enqueue); ok {
+			return x.Enqueue
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetDequeue() *DequeueCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Dequeue); ok {
+			return x.Dequeue
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetAck() *AckCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetNack() *NackCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_Nack); ok {
+			return x.Nack
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetHashSet() *HashSetCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_HashSet); ok {
+			return x.HashSet
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetSetAdd() *SetAddCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_SetAdd); ok {
+			return x.SetAdd
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetSortedSetAdd() *SortedSetAddCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_SortedSetAdd); ok {
+			return x.SortedSetAdd
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetRemoveMembers() *RemoveMembersCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_RemoveMembers); ok {
+			return x.RemoveMembers
+		}
+	}
+	return nil
+}
+
+func (x *Command) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type isCommand_Command interface {
+	isCommand_Command()
+}
+
+type Command_Set struct {
+	Set *SetRequest `protobuf:"bytes,1,opt,name=set,proto3,oneof"`
+}
+
+type Command_Delete struct {
+	Delete *DeleteRequest `protobuf:"bytes,2,opt,name=delete,proto3,oneof"`
+}
+
+type Command_ServerMetadata struct {
+	ServerMetadata *ServerMetadata `protobuf:"bytes,3,opt,name=server_metadata,json=serverMetadata,proto3,oneof"`
+}
+
+type Command_Put struct {
+	Put *PutCommand `protobuf:"bytes,4,opt,name=put,proto3,oneof"`
+}
+
+type Command_DeleteKeys struct {
+	DeleteKeys *DeleteKeysCommand `protobuf:"bytes,5,opt,name=delete_keys,json=deleteKeys,proto3,oneof"`
+}
+
+type Command_Increment struct {
+	Increment *IncrementCommand `protobuf:"bytes,6,opt,name=increment,proto3,oneof"`
+}
+
+type Command_Barrier struct {
+	Barrier *BarrierCommand `protobuf:"bytes,7,opt,name=barrier,proto3,oneof"`
+}
+
+type Command_Txn struct {
+	Txn *TxnCommand `protobuf:"bytes,9,opt,name=txn,proto3,oneof"`
+}
+
+type Command_KeepAlive struct {
+	KeepAlive *KeepAliveCommand `protobuf:"bytes,10,opt,name=keep_alive,json=keepAlive,proto3,oneof"`
+}
+
+type Command_Enqueue struct {
+	Enqueue *EnqueueCommand `protobuf:"bytes,11,opt,name=enqueue,proto3,oneof"`
+}
+
+type Command_Dequeue struct {
+	Dequeue *DequeueCommand `protobuf:"bytes,12,opt,name=dequeue,proto3,oneof"`
+}
+
+type Command_Ack struct {
+	Ack *AckCommand `protobuf:"bytes,13,opt,name=ack,proto3,oneof"`
+}
+
+type Command_Nack struct {
+	Nack *NackCommand `protobuf:"bytes,14,opt,name=nack,proto3,oneof"`
+}
+
+type Command_HashSet struct {
+	HashSet *HashSetCommand `protobuf:"bytes,15,opt,name=hash_set,json=hashSet,proto3,oneof"`
+}
+
+type Command_SetAdd struct {
+	SetAdd *SetAddCommand `protobuf:"bytes,16,opt,name=set_add,json=setAdd,proto3,oneof"`
+}
+
+type Command_SortedSetAdd struct {
+	SortedSetAdd *SortedSetAddCommand `protobuf:"bytes,17,opt,name=sorted_set_add,json=sortedSetAdd,proto3,oneof"`
+}
+
+type Command_RemoveMembers struct {
+	RemoveMembers *RemoveMembersCommand `protobuf:"bytes,18,opt,name=remove_members,json=removeMembers,proto3,oneof"`
+}
+
+func (*Command_Set) isCommand_Command() {}
+
+func (*Command_Delete) isCommand_Command() {}
+
+func (*Command_ServerMetadata) isCommand_Command() {}
+
+func (*Command_Put) isCommand_Command() {}
+
+func (*Command_DeleteKeys) isCommand_Command() {}
+
+func (*Command_Increment) isCommand_Command() {}
+
+func (*Command_Barrier) isCommand_Command() {}
+
+func (*Command_Txn) isCommand_Command() {}
+
+func (*Command_KeepAlive) isCommand_Command() {}
+
+func (*Command_Enqueue) isCommand_Command() {}
+
+func (*Command_Dequeue) isCommand_Command() {}
+
+func (*Command_Ack) isCommand_Command() {}
+
+func (*Command_Nack) isCommand_Command() {}
+
+func (*Command_HashSet) isCommand_Command() {}
+
+func (*Command_SetAdd) isCommand_Command() {}
+
+func (*Command_SortedSetAdd) isCommand_Command() {}
+
+func (*Command_RemoveMembers) isCommand_Command() {}
+
+// PutCommand writes the values of keys atomically, if its condition holds.
+type PutCommand struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Entries   []*KeyValue            `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Condition PutCommand_Condition   `protobuf:"varint,2,opt,name=condition,proto3,enum=dkv.v1.PutCommand_Condition" json:"condition,omitempty"`
+	// Expiration time of the keys, in Unix nanoseconds. Zero never expires the
+	// keys.
+	ExpireAt      int64 `protobuf:"varint,3,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutCommand) Reset() {
+	*x = PutCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCommand) ProtoMessage() {}
+
+func (x *PutCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCommand.ProtoReflect.Descriptor instead.
+func (*PutCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PutCommand) GetEntries() []*KeyValue {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *PutCommand) GetCondition() PutCommand_Condition {
+	if x != nil {
+		return x.Condition
+	}
+	return PutCommand_CONDITION_UNSPECIFIED
+}
+
+func (x *PutCommand) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+type KeyValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyValue) Reset() {
+	*x = KeyValue{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyValue) ProtoMessage() {}
+
+func (x *KeyValue) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyValue.ProtoReflect.Descriptor instead.
+func (*KeyValue) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *KeyValue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyValue) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// DeleteKeysCommand deletes keys and their collections atomically.
+type DeleteKeysCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Keys  []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// Only delete the keys expired at the time of the command.
+	ExpiredOnly   bool `protobuf:"varint,2,opt,name=expired_only,json=expiredOnly,proto3" json:"expired_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteKeysCommand) Reset() {
+	*x = DeleteKeysCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteKeysCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteKeysCommand) ProtoMessage() {}
+
+func (x *DeleteKeysCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteKeysCommand.ProtoReflect.Descriptor instead.
+func (*DeleteKeysCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeleteKeysCommand) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *DeleteKeysCommand) GetExpiredOnly() bool {
+	if x != nil {
+		return x.ExpiredOnly
+	}
+	return false
+}
+
+// KeepAliveCommand postpones the expiration of the keys which exist. The
+// values and the revisions of the keys are not changed.
+type KeepAliveCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Keys  []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// Expiration time of the keys, in Unix nanoseconds.
+	ExpireAt      int64 `protobuf:"varint,2,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeepAliveCommand) Reset() {
+	*x = KeepAliveCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeepAliveCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeepAliveCommand) ProtoMessage() {}
+
+func (x *KeepAliveCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeepAliveCommand.ProtoReflect.Descriptor instead.
+func (*KeepAliveCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *KeepAliveCommand) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *KeepAliveCommand) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+// EnqueueCommand appends a message to a queue. The ID of the message is the
+// Raft index of the command, which orders the messages.
+type EnqueueCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queue         string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueCommand) Reset() {
+	*x = EnqueueCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueCommand) ProtoMessage() {}
+
+func (x *EnqueueCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueCommand.ProtoReflect.Descriptor instead.
+func (*EnqueueCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EnqueueCommand) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *EnqueueCommand) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+// DequeueCommand claims the first message of a queue which is not claimed.
+// The claim of the message is the Raft index of the command.
+type DequeueCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Queue string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Expiration time of the claim, in Unix nanoseconds. The message is
+	// requeued once its claim expires.
+	ClaimUntil    int64 `protobuf:"varint,2,opt,name=claim_until,json=claimUntil,proto3" json:"claim_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DequeueCommand) Reset() {
+	*x = DequeueCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DequeueCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DequeueCommand) ProtoMessage() {}
+
+func (x *DequeueCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DequeueCommand.ProtoReflect.Descriptor instead.
+func (*DequeueCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DequeueCommand) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *DequeueCommand) GetClaimUntil() int64 {
+	if x != nil {
+		return x.ClaimUntil
+	}
+	return 0
+}
+
+// QueueClaim is a claimed message of a queue.
+type QueueClaim struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queue         string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Claim         int64                  `protobuf:"varint,3,opt,name=claim,proto3" json:"claim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueClaim) Reset() {
+	*x = QueueClaim{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueClaim) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueClaim) ProtoMessage() {}
+
+func (x *QueueClaim) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueClaim.ProtoReflect.Descriptor instead.
+func (*QueueClaim) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QueueClaim) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *QueueClaim) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QueueClaim) GetClaim() int64 {
+	if x != nil {
+		return x.Claim
+	}
+	return 0
+}
+
+// AckCommand deletes a claimed message.
+type AckCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Claim         *QueueClaim            `protobuf:"bytes,1,opt,name=claim,proto3" json:"claim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckCommand) Reset() {
+	*x = AckCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckCommand) ProtoMessage() {}
+
+func (x *AckCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckCommand.ProtoReflect.Descriptor instead.
+func (*AckCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AckCommand) GetClaim() *QueueClaim {
+	if x != nil {
+		return x.Claim
+	}
+	return nil
+}
+
+// NackCommand requeues claimed messages at their position in their queue.
+type NackCommand struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Claims []*QueueClaim          `protobuf:"bytes,1,rep,name=claims,proto3" json:"claims,omitempty"`
+	// Only requeue the messages whose claim expired at the time of the command.
+	ExpiredOnly   bool `protobuf:"varint,2,opt,name=expired_only,json=expiredOnly,proto3" json:"expired_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackCommand) Reset() {
+	*x = NackCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackCommand) ProtoMessage() {}
+
+func (x *NackCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackCommand.ProtoReflect.Descriptor instead.
+func (*NackCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NackCommand) GetClaims() []*QueueClaim {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+func (x *NackCommand) GetExpiredOnly() bool {
+	if x != nil {
+		return x.ExpiredOnly
+	}
+	return false
+}
+
+// HashSetCommand sets the values of fields of a hash. The number of created
+// fields is the count of the result.
+type HashSetCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Fields        []*KeyValue            `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HashSetCommand) Reset() {
+	*x = HashSetCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HashSetCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashSetCommand) ProtoMessage() {}
+
+func (x *HashSetCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashSetCommand.ProtoReflect.Descriptor instead.
+func (*HashSetCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HashSetCommand) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *HashSetCommand) GetFields() []*KeyValue {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// SetAddCommand adds members to a set. The number of added members is the
+// count of the result.
+type SetAddCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Members       []string               `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAddCommand) Reset() {
+	*x = SetAddCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAddCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAddCommand) ProtoMessage() {}
+
+func (x *SetAddCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAddCommand.ProtoReflect.Descriptor instead.
+func (*SetAddCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetAddCommand) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SetAddCommand) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// ScoredMember is a member of a sorted set and its score.
+type ScoredMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Member        string                 `protobuf:"bytes,1,opt,name=member,proto3" json:"member,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoredMember) Reset() {
+	*x = ScoredMember{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredMember) ProtoMessage() {}
+
+func (x *ScoredMember) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredMember.ProtoReflect.Descriptor instead.
+func (*ScoredMember) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ScoredMember) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+func (x *ScoredMember) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// SortedSetAddCommand sets the scores of members of a sorted set. The number
+// of added members is the count of the result.
+type SortedSetAddCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Members       []*ScoredMember        `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SortedSetAddCommand) Reset() {
+	*x = SortedSetAddCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SortedSetAddCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SortedSetAddCommand) ProtoMessage() {}
+
+func (x *SortedSetAddCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SortedSetAddCommand.ProtoReflect.Descriptor instead.
+func (*SortedSetAddCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SortedSetAddCommand) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SortedSetAddCommand) GetMembers() []*ScoredMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// RemoveMembersCommand deletes fields of a hash, or members of a set or of a
+// sorted set. The number of deleted members is the count of the result.
+type RemoveMembersCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          CollectionType         `protobuf:"varint,1,opt,name=type,proto3,enum=dkv.v1.CollectionType" json:"type,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Members       []string               `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMembersCommand) Reset() {
+	*x = RemoveMembersCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMembersCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMembersCommand) ProtoMessage() {}
+
+func (x *RemoveMembersCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMembersCommand.ProtoReflect.Descriptor instead.
+func (*RemoveMembersCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RemoveMembersCommand) GetType() CollectionType {
+	if x != nil {
+		return x.Type
+	}
+	return CollectionType_COLLECTION_TYPE_UNSPECIFIED
+}
+
+func (x *RemoveMembersCommand) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RemoveMembersCommand) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// IncrementCommand adds a delta to the integer value of a key. A missing key
+// is the initial value.
+type IncrementCommand struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Key     string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Delta   int64                  `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	Initial int64                  `protobuf:"varint,3,opt,name=initial,proto3" json:"initial,omitempty"`
+	// Bounds of the new value. The increment fails if it is out of bounds.
+	Min           *int64 `protobuf:"varint,4,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max           *int64 `protobuf:"varint,5,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementCommand) Reset() {
+	*x = IncrementCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementCommand) ProtoMessage() {}
+
+func (x *IncrementCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementCommand.ProtoReflect.Descriptor instead.
+func (*IncrementCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *IncrementCommand) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *IncrementCommand) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *IncrementCommand) GetInitial() int64 {
+	if x != nil {
+		return x.Initial
+	}
+	return 0
+}
+
+func (x *IncrementCommand) GetMin() int64 {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return 0
+}
+
+func (x *IncrementCommand) GetMax() int64 {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return 0
+}
+
+// TxnCommand executes operations atomically, depending on comparisons, like
+// the transactions of etcd. The revisions of the keys are the Raft indexes of
+// their writes.
+type TxnCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The success operations are executed if all the comparisons hold, the
+	// failure operations otherwise.
+	Compare       []*Comparison `protobuf:"bytes,1,rep,name=compare,proto3" json:"compare,omitempty"`
+	Success       []*Operation  `protobuf:"bytes,2,rep,name=success,proto3" json:"success,omitempty"`
+	Failure       []*Operation  `protobuf:"bytes,3,rep,name=failure,proto3" json:"failure,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnCommand) Reset() {
+	*x = TxnCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnCommand) ProtoMessage() {}
+
+func (x *TxnCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnCommand.ProtoReflect.Descriptor instead.
+func (*TxnCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TxnCommand) GetCompare() []*Comparison {
+	if x != nil {
+		return x.Compare
+	}
+	return nil
+}
+
+func (x *TxnCommand) GetSuccess() []*Operation {
+	if x != nil {
+		return x.Success
+	}
+	return nil
+}
+
+func (x *TxnCommand) GetFailure() []*Operation {
+	if x != nil {
+		return x.Failure
+	}
+	return nil
+}
+
+// Comparison compares a property of the keys of a range to a value. A missing
+// key has a zero version and revisions, and fails the value comparisons.
+type Comparison struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Range of keys [key, range_end). An empty range_end is the key only, and
+	// "\0" is all the keys greater than or equal to key.
+	Key      []byte            `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	RangeEnd []byte            `protobuf:"bytes,2,opt,name=range_end,json=rangeEnd,proto3" json:"range_end,omitempty"`
+	Target   Comparison_Target `protobuf:"varint,3,opt,name=target,proto3,enum=dkv.v1.Comparison_Target" json:"target,omitempty"`
+	Result   Comparison_Result `protobuf:"varint,4,opt,name=result,proto3,enum=dkv.v1.Comparison_Result" json:"result,omitempty"`
+	// Version or revision compared.
+	Number        int64  `protobuf:"varint,5,opt,name=number,proto3" json:"number,omitempty"`
+	Value         []byte `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Comparison) Reset() {
+	*x = Comparison{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comparison) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comparison) ProtoMessage() {}
+
+func (x *Comparison) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comparison.ProtoReflect.Descriptor instead.
+func (*Comparison) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Comparison) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *Comparison) GetRangeEnd() []byte {
+	if x != nil {
+		return x.RangeEnd
+	}
+	return nil
+}
+
+func (x *Comparison) GetTarget() Comparison_Target {
+	if x != nil {
+		return x.Target
+	}
+	return Comparison_TARGET_VERSION
+}
+
+func (x *Comparison) GetResult() Comparison_Result {
+	if x != nil {
+		return x.Result
+	}
+	return Comparison_RESULT_EQUAL
+}
+
+func (x *Comparison) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Comparison) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type Operation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Operation:
+	//
+	//	*Operation_Range
+	//	*Operation_Put
+	//	*Operation_DeleteRange
+	//	*Operation_Txn
+	Operation     isOperation_Operation `protobuf_oneof:"operation"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Operation) GetOperation() isOperation_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *Operation) GetRange() *RangeOperation {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Range); ok {
+			return x.Range
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetPut() *PutOperation {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Put); ok {
+			return x.Put
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetDeleteRange() *DeleteRangeOperation {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_DeleteRange); ok {
+			return x.DeleteRange
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetTxn() *TxnCommand {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Txn); ok {
+			return x.Txn
+		}
+	}
+	return nil
+}
+
+type isOperation_Operation interface {
+	isOperation_Operation()
+}
+
+type Operation_Range struct {
+	Range *RangeOperation `protobuf:"bytes,1,opt,name=range,proto3,oneof"`
+}
+
+type Operation_Put struct {
+	Put *PutOperation `protobuf:"bytes,2,opt,name=put,proto3,oneof"`
+}
+
+type Operation_DeleteRange struct {
+	DeleteRange *DeleteRangeOperation `protobuf:"bytes,3,opt,name=delete_range,json=deleteRange,proto3,oneof"`
+}
+
+type Operation_Txn struct {
+	Txn *TxnCommand `protobuf:"bytes,4,opt,name=txn,proto3,oneof"`
+}
+
+func (*Operation_Range) isOperation_Operation() {}
+
+func (*Operation_Put) isOperation_Operation() {}
+
+func (*Operation_DeleteRange) isOperation_Operation() {}
+
+func (*Operation_Txn) isOperation_Operation() {}
+
+type RangeOperation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Range of keys, like Comparison.
+	Key      []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	RangeEnd []byte `protobuf:"bytes,2,opt,name=range_end,json=rangeEnd,proto3" json:"range_end,omitempty"`
+	// Maximum number of keys returned. Zero is no limit.
+	Limit      int64                     `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	SortOrder  RangeOperation_SortOrder  `protobuf:"varint,4,opt,name=sort_order,json=sortOrder,proto3,enum=dkv.v1.RangeOperation_SortOrder" json:"sort_order,omitempty"`
+	SortTarget RangeOperation_SortTarget `protobuf:"varint,5,opt,name=sort_target,json=sortTarget,proto3,enum=dkv.v1.RangeOperation_SortTarget" json:"sort_target,omitempty"`
+	KeysOnly   bool                      `protobuf:"varint,6,opt,name=keys_only,json=keysOnly,proto3" json:"keys_only,omitempty"`
+	CountOnly  bool                      `protobuf:"varint,7,opt,name=count_only,json=countOnly,proto3" json:"count_only,omitempty"`
+	// Revision of the read. Zero is the current revision. The history of the
+	// keys is not kept: a past revision can only be read if no key changed
+	// since.
+	Revision      int64 `protobuf:"varint,8,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeOperation) Reset() {
+	*x = RangeOperation{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeOperation) ProtoMessage() {}
+
+func (x *RangeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeOperation.ProtoReflect.Descriptor instead.
+func (*RangeOperation) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RangeOperation) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RangeOperation) GetRangeEnd() []byte {
+	if x != nil {
+		return x.RangeEnd
+	}
+	return nil
+}
+
+func (x *RangeOperation) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RangeOperation) GetSortOrder() RangeOperation_SortOrder {
+	if x != nil {
+		return x.SortOrder
+	}
+	return RangeOperation_SORT_ORDER_NONE
+}
+
+func (x *RangeOperation) GetSortTarget() RangeOperation_SortTarget {
+	if x != nil {
+		return x.SortTarget
+	}
+	return RangeOperation_SORT_TARGET_KEY
+}
+
+func (x *RangeOperation) GetKeysOnly() bool {
+	if x != nil {
+		return x.KeysOnly
+	}
+	return false
+}
+
+func (x *RangeOperation) GetCountOnly() bool {
+	if x != nil {
+		return x.CountOnly
+	}
+	return false
+}
+
+func (x *RangeOperation) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type PutOperation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Return the key before the write.
+	PrevKv bool `protobuf:"varint,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	// Keep the value of the key, which must exist.
+	IgnoreValue bool `protobuf:"varint,4,opt,name=ignore_value,json=ignoreValue,proto3" json:"ignore_value,omitempty"`
+	// Media type of the value, kept until the next write of the key.
+	ContentType   string `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutOperation) Reset() {
+	*x = PutOperation{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutOperation) ProtoMessage() {}
+
+func (x *PutOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutOperation.ProtoReflect.Descriptor instead.
+func (*PutOperation) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PutOperation) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutOperation) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *PutOperation) GetPrevKv() bool {
+	if x != nil {
+		return x.PrevKv
+	}
+	return false
+}
+
+func (x *PutOperation) GetIgnoreValue() bool {
+	if x != nil {
+		return x.IgnoreValue
+	}
+	return false
+}
+
+func (x *PutOperation) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type DeleteRangeOperation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Range of keys, like Comparison.
+	Key      []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	RangeEnd []byte `protobuf:"bytes,2,opt,name=range_end,json=rangeEnd,proto3" json:"range_end,omitempty"`
+	// Return the deleted keys.
+	PrevKv        bool `protobuf:"varint,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRangeOperation) Reset() {
+	*x = DeleteRangeOperation{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRangeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRangeOperation) ProtoMessage() {}
+
+func (x *DeleteRangeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRangeOperation.ProtoReflect.Descriptor instead.
+func (*DeleteRangeOperation) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteRangeOperation) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *DeleteRangeOperation) GetRangeEnd() []byte {
+	if x != nil {
+		return x.RangeEnd
+	}
+	return nil
+}
+
+func (x *DeleteRangeOperation) GetPrevKv() bool {
+	if x != nil {
+		return x.PrevKv
+	}
+	return false
+}
+
+// RevisionedKeyValue is a key, its value and its revisions.
+type RevisionedKeyValue struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Revision of the creation of the key.
+	CreateRevision int64 `protobuf:"varint,3,opt,name=create_revision,json=createRevision,proto3" json:"create_revision,omitempty"`
+	// Revision of the last write of the key.
+	ModRevision int64 `protobuf:"varint,4,opt,name=mod_revision,json=modRevision,proto3" json:"mod_revision,omitempty"`
+	// Number of writes of the key since its creation.
+	Version int64 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// Media type of the value, if it was set by its last write.
+	ContentType   string `protobuf:"bytes,6,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevisionedKeyValue) Reset() {
+	*x = RevisionedKeyValue{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevisionedKeyValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevisionedKeyValue) ProtoMessage() {}
+
+func (x *RevisionedKeyValue) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevisionedKeyValue.ProtoReflect.Descriptor instead.
+func (*RevisionedKeyValue) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RevisionedKeyValue) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RevisionedKeyValue) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *RevisionedKeyValue) GetCreateRevision() int64 {
+	if x != nil {
+		return x.CreateRevision
+	}
+	return 0
+}
+
+func (x *RevisionedKeyValue) GetModRevision() int64 {
+	if x != nil {
+		return x.ModRevision
+	}
+	return 0
+}
+
+func (x *RevisionedKeyValue) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RevisionedKeyValue) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type TxnResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the comparisons hold.
+	Succeeded     bool               `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Results       []*OperationResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnResult) Reset() {
+	*x = TxnResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnResult) ProtoMessage() {}
+
+func (x *TxnResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnResult.ProtoReflect.Descriptor instead.
+func (*TxnResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TxnResult) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *TxnResult) GetResults() []*OperationResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type OperationResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*OperationResult_Range
+	//	*OperationResult_Put
+	//	*OperationResult_DeleteRange
+	//	*OperationResult_Txn
+	Result        isOperationResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationResult) Reset() {
+	*x = OperationResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationResult) ProtoMessage() {}
+
+func (x *OperationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationResult.ProtoReflect.Descriptor instead.
+func (*OperationResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *OperationResult) GetResult() isOperationResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *OperationResult) GetRange() *RangeResult {
+	if x != nil {
+		if x, ok := x.Result.(*OperationResult_Range); ok {
+			return x.Range
+		}
+	}
+	return nil
+}
+
+func (x *OperationResult) GetPut() *PutResult {
+	if x != nil {
+		if x, ok := x.Result.(*OperationResult_Put); ok {
+			return x.Put
+		}
+	}
+	return nil
+}
+
+func (x *OperationResult) GetDeleteRange() *DeleteRangeResult {
+	if x != nil {
+		if x, ok := x.Result.(*OperationResult_DeleteRange); ok {
+			return x.DeleteRange
+		}
+	}
+	return nil
+}
+
+func (x *OperationResult) GetTxn() *TxnResult {
+	if x != nil {
+		if x, ok := x.Result.(*OperationResult_Txn); ok {
+			return x.Txn
+		}
+	}
+	return nil
+}
+
+type isOperationResult_Result interface {
+	isOperationResult_Result()
+}
+
+type OperationResult_Range struct {
+	Range *RangeResult `protobuf:"bytes,1,opt,name=range,proto3,oneof"`
+}
+
+type OperationResult_Put struct {
+	Put *PutResult `protobuf:"bytes,2,opt,name=put,proto3,oneof"`
+}
+
+type OperationResult_DeleteRange struct {
+	DeleteRange *DeleteRangeResult `protobuf:"bytes,3,opt,name=delete_range,json=deleteRange,proto3,oneof"`
+}
+
+type OperationResult_Txn struct {
+	Txn *TxnResult `protobuf:"bytes,4,opt,name=txn,proto3,oneof"`
+}
+
+func (*OperationResult_Range) isOperationResult_Result() {}
+
+func (*OperationResult_Put) isOperationResult_Result() {}
+
+func (*OperationResult_DeleteRange) isOperationResult_Result() {}
+
+func (*OperationResult_Txn) isOperationResult_Result() {}
+
+type RangeResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kvs   []*RevisionedKeyValue  `protobuf:"bytes,1,rep,name=kvs,proto3" json:"kvs,omitempty"`
+	// Whether more keys are in the range than the limit.
+	More bool `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"`
+	// Number of keys in the range.
+	Count         int64 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeResult) Reset() {
+	*x = RangeResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeResult) ProtoMessage() {}
+
+func (x *RangeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeResult.ProtoReflect.Descriptor instead.
+func (*RangeResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RangeResult) GetKvs() []*RevisionedKeyValue {
+	if x != nil {
+		return x.Kvs
+	}
+	return nil
+}
+
+func (x *RangeResult) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+func (x *RangeResult) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type PutResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PrevKv        *RevisionedKeyValue    `protobuf:"bytes,1,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutResult) Reset() {
+	*x = PutResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutResult) ProtoMessage() {}
+
+func (x *PutResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResult.ProtoReflect.Descriptor instead.
+func (*PutResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PutResult) GetPrevKv() *RevisionedKeyValue {
+	if x != nil {
+		return x.PrevKv
+	}
+	return nil
+}
+
+type DeleteRangeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	PrevKvs       []*RevisionedKeyValue  `protobuf:"bytes,2,rep,name=prev_kvs,json=prevKvs,proto3" json:"prev_kvs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRangeResult) Reset() {
+	*x = DeleteRangeResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRangeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRangeResult) ProtoMessage() {}
+
+func (x *DeleteRangeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRangeResult.ProtoReflect.Descriptor instead.
+func (*DeleteRangeResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteRangeResult) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *DeleteRangeResult) GetPrevKvs() []*RevisionedKeyValue {
+	if x != nil {
+		return x.PrevKvs
+	}
+	return nil
+}
+
+// BarrierCommand is a no-op. A read served once its index is applied is
+// linearizable.
+type BarrierCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BarrierCommand) Reset() {
+	*x = BarrierCommand{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BarrierCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BarrierCommand) ProtoMessage() {}
+
+func (x *BarrierCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BarrierCommand.ProtoReflect.Descriptor instead.
+func (*BarrierCommand) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{28}
+}
+
+// ServerMetadata are the labels of a server, replicated in the Raft log.
+type ServerMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Zone of the server. There is at most one voter per zone.
+	Zone          string `protobuf:"bytes,2,opt,name=zone,proto3" json:"zone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerMetadata) Reset() {
+	*x = ServerMetadata{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMetadata) ProtoMessage() {}
+
+func (x *ServerMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMetadata.ProtoReflect.Descriptor instead.
+func (*ServerMetadata) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ServerMetadata) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ServerMetadata) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+// CommandResult is the result of a Command applied by the FSM.
+//
+// It is the FSM response of a Raft log entry, which is encoded so that it
+// survives the forwarding of the command to the leader.
+type CommandResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Error string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Number of keys written or deleted by the command. A conditional write
+	// which does not hold writes no key.
+	Count int64 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// Value of an incremented key.
+	Value int64 `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
+	// Result of a transaction.
+	Txn *TxnResult `protobuf:"bytes,5,opt,name=txn,proto3" json:"txn,omitempty"`
+	// Message claimed by a dequeue, if any.
+	Message       *QueueMessage `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandResult) Reset() {
+	*x = CommandResult{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandResult) ProtoMessage() {}
+
+func (x *CommandResult) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
+func (*CommandResult) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CommandResult) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CommandResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CommandResult) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *CommandResult) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *CommandResult) GetTxn() *TxnResult {
+	if x != nil {
+		return x.Txn
+	}
+	return nil
+}
+
+func (x *CommandResult) GetMessage() *QueueMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type GetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Maximum staleness of the value read from a follower. If unset, the local
+	// value is served whatever its staleness. Otherwise, a follower which cannot
+	// guarantee it fails with UNAVAILABLE, and the read can be retried.
+	MaxStaleness *durationpb.Duration `protobuf:"bytes,2,opt,name=max_staleness,json=maxStaleness,proto3" json:"max_staleness,omitempty"`
+	// Consistency token. If set, the node waits until it applied this index,
+	// and fails with UNAVAILABLE on timeout.
+	MinIndex uint64 `protobuf:"varint,3,opt,name=min_index,json=minIndex,proto3" json:"min_index,omitempty"`
+	// Serve the read on the leader once it confirmed its leadership and applied
+	// the entries committed before the read. Other nodes fail with UNAVAILABLE.
+	Linearizable  bool `protobuf:"varint,4,opt,name=linearizable,proto3" json:"linearizable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetRequest) GetMaxStaleness() *durationpb.Duration {
+	if x != nil {
+		return x.MaxStaleness
+	}
+	return nil
+}
+
+func (x *GetRequest) GetMinIndex() uint64 {
+	if x != nil {
+		return x.MinIndex
+	}
+	return 0
+}
+
+func (x *GetRequest) GetLinearizable() bool {
+	if x != nil {
+		return x.Linearizable
+	}
+	return false
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type SetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetRequest) Reset() {
+	*x = SetRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetRequest) ProtoMessage() {}
+
+func (x *SetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetRequest.ProtoReflect.Descriptor instead.
+func (*SetRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SetRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type SetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetResponse) Reset() {
+	*x = SetResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetResponse) ProtoMessage() {}
+
+func (x *SetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetResponse.ProtoReflect.Descriptor instead.
+func (*SetResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SetResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DeleteRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeleteResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type IncrementRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Delta int64                  `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// Value of a missing key, before the delta is added.
+	Initial int64 `protobuf:"varint,3,opt,name=initial,proto3" json:"initial,omitempty"`
+	// Bounds of the new value. The increment fails with OUT_OF_RANGE if it is
+	// out of bounds, like an overflow, and FAILED_PRECONDITION if the value is
+	// not an integer.
+	Min           *int64 `protobuf:"varint,4,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max           *int64 `protobuf:"varint,5,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementRequest) Reset() {
+	*x = IncrementRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementRequest) ProtoMessage() {}
+
+func (x *IncrementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementRequest.ProtoReflect.Descriptor instead.
+func (*IncrementRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *IncrementRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *IncrementRequest) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *IncrementRequest) GetInitial() int64 {
+	if x != nil {
+		return x.Initial
+	}
+	return 0
+}
+
+func (x *IncrementRequest) GetMin() int64 {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return 0
+}
+
+func (x *IncrementRequest) GetMax() int64 {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return 0
+}
+
+type IncrementResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// New value of the key.
+	Value int64 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementResponse) Reset() {
+	*x = IncrementResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementResponse) ProtoMessage() {}
+
+func (x *IncrementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementResponse.ProtoReflect.Descriptor instead.
+func (*IncrementResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *IncrementResponse) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *IncrementResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+// Lease is the key of a lock holder or of an election candidate.
+type Lease struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Create revision of the key: the fencing token of the holder.
+	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	// Value of a candidate.
+	Value         string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lease) Reset() {
+	*x = Lease{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lease) ProtoMessage() {}
+
+func (x *Lease) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lease.ProtoReflect.Descriptor instead.
+func (*Lease) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Lease) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Lease) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Lease) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type LockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// TTL of the lease.
+	Ttl           *durationpb.Duration `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockRequest) Reset() {
+	*x = LockRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockRequest) ProtoMessage() {}
+
+func (x *LockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
+func (*LockRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *LockRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LockRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type LockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lease         *Lease                 `protobuf:"bytes,1,opt,name=lease,proto3" json:"lease,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockResponse) Reset() {
+	*x = LockResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockResponse) ProtoMessage() {}
+
+func (x *LockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockResponse.ProtoReflect.Descriptor instead.
+func (*LockResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *LockResponse) GetLease() *Lease {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+type UnlockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Revision of the lease, which is only released if it is still held.
+	Revision      int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockRequest) Reset() {
+	*x = UnlockRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockRequest) ProtoMessage() {}
+
+func (x *UnlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockRequest.ProtoReflect.Descriptor instead.
+func (*UnlockRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *UnlockRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UnlockRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type UnlockResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the deletion of the lease.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockResponse) Reset() {
+	*x = UnlockResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockResponse) ProtoMessage() {}
+
+func (x *UnlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockResponse.ProtoReflect.Descriptor instead.
+func (*UnlockResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *UnlockResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type CampaignRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Value of the candidate, such as its address.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// TTL of the lease.
+	Ttl           *durationpb.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CampaignRequest) Reset() {
+	*x = CampaignRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CampaignRequest) ProtoMessage() {}
+
+func (x *CampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CampaignRequest.ProtoReflect.Descriptor instead.
+func (*CampaignRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CampaignRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CampaignRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *CampaignRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type CampaignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lease         *Lease                 `protobuf:"bytes,1,opt,name=lease,proto3" json:"lease,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CampaignResponse) Reset() {
+	*x = CampaignResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CampaignResponse) ProtoMessage() {}
+
+func (x *CampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CampaignResponse.ProtoReflect.Descriptor instead.
+func (*CampaignResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CampaignResponse) GetLease() *Lease {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+type ResignRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Revision of the lease, which is only released if it is still held.
+	Revision      int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResignRequest) Reset() {
+	*x = ResignRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResignRequest) ProtoMessage() {}
+
+func (x *ResignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResignRequest.ProtoReflect.Descriptor instead.
+func (*ResignRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ResignRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ResignRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type ResignResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the deletion of the lease.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResignResponse) Reset() {
+	*x = ResignResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResignResponse) ProtoMessage() {}
+
+func (x *ResignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResignResponse.ProtoReflect.Descriptor instead.
+func (*ResignResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ResignResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type ObserveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObserveRequest) Reset() {
+	*x = ObserveRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObserveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObserveRequest) ProtoMessage() {}
+
+func (x *ObserveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObserveRequest.ProtoReflect.Descriptor instead.
+func (*ObserveRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ObserveRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ObserveResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Lease of the leader, or nil if there is no leader.
+	Leader        *Lease `protobuf:"bytes,1,opt,name=leader,proto3" json:"leader,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObserveResponse) Reset() {
+	*x = ObserveResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObserveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObserveResponse) ProtoMessage() {}
+
+func (x *ObserveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObserveResponse.ProtoReflect.Descriptor instead.
+func (*ObserveResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ObserveResponse) GetLeader() *Lease {
+	if x != nil {
+		return x.Leader
+	}
+	return nil
+}
+
+type KeepAliveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Ttl           *durationpb.Duration   `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeepAliveRequest) Reset() {
+	*x = KeepAliveRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeepAliveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeepAliveRequest) ProtoMessage() {}
+
+func (x *KeepAliveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeepAliveRequest.ProtoReflect.Descriptor instead.
+func (*KeepAliveRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *KeepAliveRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeepAliveRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type KeepAliveResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the keep-alive.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeepAliveResponse) Reset() {
+	*x = KeepAliveResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeepAliveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeepAliveResponse) ProtoMessage() {}
+
+func (x *KeepAliveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeepAliveResponse.ProtoReflect.Descriptor instead.
+func (*KeepAliveResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *KeepAliveResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type QueueMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raft index of the enqueue of the message.
+	Id   int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Body string `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	// Raft index of the dequeue which claimed the message, which is the receipt
+	// of the consumer, or zero if the message is not claimed.
+	Claim int64 `protobuf:"varint,3,opt,name=claim,proto3" json:"claim,omitempty"`
+	// Number of times the message was claimed.
+	Deliveries    int64 `protobuf:"varint,4,opt,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueMessage) Reset() {
+	*x = QueueMessage{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueMessage) ProtoMessage() {}
+
+func (x *QueueMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueMessage.ProtoReflect.Descriptor instead.
+func (*QueueMessage) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *QueueMessage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QueueMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *QueueMessage) GetClaim() int64 {
+	if x != nil {
+		return x.Claim
+	}
+	return 0
+}
+
+func (x *QueueMessage) GetDeliveries() int64 {
+	if x != nil {
+		return x.Deliveries
+	}
+	return 0
+}
+
+type EnqueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queue         string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueRequest) Reset() {
+	*x = EnqueueRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueRequest) ProtoMessage() {}
+
+func (x *EnqueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueRequest.ProtoReflect.Descriptor instead.
+func (*EnqueueRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *EnqueueRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *EnqueueRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type EnqueueResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the write, which is the ID of the
+	// message.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueResponse) Reset() {
+	*x = EnqueueResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueResponse) ProtoMessage() {}
+
+func (x *EnqueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueResponse.ProtoReflect.Descriptor instead.
+func (*EnqueueResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *EnqueueResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type DequeueRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Queue string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Duration of the claim. Defaults to 30s.
+	VisibilityTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
+	// Maximum duration to wait for a message if the queue is empty. Zero
+	// returns immediately.
+	Wait          *durationpb.Duration `protobuf:"bytes,3,opt,name=wait,proto3" json:"wait,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DequeueRequest) Reset() {
+	*x = DequeueRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DequeueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DequeueRequest) ProtoMessage() {}
+
+func (x *DequeueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DequeueRequest.ProtoReflect.Descriptor instead.
+func (*DequeueRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DequeueRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *DequeueRequest) GetVisibilityTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return nil
+}
+
+func (x *DequeueRequest) GetWait() *durationpb.Duration {
+	if x != nil {
+		return x.Wait
+	}
+	return nil
+}
+
+type DequeueResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Claimed message, or nil if the queue is empty.
+	Message *QueueMessage `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DequeueResponse) Reset() {
+	*x = DequeueResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DequeueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DequeueResponse) ProtoMessage() {}
+
+func (x *DequeueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DequeueResponse.ProtoReflect.Descriptor instead.
+func (*DequeueResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *DequeueResponse) GetMessage() *QueueMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *DequeueResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type AckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queue         string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Claim         int64                  `protobuf:"varint,3,opt,name=claim,proto3" json:"claim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckRequest) Reset() {
+	*x = AckRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckRequest) ProtoMessage() {}
+
+func (x *AckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
+func (*AckRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *AckRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *AckRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AckRequest) GetClaim() int64 {
+	if x != nil {
+		return x.Claim
+	}
+	return 0
+}
+
+type AckResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResponse) Reset() {
+	*x = AckResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResponse) ProtoMessage() {}
+
+func (x *AckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
+func (*AckResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *AckResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type NackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queue         string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Claim         int64                  `protobuf:"varint,3,opt,name=claim,proto3" json:"claim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackRequest) Reset() {
+	*x = NackRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackRequest) ProtoMessage() {}
+
+func (x *NackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackRequest.ProtoReflect.Descriptor instead.
+func (*NackRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *NackRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *NackRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *NackRequest) GetClaim() int64 {
+	if x != nil {
+		return x.Claim
+	}
+	return 0
+}
+
+type NackResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Consistency token: the Raft index of the write.
+	Index         uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackResponse) Reset() {
+	*x = NackResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackResponse) ProtoMessage() {}
+
+func (x *NackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackResponse.ProtoReflect.Descriptor instead.
+func (*NackResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *NackResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type PeekRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Queue string                 `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Maximum number of messages. Defaults to 1.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Consistency token of the read, like GetRequest.
+	MinIndex      uint64 `protobuf:"varint,3,opt,name=min_index,json=minIndex,proto3" json:"min_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeekRequest) Reset() {
+	*x = PeekRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeekRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeekRequest) ProtoMessage() {}
+
+func (x *PeekRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeekRequest.ProtoReflect.Descriptor instead.
+func (*PeekRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *PeekRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *PeekRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PeekRequest) GetMinIndex() uint64 {
+	if x != nil {
+		return x.MinIndex
+	}
+	return 0
+}
+
+type PeekResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Messages []*QueueMessage        `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Number of messages which are not claimed.
+	Ready int64 `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Number of claimed messages.
+	Claimed       int64 `protobuf:"varint,3,opt,name=claimed,proto3" json:"claimed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeekResponse) Reset() {
+	*x = PeekResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeekResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeekResponse) ProtoMessage() {}
+
+func (x *PeekResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeekResponse.ProtoReflect.Descriptor instead.
+func (*PeekResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *PeekResponse) GetMessages() []*QueueMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *PeekResponse) GetReady() int64 {
+	if x != nil {
+		return x.Ready
+	}
+	return 0
+}
+
+func (x *PeekResponse) GetClaimed() int64 {
+	if x != nil {
+		return x.Claimed
+	}
+	return 0
+}
+
+type Server struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddress   string                 `protobuf:"bytes,2,opt,name=raft_address,json=raftAddress,proto3" json:"raft_address,omitempty"`
+	RpcAddress    string                 `protobuf:"bytes,3,opt,name=rpc_address,json=rpcAddress,proto3" json:"rpc_address,omitempty"`
+	IsLeader      bool                   `protobuf:"varint,4,opt,name=is_leader,json=isLeader,proto3" json:"is_leader,omitempty"`
+	Zone          string                 `protobuf:"bytes,5,opt,name=zone,proto3" json:"zone,omitempty"`
+	IsVoter       bool                   `protobuf:"varint,6,opt,name=is_voter,json=isVoter,proto3" json:"is_voter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server) Reset() {
+	*x = Server{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server) ProtoMessage() {}
+
+func (x *Server) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server.ProtoReflect.Descriptor instead.
+func (*Server) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *Server) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Server) GetRaftAddress() string {
+	if x != nil {
+		return x.RaftAddress
+	}
+	return ""
+}
+
+func (x *Server) GetRpcAddress() string {
+	if x != nil {
+		return x.RpcAddress
+	}
+	return ""
+}
+
+func (x *Server) GetIsLeader() bool {
+	if x != nil {
+		return x.IsLeader
+	}
+	return false
+}
+
+func (x *Server) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Server) GetIsVoter() bool {
+	if x != nil {
+		return x.IsVoter
+	}
+	return false
+}
+
+type GetServersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	// Types that are assignable to Command:
-	//
-	//	*Command_Set
-	//	*Command_Delete
-	Command isCommand_Command `protobuf_oneof:"command"`
+func (x *GetServersRequest) Reset() {
+	*x = GetServersRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Command) Reset() {
-	*x = Command{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[0]
+func (x *GetServersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServersRequest) ProtoMessage() {}
+
+func (x *GetServersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[64]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
+	return mi.MessageOf(x)
 }
 
-func (x *Command) String() string {
+// Deprecated: Use GetServersRequest.ProtoReflect.Descriptor instead.
+func (*GetServersRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{64}
+}
+
+type GetServersResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Servers []*Server              `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	// Token identifying the cluster, if any.
+	ClusterToken  string `protobuf:"bytes,2,opt,name=cluster_token,json=clusterToken,proto3" json:"cluster_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServersResponse) Reset() {
+	*x = GetServersResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Command) ProtoMessage() {}
+func (*GetServersResponse) ProtoMessage() {}
 
-func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *GetServersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[65]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -60,74 +4493,146 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Command.ProtoReflect.Descriptor instead.
-func (*Command) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use GetServersResponse.ProtoReflect.Descriptor instead.
+func (*GetServersResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{65}
 }
 
-func (m *Command) GetCommand() isCommand_Command {
-	if m != nil {
-		return m.Command
+func (x *GetServersResponse) GetServers() []*Server {
+	if x != nil {
+		return x.Servers
 	}
 	return nil
 }
 
-func (x *Command) GetSet() *SetRequest {
-	if x, ok := x.GetCommand().(*Command_Set); ok {
-		return x.Set
+func (x *GetServersResponse) GetClusterToken() string {
+	if x != nil {
+		return x.ClusterToken
 	}
-	return nil
+	return ""
 }
 
-func (x *Command) GetDelete() *DeleteRequest {
-	if x, ok := x.GetCommand().(*Command_Delete); ok {
-		return x.Delete
-	}
-	return nil
+type JoinServerRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// Token of the cluster to join. If set, it must match the token of the
+	// cluster.
+	ClusterToken  string `protobuf:"bytes,3,opt,name=cluster_token,json=clusterToken,proto3" json:"cluster_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type isCommand_Command interface {
-	isCommand_Command()
+func (x *JoinServerRequest) Reset() {
+	*x = JoinServerRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type Command_Set struct {
-	Set *SetRequest `protobuf:"bytes,1,opt,name=set,proto3,oneof"`
+func (x *JoinServerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type Command_Delete struct {
-	Delete *DeleteRequest `protobuf:"bytes,2,opt,name=delete,proto3,oneof"`
+func (*JoinServerRequest) ProtoMessage() {}
+
+func (x *JoinServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (*Command_Set) isCommand_Command() {}
+// Deprecated: Use JoinServerRequest.ProtoReflect.Descriptor instead.
+func (*JoinServerRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{66}
+}
 
-func (*Command_Delete) isCommand_Command() {}
+func (x *JoinServerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
 
-type GetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *JoinServerRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *JoinServerRequest) GetClusterToken() string {
+	if x != nil {
+		return x.ClusterToken
+	}
+	return ""
+}
+
+type JoinServerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+func (x *JoinServerResponse) Reset() {
+	*x = JoinServerResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetRequest) Reset() {
-	*x = GetRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[1]
+func (x *JoinServerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinServerResponse) ProtoMessage() {}
+
+func (x *JoinServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[67]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
+	return mi.MessageOf(x)
 }
 
-func (x *GetRequest) String() string {
+// Deprecated: Use JoinServerResponse.ProtoReflect.Descriptor instead.
+func (*JoinServerResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{67}
+}
+
+type LeaveServerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveServerRequest) Reset() {
+	*x = LeaveServerRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveServerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetRequest) ProtoMessage() {}
+func (*LeaveServerRequest) ProtoMessage() {}
 
-func (x *GetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *LeaveServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[68]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -137,44 +4642,87 @@ func (x *GetRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
-func (*GetRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use LeaveServerRequest.ProtoReflect.Descriptor instead.
+func (*LeaveServerRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *GetRequest) GetKey() string {
+func (x *LeaveServerRequest) GetId() string {
 	if x != nil {
-		return x.Key
+		return x.Id
 	}
 	return ""
 }
 
-type GetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type LeaveServerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveServerResponse) Reset() {
+	*x = LeaveServerResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+func (x *LeaveServerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *GetResponse) Reset() {
-	*x = GetResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[2]
+func (*LeaveServerResponse) ProtoMessage() {}
+
+func (x *LeaveServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[69]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
+	return mi.MessageOf(x)
 }
 
-func (x *GetResponse) String() string {
+// Deprecated: Use LeaveServerResponse.ProtoReflect.Descriptor instead.
+func (*LeaveServerResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{69}
+}
+
+type ServerHealth struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddress string                 `protobuf:"bytes,2,opt,name=raft_address,json=raftAddress,proto3" json:"raft_address,omitempty"`
+	IsLeader    bool                   `protobuf:"varint,3,opt,name=is_leader,json=isLeader,proto3" json:"is_leader,omitempty"`
+	IsVoter     bool                   `protobuf:"varint,4,opt,name=is_voter,json=isVoter,proto3" json:"is_voter,omitempty"`
+	// Duration since the last response of the server to the leader.
+	LastContact *durationpb.Duration `protobuf:"bytes,5,opt,name=last_contact,json=lastContact,proto3" json:"last_contact,omitempty"`
+	LastIndex   uint64               `protobuf:"varint,6,opt,name=last_index,json=lastIndex,proto3" json:"last_index,omitempty"`
+	LastTerm    uint64               `protobuf:"varint,7,opt,name=last_term,json=lastTerm,proto3" json:"last_term,omitempty"`
+	Healthy     bool                 `protobuf:"varint,8,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Time since which the server is healthy. Unset if unhealthy.
+	StableSince   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=stable_since,json=stableSince,proto3" json:"stable_since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerHealth) Reset() {
+	*x = ServerHealth{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerHealth) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetResponse) ProtoMessage() {}
+func (*ServerHealth) ProtoMessage() {}
 
-func (x *GetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *ServerHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[70]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -184,97 +4732,96 @@ func (x *GetResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
-func (*GetResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ServerHealth.ProtoReflect.Descriptor instead.
+func (*ServerHealth) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *GetResponse) GetValue() string {
+func (x *ServerHealth) GetId() string {
 	if x != nil {
-		return x.Value
+		return x.Id
 	}
 	return ""
 }
 
-type SetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+func (x *ServerHealth) GetRaftAddress() string {
+	if x != nil {
+		return x.RaftAddress
+	}
+	return ""
 }
 
-func (x *SetRequest) Reset() {
-	*x = SetRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *ServerHealth) GetIsLeader() bool {
+	if x != nil {
+		return x.IsLeader
 	}
+	return false
 }
 
-func (x *SetRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ServerHealth) GetIsVoter() bool {
+	if x != nil {
+		return x.IsVoter
+	}
+	return false
 }
 
-func (*SetRequest) ProtoMessage() {}
+func (x *ServerHealth) GetLastContact() *durationpb.Duration {
+	if x != nil {
+		return x.LastContact
+	}
+	return nil
+}
 
-func (x *SetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *ServerHealth) GetLastIndex() uint64 {
+	if x != nil {
+		return x.LastIndex
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use SetRequest.ProtoReflect.Descriptor instead.
-func (*SetRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{3}
+func (x *ServerHealth) GetLastTerm() uint64 {
+	if x != nil {
+		return x.LastTerm
+	}
+	return 0
 }
 
-func (x *SetRequest) GetKey() string {
+func (x *ServerHealth) GetHealthy() bool {
 	if x != nil {
-		return x.Key
+		return x.Healthy
 	}
-	return ""
+	return false
 }
 
-func (x *SetRequest) GetValue() string {
+func (x *ServerHealth) GetStableSince() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Value
+		return x.StableSince
 	}
-	return ""
+	return nil
 }
 
-type SetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type GetClusterHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SetResponse) Reset() {
-	*x = SetResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *GetClusterHealthRequest) Reset() {
+	*x = GetClusterHealthRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *SetResponse) String() string {
+func (x *GetClusterHealthRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetResponse) ProtoMessage() {}
+func (*GetClusterHealthRequest) ProtoMessage() {}
 
-func (x *SetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *GetClusterHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[71]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -284,37 +4831,37 @@ func (x *SetResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetResponse.ProtoReflect.Descriptor instead.
-func (*SetResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use GetClusterHealthRequest.ProtoReflect.Descriptor instead.
+func (*GetClusterHealthRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{71}
 }
 
-type DeleteRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+type GetClusterHealthResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Healthy bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Number of voters which can fail without losing the quorum.
+	FailureTolerance int32           `protobuf:"varint,2,opt,name=failure_tolerance,json=failureTolerance,proto3" json:"failure_tolerance,omitempty"`
+	Servers          []*ServerHealth `protobuf:"bytes,3,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *DeleteRequest) Reset() {
-	*x = DeleteRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *GetClusterHealthResponse) Reset() {
+	*x = GetClusterHealthResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRequest) String() string {
+func (x *GetClusterHealthResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRequest) ProtoMessage() {}
+func (*GetClusterHealthResponse) ProtoMessage() {}
 
-func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *GetClusterHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[72]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -324,42 +4871,54 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use GetClusterHealthResponse.ProtoReflect.Descriptor instead.
+func (*GetClusterHealthResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *DeleteRequest) GetKey() string {
+func (x *GetClusterHealthResponse) GetHealthy() bool {
 	if x != nil {
-		return x.Key
+		return x.Healthy
 	}
-	return ""
+	return false
 }
 
-type DeleteResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *GetClusterHealthResponse) GetFailureTolerance() int32 {
+	if x != nil {
+		return x.FailureTolerance
+	}
+	return 0
 }
 
-func (x *DeleteResponse) Reset() {
-	*x = DeleteResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *GetClusterHealthResponse) GetServers() []*ServerHealth {
+	if x != nil {
+		return x.Servers
 	}
+	return nil
 }
 
-func (x *DeleteResponse) String() string {
+type GetReadIndexRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadIndexRequest) Reset() {
+	*x = GetReadIndexRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadIndexRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteResponse) ProtoMessage() {}
+func (*GetReadIndexRequest) ProtoMessage() {}
 
-func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *GetReadIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[73]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -369,40 +4928,34 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
-func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use GetReadIndexRequest.ProtoReflect.Descriptor instead.
+func (*GetReadIndexRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{73}
 }
 
-type Server struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type GetReadIndexResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	RaftAddress string `protobuf:"bytes,2,opt,name=raft_address,json=raftAddress,proto3" json:"raft_address,omitempty"`
-	RpcAddress  string `protobuf:"bytes,3,opt,name=rpc_address,json=rpcAddress,proto3" json:"rpc_address,omitempty"`
-	IsLeader    bool   `protobuf:"varint,4,opt,name=is_leader,json=isLeader,proto3" json:"is_leader,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Server) Reset() {
-	*x = Server{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *GetReadIndexResponse) Reset() {
+	*x = GetReadIndexResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Server) String() string {
+func (x *GetReadIndexResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Server) ProtoMessage() {}
+func (*GetReadIndexResponse) ProtoMessage() {}
 
-func (x *Server) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *GetReadIndexResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[74]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -412,63 +4965,40 @@ func (x *Server) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Server.ProtoReflect.Descriptor instead.
-func (*Server) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *Server) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *Server) GetRaftAddress() string {
-	if x != nil {
-		return x.RaftAddress
-	}
-	return ""
-}
-
-func (x *Server) GetRpcAddress() string {
-	if x != nil {
-		return x.RpcAddress
-	}
-	return ""
+// Deprecated: Use GetReadIndexResponse.ProtoReflect.Descriptor instead.
+func (*GetReadIndexResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{74}
 }
 
-func (x *Server) GetIsLeader() bool {
+func (x *GetReadIndexResponse) GetIndex() uint64 {
 	if x != nil {
-		return x.IsLeader
+		return x.Index
 	}
-	return false
+	return 0
 }
 
-type GetServersRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type SnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServersRequest) Reset() {
-	*x = GetServersRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServersRequest) String() string {
+func (x *SnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServersRequest) ProtoMessage() {}
+func (*SnapshotRequest) ProtoMessage() {}
 
-func (x *GetServersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[75]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -478,37 +5008,34 @@ func (x *GetServersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServersRequest.ProtoReflect.Descriptor instead.
-func (*GetServersRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{75}
 }
 
-type GetServersResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type SnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Servers []*Server `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServersResponse) Reset() {
-	*x = GetServersResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServersResponse) String() string {
+func (x *SnapshotResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServersResponse) ProtoMessage() {}
+func (*SnapshotResponse) ProtoMessage() {}
 
-func (x *GetServersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[76]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -518,45 +5045,50 @@ func (x *GetServersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServersResponse.ProtoReflect.Descriptor instead.
-func (*GetServersResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *GetServersResponse) GetServers() []*Server {
+func (x *SnapshotResponse) GetChunk() []byte {
 	if x != nil {
-		return x.Servers
+		return x.Chunk
 	}
 	return nil
 }
 
-type JoinServerRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type SetFaultsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raft address of the peer.
+	PeerAddress string `protobuf:"bytes,1,opt,name=peer_address,json=peerAddress,proto3" json:"peer_address,omitempty"`
+	// Refuse the connections to the peer.
+	Partitioned bool `protobuf:"varint,2,opt,name=partitioned,proto3" json:"partitioned,omitempty"`
+	// Probability of dropping a write, and the rest of its connection.
+	DropRate float64 `protobuf:"fixed64,3,opt,name=drop_rate,json=dropRate,proto3" json:"drop_rate,omitempty"`
+	// Probability of resetting the connection on a write.
+	ResetRate     float64              `protobuf:"fixed64,4,opt,name=reset_rate,json=resetRate,proto3" json:"reset_rate,omitempty"`
+	Delay         *durationpb.Duration `protobuf:"bytes,5,opt,name=delay,proto3" json:"delay,omitempty"`
+	Jitter        *durationpb.Duration `protobuf:"bytes,6,opt,name=jitter,proto3" json:"jitter,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JoinServerRequest) Reset() {
-	*x = JoinServerRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[10]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *SetFaultsRequest) Reset() {
+	*x = SetFaultsRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *JoinServerRequest) String() string {
+func (x *SetFaultsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JoinServerRequest) ProtoMessage() {}
+func (*SetFaultsRequest) ProtoMessage() {}
 
-func (x *JoinServerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[10]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *SetFaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[77]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -566,49 +5098,75 @@ func (x *JoinServerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JoinServerRequest.ProtoReflect.Descriptor instead.
-func (*JoinServerRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use SetFaultsRequest.ProtoReflect.Descriptor instead.
+func (*SetFaultsRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{77}
 }
 
-func (x *JoinServerRequest) GetId() string {
+func (x *SetFaultsRequest) GetPeerAddress() string {
 	if x != nil {
-		return x.Id
+		return x.PeerAddress
 	}
 	return ""
 }
 
-func (x *JoinServerRequest) GetAddress() string {
+func (x *SetFaultsRequest) GetPartitioned() bool {
 	if x != nil {
-		return x.Address
+		return x.Partitioned
 	}
-	return ""
+	return false
 }
 
-type JoinServerResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *SetFaultsRequest) GetDropRate() float64 {
+	if x != nil {
+		return x.DropRate
+	}
+	return 0
 }
 
-func (x *JoinServerResponse) Reset() {
-	*x = JoinServerResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[11]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *SetFaultsRequest) GetResetRate() float64 {
+	if x != nil {
+		return x.ResetRate
 	}
+	return 0
 }
 
-func (x *JoinServerResponse) String() string {
+func (x *SetFaultsRequest) GetDelay() *durationpb.Duration {
+	if x != nil {
+		return x.Delay
+	}
+	return nil
+}
+
+func (x *SetFaultsRequest) GetJitter() *durationpb.Duration {
+	if x != nil {
+		return x.Jitter
+	}
+	return nil
+}
+
+type SetFaultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFaultsResponse) Reset() {
+	*x = SetFaultsResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFaultsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JoinServerResponse) ProtoMessage() {}
+func (*SetFaultsResponse) ProtoMessage() {}
 
-func (x *JoinServerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[11]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *SetFaultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[78]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -618,37 +5176,33 @@ func (x *JoinServerResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JoinServerResponse.ProtoReflect.Descriptor instead.
-func (*JoinServerResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use SetFaultsResponse.ProtoReflect.Descriptor instead.
+func (*SetFaultsResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{78}
 }
 
-type LeaveServerRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type ClearFaultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
-
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LeaveServerRequest) Reset() {
-	*x = LeaveServerRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[12]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *ClearFaultsRequest) Reset() {
+	*x = ClearFaultsRequest{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *LeaveServerRequest) String() string {
+func (x *ClearFaultsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LeaveServerRequest) ProtoMessage() {}
+func (*ClearFaultsRequest) ProtoMessage() {}
 
-func (x *LeaveServerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[12]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *ClearFaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[79]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -658,42 +5212,33 @@ func (x *LeaveServerRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LeaveServerRequest.ProtoReflect.Descriptor instead.
-func (*LeaveServerRequest) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *LeaveServerRequest) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
+// Deprecated: Use ClearFaultsRequest.ProtoReflect.Descriptor instead.
+func (*ClearFaultsRequest) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{79}
 }
 
-type LeaveServerResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type ClearFaultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LeaveServerResponse) Reset() {
-	*x = LeaveServerResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_dkv_v1_dkv_proto_msgTypes[13]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *ClearFaultsResponse) Reset() {
+	*x = ClearFaultsResponse{}
+	mi := &file_dkv_v1_dkv_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *LeaveServerResponse) String() string {
+func (x *ClearFaultsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LeaveServerResponse) ProtoMessage() {}
+func (*ClearFaultsResponse) ProtoMessage() {}
 
-func (x *LeaveServerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dkv_v1_dkv_proto_msgTypes[13]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *ClearFaultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dkv_v1_dkv_proto_msgTypes[80]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -703,141 +5248,608 @@ func (x *LeaveServerResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LeaveServerResponse.ProtoReflect.Descriptor instead.
-func (*LeaveServerResponse) Descriptor() ([]byte, []int) {
-	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ClearFaultsResponse.ProtoReflect.Descriptor instead.
+func (*ClearFaultsResponse) Descriptor() ([]byte, []int) {
+	return file_dkv_v1_dkv_proto_rawDescGZIP(), []int{80}
 }
 
 var File_dkv_v1_dkv_proto protoreflect.FileDescriptor
 
-var file_dkv_v1_dkv_proto_rawDesc = []byte{
-	0x0a, 0x10, 0x64, 0x6b, 0x76, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x6b, 0x76, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x06, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x22, 0x6d, 0x0a, 0x07, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x26, 0x0a, 0x03, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x03, 0x73, 0x65, 0x74, 0x12, 0x2f, 0x0a,
-	0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
-	0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x09,
-	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x1e, 0x0a, 0x0a, 0x47, 0x65, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x23, 0x0a, 0x0b, 0x47, 0x65, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x34,
-	0x0a, 0x0a, 0x53, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03,
-	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
-	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x21, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x10, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x79, 0x0a, 0x06, 0x53, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x66, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
-	0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x61, 0x66, 0x74, 0x41, 0x64,
-	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x70, 0x63, 0x41,
-	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x6c, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x4c, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x22, 0x13, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x53,
-	0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28,
-	0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x0e, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52,
-	0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x22, 0x3d, 0x0a, 0x11, 0x4a, 0x6f, 0x69, 0x6e,
-	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a,
-	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x14, 0x0a, 0x12, 0x4a, 0x6f, 0x69, 0x6e, 0x53,
-	0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x0a,
-	0x12, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x64, 0x22, 0x15, 0x0a, 0x13, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xa1, 0x01, 0x0a, 0x06, 0x44,
-	0x6b, 0x76, 0x41, 0x50, 0x49, 0x12, 0x2e, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x12, 0x2e, 0x64,
-	0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x13, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x03, 0x53, 0x65, 0x74, 0x12, 0x12, 0x2e, 0x64,
-	0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x13, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12,
-	0x15, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xe1,
-	0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x41, 0x50, 0x49,
-	0x12, 0x43, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x19,
-	0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x6b, 0x76, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x0a, 0x4a, 0x6f, 0x69, 0x6e, 0x53, 0x65, 0x72,
-	0x76, 0x65, 0x72, 0x12, 0x19, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x69,
-	0x6e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a,
-	0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0b, 0x4c, 0x65,
-	0x61, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x1a, 0x2e, 0x64, 0x6b, 0x76, 0x2e,
-	0x76, 0x31, 0x2e, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
-	0x65, 0x61, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x42, 0x70, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x2e, 0x64, 0x6b, 0x76, 0x2e, 0x76, 0x31,
-	0x42, 0x08, 0x44, 0x6b, 0x76, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1f, 0x64, 0x69,
-	0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x64, 0x2d, 0x6b, 0x76, 0x2f, 0x67, 0x65, 0x6e,
-	0x2f, 0x64, 0x6b, 0x76, 0x2f, 0x76, 0x31, 0x3b, 0x64, 0x6b, 0x76, 0x76, 0x31, 0xa2, 0x02, 0x03,
-	0x44, 0x58, 0x58, 0xaa, 0x02, 0x06, 0x44, 0x6b, 0x76, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x06, 0x44,
-	0x6b, 0x76, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x12, 0x44, 0x6b, 0x76, 0x5c, 0x56, 0x31, 0x5c, 0x47,
-	0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x07, 0x44, 0x6b, 0x76,
-	0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_dkv_v1_dkv_proto_rawDesc = "" +
+	"\n" +
+	"\x10dkv/v1/dkv.proto\x12\x06dkv.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa9\a\n" +
+	"\aCommand\x12&\n" +
+	"\x03set\x18\x01 \x01(\v2\x12.dkv.v1.SetRequestH\x00R\x03set\x12/\n" +
+	"\x06delete\x18\x02 \x01(\v2\x15.dkv.v1.DeleteRequestH\x00R\x06delete\x12A\n" +
+	"\x0fserver_metadata\x18\x03 \x01(\v2\x16.dkv.v1.ServerMetadataH\x00R\x0eserverMetadata\x12&\n" +
+	"\x03put\x18\x04 \x01(\v2\x12.dkv.v1.PutCommandH\x00R\x03put\x12<\n" +
+	"\vdelete_keys\x18\x05 \x01(\v2\x19.dkv.v1.DeleteKeysCommandH\x00R\n" +
+	"deleteKeys\x128\n" +
+	"\tincrement\x18\x06 \x01(\v2\x18.dkv.v1.IncrementCommandH\x00R\tincrement\x122\n" +
+	"\abarrier\x18\a \x01(\v2\x16.dkv.v1.BarrierCommandH\x00R\abarrier\x12&\n" +
+	"\x03txn\x18\t \x01(\v2\x12.dkv.v1.TxnCommandH\x00R\x03txn\x129\n" +
+	"\n" +
+	"keep_alive\x18\n" +
+	" \x01(\v2\x18.dkv.v1.KeepAliveCommandH\x00R\tkeepAlive\x122\n" +
+	"\aenqueue\x18\v \x01(\v2\x16.dkv.v1.EnqueueCommandH\x00R\aenqueue\x122\n" +
+	"\adequeue\x18\f \x01(\v2\x16.dkv.v1.DequeueCommandH\x00R\adequeue\x12&\n" +
+	"\x03ack\x18\r \x01(\v2\x12.dkv.v1.AckCommandH\x00R\x03ack\x12)\n" +
+	"\x04nack\x18\x0e \x01(\v2\x13.dkv.v1.NackCommandH\x00R\x04nack\x123\n" +
+	"\bhash_set\x18\x0f \x01(\v2\x16.dkv.v1.HashSetCommandH\x00R\ahashSet\x120\n" +
+	"\aset_add\x18\x10 \x01(\v2\x15.dkv.v1.SetAddCommandH\x00R\x06setAdd\x12C\n" +
+	"\x0esorted_set_add\x18\x11 \x01(\v2\x1b.dkv.v1.SortedSetAddCommandH\x00R\fsortedSetAdd\x12E\n" +
+	"\x0eremove_members\x18\x12 \x01(\v2\x1c.dkv.v1.RemoveMembersCommandH\x00R\rremoveMembers\x12\x12\n" +
+	"\x04time\x18\b \x01(\x03R\x04timeB\t\n" +
+	"\acommand\"\xe5\x01\n" +
+	"\n" +
+	"PutCommand\x12*\n" +
+	"\aentries\x18\x01 \x03(\v2\x10.dkv.v1.KeyValueR\aentries\x12:\n" +
+	"\tcondition\x18\x02 \x01(\x0e2\x1c.dkv.v1.PutCommand.ConditionR\tcondition\x12\x1b\n" +
+	"\texpire_at\x18\x03 \x01(\x03R\bexpireAt\"R\n" +
+	"\tCondition\x12\x19\n" +
+	"\x15CONDITION_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10CONDITION_ABSENT\x10\x01\x12\x14\n" +
+	"\x10CONDITION_EXISTS\x10\x02\"2\n" +
+	"\bKeyValue\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"P\n" +
+	"\x11DeleteKeysCommand\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12!\n" +
+	"\fexpired_only\x18\x02 \x01(\bR\vexpiredOnlyJ\x04\b\x03\x10\x04\"C\n" +
+	"\x10KeepAliveCommand\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12\x1b\n" +
+	"\texpire_at\x18\x02 \x01(\x03R\bexpireAt\":\n" +
+	"\x0eEnqueueCommand\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\"G\n" +
+	"\x0eDequeueCommand\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x1f\n" +
+	"\vclaim_until\x18\x02 \x01(\x03R\n" +
+	"claimUntil\"H\n" +
+	"\n" +
+	"QueueClaim\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05claim\x18\x03 \x01(\x03R\x05claim\"6\n" +
+	"\n" +
+	"AckCommand\x12(\n" +
+	"\x05claim\x18\x01 \x01(\v2\x12.dkv.v1.QueueClaimR\x05claim\"\\\n" +
+	"\vNackCommand\x12*\n" +
+	"\x06claims\x18\x01 \x03(\v2\x12.dkv.v1.QueueClaimR\x06claims\x12!\n" +
+	"\fexpired_only\x18\x02 \x01(\bR\vexpiredOnly\"L\n" +
+	"\x0eHashSetCommand\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x06fields\x18\x02 \x03(\v2\x10.dkv.v1.KeyValueR\x06fields\";\n" +
+	"\rSetAddCommand\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\amembers\x18\x02 \x03(\tR\amembers\"<\n" +
+	"\fScoredMember\x12\x16\n" +
+	"\x06member\x18\x01 \x01(\tR\x06member\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\"W\n" +
+	"\x13SortedSetAddCommand\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\amembers\x18\x02 \x03(\v2\x14.dkv.v1.ScoredMemberR\amembers\"n\n" +
+	"\x14RemoveMembersCommand\x12*\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x16.dkv.v1.CollectionTypeR\x04type\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x18\n" +
+	"\amembers\x18\x03 \x03(\tR\amembers\"\x92\x01\n" +
+	"\x10IncrementCommand\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x03R\x05delta\x12\x18\n" +
+	"\ainitial\x18\x03 \x01(\x03R\ainitial\x12\x15\n" +
+	"\x03min\x18\x04 \x01(\x03H\x00R\x03min\x88\x01\x01\x12\x15\n" +
+	"\x03max\x18\x05 \x01(\x03H\x01R\x03max\x88\x01\x01B\x06\n" +
+	"\x04_minB\x06\n" +
+	"\x04_max\"\x94\x01\n" +
+	"\n" +
+	"TxnCommand\x12,\n" +
+	"\acompare\x18\x01 \x03(\v2\x12.dkv.v1.ComparisonR\acompare\x12+\n" +
+	"\asuccess\x18\x02 \x03(\v2\x11.dkv.v1.OperationR\asuccess\x12+\n" +
+	"\afailure\x18\x03 \x03(\v2\x11.dkv.v1.OperationR\afailure\"\x8b\x03\n" +
+	"\n" +
+	"Comparison\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x1b\n" +
+	"\trange_end\x18\x02 \x01(\fR\brangeEnd\x121\n" +
+	"\x06target\x18\x03 \x01(\x0e2\x19.dkv.v1.Comparison.TargetR\x06target\x121\n" +
+	"\x06result\x18\x04 \x01(\x0e2\x19.dkv.v1.Comparison.ResultR\x06result\x12\x16\n" +
+	"\x06number\x18\x05 \x01(\x03R\x06number\x12\x14\n" +
+	"\x05value\x18\x06 \x01(\fR\x05value\"c\n" +
+	"\x06Target\x12\x12\n" +
+	"\x0eTARGET_VERSION\x10\x00\x12\x1a\n" +
+	"\x16TARGET_CREATE_REVISION\x10\x01\x12\x17\n" +
+	"\x13TARGET_MOD_REVISION\x10\x02\x12\x10\n" +
+	"\fTARGET_VALUE\x10\x03\"U\n" +
+	"\x06Result\x12\x10\n" +
+	"\fRESULT_EQUAL\x10\x00\x12\x12\n" +
+	"\x0eRESULT_GREATER\x10\x01\x12\x0f\n" +
+	"\vRESULT_LESS\x10\x02\x12\x14\n" +
+	"\x10RESULT_NOT_EQUAL\x10\x03\"\xdd\x01\n" +
+	"\tOperation\x12.\n" +
+	"\x05range\x18\x01 \x01(\v2\x16.dkv.v1.RangeOperationH\x00R\x05range\x12(\n" +
+	"\x03put\x18\x02 \x01(\v2\x14.dkv.v1.PutOperationH\x00R\x03put\x12A\n" +
+	"\fdelete_range\x18\x03 \x01(\v2\x1c.dkv.v1.DeleteRangeOperationH\x00R\vdeleteRange\x12&\n" +
+	"\x03txn\x18\x04 \x01(\v2\x12.dkv.v1.TxnCommandH\x00R\x03txnB\v\n" +
+	"\toperation\"\x96\x04\n" +
+	"\x0eRangeOperation\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x1b\n" +
+	"\trange_end\x18\x02 \x01(\fR\brangeEnd\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12?\n" +
+	"\n" +
+	"sort_order\x18\x04 \x01(\x0e2 .dkv.v1.RangeOperation.SortOrderR\tsortOrder\x12B\n" +
+	"\vsort_target\x18\x05 \x01(\x0e2!.dkv.v1.RangeOperation.SortTargetR\n" +
+	"sortTarget\x12\x1b\n" +
+	"\tkeys_only\x18\x06 \x01(\bR\bkeysOnly\x12\x1d\n" +
+	"\n" +
+	"count_only\x18\a \x01(\bR\tcountOnly\x12\x1a\n" +
+	"\brevision\x18\b \x01(\x03R\brevision\"O\n" +
+	"\tSortOrder\x12\x13\n" +
+	"\x0fSORT_ORDER_NONE\x10\x00\x12\x15\n" +
+	"\x11SORT_ORDER_ASCEND\x10\x01\x12\x16\n" +
+	"\x12SORT_ORDER_DESCEND\x10\x02\"\x90\x01\n" +
+	"\n" +
+	"SortTarget\x12\x13\n" +
+	"\x0fSORT_TARGET_KEY\x10\x00\x12\x17\n" +
+	"\x13SORT_TARGET_VERSION\x10\x01\x12\x1f\n" +
+	"\x1bSORT_TARGET_CREATE_REVISION\x10\x02\x12\x1c\n" +
+	"\x18SORT_TARGET_MOD_REVISION\x10\x03\x12\x15\n" +
+	"\x11SORT_TARGET_VALUE\x10\x04\"\x95\x01\n" +
+	"\fPutOperation\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x17\n" +
+	"\aprev_kv\x18\x03 \x01(\bR\x06prevKv\x12!\n" +
+	"\fignore_value\x18\x04 \x01(\bR\vignoreValue\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\"^\n" +
+	"\x14DeleteRangeOperation\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x1b\n" +
+	"\trange_end\x18\x02 \x01(\fR\brangeEnd\x12\x17\n" +
+	"\aprev_kv\x18\x03 \x01(\bR\x06prevKv\"\xc5\x01\n" +
+	"\x12RevisionedKeyValue\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12'\n" +
+	"\x0fcreate_revision\x18\x03 \x01(\x03R\x0ecreateRevision\x12!\n" +
+	"\fmod_revision\x18\x04 \x01(\x03R\vmodRevision\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12!\n" +
+	"\fcontent_type\x18\x06 \x01(\tR\vcontentType\"\\\n" +
+	"\tTxnResult\x12\x1c\n" +
+	"\tsucceeded\x18\x01 \x01(\bR\tsucceeded\x121\n" +
+	"\aresults\x18\x02 \x03(\v2\x17.dkv.v1.OperationResultR\aresults\"\xd6\x01\n" +
+	"\x0fOperationResult\x12+\n" +
+	"\x05range\x18\x01 \x01(\v2\x13.dkv.v1.RangeResultH\x00R\x05range\x12%\n" +
+	"\x03put\x18\x02 \x01(\v2\x11.dkv.v1.PutResultH\x00R\x03put\x12>\n" +
+	"\fdelete_range\x18\x03 \x01(\v2\x19.dkv.v1.DeleteRangeResultH\x00R\vdeleteRange\x12%\n" +
+	"\x03txn\x18\x04 \x01(\v2\x11.dkv.v1.TxnResultH\x00R\x03txnB\b\n" +
+	"\x06result\"e\n" +
+	"\vRangeResult\x12,\n" +
+	"\x03kvs\x18\x01 \x03(\v2\x1a.dkv.v1.RevisionedKeyValueR\x03kvs\x12\x12\n" +
+	"\x04more\x18\x02 \x01(\bR\x04more\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\"@\n" +
+	"\tPutResult\x123\n" +
+	"\aprev_kv\x18\x01 \x01(\v2\x1a.dkv.v1.RevisionedKeyValueR\x06prevKv\"d\n" +
+	"\x11DeleteRangeResult\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\x125\n" +
+	"\bprev_kvs\x18\x02 \x03(\v2\x1a.dkv.v1.RevisionedKeyValueR\aprevKvs\"\x10\n" +
+	"\x0eBarrierCommand\"4\n" +
+	"\x0eServerMetadata\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04zone\x18\x02 \x01(\tR\x04zone\"\xbc\x01\n" +
+	"\rCommandResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x03R\x05value\x12#\n" +
+	"\x03txn\x18\x05 \x01(\v2\x11.dkv.v1.TxnResultR\x03txn\x12.\n" +
+	"\amessage\x18\x06 \x01(\v2\x14.dkv.v1.QueueMessageR\amessage\"\x9f\x01\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12>\n" +
+	"\rmax_staleness\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\fmaxStaleness\x12\x1b\n" +
+	"\tmin_index\x18\x03 \x01(\x04R\bminIndex\x12\"\n" +
+	"\flinearizable\x18\x04 \x01(\bR\flinearizable\"#\n" +
+	"\vGetResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"4\n" +
+	"\n" +
+	"SetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"#\n" +
+	"\vSetResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"!\n" +
+	"\rDeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"&\n" +
+	"\x0eDeleteResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"\x92\x01\n" +
+	"\x10IncrementRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x03R\x05delta\x12\x18\n" +
+	"\ainitial\x18\x03 \x01(\x03R\ainitial\x12\x15\n" +
+	"\x03min\x18\x04 \x01(\x03H\x00R\x03min\x88\x01\x01\x12\x15\n" +
+	"\x03max\x18\x05 \x01(\x03H\x01R\x03max\x88\x01\x01B\x06\n" +
+	"\x04_minB\x06\n" +
+	"\x04_max\"?\n" +
+	"\x11IncrementResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x03R\x05value\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\"K\n" +
+	"\x05Lease\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"N\n" +
+	"\vLockRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x03ttl\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"3\n" +
+	"\fLockResponse\x12#\n" +
+	"\x05lease\x18\x01 \x01(\v2\r.dkv.v1.LeaseR\x05lease\"=\n" +
+	"\rUnlockRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\"&\n" +
+	"\x0eUnlockResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"h\n" +
+	"\x0fCampaignRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12+\n" +
+	"\x03ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"7\n" +
+	"\x10CampaignResponse\x12#\n" +
+	"\x05lease\x18\x01 \x01(\v2\r.dkv.v1.LeaseR\x05lease\"=\n" +
+	"\rResignRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\"&\n" +
+	"\x0eResignResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"$\n" +
+	"\x0eObserveRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"8\n" +
+	"\x0fObserveResponse\x12%\n" +
+	"\x06leader\x18\x01 \x01(\v2\r.dkv.v1.LeaseR\x06leader\"Q\n" +
+	"\x10KeepAliveRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12+\n" +
+	"\x03ttl\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\")\n" +
+	"\x11KeepAliveResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"h\n" +
+	"\fQueueMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\x12\x14\n" +
+	"\x05claim\x18\x03 \x01(\x03R\x05claim\x12\x1e\n" +
+	"\n" +
+	"deliveries\x18\x04 \x01(\x03R\n" +
+	"deliveries\":\n" +
+	"\x0eEnqueueRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\"'\n" +
+	"\x0fEnqueueResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"\x9f\x01\n" +
+	"\x0eDequeueRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12H\n" +
+	"\x12visibility_timeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x11visibilityTimeout\x12-\n" +
+	"\x04wait\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x04wait\"W\n" +
+	"\x0fDequeueResponse\x12.\n" +
+	"\amessage\x18\x01 \x01(\v2\x14.dkv.v1.QueueMessageR\amessage\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\"H\n" +
+	"\n" +
+	"AckRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05claim\x18\x03 \x01(\x03R\x05claim\"#\n" +
+	"\vAckResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"I\n" +
+	"\vNackRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05claim\x18\x03 \x01(\x03R\x05claim\"$\n" +
+	"\fNackResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"V\n" +
+	"\vPeekRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1b\n" +
+	"\tmin_index\x18\x03 \x01(\x04R\bminIndex\"p\n" +
+	"\fPeekResponse\x120\n" +
+	"\bmessages\x18\x01 \x03(\v2\x14.dkv.v1.QueueMessageR\bmessages\x12\x14\n" +
+	"\x05ready\x18\x02 \x01(\x03R\x05ready\x12\x18\n" +
+	"\aclaimed\x18\x03 \x01(\x03R\aclaimed\"\xa8\x01\n" +
+	"\x06Server\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fraft_address\x18\x02 \x01(\tR\vraftAddress\x12\x1f\n" +
+	"\vrpc_address\x18\x03 \x01(\tR\n" +
+	"rpcAddress\x12\x1b\n" +
+	"\tis_leader\x18\x04 \x01(\bR\bisLeader\x12\x12\n" +
+	"\x04zone\x18\x05 \x01(\tR\x04zone\x12\x19\n" +
+	"\bis_voter\x18\x06 \x01(\bR\aisVoter\"\x13\n" +
+	"\x11GetServersRequest\"c\n" +
+	"\x12GetServersResponse\x12(\n" +
+	"\aservers\x18\x01 \x03(\v2\x0e.dkv.v1.ServerR\aservers\x12#\n" +
+	"\rcluster_token\x18\x02 \x01(\tR\fclusterToken\"b\n" +
+	"\x11JoinServerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12#\n" +
+	"\rcluster_token\x18\x03 \x01(\tR\fclusterToken\"\x14\n" +
+	"\x12JoinServerResponse\"$\n" +
+	"\x12LeaveServerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13LeaveServerResponse\"\xcc\x02\n" +
+	"\fServerHealth\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fraft_address\x18\x02 \x01(\tR\vraftAddress\x12\x1b\n" +
+	"\tis_leader\x18\x03 \x01(\bR\bisLeader\x12\x19\n" +
+	"\bis_voter\x18\x04 \x01(\bR\aisVoter\x12<\n" +
+	"\flast_contact\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vlastContact\x12\x1d\n" +
+	"\n" +
+	"last_index\x18\x06 \x01(\x04R\tlastIndex\x12\x1b\n" +
+	"\tlast_term\x18\a \x01(\x04R\blastTerm\x12\x18\n" +
+	"\ahealthy\x18\b \x01(\bR\ahealthy\x12=\n" +
+	"\fstable_since\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vstableSince\"\x19\n" +
+	"\x17GetClusterHealthRequest\"\x91\x01\n" +
+	"\x18GetClusterHealthResponse\x12\x18\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12+\n" +
+	"\x11failure_tolerance\x18\x02 \x01(\x05R\x10failureTolerance\x12.\n" +
+	"\aservers\x18\x03 \x03(\v2\x14.dkv.v1.ServerHealthR\aservers\"\x15\n" +
+	"\x13GetReadIndexRequest\",\n" +
+	"\x14GetReadIndexResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\"\x11\n" +
+	"\x0fSnapshotRequest\"(\n" +
+	"\x10SnapshotResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"\xf7\x01\n" +
+	"\x10SetFaultsRequest\x12!\n" +
+	"\fpeer_address\x18\x01 \x01(\tR\vpeerAddress\x12 \n" +
+	"\vpartitioned\x18\x02 \x01(\bR\vpartitioned\x12\x1b\n" +
+	"\tdrop_rate\x18\x03 \x01(\x01R\bdropRate\x12\x1d\n" +
+	"\n" +
+	"reset_rate\x18\x04 \x01(\x01R\tresetRate\x12/\n" +
+	"\x05delay\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x05delay\x121\n" +
+	"\x06jitter\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x06jitter\"\x13\n" +
+	"\x11SetFaultsResponse\"\x14\n" +
+	"\x12ClearFaultsRequest\"\x15\n" +
+	"\x13ClearFaultsResponse*\x84\x01\n" +
+	"\x0eCollectionType\x12\x1f\n" +
+	"\x1bCOLLECTION_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14COLLECTION_TYPE_HASH\x10\x01\x12\x17\n" +
+	"\x13COLLECTION_TYPE_SET\x10\x02\x12\x1e\n" +
+	"\x1aCOLLECTION_TYPE_SORTED_SET\x10\x032\xe3\x01\n" +
+	"\x06DkvAPI\x12.\n" +
+	"\x03Get\x12\x12.dkv.v1.GetRequest\x1a\x13.dkv.v1.GetResponse\x12.\n" +
+	"\x03Set\x12\x12.dkv.v1.SetRequest\x1a\x13.dkv.v1.SetResponse\x127\n" +
+	"\x06Delete\x12\x15.dkv.v1.DeleteRequest\x1a\x16.dkv.v1.DeleteResponse\x12@\n" +
+	"\tIncrement\x12\x18.dkv.v1.IncrementRequest\x1a\x19.dkv.v1.IncrementResponse2\xed\x02\n" +
+	"\aLockAPI\x121\n" +
+	"\x04Lock\x12\x13.dkv.v1.LockRequest\x1a\x14.dkv.v1.LockResponse\x127\n" +
+	"\x06Unlock\x12\x15.dkv.v1.UnlockRequest\x1a\x16.dkv.v1.UnlockResponse\x12=\n" +
+	"\bCampaign\x12\x17.dkv.v1.CampaignRequest\x1a\x18.dkv.v1.CampaignResponse\x127\n" +
+	"\x06Resign\x12\x15.dkv.v1.ResignRequest\x1a\x16.dkv.v1.ResignResponse\x12<\n" +
+	"\aObserve\x12\x16.dkv.v1.ObserveRequest\x1a\x17.dkv.v1.ObserveResponse0\x01\x12@\n" +
+	"\tKeepAlive\x12\x18.dkv.v1.KeepAliveRequest\x1a\x19.dkv.v1.KeepAliveResponse2\x98\x02\n" +
+	"\bQueueAPI\x12:\n" +
+	"\aEnqueue\x12\x16.dkv.v1.EnqueueRequest\x1a\x17.dkv.v1.EnqueueResponse\x12:\n" +
+	"\aDequeue\x12\x16.dkv.v1.DequeueRequest\x1a\x17.dkv.v1.DequeueResponse\x12.\n" +
+	"\x03Ack\x12\x12.dkv.v1.AckRequest\x1a\x13.dkv.v1.AckResponse\x121\n" +
+	"\x04Nack\x12\x13.dkv.v1.NackRequest\x1a\x14.dkv.v1.NackResponse\x121\n" +
+	"\x04Peek\x12\x13.dkv.v1.PeekRequest\x1a\x14.dkv.v1.PeekResponse2\x83\x03\n" +
+	"\rMembershipAPI\x12C\n" +
+	"\n" +
+	"GetServers\x12\x19.dkv.v1.GetServersRequest\x1a\x1a.dkv.v1.GetServersResponse\x12C\n" +
+	"\n" +
+	"JoinServer\x12\x19.dkv.v1.JoinServerRequest\x1a\x1a.dkv.v1.JoinServerResponse\x12F\n" +
+	"\vLeaveServer\x12\x1a.dkv.v1.LeaveServerRequest\x1a\x1b.dkv.v1.LeaveServerResponse\x12U\n" +
+	"\x10GetClusterHealth\x12\x1f.dkv.v1.GetClusterHealthRequest\x1a .dkv.v1.GetClusterHealthResponse\x12I\n" +
+	"\fGetReadIndex\x12\x1b.dkv.v1.GetReadIndexRequest\x1a\x1c.dkv.v1.GetReadIndexResponse2\xd5\x01\n" +
+	"\bAdminAPI\x12?\n" +
+	"\bSnapshot\x12\x17.dkv.v1.SnapshotRequest\x1a\x18.dkv.v1.SnapshotResponse0\x01\x12@\n" +
+	"\tSetFaults\x12\x18.dkv.v1.SetFaultsRequest\x1a\x19.dkv.v1.SetFaultsResponse\x12F\n" +
+	"\vClearFaults\x12\x1a.dkv.v1.ClearFaultsRequest\x1a\x1b.dkv.v1.ClearFaultsResponseB!Z\x1fdistributed-kv/gen/dkv/v1;dkvv1b\x06proto3"
 
 var (
 	file_dkv_v1_dkv_proto_rawDescOnce sync.Once
-	file_dkv_v1_dkv_proto_rawDescData = file_dkv_v1_dkv_proto_rawDesc
+	file_dkv_v1_dkv_proto_rawDescData []byte
 )
 
 func file_dkv_v1_dkv_proto_rawDescGZIP() []byte {
 	file_dkv_v1_dkv_proto_rawDescOnce.Do(func() {
-		file_dkv_v1_dkv_proto_rawDescData = protoimpl.X.CompressGZIP(file_dkv_v1_dkv_proto_rawDescData)
+		file_dkv_v1_dkv_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dkv_v1_dkv_proto_rawDesc), len(file_dkv_v1_dkv_proto_rawDesc)))
 	})
 	return file_dkv_v1_dkv_proto_rawDescData
 }
 
-var file_dkv_v1_dkv_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
-var file_dkv_v1_dkv_proto_goTypes = []interface{}{
-	(*Command)(nil),             // 0: dkv.v1.Command
-	(*GetRequest)(nil),          // 1: dkv.v1.GetRequest
-	(*GetResponse)(nil),         // 2: dkv.v1.GetResponse
-	(*SetRequest)(nil),          // 3: dkv.v1.SetRequest
-	(*SetResponse)(nil),         // 4: dkv.v1.SetResponse
-	(*DeleteRequest)(nil),       // 5: dkv.v1.DeleteRequest
-	(*DeleteResponse)(nil),      // 6: dkv.v1.DeleteResponse
-	(*Server)(nil),              // 7: dkv.v1.Server
-	(*GetServersRequest)(nil),   // 8: dkv.v1.GetServersRequest
-	(*GetServersResponse)(nil),  // 9: dkv.v1.GetServersResponse
-	(*JoinServerRequest)(nil),   // 10: dkv.v1.JoinServerRequest
-	(*JoinServerResponse)(nil),  // 11: dkv.v1.JoinServerResponse
-	(*LeaveServerRequest)(nil),  // 12: dkv.v1.LeaveServerRequest
-	(*LeaveServerResponse)(nil), // 13: dkv.v1.LeaveServerResponse
+var file_dkv_v1_dkv_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_dkv_v1_dkv_proto_msgTypes = make([]protoimpl.MessageInfo, 81)
+var file_dkv_v1_dkv_proto_goTypes = []any{
+	(CollectionType)(0),              // 0: dkv.v1.CollectionType
+	(PutCommand_Condition)(0),        // 1: dkv.v1.PutCommand.Condition
+	(Comparison_Target)(0),           // 2: dkv.v1.Comparison.Target
+	(Comparison_Result)(0),           // 3: dkv.v1.Comparison.Result
+	(RangeOperation_SortOrder)(0),    // 4: dkv.v1.RangeOperation.SortOrder
+	(RangeOperation_SortTarget)(0),   // 5: dkv.v1.RangeOperation.SortTarget
+	(*Command)(nil),                  // 6: dkv.v1.Command
+	(*PutCommand)(nil),               // 7: dkv.v1.PutCommand
+	(*KeyValue)(nil),                 // 8: dkv.v1.KeyValue
+	(*DeleteKeysCommand)(nil),        // 9: dkv.v1.DeleteKeysCommand
+	(*KeepAliveCommand)(nil),         // 10: dkv.v1.KeepAliveCommand
+	(*EnqueueCommand)(nil),           // 11: dkv.v1.EnqueueCommand
+	(*DequeueCommand)(nil),           // 12: dkv.v1.DequeueCommand
+	(*QueueClaim)(nil),               // 13: dkv.v1.QueueClaim
+	(*AckCommand)(nil),               // 14: dkv.v1.AckCommand
+	(*NackCommand)(nil),              // 15: dkv.v1.NackCommand
+	(*HashSetCommand)(nil),           // 16: dkv.v1.HashSetCommand
+	(*SetAddCommand)(nil),            // 17: dkv.v1.SetAddCommand
+	(*ScoredMember)(nil),             // 18: dkv.v1.ScoredMember
+	(*SortedSetAddCommand)(nil),      // 19: dkv.v1.SortedSetAddCommand
+	(*RemoveMembersCommand)(nil),     // 20: dkv.v1.RemoveMembersCommand
+	(*IncrementCommand)(nil),         // 21: dkv.v1.IncrementCommand
+	(*TxnCommand)(nil),               // 22: dkv.v1.TxnCommand
+	(*Comparison)(nil),               // 23: dkv.v1.Comparison
+	(*Operation)(nil),                // 24: dkv.v1.Operation
+	(*RangeOperation)(nil),           // 25: dkv.v1.RangeOperation
+	(*PutOperation)(nil),             // 26: dkv.v1.PutOperation
+	(*DeleteRangeOperation)(nil),     // 27: dkv.v1.DeleteRangeOperation
+	(*RevisionedKeyValue)(nil),       // 28: dkv.v1.RevisionedKeyValue
+	(*TxnResult)(nil),                // 29: dkv.v1.TxnResult
+	(*OperationResult)(nil),          // 30: dkv.v1.OperationResult
+	(*RangeResult)(nil),              // 31: dkv.v1.RangeResult
+	(*PutResult)(nil),                // 32: dkv.v1.PutResult
+	(*DeleteRangeResult)(nil),        // 33: dkv.v1.DeleteRangeResult
+	(*BarrierCommand)(nil),           // 34: dkv.v1.BarrierCommand
+	(*ServerMetadata)(nil),           // 35: dkv.v1.ServerMetadata
+	(*CommandResult)(nil),            // 36: dkv.v1.CommandResult
+	(*GetRequest)(nil),               // 37: dkv.v1.GetRequest
+	(*GetResponse)(nil),              // 38: dkv.v1.GetResponse
+	(*SetRequest)(nil),               // 39: dkv.v1.SetRequest
+	(*SetResponse)(nil),              // 40: dkv.v1.SetResponse
+	(*DeleteRequest)(nil),            // 41: dkv.v1.DeleteRequest
+	(*DeleteResponse)(nil),           // 42: dkv.v1.DeleteResponse
+	(*IncrementRequest)(nil),         // 43: dkv.v1.IncrementRequest
+	(*IncrementResponse)(nil),        // 44: dkv.v1.IncrementResponse
+	(*Lease)(nil),                    // 45: dkv.v1.Lease
+	(*LockRequest)(nil),              // 46: dkv.v1.LockRequest
+	(*LockResponse)(nil),             // 47: dkv.v1.LockResponse
+	(*UnlockRequest)(nil),            // 48: dkv.v1.UnlockRequest
+	(*UnlockResponse)(nil),           // 49: dkv.v1.UnlockResponse
+	(*CampaignRequest)(nil),          // 50: dkv.v1.CampaignRequest
+	(*CampaignResponse)(nil),         // 51: dkv.v1.CampaignResponse
+	(*ResignRequest)(nil),            // 52: dkv.v1.ResignRequest
+	(*ResignResponse)(nil),           // 53: dkv.v1.ResignResponse
+	(*ObserveRequest)(nil),           // 54: dkv.v1.ObserveRequest
+	(*ObserveResponse)(nil),          // 55: dkv.v1.ObserveResponse
+	(*KeepAliveRequest)(nil),         // 56: dkv.v1.KeepAliveRequest
+	(*KeepAliveResponse)(nil),        // 57: dkv.v1.KeepAliveResponse
+	(*QueueMessage)(nil),             // 58: dkv.v1.QueueMessage
+	(*EnqueueRequest)(nil),           // 59: dkv.v1.EnqueueRequest
+	(*EnqueueResponse)(nil),          // 60: dkv.v1.EnqueueResponse
+	(*DequeueRequest)(nil),           // 61: dkv.v1.DequeueRequest
+	(*DequeueResponse)(nil),          // 62: dkv.v1.DequeueResponse
+	(*AckRequest)(nil),               // 63: dkv.v1.AckRequest
+	(*AckResponse)(nil),              // 64: dkv.v1.AckResponse
+	(*NackRequest)(nil),              // 65: dkv.v1.NackRequest
+	(*NackResponse)(nil),             // 66: dkv.v1.NackResponse
+	(*PeekRequest)(nil),              // 67: dkv.v1.PeekRequest
+	(*PeekResponse)(nil),             // 68: dkv.v1.PeekResponse
+	(*Server)(nil),                   // 69: dkv.v1.Server
+	(*GetServersRequest)(nil),        // 70: dkv.v1.GetServersRequest
+	(*GetServersResponse)(nil),       // 71: dkv.v1.GetServersResponse
+	(*JoinServerRequest)(nil),        // 72: dkv.v1.JoinServerRequest
+	(*JoinServerResponse)(nil),       // 73: dkv.v1.JoinServerResponse
+	(*LeaveServerRequest)(nil),       // 74: dkv.v1.LeaveServerRequest
+	(*LeaveServerResponse)(nil),      // 75: dkv.v1.LeaveServerResponse
+	(*ServerHealth)(nil),             // 76: dkv.v1.ServerHealth
+	(*GetClusterHealthRequest)(nil),  // 77: dkv.v1.GetClusterHealthRequest
+	(*GetClusterHealthResponse)(nil), // 78: dkv.v1.GetClusterHealthResponse
+	(*GetReadIndexRequest)(nil),      // 79: dkv.v1.GetReadIndexRequest
+	(*GetReadIndexResponse)(nil),     // 80: dkv.v1.GetReadIndexResponse
+	(*SnapshotRequest)(nil),          // 81: dkv.v1.SnapshotRequest
+	(*SnapshotResponse)(nil),         // 82: dkv.v1.SnapshotResponse
+	(*SetFaultsRequest)(nil),         // 83: dkv.v1.SetFaultsRequest
+	(*SetFaultsResponse)(nil),        // 84: dkv.v1.SetFaultsResponse
+	(*ClearFaultsRequest)(nil),       // 85: dkv.v1.ClearFaultsRequest
+	(*ClearFaultsResponse)(nil),      // 86: dkv.v1.ClearFaultsResponse
+	(*durationpb.Duration)(nil),      // 87: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),    // 88: google.protobuf.Timestamp
 }
 var file_dkv_v1_dkv_proto_depIdxs = []int32{
-	3,  // 0: dkv.v1.Command.set:type_name -> dkv.v1.SetRequest
-	5,  // 1: dkv.v1.Command.delete:type_name -> dkv.v1.DeleteRequest
-	7,  // 2: dkv.v1.GetServersResponse.servers:type_name -> dkv.v1.Server
-	1,  // 3: dkv.v1.DkvAPI.Get:input_type -> dkv.v1.GetRequest
-	3,  // 4: dkv.v1.DkvAPI.Set:input_type -> dkv.v1.SetRequest
-	5,  // 5: dkv.v1.DkvAPI.Delete:input_type -> dkv.v1.DeleteRequest
-	8,  // 6: dkv.v1.MembershipAPI.GetServers:input_type -> dkv.v1.GetServersRequest
-	10, // 7: dkv.v1.MembershipAPI.JoinServer:input_type -> dkv.v1.JoinServerRequest
-	12, // 8: dkv.v1.MembershipAPI.LeaveServer:input_type -> dkv.v1.LeaveServerRequest
-	2,  // 9: dkv.v1.DkvAPI.Get:output_type -> dkv.v1.GetResponse
-	4,  // 10: dkv.v1.DkvAPI.Set:output_type -> dkv.v1.SetResponse
-	6,  // 11: dkv.v1.DkvAPI.Delete:output_type -> dkv.v1.DeleteResponse
-	9,  // 12: dkv.v1.MembershipAPI.GetServers:output_type -> dkv.v1.GetServersResponse
-	11, // 13: dkv.v1.MembershipAPI.JoinServer:output_type -> dkv.v1.JoinServerResponse
-	13, // 14: dkv.v1.MembershipAPI.LeaveServer:output_type -> dkv.v1.LeaveServerResponse
-	9,  // [9:15] is the sub-list for method output_type
-	3,  // [3:9] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	39, // 0: dkv.v1.Command.set:type_name -> dkv.v1.SetRequest
+	41, // 1: dkv.v1.Command.delete:type_name -> dkv.v1.DeleteRequest
+	35, // 2: dkv.v1.Command.server_metadata:type_name -> dkv.v1.ServerMetadata
+	7,  // 3: dkv.v1.Command.put:type_name -> dkv.v1.PutCommand
+	9,  // 4: dkv.v1.Command.delete_keys:type_name -> dkv.v1.DeleteKeysCommand
+	21, // 5: dkv.v1.Command.increment:type_name -> dkv.v1.IncrementCommand
+	34, // 6: dkv.v1.Command.barrier:type_name -> dkv.v1.BarrierCommand
+	22, // 7: dkv.v1.Command.txn:type_name -> dkv.v1.TxnCommand
+	10, // 8: dkv.v1.Command.keep_alive:type_name -> dkv.v1.KeepAliveCommand
+	11, // 9: dkv.v1.Command.enqueue:type_name -> dkv.v1.EnqueueCommand
+	12, // 10: dkv.v1.Command.dequeue:type_name -> dkv.v1.DequeueCommand
+	14, // 11: dkv.v1.Command.ack:type_name -> dkv.v1.AckCommand
+	15, // 12: dkv.v1.Command.nack:type_name -> dkv.v1.NackCommand
+	16, // 13: dkv.v1.Command.hash_set:type_name -> dkv.v1.HashSetCommand
+	17, // 14: dkv.v1.Command.set_add:type_name -> dkv.v1.SetAddCommand
+	19, // 15: dkv.v1.Command.sorted_set_add:type_name -> dkv.v1.SortedSetAddCommand
+	20, // 16: dkv.v1.Command.remove_members:type_name -> dkv.v1.RemoveMembersCommand
+	8,  // 17: dkv.v1.PutCommand.entries:type_name -> dkv.v1.KeyValue
+	1,  // 18: dkv.v1.PutCommand.condition:type_name -> dkv.v1.PutCommand.Condition
+	13, // 19: dkv.v1.AckCommand.claim:type_name -> dkv.v1.QueueClaim
+	13, // 20: dkv.v1.NackCommand.claims:type_name -> dkv.v1.QueueClaim
+	8,  // 21: dkv.v1.HashSetCommand.fields:type_name -> dkv.v1.KeyValue
+	18, // 22: dkv.v1.SortedSetAddCommand.members:type_name -> dkv.v1.ScoredMember
+	0,  // 23: dkv.v1.RemoveMembersCommand.type:type_name -> dkv.v1.CollectionType
+	23, // 24: dkv.v1.TxnCommand.compare:type_name -> dkv.v1.Comparison
+	24, // 25: dkv.v1.TxnCommand.success:type_name -> dkv.v1.Operation
+	24, // 26: dkv.v1.TxnCommand.failure:type_name -> dkv.v1.Operation
+	2,  // 27: dkv.v1.Comparison.target:type_name -> dkv.v1.Comparison.Target
+	3,  // 28: dkv.v1.Comparison.result:type_name -> dkv.v1.Comparison.Result
+	25, // 29: dkv.v1.Operation.range:type_name -> dkv.v1.RangeOperation
+	26, // 30: dkv.v1.Operation.put:type_name -> dkv.v1.PutOperation
+	27, // 31: dkv.v1.Operation.delete_range:type_name -> dkv.v1.DeleteRangeOperation
+	22, // 32: dkv.v1.Operation.txn:type_name -> dkv.v1.TxnCommand
+	4,  // 33: dkv.v1.RangeOperation.sort_order:type_name -> dkv.v1.RangeOperation.SortOrder
+	5,  // 34: dkv.v1.RangeOperation.sort_target:type_name -> dkv.v1.RangeOperation.SortTarget
+	30, // 35: dkv.v1.TxnResult.results:type_name -> dkv.v1.OperationResult
+	31, // 36: dkv.v1.OperationResult.range:type_name -> dkv.v1.RangeResult
+	32, // 37: dkv.v1.OperationResult.put:type_name -> dkv.v1.PutResult
+	33, // 38: dkv.v1.OperationResult.delete_range:type_name -> dkv.v1.DeleteRangeResult
+	29, // 39: dkv.v1.OperationResult.txn:type_name -> dkv.v1.TxnResult
+	28, // 40: dkv.v1.RangeResult.kvs:type_name -> dkv.v1.RevisionedKeyValue
+	28, // 41: dkv.v1.PutResult.prev_kv:type_name -> dkv.v1.RevisionedKeyValue
+	28, // 42: dkv.v1.DeleteRangeResult.prev_kvs:type_name -> dkv.v1.RevisionedKeyValue
+	29, // 43: dkv.v1.CommandResult.txn:type_name -> dkv.v1.TxnResult
+	58, // 44: dkv.v1.CommandResult.message:type_name -> dkv.v1.QueueMessage
+	87, // 45: dkv.v1.GetRequest.max_staleness:type_name -> google.protobuf.Duration
+	87, // 46: dkv.v1.LockRequest.ttl:type_name -> google.protobuf.Duration
+	45, // 47: dkv.v1.LockResponse.lease:type_name -> dkv.v1.Lease
+	87, // 48: dkv.v1.CampaignRequest.ttl:type_name -> google.protobuf.Duration
+	45, // 49: dkv.v1.CampaignResponse.lease:type_name -> dkv.v1.Lease
+	45, // 50: dkv.v1.ObserveResponse.leader:type_name -> dkv.v1.Lease
+	87, // 51: dkv.v1.KeepAliveRequest.ttl:type_name -> google.protobuf.Duration
+	87, // 52: dkv.v1.DequeueRequest.visibility_timeout:type_name -> google.protobuf.Duration
+	87, // 53: dkv.v1.DequeueRequest.wait:type_name -> google.protobuf.Duration
+	58, // 54: dkv.v1.DequeueResponse.message:type_name -> dkv.v1.QueueMessage
+	58, // 55: dkv.v1.PeekResponse.messages:type_name -> dkv.v1.QueueMessage
+	69, // 56: dkv.v1.GetServersResponse.servers:type_name -> dkv.v1.Server
+	87, // 57: dkv.v1.ServerHealth.last_contact:type_name -> google.protobuf.Duration
+	88, // 58: dkv.v1.ServerHealth.stable_since:type_name -> google.protobuf.Timestamp
+	76, // 59: dkv.v1.GetClusterHealthResponse.servers:type_name -> dkv.v1.ServerHealth
+	87, // 60: dkv.v1.SetFaultsRequest.delay:type_name -> google.protobuf.Duration
+	87, // 61: dkv.v1.SetFaultsRequest.jitter:type_name -> google.protobuf.Duration
+	37, // 62: dkv.v1.DkvAPI.Get:input_type -> dkv.v1.GetRequest
+	39, // 63: dkv.v1.DkvAPI.Set:input_type -> dkv.v1.SetRequest
+	41, // 64: dkv.v1.DkvAPI.Delete:input_type -> dkv.v1.DeleteRequest
+	43, // 65: dkv.v1.DkvAPI.Increment:input_type -> dkv.v1.IncrementRequest
+	46, // 66: dkv.v1.LockAPI.Lock:input_type -> dkv.v1.LockRequest
+	48, // 67: dkv.v1.LockAPI.Unlock:input_type -> dkv.v1.UnlockRequest
+	50, // 68: dkv.v1.LockAPI.Campaign:input_type -> dkv.v1.CampaignRequest
+	52, // 69: dkv.v1.LockAPI.Resign:input_type -> dkv.v1.ResignRequest
+	54, // 70: dkv.v1.LockAPI.Observe:input_type -> dkv.v1.ObserveRequest
+	56, // 71: dkv.v1.LockAPI.KeepAlive:input_type -> dkv.v1.KeepAliveRequest
+	59, // 72: dkv.v1.QueueAPI.Enqueue:input_type -> dkv.v1.EnqueueRequest
+	61, // 73: dkv.v1.QueueAPI.Dequeue:input_type -> dkv.v1.DequeueRequest
+	63, // 74: dkv.v1.QueueAPI.Ack:input_type -> dkv.v1.AckRequest
+	65, // 75: dkv.v1.QueueAPI.Nack:input_type -> dkv.v1.NackRequest
+	67, // 76: dkv.v1.QueueAPI.Peek:input_type -> dkv.v1.PeekRequest
+	70, // 77: dkv.v1.MembershipAPI.GetServers:input_type -> dkv.v1.GetServersRequest
+	72, // 78: dkv.v1.MembershipAPI.JoinServer:input_type -> dkv.v1.JoinServerRequest
+	74, // 79: dkv.v1.MembershipAPI.LeaveServer:input_type -> dkv.v1.LeaveServerRequest
+	77, // 80: dkv.v1.MembershipAPI.GetClusterHealth:input_type -> dkv.v1.GetClusterHealthRequest
+	79, // 81: dkv.v1.MembershipAPI.GetReadIndex:input_type -> dkv.v1.GetReadIndexRequest
+	81, // 82: dkv.v1.AdminAPI.Snapshot:input_type -> dkv.v1.SnapshotRequest
+	83, // 83: dkv.v1.AdminAPI.SetFaults:input_type -> dkv.v1.SetFaultsRequest
+	85, // 84: dkv.v1.AdminAPI.ClearFaults:input_type -> dkv.v1.ClearFaultsRequest
+	38, // 85: dkv.v1.DkvAPI.Get:output_type -> dkv.v1.GetResponse
+	40, // 86: dkv.v1.DkvAPI.Set:output_type -> dkv.v1.SetResponse
+	42, // 87: dkv.v1.DkvAPI.Delete:output_type -> dkv.v1.DeleteResponse
+	44, // 88: dkv.v1.DkvAPI.Increment:output_type -> dkv.v1.IncrementResponse
+	47, // 89: dkv.v1.LockAPI.Lock:output_type -> dkv.v1.LockResponse
+	49, // 90: dkv.v1.LockAPI.Unlock:output_type -> dkv.v1.UnlockResponse
+	51, // 91: dkv.v1.LockAPI.Campaign:output_type -> dkv.v1.CampaignResponse
+	53, // 92: dkv.v1.LockAPI.Resign:output_type -> dkv.v1.ResignResponse
+	55, // 93: dkv.v1.LockAPI.Observe:output_type -> dkv.v1.ObserveResponse
+	57, // 94: dkv.v1.LockAPI.KeepAlive:output_type -> dkv.v1.KeepAliveResponse
+	60, // 95: dkv.v1.QueueAPI.Enqueue:output_type -> dkv.v1.EnqueueResponse
+	62, // 96: dkv.v1.QueueAPI.Dequeue:output_type -> dkv.v1.DequeueResponse
+	64, // 97: dkv.v1.QueueAPI.Ack:output_type -> dkv.v1.AckResponse
+	66, // 98: dkv.v1.QueueAPI.Nack:output_type -> dkv.v1.NackResponse
+	68, // 99: dkv.v1.QueueAPI.Peek:output_type -> dkv.v1.PeekResponse
+	71, // 100: dkv.v1.MembershipAPI.GetServers:output_type -> dkv.v1.GetServersResponse
+	73, // 101: dkv.v1.MembershipAPI.JoinServer:output_type -> dkv.v1.JoinServerResponse
+	75, // 102: dkv.v1.MembershipAPI.LeaveServer:output_type -> dkv.v1.LeaveServerResponse
+	78, // 103: dkv.v1.MembershipAPI.GetClusterHealth:output_type -> dkv.v1.GetClusterHealthResponse
+	80, // 104: dkv.v1.MembershipAPI.GetReadIndex:output_type -> dkv.v1.GetReadIndexResponse
+	82, // 105: dkv.v1.AdminAPI.Snapshot:output_type -> dkv.v1.SnapshotResponse
+	84, // 106: dkv.v1.AdminAPI.SetFaults:output_type -> dkv.v1.SetFaultsResponse
+	86, // 107: dkv.v1.AdminAPI.ClearFaults:output_type -> dkv.v1.ClearFaultsResponse
+	85, // [85:108] is the sub-list for method output_type
+	62, // [62:85] is the sub-list for method input_type
+	62, // [62:62] is the sub-list for extension type_name
+	62, // [62:62] is the sub-list for extension extendee
+	0,  // [0:62] is the sub-list for field type_name
 }
 
 func init() { file_dkv_v1_dkv_proto_init() }
@@ -845,196 +5857,55 @@ func file_dkv_v1_dkv_proto_init() {
 	if File_dkv_v1_dkv_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_dkv_v1_dkv_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Command); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Server); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetServersRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetServersResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JoinServerRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JoinServerResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LeaveServerRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_dkv_v1_dkv_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LeaveServerResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
-	file_dkv_v1_dkv_proto_msgTypes[0].OneofWrappers = []interface{}{
+	file_dkv_v1_dkv_proto_msgTypes[0].OneofWrappers = []any{
 		(*Command_Set)(nil),
 		(*Command_Delete)(nil),
+		(*Command_ServerMetadata)(nil),
+		(*Command_Put)(nil),
+		(*Command_DeleteKeys)(nil),
+		(*Command_Increment)(nil),
+		(*Command_Barrier)(nil),
+		(*Command_Txn)(nil),
+		(*Command_KeepAlive)(nil),
+		(*Command_Enqueue)(nil),
+		(*Command_Dequeue)(nil),
+		(*Command_Ack)(nil),
+		(*Command_Nack)(nil),
+		(*Command_HashSet)(nil),
+		(*Command_SetAdd)(nil),
+		(*Command_SortedSetAdd)(nil),
+		(*Command_RemoveMembers)(nil),
+	}
+	file_dkv_v1_dkv_proto_msgTypes[15].OneofWrappers = []any{}
+	file_dkv_v1_dkv_proto_msgTypes[18].OneofWrappers = []any{
+		(*Operation_Range)(nil),
+		(*Operation_Put)(nil),
+		(*Operation_DeleteRange)(nil),
+		(*Operation_Txn)(nil),
+	}
+	file_dkv_v1_dkv_proto_msgTypes[24].OneofWrappers = []any{
+		(*OperationResult_Range)(nil),
+		(*OperationResult_Put)(nil),
+		(*OperationResult_DeleteRange)(nil),
+		(*OperationResult_Txn)(nil),
 	}
+	file_dkv_v1_dkv_proto_msgTypes[37].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_dkv_v1_dkv_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   14,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dkv_v1_dkv_proto_rawDesc), len(file_dkv_v1_dkv_proto_rawDesc)),
+			NumEnums:      6,
+			NumMessages:   81,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   5,
 		},
 		GoTypes:           file_dkv_v1_dkv_proto_goTypes,
 		DependencyIndexes: file_dkv_v1_dkv_proto_depIdxs,
+		EnumInfos:         file_dkv_v1_dkv_proto_enumTypes,
 		MessageInfos:      file_dkv_v1_dkv_proto_msgTypes,
 	}.Build()
 	File_dkv_v1_dkv_proto = out.File
-	file_dkv_v1_dkv_proto_rawDesc = nil
 	file_dkv_v1_dkv_proto_goTypes = nil
 	file_dkv_v1_dkv_proto_depIdxs = nil
 }
@@ -23,8 +23,14 @@ const _ = connect.IsAtLeastVersion1_13_0
 const (
 	// DkvAPIName is the fully-qualified name of the DkvAPI service.
 	DkvAPIName = "dkv.v1.DkvAPI"
+	// LockAPIName is the fully-qualified name of the LockAPI service.
+	LockAPIName = "dkv.v1.LockAPI"
+	// QueueAPIName is the fully-qualified name of the QueueAPI service.
+	QueueAPIName = "dkv.v1.QueueAPI"
 	// MembershipAPIName is the fully-qualified name of the MembershipAPI service.
 	MembershipAPIName = "dkv.v1.MembershipAPI"
+	// AdminAPIName is the fully-qualified name of the AdminAPI service.
+	AdminAPIName = "dkv.v1.AdminAPI"
 )
 
 // These constants are the fully-qualified names of the RPCs defined in this package. They're
@@ -41,6 +47,30 @@ const (
 	DkvAPISetProcedure = "/dkv.v1.DkvAPI/Set"
 	// DkvAPIDeleteProcedure is the fully-qualified name of the DkvAPI's Delete RPC.
 	DkvAPIDeleteProcedure = "/dkv.v1.DkvAPI/Delete"
+	// DkvAPIIncrementProcedure is the fully-qualified name of the DkvAPI's Increment RPC.
+	DkvAPIIncrementProcedure = "/dkv.v1.DkvAPI/Increment"
+	// LockAPILockProcedure is the fully-qualified name of the LockAPI's Lock RPC.
+	LockAPILockProcedure = "/dkv.v1.LockAPI/Lock"
+	// LockAPIUnlockProcedure is the fully-qualified name of the LockAPI's Unlock RPC.
+	LockAPIUnlockProcedure = "/dkv.v1.LockAPI/Unlock"
+	// LockAPICampaignProcedure is the fully-qualified name of the LockAPI's Campaign RPC.
+	LockAPICampaignProcedure = "/dkv.v1.LockAPI/Campaign"
+	// LockAPIResignProcedure is the fully-qualified name of the LockAPI's Resign RPC.
+	LockAPIResignProcedure = "/dkv.v1.LockAPI/Resign"
+	// LockAPIObserveProcedure is the fully-qualified name of the LockAPI's Observe RPC.
+	LockAPIObserveProcedure = "/dkv.v1.LockAPI/Observe"
+	// LockAPIKeepAliveProcedure is the fully-qualified name of the LockAPI's KeepAlive RPC.
+	LockAPIKeepAliveProcedure = "/dkv.v1.LockAPI/KeepAlive"
+	// QueueAPIEnqueueProcedure is the fully-qualified name of the QueueAPI's Enqueue RPC.
+	QueueAPIEnqueueProcedure = "/dkv.v1.QueueAPI/Enqueue"
+	// QueueAPIDequeueProcedure is the fully-qualified name of the QueueAPI's Dequeue RPC.
+	QueueAPIDequeueProcedure = "/dkv.v1.QueueAPI/Dequeue"
+	// QueueAPIAckProcedure is the fully-qualified name of the QueueAPI's Ack RPC.
+	QueueAPIAckProcedure = "/dkv.v1.QueueAPI/Ack"
+	// QueueAPINackProcedure is the fully-qualified name of the QueueAPI's Nack RPC.
+	QueueAPINackProcedure = "/dkv.v1.QueueAPI/Nack"
+	// QueueAPIPeekProcedure is the fully-qualified name of the QueueAPI's Peek RPC.
+	QueueAPIPeekProcedure = "/dkv.v1.QueueAPI/Peek"
 	// MembershipAPIGetServersProcedure is the fully-qualified name of the MembershipAPI's GetServers
 	// RPC.
 	MembershipAPIGetServersProcedure = "/dkv.v1.MembershipAPI/GetServers"
@@ -50,18 +80,18 @@ const (
 	// MembershipAPILeaveServerProcedure is the fully-qualified name of the MembershipAPI's LeaveServer
 	// RPC.
 	MembershipAPILeaveServerProcedure = "/dkv.v1.MembershipAPI/LeaveServer"
-)
-
-// These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
-var (
-	dkvAPIServiceDescriptor                  = v1.File_dkv_v1_dkv_proto.Services().ByName("DkvAPI")
-	dkvAPIGetMethodDescriptor                = dkvAPIServiceDescriptor.Methods().ByName("Get")
-	dkvAPISetMethodDescriptor                = dkvAPIServiceDescriptor.Methods().ByName("Set")
-	dkvAPIDeleteMethodDescriptor             = dkvAPIServiceDescriptor.Methods().ByName("Delete")
-	membershipAPIServiceDescriptor           = v1.File_dkv_v1_dkv_proto.Services().ByName("MembershipAPI")
-	membershipAPIGetServersMethodDescriptor  = membershipAPIServiceDescriptor.Methods().ByName("GetServers")
-	membershipAPIJoinServerMethodDescriptor  = membershipAPIServiceDescriptor.Methods().ByName("JoinServer")
-	membershipAPILeaveServerMethodDescriptor = membershipAPIServiceDescriptor.Methods().ByName("LeaveServer")
+	// MembershipAPIGetClusterHealthProcedure is the fully-qualified name of the MembershipAPI's
+	// GetClusterHealth RPC.
+	MembershipAPIGetClusterHealthProcedure = "/dkv.v1.MembershipAPI/GetClusterHealth"
+	// MembershipAPIGetReadIndexProcedure is the fully-qualified name of the MembershipAPI's
+	// GetReadIndex RPC.
+	MembershipAPIGetReadIndexProcedure = "/dkv.v1.MembershipAPI/GetReadIndex"
+	// AdminAPISnapshotProcedure is the fully-qualified name of the AdminAPI's Snapshot RPC.
+	AdminAPISnapshotProcedure = "/dkv.v1.AdminAPI/Snapshot"
+	// AdminAPISetFaultsProcedure is the fully-qualified name of the AdminAPI's SetFaults RPC.
+	AdminAPISetFaultsProcedure = "/dkv.v1.AdminAPI/SetFaults"
+	// AdminAPIClearFaultsProcedure is the fully-qualified name of the AdminAPI's ClearFaults RPC.
+	AdminAPIClearFaultsProcedure = "/dkv.v1.AdminAPI/ClearFaults"
 )
 
 // DkvAPIClient is a client for the dkv.v1.DkvAPI service.
@@ -69,6 +99,10 @@ type DkvAPIClient interface {
 	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
 	Set(context.Context, *connect.Request[v1.SetRequest]) (*connect.Response[v1.SetResponse], error)
 	Delete(context.Context, *connect.Request[v1.DeleteRequest]) (*connect.Response[v1.DeleteResponse], error)
+	// Increment adds a delta to the signed 64-bit integer value of a key,
+	// atomically. It is not idempotent: UNAVAILABLE is only returned if the
+	// increment was not applied, and other errors must not be retried.
+	Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error)
 }
 
 // NewDkvAPIClient constructs a client for the dkv.v1.DkvAPI service. By default, it uses the
@@ -80,23 +114,30 @@ type DkvAPIClient interface {
 // http://api.acme.com or https://acme.com/grpc).
 func NewDkvAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) DkvAPIClient {
 	baseURL = strings.TrimRight(baseURL, "/")
+	dkvAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("DkvAPI").Methods()
 	return &dkvAPIClient{
 		get: connect.NewClient[v1.GetRequest, v1.GetResponse](
 			httpClient,
 			baseURL+DkvAPIGetProcedure,
-			connect.WithSchema(dkvAPIGetMethodDescriptor),
+			connect.WithSchema(dkvAPIMethods.ByName("Get")),
 			connect.WithClientOptions(opts...),
 		),
 		set: connect.NewClient[v1.SetRequest, v1.SetResponse](
 			httpClient,
 			baseURL+DkvAPISetProcedure,
-			connect.WithSchema(dkvAPISetMethodDescriptor),
+			connect.WithSchema(dkvAPIMethods.ByName("Set")),
 			connect.WithClientOptions(opts...),
 		),
 		delete: connect.NewClient[v1.DeleteRequest, v1.DeleteResponse](
 			httpClient,
 			baseURL+DkvAPIDeleteProcedure,
-			connect.WithSchema(dkvAPIDeleteMethodDescriptor),
+			connect.WithSchema(dkvAPIMethods.ByName("Delete")),
+			connect.WithClientOptions(opts...),
+		),
+		increment: connect.NewClient[v1.IncrementRequest, v1.IncrementResponse](
+			httpClient,
+			baseURL+DkvAPIIncrementProcedure,
+			connect.WithSchema(dkvAPIMethods.ByName("Increment")),
 			connect.WithClientOptions(opts...),
 		),
 	}
@@ -104,9 +145,10 @@ func NewDkvAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...conn
 
 // dkvAPIClient implements DkvAPIClient.
 type dkvAPIClient struct {
-	get    *connect.Client[v1.GetRequest, v1.GetResponse]
-	set    *connect.Client[v1.SetRequest, v1.SetResponse]
-	delete *connect.Client[v1.DeleteRequest, v1.DeleteResponse]
+	get       *connect.Client[v1.GetRequest, v1.GetResponse]
+	set       *connect.Client[v1.SetRequest, v1.SetResponse]
+	delete    *connect.Client[v1.DeleteRequest, v1.DeleteResponse]
+	increment *connect.Client[v1.IncrementRequest, v1.IncrementResponse]
 }
 
 // Get calls dkv.v1.DkvAPI.Get.
@@ -124,11 +166,20 @@ func (c *dkvAPIClient) Delete(ctx context.Context, req *connect.Request[v1.Delet
 	return c.delete.CallUnary(ctx, req)
 }
 
+// Increment calls dkv.v1.DkvAPI.Increment.
+func (c *dkvAPIClient) Increment(ctx context.Context, req *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error) {
+	return c.increment.CallUnary(ctx, req)
+}
+
 // DkvAPIHandler is an implementation of the dkv.v1.DkvAPI service.
 type DkvAPIHandler interface {
 	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
 	Set(context.Context, *connect.Request[v1.SetRequest]) (*connect.Response[v1.SetResponse], error)
 	Delete(context.Context, *connect.Request[v1.DeleteRequest]) (*connect.Response[v1.DeleteResponse], error)
+	// Increment adds a delta to the signed 64-bit integer value of a key,
+	// atomically. It is not idempotent: UNAVAILABLE is only returned if the
+	// increment was not applied, and other errors must not be retried.
+	Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error)
 }
 
 // NewDkvAPIHandler builds an HTTP handler from the service implementation. It returns the path on
@@ -137,22 +188,29 @@ type DkvAPIHandler interface {
 // By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
 // and JSON codecs. They also support gzip compression.
 func NewDkvAPIHandler(svc DkvAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	dkvAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("DkvAPI").Methods()
 	dkvAPIGetHandler := connect.NewUnaryHandler(
 		DkvAPIGetProcedure,
 		svc.Get,
-		connect.WithSchema(dkvAPIGetMethodDescriptor),
+		connect.WithSchema(dkvAPIMethods.ByName("Get")),
 		connect.WithHandlerOptions(opts...),
 	)
 	dkvAPISetHandler := connect.NewUnaryHandler(
 		DkvAPISetProcedure,
 		svc.Set,
-		connect.WithSchema(dkvAPISetMethodDescriptor),
+		connect.WithSchema(dkvAPIMethods.ByName("Set")),
 		connect.WithHandlerOptions(opts...),
 	)
 	dkvAPIDeleteHandler := connect.NewUnaryHandler(
 		DkvAPIDeleteProcedure,
 		svc.Delete,
-		connect.WithSchema(dkvAPIDeleteMethodDescriptor),
+		connect.WithSchema(dkvAPIMethods.ByName("Delete")),
+		connect.WithHandlerOptions(opts...),
+	)
+	dkvAPIIncrementHandler := connect.NewUnaryHandler(
+		DkvAPIIncrementProcedure,
+		svc.Increment,
+		connect.WithSchema(dkvAPIMethods.ByName("Increment")),
 		connect.WithHandlerOptions(opts...),
 	)
 	return "/dkv.v1.DkvAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -163,6 +221,8 @@ func NewDkvAPIHandler(svc DkvAPIHandler, opts ...connect.HandlerOption) (string,
 			dkvAPISetHandler.ServeHTTP(w, r)
 		case DkvAPIDeleteProcedure:
 			dkvAPIDeleteHandler.ServeHTTP(w, r)
+		case DkvAPIIncrementProcedure:
+			dkvAPIIncrementHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -184,11 +244,434 @@ func (UnimplementedDkvAPIHandler) Delete(context.Context, *connect.Request[v1.De
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.DkvAPI.Delete is not implemented"))
 }
 
+func (UnimplementedDkvAPIHandler) Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.DkvAPI.Increment is not implemented"))
+}
+
+// LockAPIClient is a client for the dkv.v1.LockAPI service.
+type LockAPIClient interface {
+	// Lock waits until the lock is acquired, and returns the lease of the
+	// holder. The lease of a waiter is kept alive by the server, and deleted if
+	// the request is canceled.
+	Lock(context.Context, *connect.Request[v1.LockRequest]) (*connect.Response[v1.LockResponse], error)
+	// Unlock deletes the lease of the holder. NOT_FOUND is returned if the lease
+	// expired, or if its revision is not the one of the holder.
+	Unlock(context.Context, *connect.Request[v1.UnlockRequest]) (*connect.Response[v1.UnlockResponse], error)
+	// Campaign waits until the candidate is elected leader, like Lock.
+	Campaign(context.Context, *connect.Request[v1.CampaignRequest]) (*connect.Response[v1.CampaignResponse], error)
+	// Resign deletes the lease of the leader, like Unlock.
+	Resign(context.Context, *connect.Request[v1.ResignRequest]) (*connect.Response[v1.ResignResponse], error)
+	// Observe streams the leader of an election each time it changes.
+	Observe(context.Context, *connect.Request[v1.ObserveRequest]) (*connect.ServerStreamForClient[v1.ObserveResponse], error)
+	// KeepAlive postpones the expiration of a lease. NOT_FOUND is returned if
+	// the lease expired.
+	KeepAlive(context.Context, *connect.Request[v1.KeepAliveRequest]) (*connect.Response[v1.KeepAliveResponse], error)
+}
+
+// NewLockAPIClient constructs a client for the dkv.v1.LockAPI service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewLockAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) LockAPIClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	lockAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("LockAPI").Methods()
+	return &lockAPIClient{
+		lock: connect.NewClient[v1.LockRequest, v1.LockResponse](
+			httpClient,
+			baseURL+LockAPILockProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("Lock")),
+			connect.WithClientOptions(opts...),
+		),
+		unlock: connect.NewClient[v1.UnlockRequest, v1.UnlockResponse](
+			httpClient,
+			baseURL+LockAPIUnlockProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("Unlock")),
+			connect.WithClientOptions(opts...),
+		),
+		campaign: connect.NewClient[v1.CampaignRequest, v1.CampaignResponse](
+			httpClient,
+			baseURL+LockAPICampaignProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("Campaign")),
+			connect.WithClientOptions(opts...),
+		),
+		resign: connect.NewClient[v1.ResignRequest, v1.ResignResponse](
+			httpClient,
+			baseURL+LockAPIResignProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("Resign")),
+			connect.WithClientOptions(opts...),
+		),
+		observe: connect.NewClient[v1.ObserveRequest, v1.ObserveResponse](
+			httpClient,
+			baseURL+LockAPIObserveProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("Observe")),
+			connect.WithClientOptions(opts...),
+		),
+		keepAlive: connect.NewClient[v1.KeepAliveRequest, v1.KeepAliveResponse](
+			httpClient,
+			baseURL+LockAPIKeepAliveProcedure,
+			connect.WithSchema(lockAPIMethods.ByName("KeepAlive")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// lockAPIClient implements LockAPIClient.
+type lockAPIClient struct {
+	lock      *connect.Client[v1.LockRequest, v1.LockResponse]
+	unlock    *connect.Client[v1.UnlockRequest, v1.UnlockResponse]
+	campaign  *connect.Client[v1.CampaignRequest, v1.CampaignResponse]
+	resign    *connect.Client[v1.ResignRequest, v1.ResignResponse]
+	observe   *connect.Client[v1.ObserveRequest, v1.ObserveResponse]
+	keepAlive *connect.Client[v1.KeepAliveRequest, v1.KeepAliveResponse]
+}
+
+// Lock calls dkv.v1.LockAPI.Lock.
+func (c *lockAPIClient) Lock(ctx context.Context, req *connect.Request[v1.LockRequest]) (*connect.Response[v1.LockResponse], error) {
+	return c.lock.CallUnary(ctx, req)
+}
+
+// Unlock calls dkv.v1.LockAPI.Unlock.
+func (c *lockAPIClient) Unlock(ctx context.Context, req *connect.Request[v1.UnlockRequest]) (*connect.Response[v1.UnlockResponse], error) {
+	return c.unlock.CallUnary(ctx, req)
+}
+
+// Campaign calls dkv.v1.LockAPI.Campaign.
+func (c *lockAPIClient) Campaign(ctx context.Context, req *connect.Request[v1.CampaignRequest]) (*connect.Response[v1.CampaignResponse], error) {
+	return c.campaign.CallUnary(ctx, req)
+}
+
+// Resign calls dkv.v1.LockAPI.Resign.
+func (c *lockAPIClient) Resign(ctx context.Context, req *connect.Request[v1.ResignRequest]) (*connect.Response[v1.ResignResponse], error) {
+	return c.resign.CallUnary(ctx, req)
+}
+
+// Observe calls dkv.v1.LockAPI.Observe.
+func (c *lockAPIClient) Observe(ctx context.Context, req *connect.Request[v1.ObserveRequest]) (*connect.ServerStreamForClient[v1.ObserveResponse], error) {
+	return c.observe.CallServerStream(ctx, req)
+}
+
+// KeepAlive calls dkv.v1.LockAPI.KeepAlive.
+func (c *lockAPIClient) KeepAlive(ctx context.Context, req *connect.Request[v1.KeepAliveRequest]) (*connect.Response[v1.KeepAliveResponse], error) {
+	return c.keepAlive.CallUnary(ctx, req)
+}
+
+// LockAPIHandler is an implementation of the dkv.v1.LockAPI service.
+type LockAPIHandler interface {
+	// Lock waits until the lock is acquired, and returns the lease of the
+	// holder. The lease of a waiter is kept alive by the server, and deleted if
+	// the request is canceled.
+	Lock(context.Context, *connect.Request[v1.LockRequest]) (*connect.Response[v1.LockResponse], error)
+	// Unlock deletes the lease of the holder. NOT_FOUND is returned if the lease
+	// expired, or if its revision is not the one of the holder.
+	Unlock(context.Context, *connect.Request[v1.UnlockRequest]) (*connect.Response[v1.UnlockResponse], error)
+	// Campaign waits until the candidate is elected leader, like Lock.
+	Campaign(context.Context, *connect.Request[v1.CampaignRequest]) (*connect.Response[v1.CampaignResponse], error)
+	// Resign deletes the lease of the leader, like Unlock.
+	Resign(context.Context, *connect.Request[v1.ResignRequest]) (*connect.Response[v1.ResignResponse], error)
+	// Observe streams the leader of an election each time it changes.
+	Observe(context.Context, *connect.Request[v1.ObserveRequest], *connect.ServerStream[v1.ObserveResponse]) error
+	// KeepAlive postpones the expiration of a lease. NOT_FOUND is returned if
+	// the lease expired.
+	KeepAlive(context.Context, *connect.Request[v1.KeepAliveRequest]) (*connect.Response[v1.KeepAliveResponse], error)
+}
+
+// NewLockAPIHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewLockAPIHandler(svc LockAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	lockAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("LockAPI").Methods()
+	lockAPILockHandler := connect.NewUnaryHandler(
+		LockAPILockProcedure,
+		svc.Lock,
+		connect.WithSchema(lockAPIMethods.ByName("Lock")),
+		connect.WithHandlerOptions(opts...),
+	)
+	lockAPIUnlockHandler := connect.NewUnaryHandler(
+		LockAPIUnlockProcedure,
+		svc.Unlock,
+		connect.WithSchema(lockAPIMethods.ByName("Unlock")),
+		connect.WithHandlerOptions(opts...),
+	)
+	lockAPICampaignHandler := connect.NewUnaryHandler(
+		LockAPICampaignProcedure,
+		svc.Campaign,
+		connect.WithSchema(lockAPIMethods.ByName("Campaign")),
+		connect.WithHandlerOptions(opts...),
+	)
+	lockAPIResignHandler := connect.NewUnaryHandler(
+		LockAPIResignProcedure,
+		svc.Resign,
+		connect.WithSchema(lockAPIMethods.ByName("Resign")),
+		connect.WithHandlerOptions(opts...),
+	)
+	lockAPIObserveHandler := connect.NewServerStreamHandler(
+		LockAPIObserveProcedure,
+		svc.Observe,
+		connect.WithSchema(lockAPIMethods.ByName("Observe")),
+		connect.WithHandlerOptions(opts...),
+	)
+	lockAPIKeepAliveHandler := connect.NewUnaryHandler(
+		LockAPIKeepAliveProcedure,
+		svc.KeepAlive,
+		connect.WithSchema(lockAPIMethods.ByName("KeepAlive")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/dkv.v1.LockAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case LockAPILockProcedure:
+			lockAPILockHandler.ServeHTTP(w, r)
+		case LockAPIUnlockProcedure:
+			lockAPIUnlockHandler.ServeHTTP(w, r)
+		case LockAPICampaignProcedure:
+			lockAPICampaignHandler.ServeHTTP(w, r)
+		case LockAPIResignProcedure:
+			lockAPIResignHandler.ServeHTTP(w, r)
+		case LockAPIObserveProcedure:
+			lockAPIObserveHandler.ServeHTTP(w, r)
+		case LockAPIKeepAliveProcedure:
+			lockAPIKeepAliveHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedLockAPIHandler returns CodeUnimplemented from all methods.
+type UnimplementedLockAPIHandler struct{}
+
+func (UnimplementedLockAPIHandler) Lock(context.Context, *connect.Request[v1.LockRequest]) (*connect.Response[v1.LockResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.Lock is not implemented"))
+}
+
+func (UnimplementedLockAPIHandler) Unlock(context.Context, *connect.Request[v1.UnlockRequest]) (*connect.Response[v1.UnlockResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.Unlock is not implemented"))
+}
+
+func (UnimplementedLockAPIHandler) Campaign(context.Context, *connect.Request[v1.CampaignRequest]) (*connect.Response[v1.CampaignResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.Campaign is not implemented"))
+}
+
+func (UnimplementedLockAPIHandler) Resign(context.Context, *connect.Request[v1.ResignRequest]) (*connect.Response[v1.ResignResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.Resign is not implemented"))
+}
+
+func (UnimplementedLockAPIHandler) Observe(context.Context, *connect.Request[v1.ObserveRequest], *connect.ServerStream[v1.ObserveResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.Observe is not implemented"))
+}
+
+func (UnimplementedLockAPIHandler) KeepAlive(context.Context, *connect.Request[v1.KeepAliveRequest]) (*connect.Response[v1.KeepAliveResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.LockAPI.KeepAlive is not implemented"))
+}
+
+// QueueAPIClient is a client for the dkv.v1.QueueAPI service.
+type QueueAPIClient interface {
+	Enqueue(context.Context, *connect.Request[v1.EnqueueRequest]) (*connect.Response[v1.EnqueueResponse], error)
+	// Dequeue claims the first message of a queue which is not claimed. It is
+	// not idempotent: UNAVAILABLE is only returned if the dequeue was not
+	// applied.
+	Dequeue(context.Context, *connect.Request[v1.DequeueRequest]) (*connect.Response[v1.DequeueResponse], error)
+	// Ack deletes a claimed message. FAILED_PRECONDITION is returned if its
+	// claim expired.
+	Ack(context.Context, *connect.Request[v1.AckRequest]) (*connect.Response[v1.AckResponse], error)
+	// Nack requeues a claimed message at its position. FAILED_PRECONDITION is
+	// returned if its claim expired.
+	Nack(context.Context, *connect.Request[v1.NackRequest]) (*connect.Response[v1.NackResponse], error)
+	// Peek returns the first messages of a queue which are not claimed, without
+	// claiming them.
+	Peek(context.Context, *connect.Request[v1.PeekRequest]) (*connect.Response[v1.PeekResponse], error)
+}
+
+// NewQueueAPIClient constructs a client for the dkv.v1.QueueAPI service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewQueueAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) QueueAPIClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	queueAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("QueueAPI").Methods()
+	return &queueAPIClient{
+		enqueue: connect.NewClient[v1.EnqueueRequest, v1.EnqueueResponse](
+			httpClient,
+			baseURL+QueueAPIEnqueueProcedure,
+			connect.WithSchema(queueAPIMethods.ByName("Enqueue")),
+			connect.WithClientOptions(opts...),
+		),
+		dequeue: connect.NewClient[v1.DequeueRequest, v1.DequeueResponse](
+			httpClient,
+			baseURL+QueueAPIDequeueProcedure,
+			connect.WithSchema(queueAPIMethods.ByName("Dequeue")),
+			connect.WithClientOptions(opts...),
+		),
+		ack: connect.NewClient[v1.AckRequest, v1.AckResponse](
+			httpClient,
+			baseURL+QueueAPIAckProcedure,
+			connect.WithSchema(queueAPIMethods.ByName("Ack")),
+			connect.WithClientOptions(opts...),
+		),
+		nack: connect.NewClient[v1.NackRequest, v1.NackResponse](
+			httpClient,
+			baseURL+QueueAPINackProcedure,
+			connect.WithSchema(queueAPIMethods.ByName("Nack")),
+			connect.WithClientOptions(opts...),
+		),
+		peek: connect.NewClient[v1.PeekRequest, v1.PeekResponse](
+			httpClient,
+			baseURL+QueueAPIPeekProcedure,
+			connect.WithSchema(queueAPIMethods.ByName("Peek")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// queueAPIClient implements QueueAPIClient.
+type queueAPIClient struct {
+	enqueue *connect.Client[v1.EnqueueRequest, v1.EnqueueResponse]
+	dequeue *connect.Client[v1.DequeueRequest, v1.DequeueResponse]
+	ack     *connect.Client[v1.AckRequest, v1.AckResponse]
+	nack    *connect.Client[v1.NackRequest, v1.NackResponse]
+	peek    *connect.Client[v1.PeekRequest, v1.PeekResponse]
+}
+
+// Enqueue calls dkv.v1.QueueAPI.Enqueue.
+func (c *queueAPIClient) Enqueue(ctx context.Context, req *connect.Request[v1.EnqueueRequest]) (*connect.Response[v1.EnqueueResponse], error) {
+	return c.enqueue.CallUnary(ctx, req)
+}
+
+// Dequeue calls dkv.v1.QueueAPI.Dequeue.
+func (c *queueAPIClient) Dequeue(ctx context.Context, req *connect.Request[v1.DequeueRequest]) (*connect.Response[v1.DequeueResponse], error) {
+	return c.dequeue.CallUnary(ctx, req)
+}
+
+// Ack calls dkv.v1.QueueAPI.Ack.
+func (c *queueAPIClient) Ack(ctx context.Context, req *connect.Request[v1.AckRequest]) (*connect.Response[v1.AckResponse], error) {
+	return c.ack.CallUnary(ctx, req)
+}
+
+// Nack calls dkv.v1.QueueAPI.Nack.
+func (c *queueAPIClient) Nack(ctx context.Context, req *connect.Request[v1.NackRequest]) (*connect.Response[v1.NackResponse], error) {
+	return c.nack.CallUnary(ctx, req)
+}
+
+// Peek calls dkv.v1.QueueAPI.Peek.
+func (c *queueAPIClient) Peek(ctx context.Context, req *connect.Request[v1.PeekRequest]) (*connect.Response[v1.PeekResponse], error) {
+	return c.peek.CallUnary(ctx, req)
+}
+
+// QueueAPIHandler is an implementation of the dkv.v1.QueueAPI service.
+type QueueAPIHandler interface {
+	Enqueue(context.Context, *connect.Request[v1.EnqueueRequest]) (*connect.Response[v1.EnqueueResponse], error)
+	// Dequeue claims the first message of a queue which is not claimed. It is
+	// not idempotent: UNAVAILABLE is only returned if the dequeue was not
+	// applied.
+	Dequeue(context.Context, *connect.Request[v1.DequeueRequest]) (*connect.Response[v1.DequeueResponse], error)
+	// Ack deletes a claimed message. FAILED_PRECONDITION is returned if its
+	// claim expired.
+	Ack(context.Context, *connect.Request[v1.AckRequest]) (*connect.Response[v1.AckResponse], error)
+	// Nack requeues a claimed message at its position. FAILED_PRECONDITION is
+	// returned if its claim expired.
+	Nack(context.Context, *connect.Request[v1.NackRequest]) (*connect.Response[v1.NackResponse], error)
+	// Peek returns the first messages of a queue which are not claimed, without
+	// claiming them.
+	Peek(context.Context, *connect.Request[v1.PeekRequest]) (*connect.Response[v1.PeekResponse], error)
+}
+
+// NewQueueAPIHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewQueueAPIHandler(svc QueueAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	queueAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("QueueAPI").Methods()
+	queueAPIEnqueueHandler := connect.NewUnaryHandler(
+		QueueAPIEnqueueProcedure,
+		svc.Enqueue,
+		connect.WithSchema(queueAPIMethods.ByName("Enqueue")),
+		connect.WithHandlerOptions(opts...),
+	)
+	queueAPIDequeueHandler := connect.NewUnaryHandler(
+		QueueAPIDequeueProcedure,
+		svc.Dequeue,
+		connect.WithSchema(queueAPIMethods.ByName("Dequeue")),
+		connect.WithHandlerOptions(opts...),
+	)
+	queueAPIAckHandler := connect.NewUnaryHandler(
+		QueueAPIAckProcedure,
+		svc.Ack,
+		connect.WithSchema(queueAPIMethods.ByName("Ack")),
+		connect.WithHandlerOptions(opts...),
+	)
+	queueAPINackHandler := connect.NewUnaryHandler(
+		QueueAPINackProcedure,
+		svc.Nack,
+		connect.WithSchema(queueAPIMethods.ByName("Nack")),
+		connect.WithHandlerOptions(opts...),
+	)
+	queueAPIPeekHandler := connect.NewUnaryHandler(
+		QueueAPIPeekProcedure,
+		svc.Peek,
+		connect.WithSchema(queueAPIMethods.ByName("Peek")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/dkv.v1.QueueAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case QueueAPIEnqueueProcedure:
+			queueAPIEnqueueHandler.ServeHTTP(w, r)
+		case QueueAPIDequeueProcedure:
+			queueAPIDequeueHandler.ServeHTTP(w, r)
+		case QueueAPIAckProcedure:
+			queueAPIAckHandler.ServeHTTP(w, r)
+		case QueueAPINackProcedure:
+			queueAPINackHandler.ServeHTTP(w, r)
+		case QueueAPIPeekProcedure:
+			queueAPIPeekHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedQueueAPIHandler returns CodeUnimplemented from all methods.
+type UnimplementedQueueAPIHandler struct{}
+
+func (UnimplementedQueueAPIHandler) Enqueue(context.Context, *connect.Request[v1.EnqueueRequest]) (*connect.Response[v1.EnqueueResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.QueueAPI.Enqueue is not implemented"))
+}
+
+func (UnimplementedQueueAPIHandler) Dequeue(context.Context, *connect.Request[v1.DequeueRequest]) (*connect.Response[v1.DequeueResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.QueueAPI.Dequeue is not implemented"))
+}
+
+func (UnimplementedQueueAPIHandler) Ack(context.Context, *connect.Request[v1.AckRequest]) (*connect.Response[v1.AckResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.QueueAPI.Ack is not implemented"))
+}
+
+func (UnimplementedQueueAPIHandler) Nack(context.Context, *connect.Request[v1.NackRequest]) (*connect.Response[v1.NackResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.QueueAPI.Nack is not implemented"))
+}
+
+func (UnimplementedQueueAPIHandler) Peek(context.Context, *connect.Request[v1.PeekRequest]) (*connect.Response[v1.PeekResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.QueueAPI.Peek is not implemented"))
+}
+
 // MembershipAPIClient is a client for the dkv.v1.MembershipAPI service.
 type MembershipAPIClient interface {
 	GetServers(context.Context, *connect.Request[v1.GetServersRequest]) (*connect.Response[v1.GetServersResponse], error)
 	JoinServer(context.Context, *connect.Request[v1.JoinServerRequest]) (*connect.Response[v1.JoinServerResponse], error)
 	LeaveServer(context.Context, *connect.Request[v1.LeaveServerRequest]) (*connect.Response[v1.LeaveServerResponse], error)
+	// GetClusterHealth returns the health of the servers, as seen by the leader.
+	GetClusterHealth(context.Context, *connect.Request[v1.GetClusterHealthRequest]) (*connect.Response[v1.GetClusterHealthResponse], error)
+	// GetReadIndex returns the index which a node must apply to serve a
+	// linearizable read. It is served by the leader once it confirmed its
+	// leadership, without writing to the Raft log. Other nodes fail with
+	// UNAVAILABLE.
+	GetReadIndex(context.Context, *connect.Request[v1.GetReadIndexRequest]) (*connect.Response[v1.GetReadIndexResponse], error)
 }
 
 // NewMembershipAPIClient constructs a client for the dkv.v1.MembershipAPI service. By default, it
@@ -200,23 +683,36 @@ type MembershipAPIClient interface {
 // http://api.acme.com or https://acme.com/grpc).
 func NewMembershipAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) MembershipAPIClient {
 	baseURL = strings.TrimRight(baseURL, "/")
+	membershipAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("MembershipAPI").Methods()
 	return &membershipAPIClient{
 		getServers: connect.NewClient[v1.GetServersRequest, v1.GetServersResponse](
 			httpClient,
 			baseURL+MembershipAPIGetServersProcedure,
-			connect.WithSchema(membershipAPIGetServersMethodDescriptor),
+			connect.WithSchema(membershipAPIMethods.ByName("GetServers")),
 			connect.WithClientOptions(opts...),
 		),
 		joinServer: connect.NewClient[v1.JoinServerRequest, v1.JoinServerResponse](
 			httpClient,
 			baseURL+MembershipAPIJoinServerProcedure,
-			connect.WithSchema(membershipAPIJoinServerMethodDescriptor),
+			connect.WithSchema(membershipAPIMethods.ByName("JoinServer")),
 			connect.WithClientOptions(opts...),
 		),
 		leaveServer: connect.NewClient[v1.LeaveServerRequest, v1.LeaveServerResponse](
 			httpClient,
 			baseURL+MembershipAPILeaveServerProcedure,
-			connect.WithSchema(membershipAPILeaveServerMethodDescriptor),
+			connect.WithSchema(membershipAPIMethods.ByName("LeaveServer")),
+			connect.WithClientOptions(opts...),
+		),
+		getClusterHealth: connect.NewClient[v1.GetClusterHealthRequest, v1.GetClusterHealthResponse](
+			httpClient,
+			baseURL+MembershipAPIGetClusterHealthProcedure,
+			connect.WithSchema(membershipAPIMethods.ByName("GetClusterHealth")),
+			connect.WithClientOptions(opts...),
+		),
+		getReadIndex: connect.NewClient[v1.GetReadIndexRequest, v1.GetReadIndexResponse](
+			httpClient,
+			baseURL+MembershipAPIGetReadIndexProcedure,
+			connect.WithSchema(membershipAPIMethods.ByName("GetReadIndex")),
 			connect.WithClientOptions(opts...),
 		),
 	}
@@ -224,9 +720,11 @@ func NewMembershipAPIClient(httpClient connect.HTTPClient, baseURL string, opts
 
 // membershipAPIClient implements MembershipAPIClient.
 type membershipAPIClient struct {
-	getServers  *connect.Client[v1.GetServersRequest, v1.GetServersResponse]
-	joinServer  *connect.Client[v1.JoinServerRequest, v1.JoinServerResponse]
-	leaveServer *connect.Client[v1.LeaveServerRequest, v1.LeaveServerResponse]
+	getServers       *connect.Client[v1.GetServersRequest, v1.GetServersResponse]
+	joinServer       *connect.Client[v1.JoinServerRequest, v1.JoinServerResponse]
+	leaveServer      *connect.Client[v1.LeaveServerRequest, v1.LeaveServerResponse]
+	getClusterHealth *connect.Client[v1.GetClusterHealthRequest, v1.GetClusterHealthResponse]
+	getReadIndex     *connect.Client[v1.GetReadIndexRequest, v1.GetReadIndexResponse]
 }
 
 // GetServers calls dkv.v1.MembershipAPI.GetServers.
@@ -244,11 +742,28 @@ func (c *membershipAPIClient) LeaveServer(ctx context.Context, req *connect.Requ
 	return c.leaveServer.CallUnary(ctx, req)
 }
 
+// GetClusterHealth calls dkv.v1.MembershipAPI.GetClusterHealth.
+func (c *membershipAPIClient) GetClusterHealth(ctx context.Context, req *connect.Request[v1.GetClusterHealthRequest]) (*connect.Response[v1.GetClusterHealthResponse], error) {
+	return c.getClusterHealth.CallUnary(ctx, req)
+}
+
+// GetReadIndex calls dkv.v1.MembershipAPI.GetReadIndex.
+func (c *membershipAPIClient) GetReadIndex(ctx context.Context, req *connect.Request[v1.GetReadIndexRequest]) (*connect.Response[v1.GetReadIndexResponse], error) {
+	return c.getReadIndex.CallUnary(ctx, req)
+}
+
 // MembershipAPIHandler is an implementation of the dkv.v1.MembershipAPI service.
 type MembershipAPIHandler interface {
 	GetServers(context.Context, *connect.Request[v1.GetServersRequest]) (*connect.Response[v1.GetServersResponse], error)
 	JoinServer(context.Context, *connect.Request[v1.JoinServerRequest]) (*connect.Response[v1.JoinServerResponse], error)
 	LeaveServer(context.Context, *connect.Request[v1.LeaveServerRequest]) (*connect.Response[v1.LeaveServerResponse], error)
+	// GetClusterHealth returns the health of the servers, as seen by the leader.
+	GetClusterHealth(context.Context, *connect.Request[v1.GetClusterHealthRequest]) (*connect.Response[v1.GetClusterHealthResponse], error)
+	// GetReadIndex returns the index which a node must apply to serve a
+	// linearizable read. It is served by the leader once it confirmed its
+	// leadership, without writing to the Raft log. Other nodes fail with
+	// UNAVAILABLE.
+	GetReadIndex(context.Context, *connect.Request[v1.GetReadIndexRequest]) (*connect.Response[v1.GetReadIndexResponse], error)
 }
 
 // NewMembershipAPIHandler builds an HTTP handler from the service implementation. It returns the
@@ -257,22 +772,35 @@ type MembershipAPIHandler interface {
 // By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
 // and JSON codecs. They also support gzip compression.
 func NewMembershipAPIHandler(svc MembershipAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	membershipAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("MembershipAPI").Methods()
 	membershipAPIGetServersHandler := connect.NewUnaryHandler(
 		MembershipAPIGetServersProcedure,
 		svc.GetServers,
-		connect.WithSchema(membershipAPIGetServersMethodDescriptor),
+		connect.WithSchema(membershipAPIMethods.ByName("GetServers")),
 		connect.WithHandlerOptions(opts...),
 	)
 	membershipAPIJoinServerHandler := connect.NewUnaryHandler(
 		MembershipAPIJoinServerProcedure,
 		svc.JoinServer,
-		connect.WithSchema(membershipAPIJoinServerMethodDescriptor),
+		connect.WithSchema(membershipAPIMethods.ByName("JoinServer")),
 		connect.WithHandlerOptions(opts...),
 	)
 	membershipAPILeaveServerHandler := connect.NewUnaryHandler(
 		MembershipAPILeaveServerProcedure,
 		svc.LeaveServer,
-		connect.WithSchema(membershipAPILeaveServerMethodDescriptor),
+		connect.WithSchema(membershipAPIMethods.ByName("LeaveServer")),
+		connect.WithHandlerOptions(opts...),
+	)
+	membershipAPIGetClusterHealthHandler := connect.NewUnaryHandler(
+		MembershipAPIGetClusterHealthProcedure,
+		svc.GetClusterHealth,
+		connect.WithSchema(membershipAPIMethods.ByName("GetClusterHealth")),
+		connect.WithHandlerOptions(opts...),
+	)
+	membershipAPIGetReadIndexHandler := connect.NewUnaryHandler(
+		MembershipAPIGetReadIndexProcedure,
+		svc.GetReadIndex,
+		connect.WithSchema(membershipAPIMethods.ByName("GetReadIndex")),
 		connect.WithHandlerOptions(opts...),
 	)
 	return "/dkv.v1.MembershipAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -283,6 +811,10 @@ func NewMembershipAPIHandler(svc MembershipAPIHandler, opts ...connect.HandlerOp
 			membershipAPIJoinServerHandler.ServeHTTP(w, r)
 		case MembershipAPILeaveServerProcedure:
 			membershipAPILeaveServerHandler.ServeHTTP(w, r)
+		case MembershipAPIGetClusterHealthProcedure:
+			membershipAPIGetClusterHealthHandler.ServeHTTP(w, r)
+		case MembershipAPIGetReadIndexProcedure:
+			membershipAPIGetReadIndexHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -303,3 +835,141 @@ func (UnimplementedMembershipAPIHandler) JoinServer(context.Context, *connect.Re
 func (UnimplementedMembershipAPIHandler) LeaveServer(context.Context, *connect.Request[v1.LeaveServerRequest]) (*connect.Response[v1.LeaveServerResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.MembershipAPI.LeaveServer is not implemented"))
 }
+
+func (UnimplementedMembershipAPIHandler) GetClusterHealth(context.Context, *connect.Request[v1.GetClusterHealthRequest]) (*connect.Response[v1.GetClusterHealthResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.MembershipAPI.GetClusterHealth is not implemented"))
+}
+
+func (UnimplementedMembershipAPIHandler) GetReadIndex(context.Context, *connect.Request[v1.GetReadIndexRequest]) (*connect.Response[v1.GetReadIndexResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.MembershipAPI.GetReadIndex is not implemented"))
+}
+
+// AdminAPIClient is a client for the dkv.v1.AdminAPI service.
+type AdminAPIClient interface {
+	// Snapshot streams a point-in-time backup of the store.
+	Snapshot(context.Context, *connect.Request[v1.SnapshotRequest]) (*connect.ServerStreamForClient[v1.SnapshotResponse], error)
+	// SetFaults injects network faults on the Raft connections to a peer. It is
+	// a debug RPC, only available if fault injection is enabled on the node.
+	SetFaults(context.Context, *connect.Request[v1.SetFaultsRequest]) (*connect.Response[v1.SetFaultsResponse], error)
+	// ClearFaults removes the injected network faults.
+	ClearFaults(context.Context, *connect.Request[v1.ClearFaultsRequest]) (*connect.Response[v1.ClearFaultsResponse], error)
+}
+
+// NewAdminAPIClient constructs a client for the dkv.v1.AdminAPI service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminAPIClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("AdminAPI").Methods()
+	return &adminAPIClient{
+		snapshot: connect.NewClient[v1.SnapshotRequest, v1.SnapshotResponse](
+			httpClient,
+			baseURL+AdminAPISnapshotProcedure,
+			connect.WithSchema(adminAPIMethods.ByName("Snapshot")),
+			connect.WithClientOptions(opts...),
+		),
+		setFaults: connect.NewClient[v1.SetFaultsRequest, v1.SetFaultsResponse](
+			httpClient,
+			baseURL+AdminAPISetFaultsProcedure,
+			connect.WithSchema(adminAPIMethods.ByName("SetFaults")),
+			connect.WithClientOptions(opts...),
+		),
+		clearFaults: connect.NewClient[v1.ClearFaultsRequest, v1.ClearFaultsResponse](
+			httpClient,
+			baseURL+AdminAPIClearFaultsProcedure,
+			connect.WithSchema(adminAPIMethods.ByName("ClearFaults")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminAPIClient implements AdminAPIClient.
+type adminAPIClient struct {
+	snapshot    *connect.Client[v1.SnapshotRequest, v1.SnapshotResponse]
+	setFaults   *connect.Client[v1.SetFaultsRequest, v1.SetFaultsResponse]
+	clearFaults *connect.Client[v1.ClearFaultsRequest, v1.ClearFaultsResponse]
+}
+
+// Snapshot calls dkv.v1.AdminAPI.Snapshot.
+func (c *adminAPIClient) Snapshot(ctx context.Context, req *connect.Request[v1.SnapshotRequest]) (*connect.ServerStreamForClient[v1.SnapshotResponse], error) {
+	return c.snapshot.CallServerStream(ctx, req)
+}
+
+// SetFaults calls dkv.v1.AdminAPI.SetFaults.
+func (c *adminAPIClient) SetFaults(ctx context.Context, req *connect.Request[v1.SetFaultsRequest]) (*connect.Response[v1.SetFaultsResponse], error) {
+	return c.setFaults.CallUnary(ctx, req)
+}
+
+// ClearFaults calls dkv.v1.AdminAPI.ClearFaults.
+func (c *adminAPIClient) ClearFaults(ctx context.Context, req *connect.Request[v1.ClearFaultsRequest]) (*connect.Response[v1.ClearFaultsResponse], error) {
+	return c.clearFaults.CallUnary(ctx, req)
+}
+
+// AdminAPIHandler is an implementation of the dkv.v1.AdminAPI service.
+type AdminAPIHandler interface {
+	// Snapshot streams a point-in-time backup of the store.
+	Snapshot(context.Context, *connect.Request[v1.SnapshotRequest], *connect.ServerStream[v1.SnapshotResponse]) error
+	// SetFaults injects network faults on the Raft connections to a peer. It is
+	// a debug RPC, only available if fault injection is enabled on the node.
+	SetFaults(context.Context, *connect.Request[v1.SetFaultsRequest]) (*connect.Response[v1.SetFaultsResponse], error)
+	// ClearFaults removes the injected network faults.
+	ClearFaults(context.Context, *connect.Request[v1.ClearFaultsRequest]) (*connect.Response[v1.ClearFaultsResponse], error)
+}
+
+// NewAdminAPIHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminAPIHandler(svc AdminAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminAPIMethods := v1.File_dkv_v1_dkv_proto.Services().ByName("AdminAPI").Methods()
+	adminAPISnapshotHandler := connect.NewServerStreamHandler(
+		AdminAPISnapshotProcedure,
+		svc.Snapshot,
+		connect.WithSchema(adminAPIMethods.ByName("Snapshot")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminAPISetFaultsHandler := connect.NewUnaryHandler(
+		AdminAPISetFaultsProcedure,
+		svc.SetFaults,
+		connect.WithSchema(adminAPIMethods.ByName("SetFaults")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminAPIClearFaultsHandler := connect.NewUnaryHandler(
+		AdminAPIClearFaultsProcedure,
+		svc.ClearFaults,
+		connect.WithSchema(adminAPIMethods.ByName("ClearFaults")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/dkv.v1.AdminAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminAPISnapshotProcedure:
+			adminAPISnapshotHandler.ServeHTTP(w, r)
+		case AdminAPISetFaultsProcedure:
+			adminAPISetFaultsHandler.ServeHTTP(w, r)
+		case AdminAPIClearFaultsProcedure:
+			adminAPIClearFaultsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminAPIHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminAPIHandler struct{}
+
+func (UnimplementedAdminAPIHandler) Snapshot(context.Context, *connect.Request[v1.SnapshotRequest], *connect.ServerStream[v1.SnapshotResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.AdminAPI.Snapshot is not implemented"))
+}
+
+func (UnimplementedAdminAPIHandler) SetFaults(context.Context, *connect.Request[v1.SetFaultsRequest]) (*connect.Response[v1.SetFaultsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.AdminAPI.SetFaults is not implemented"))
+}
+
+func (UnimplementedAdminAPIHandler) ClearFaults(context.Context, *connect.Request[v1.ClearFaultsRequest]) (*connect.Response[v1.ClearFaultsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("dkv.v1.AdminAPI.ClearFaults is not implemented"))
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/backup"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
+)
+
+// snapshotChunkSize is the maximum size of a streamed snapshot chunk.
+const snapshotChunkSize = 64 * 1024
+
+var _ dkvv1connect.AdminAPIHandler = (*AdminAPIHandler)(nil)
+
+type AdminAPIHandler struct {
+	Store *distributed.Store
+	// Audit records the administrative operations, if set.
+	Audit audit.Sink
+	// Faults injects network faults in the Raft transport, if set. The fault
+	// RPCs are unavailable otherwise.
+	Faults *distributed.FaultInjector
+}
+
+func (a *AdminAPIHandler) Snapshot(
+	ctx context.Context,
+	req *connect.Request[dkvv1.SnapshotRequest],
+	stream *connect.ServerStream[dkvv1.SnapshotResponse],
+) error {
+	index, err := a.snapshot(stream)
+	if a.Audit != nil {
+		a.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "Snapshot", "", index, err,
+		))
+	}
+	return err
+}
+
+func (a *AdminAPIHandler) snapshot(
+	stream *connect.ServerStream[dkvv1.SnapshotResponse],
+) (uint64, error) {
+	meta, snapshot, err := a.Store.Snapshot()
+	if err != nil {
+		return 0, err
+	}
+	defer snapshot.Close()
+
+	w := bufio.NewWriterSize(&chunkWriter{stream: stream}, snapshotChunkSize)
+	if _, err := backup.Write(w, meta.Index, meta.Term, snapshot); err != nil {
+		return meta.Index, err
+	}
+	return meta.Index, w.Flush()
+}
+
+func (a *AdminAPIHandler) SetFaults(
+	ctx context.Context,
+	req *connect.Request[dkvv1.SetFaultsRequest],
+) (*connect.Response[dkvv1.SetFaultsResponse], error) {
+	if a.Faults == nil {
+		return nil, errFaultInjectionDisabled()
+	}
+	msg := req.Msg
+	if msg.GetPeerAddress() == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("peer address is required"))
+	}
+	for _, rate := range []float64{msg.GetDropRate(), msg.GetResetRate()} {
+		if rate < 0 || rate > 1 {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				errors.New("rates must be between 0 and 1"),
+			)
+		}
+	}
+	a.Faults.SetFaults(raft.ServerAddress(msg.GetPeerAddress()), distributed.Faults{
+		Partitioned: msg.GetPartitioned(),
+		DropRate:    msg.GetDropRate(),
+		ResetRate:   msg.GetResetRate(),
+		Delay:       msg.GetDelay().AsDuration(),
+		Jitter:      msg.GetJitter().AsDuration(),
+	})
+	if a.Audit != nil {
+		a.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "SetFaults", msg.GetPeerAddress(), 0, nil,
+		))
+	}
+	return connect.NewResponse(&dkvv1.SetFaultsResponse{}), nil
+}
+
+func (a *AdminAPIHandler) ClearFaults(
+	ctx context.Context,
+	req *connect.Request[dkvv1.ClearFaultsRequest],
+) (*connect.Response[dkvv1.ClearFaultsResponse], error) {
+	if a.Faults == nil {
+		return nil, errFaultInjectionDisabled()
+	}
+	a.Faults.Clear()
+	if a.Audit != nil {
+		a.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "ClearFaults", "", 0, nil,
+		))
+	}
+	return connect.NewResponse(&dkvv1.ClearFaultsResponse{}), nil
+}
+
+func errFaultInjectionDisabled() error {
+	return connect.NewError(
+		connect.CodeFailedPrecondition,
+		errors.New("fault injection is disabled"),
+	)
+}
+
+// chunkWriter sends each write as a chunk of the stream.
+type chunkWriter struct {
+	stream *connect.ServerStream[dkvv1.SnapshotResponse]
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&dkvv1.SnapshotResponse{Chunk: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	dkvv1 "distributed-kv/gen/dkv/v1"
 	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/audit"
 	"distributed-kv/internal/store"
+	"errors"
+	"time"
 
 	"connectrpc.com/connect"
 )
@@ -13,29 +16,81 @@ var _ dkvv1connect.DkvAPIHandler = (*DkvAPIHandler)(nil)
 
 type DkvAPIHandler struct {
 	store.Store
+	// Audit records the write operations, if set.
+	Audit audit.Sink
 }
 
 func (d *DkvAPIHandler) Delete(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[dkvv1.DeleteRequest],
 ) (*connect.Response[dkvv1.DeleteResponse], error) {
-	return &connect.Response[dkvv1.DeleteResponse]{}, d.Store.Delete(req.Msg.Key)
+	index, err := d.Store.Delete(req.Msg.Key)
+	if d.Audit != nil {
+		d.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "Delete", req.Msg.Key, index, err,
+		))
+	}
+	return &connect.Response[dkvv1.DeleteResponse]{
+		Msg: &dkvv1.DeleteResponse{Index: index},
+	}, keyError(err)
 }
 
 func (d *DkvAPIHandler) Get(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[dkvv1.GetRequest],
 ) (*connect.Response[dkvv1.GetResponse], error) {
-	res, err := d.Store.Get(req.Msg.Key)
-	if err != nil {
-		return nil, err
+	opts := store.ReadOptions{
+		MaxStaleness: req.Msg.GetMaxStaleness().AsDuration(),
+		MinIndex:     req.Msg.GetMinIndex(),
+		Linearizable: req.Msg.GetLinearizable(),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.Timeout = time.Until(deadline)
+	}
+	res, err := d.Store.Get(req.Msg.Key, opts)
+	if errors.Is(err, store.ErrStaleRead) {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	} else if errors.Is(err, store.ErrNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, keyError(err)
 	}
 	return &connect.Response[dkvv1.GetResponse]{Msg: &dkvv1.GetResponse{Value: res}}, nil
 }
 
+func (d *DkvAPIHandler) Increment(
+	ctx context.Context,
+	req *connect.Request[dkvv1.IncrementRequest],
+) (*connect.Response[dkvv1.IncrementResponse], error) {
+	index, value, err := d.Store.Increment(req.Msg.GetKey(), req.Msg.GetDelta(), store.IncrementOptions{
+		Initial: req.Msg.GetInitial(),
+		Min:     req.Msg.Min,
+		Max:     req.Msg.Max,
+	})
+	if d.Audit != nil {
+		d.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "Increment", req.Msg.GetKey(), index, err,
+		))
+	}
+	if err != nil {
+		return nil, incrementError(err)
+	}
+	return &connect.Response[dkvv1.IncrementResponse]{
+		Msg: &dkvv1.IncrementResponse{Value: value, Index: index},
+	}, nil
+}
+
 func (d *DkvAPIHandler) Set(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[dkvv1.SetRequest],
 ) (*connect.Response[dkvv1.SetResponse], error) {
-	return &connect.Response[dkvv1.SetResponse]{}, d.Store.Set(req.Msg.Key, req.Msg.Value)
+	index, err := d.Store.Set(req.Msg.Key, req.Msg.Value)
+	if d.Audit != nil {
+		d.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "Set", req.Msg.Key, index, err,
+		))
+	}
+	return &connect.Response[dkvv1.SetResponse]{
+		Msg: &dkvv1.SetResponse{Index: index},
+	}, keyError(err)
 }
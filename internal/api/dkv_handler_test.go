@@ -5,20 +5,30 @@ import (
 	dkvv1 "distributed-kv/gen/dkv/v1"
 	"distributed-kv/gen/dkv/v1/dkvv1connect"
 	"distributed-kv/internal/api"
+	"distributed-kv/internal/audit"
+	kvstore "distributed-kv/internal/store"
 	"distributed-kv/mocks/mockstore"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func TestDkvAPIHandler(t *testing.T) {
 	t.Parallel()
 
 	store := mockstore.NewStore(t)
-	svc := &api.DkvAPIHandler{Store: store}
+	sink := &recordingSink{}
+	svc := &api.DkvAPIHandler{Store: store, Audit: sink}
 	path, h := dkvv1connect.NewDkvAPIHandler(svc)
 	mux := http.NewServeMux()
 	mux.Handle(path, h)
@@ -29,10 +39,10 @@ func TestDkvAPIHandler(t *testing.T) {
 
 	t.Run("Set", func(t *testing.T) {
 		// Arrange
-		store.EXPECT().Set("key", "value").Return(nil)
+		store.EXPECT().Set("key", "value").Return(1, nil)
 
 		// Act
-		_, err := client.Set(context.Background(), &connect.Request[dkvv1.SetRequest]{
+		res, err := client.Set(context.Background(), &connect.Request[dkvv1.SetRequest]{
 			Msg: &dkvv1.SetRequest{
 				Key:   "key",
 				Value: "value",
@@ -41,11 +51,18 @@ func TestDkvAPIHandler(t *testing.T) {
 
 		// Assert
 		require.NoError(t, err)
+		require.Equal(t, uint64(1), res.Msg.GetIndex())
+		entry := sink.last()
+		require.Equal(t, "Set", entry.Operation)
+		require.Equal(t, "key", entry.Key)
+		require.Equal(t, uint64(1), entry.Index)
+		require.Equal(t, audit.OutcomeSuccess, entry.Outcome)
+		require.NotEmpty(t, entry.Caller)
 	})
 
 	t.Run("Get", func(t *testing.T) {
 		// Arrange
-		store.EXPECT().Get("key").Return("value", nil)
+		store.EXPECT().Get("key", kvstore.ReadOptions{}).Return("value", nil)
 
 		// Act
 		res, err := client.Get(context.Background(), &connect.Request[dkvv1.GetRequest]{
@@ -59,9 +76,72 @@ func TestDkvAPIHandler(t *testing.T) {
 		require.Equal(t, "value", res.Msg.Value)
 	})
 
+	t.Run("Get with a consistency token", func(t *testing.T) {
+		// Arrange
+		store.EXPECT().Get("token", kvstore.ReadOptions{MinIndex: 1}).Return("value", nil)
+
+		// Act
+		res, err := client.Get(context.Background(), &connect.Request[dkvv1.GetRequest]{
+			Msg: &dkvv1.GetRequest{
+				Key:      "token",
+				MinIndex: 1,
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "value", res.Msg.GetValue())
+	})
+
+	t.Run("Get stale read is retryable", func(t *testing.T) {
+		// Arrange
+		store.EXPECT().
+			Get("stale", kvstore.ReadOptions{MaxStaleness: time.Second}).
+			Return("", fmt.Errorf("%w: no contact with the leader", kvstore.ErrStaleRead))
+
+		// Act
+		_, err := client.Get(context.Background(), &connect.Request[dkvv1.GetRequest]{
+			Msg: &dkvv1.GetRequest{
+				Key:          "stale",
+				MaxStaleness: durationpb.New(time.Second),
+			},
+		})
+
+		// Assert
+		require.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+	})
+
+	t.Run("Get missing key", func(t *testing.T) {
+		// Arrange
+		store.EXPECT().
+			Get("missing", kvstore.ReadOptions{}).
+			Return("", kvstore.ErrNotFound)
+
+		// Act
+		_, err := client.Get(context.Background(), &connect.Request[dkvv1.GetRequest]{
+			Msg: &dkvv1.GetRequest{Key: "missing"},
+		})
+
+		// Assert
+		require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("Set reserved key", func(t *testing.T) {
+		// Arrange
+		store.EXPECT().Set("\x00applied", "1").Return(0, kvstore.ErrReservedKey)
+
+		// Act
+		_, err := client.Set(context.Background(), &connect.Request[dkvv1.SetRequest]{
+			Msg: &dkvv1.SetRequest{Key: "\x00applied", Value: "1"},
+		})
+
+		// Assert
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		// Arrange
-		store.EXPECT().Delete("key").Return(nil)
+		store.EXPECT().Delete("key").Return(2, nil)
 
 		// Act
 		_, err := client.Delete(context.Background(), &connect.Request[dkvv1.DeleteRequest]{
@@ -72,5 +152,102 @@ func TestDkvAPIHandler(t *testing.T) {
 
 		// Assert
 		require.NoError(t, err)
+		entry := sink.last()
+		require.Equal(t, "Delete", entry.Operation)
+		require.Equal(t, uint64(2), entry.Index)
+	})
+
+	t.Run("Increment", func(t *testing.T) {
+		// Arrange
+		opts := kvstore.IncrementOptions{Initial: 10, Min: proto.Int64(0)}
+		store.EXPECT().Increment("counter", int64(-1), opts).Return(3, 9, nil)
+
+		// Act
+		res, err := client.Increment(context.Background(), &connect.Request[dkvv1.IncrementRequest]{
+			Msg: &dkvv1.IncrementRequest{
+				Key:     "counter",
+				Delta:   -1,
+				Initial: 10,
+				Min:     proto.Int64(0),
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, int64(9), res.Msg.GetValue())
+		require.Equal(t, uint64(3), res.Msg.GetIndex())
+		entry := sink.last()
+		require.Equal(t, "Increment", entry.Operation)
+		require.Equal(t, "counter", entry.Key)
+	})
+
+	t.Run("Increment errors", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			err      error
+			expected connect.Code
+		}{
+			{title: "Not an integer", err: kvstore.ErrNotInteger, expected: connect.CodeFailedPrecondition},
+			{title: "Overflow", err: kvstore.ErrOverflow, expected: connect.CodeOutOfRange},
+			{title: "Out of range", err: kvstore.ErrOutOfRange, expected: connect.CodeOutOfRange},
+			{title: "No leader", err: kvstore.ErrNoLeader, expected: connect.CodeUnavailable},
+			{
+				title:    "Leadership lost is not retryable",
+				err:      raft.ErrLeadershipLost,
+				expected: connect.CodeUnknown,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				// Arrange
+				key := "increment/" + tt.title
+				store.EXPECT().Increment(key, int64(1), kvstore.IncrementOptions{}).Return(0, 0, tt.err)
+
+				// Act
+				_, err := client.Increment(context.Background(), &connect.Request[dkvv1.IncrementRequest]{
+					Msg: &dkvv1.IncrementRequest{Key: key, Delta: 1},
+				})
+
+				// Assert
+				require.Equal(t, tt.expected, connect.CodeOf(err))
+			})
+		}
 	})
+
+	t.Run("Set failure is audited", func(t *testing.T) {
+		// Arrange
+		store.EXPECT().Set("key", "fail").Return(0, errors.New("no leader"))
+
+		// Act
+		_, err := client.Set(context.Background(), &connect.Request[dkvv1.SetRequest]{
+			Msg: &dkvv1.SetRequest{
+				Key:   "key",
+				Value: "fail",
+			},
+		})
+
+		// Assert
+		require.Error(t, err)
+		entry := sink.last()
+		require.Equal(t, audit.OutcomeFailure, entry.Outcome)
+		require.Equal(t, "no leader", entry.Error)
+	})
+}
+
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []audit.Entry
+}
+
+func (s *recordingSink) Record(e audit.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (s *recordingSink) last() audit.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[len(s.entries)-1]
 }
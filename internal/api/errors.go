@@ -0,0 +1,73 @@
+package api
+
+import (
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
+)
+
+// leaderError marks the errors caused by a missing or changing leader as
+// UNAVAILABLE, so that the clients retry them.
+func leaderError(err error) error {
+	if errors.Is(err, store.ErrNoLeader) ||
+		errors.Is(err, distributed.ErrNotLeader) ||
+		errors.Is(err, raft.ErrNotLeader) ||
+		errors.Is(err, raft.ErrLeadershipLost) ||
+		errors.Is(err, raft.ErrLeadershipTransferInProgress) {
+		return connect.NewError(connect.CodeUnavailable, err)
+	}
+	return err
+}
+
+// keyError marks the reads and writes of the keys with the reserved prefix as
+// INVALID_ARGUMENT, and converts the other errors like leaderError.
+func keyError(err error) error {
+	if errors.Is(err, store.ErrReservedKey) {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return leaderError(err)
+}
+
+// incrementError converts the errors of an increment. Unlike leaderError, a
+// leadership lost during the increment is not UNAVAILABLE, since the increment
+// may have been committed and must not be retried.
+func incrementError(err error) error {
+	switch {
+	case errors.Is(err, raft.ErrLeadershipLost):
+		return connect.NewError(connect.CodeUnknown, err)
+	case errors.Is(err, store.ErrNotInteger), errors.Is(err, store.ErrWrongType):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	case errors.Is(err, store.ErrOverflow), errors.Is(err, store.ErrOutOfRange):
+		return connect.NewError(connect.CodeOutOfRange, err)
+	}
+	return keyError(err)
+}
+
+// leaseError converts the errors of the locks and of the elections. Like
+// incrementError, a leadership lost while creating a lease is not UNAVAILABLE,
+// since the lease may have been created and must not be created twice.
+func leaseError(err error) error {
+	switch {
+	case errors.Is(err, raft.ErrLeadershipLost):
+		return connect.NewError(connect.CodeUnknown, err)
+	case errors.Is(err, distributed.ErrLeaseExpired):
+		return connect.NewError(connect.CodeNotFound, err)
+	}
+	return leaderError(err)
+}
+
+// queueError converts the errors of the queues. Like incrementError, a
+// leadership lost during a write is not UNAVAILABLE, since the write may have
+// been committed and must not be retried.
+func queueError(err error) error {
+	switch {
+	case errors.Is(err, raft.ErrLeadershipLost):
+		return connect.NewError(connect.CodeUnknown, err)
+	case errors.Is(err, distributed.ErrClaimExpired):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	return leaderError(err)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"connectrpc.com/connect"
+)
+
+type connContextKey struct{}
+
+// ConnContext stores the connection in the context to identify the callers.
+//
+// It is meant to be used as http.Server.ConnContext.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// CallerIdentity returns the Common Name of the client certificate of the
+// caller, or its address if the caller is not authenticated.
+func CallerIdentity(ctx context.Context, peer connect.Peer) string {
+	if c, ok := ctx.Value(connContextKey{}).(*tls.Conn); ok {
+		if certs := c.ConnectionState().PeerCertificates; len(certs) > 0 {
+			return certs[0].Subject.CommonName
+		}
+	}
+	return peer.Addr
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// defaultLeaseTTL is the TTL of the leases requested without TTL.
+	defaultLeaseTTL = time.Minute
+	// minLeaseTTL is the minimum TTL of a lease, which is kept alive several
+	// times per TTL.
+	minLeaseTTL = time.Second
+)
+
+var _ dkvv1connect.LockAPIHandler = (*LockAPIHandler)(nil)
+
+// LockAPIHandler serves the locks and the elections. The waiting requests
+// hold the store, so the handler must be closed before the store is shut down.
+type LockAPIHandler struct {
+	Store *distributed.Store
+	// Audit records the acquisitions and the releases, if set.
+	Audit audit.Sink
+
+	// mu guards closed, so that no wait starts once closed.
+	mu     sync.Mutex
+	closed bool
+	waits  sync.WaitGroup
+}
+
+// Close waits for the waiting requests to return and refuses the next ones.
+// The connections must be closed first, so that the requests are canceled.
+func (l *LockAPIHandler) Close() {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	l.waits.Wait()
+}
+
+// begin registers a waiting request. It returns false if the handler is
+// closed, otherwise the request must call l.waits.Done.
+func (l *LockAPIHandler) begin() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.waits.Add(1)
+	return true
+}
+
+func (l *LockAPIHandler) Lock(
+	ctx context.Context,
+	req *connect.Request[dkvv1.LockRequest],
+) (*connect.Response[dkvv1.LockResponse], error) {
+	lease, err := l.acquire(ctx, req.Msg.GetName(), "", req.Msg.GetTtl())
+	l.record(ctx, req.Peer(), "Lock", req.Msg.GetName(), uint64(lease.GetRevision()), err)
+	if err != nil {
+		return nil, leaseError(err)
+	}
+	return connect.NewResponse(&dkvv1.LockResponse{Lease: lease}), nil
+}
+
+func (l *LockAPIHandler) Unlock(
+	ctx context.Context,
+	req *connect.Request[dkvv1.UnlockRequest],
+) (*connect.Response[dkvv1.UnlockResponse], error) {
+	index, err := l.release(req.Msg.GetKey(), req.Msg.GetRevision())
+	l.record(ctx, req.Peer(), "Unlock", req.Msg.GetKey(), index, err)
+	if err != nil {
+		return nil, leaseError(err)
+	}
+	return connect.NewResponse(&dkvv1.UnlockResponse{Index: index}), nil
+}
+
+func (l *LockAPIHandler) Campaign(
+	ctx context.Context,
+	req *connect.Request[dkvv1.CampaignRequest],
+) (*connect.Response[dkvv1.CampaignResponse], error) {
+	lease, err := l.acquire(ctx, req.Msg.GetName(), req.Msg.GetValue(), req.Msg.GetTtl())
+	l.record(ctx, req.Peer(), "Campaign", req.Msg.GetName(), uint64(lease.GetRevision()), err)
+	if err != nil {
+		return nil, leaseError(err)
+	}
+	return connect.NewResponse(&dkvv1.CampaignResponse{Lease: lease}), nil
+}
+
+func (l *LockAPIHandler) Resign(
+	ctx context.Context,
+	req *connect.Request[dkvv1.ResignRequest],
+) (*connect.Response[dkvv1.ResignResponse], error) {
+	index, err := l.release(req.Msg.GetKey(), req.Msg.GetRevision())
+	l.record(ctx, req.Peer(), "Resign", req.Msg.GetKey(), index, err)
+	if err != nil {
+		return nil, leaseError(err)
+	}
+	return connect.NewResponse(&dkvv1.ResignResponse{Index: index}), nil
+}
+
+func (l *LockAPIHandler) Observe(
+	ctx context.Context,
+	req *connect.Request[dkvv1.ObserveRequest],
+	stream *connect.ServerStream[dkvv1.ObserveResponse],
+) error {
+	if req.Msg.GetName() == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("name is required"))
+	}
+	if !l.begin() {
+		return errServerClosed()
+	}
+	defer l.waits.Done()
+	return leaseError(l.Store.Observe(ctx, req.Msg.GetName(), func(leader *dkvv1.Lease) error {
+		return stream.Send(&dkvv1.ObserveResponse{Leader: leader})
+	}))
+}
+
+func (l *LockAPIHandler) KeepAlive(
+	_ context.Context,
+	req *connect.Request[dkvv1.KeepAliveRequest],
+) (*connect.Response[dkvv1.KeepAliveResponse], error) {
+	if req.Msg.GetKey() == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("key is required"))
+	}
+	ttl, err := leaseTTL(req.Msg.GetTtl())
+	if err != nil {
+		return nil, err
+	}
+	index, err := l.Store.RenewLease(req.Msg.GetKey(), ttl)
+	if err != nil {
+		return nil, leaseError(err)
+	}
+	return connect.NewResponse(&dkvv1.KeepAliveResponse{Index: index}), nil
+}
+
+// acquire waits until a new lease of the name is the oldest one.
+func (l *LockAPIHandler) acquire(
+	ctx context.Context,
+	name, value string,
+	ttl *durationpb.Duration,
+) (*dkvv1.Lease, error) {
+	if name == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("name is required"))
+	}
+	d, err := leaseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !l.begin() {
+		return nil, errServerClosed()
+	}
+	defer l.waits.Done()
+	return l.Store.Acquire(ctx, name, value, d)
+}
+
+// release deletes a lease held with the revision, and returns the Raft index
+// of the deletion.
+func (l *LockAPIHandler) release(key string, revision int64) (uint64, error) {
+	if key == "" {
+		return 0, connect.NewError(connect.CodeInvalidArgument, errors.New("key is required"))
+	}
+	return l.Store.Release(key, revision)
+}
+
+func (l *LockAPIHandler) record(
+	ctx context.Context,
+	peer connect.Peer,
+	operation, key string,
+	index uint64,
+	err error,
+) {
+	if l.Audit != nil {
+		l.Audit.Record(audit.NewEntry(CallerIdentity(ctx, peer), operation, key, index, err))
+	}
+}
+
+// leaseTTL returns the TTL of a lease, or the default TTL if it is not set.
+func leaseTTL(ttl *durationpb.Duration) (time.Duration, error) {
+	if ttl == nil {
+		return defaultLeaseTTL, nil
+	}
+	if d := ttl.AsDuration(); d >= minLeaseTTL {
+		return d, nil
+	}
+	return 0, connect.NewError(
+		connect.CodeInvalidArgument,
+		fmt.Errorf("ttl must be at least %s", minLeaseTTL),
+	)
+}
+
+func errServerClosed() error {
+	return connect.NewError(connect.CodeUnavailable, errors.New("server closed"))
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	dkvv1 "distributed-kv/gen/dkv/v1"
 	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/audit"
 	"distributed-kv/internal/store/distributed"
+	"errors"
+	"fmt"
 
 	"connectrpc.com/connect"
 	"github.com/hashicorp/raft"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var _ dkvv1connect.MembershipAPIHandler = (*MembershipAPIHandler)(nil)
@@ -15,6 +20,8 @@ var _ dkvv1connect.MembershipAPIHandler = (*MembershipAPIHandler)(nil)
 type MembershipAPIHandler struct {
 	AdvertiseNodes map[raft.ServerID]string
 	Store          *distributed.Store
+	// Audit records the membership changes, if set.
+	Audit audit.Sink
 }
 
 func (m *MembershipAPIHandler) GetServers(
@@ -27,37 +34,111 @@ func (m *MembershipAPIHandler) GetServers(
 	}
 	protoServers := make([]*dkvv1.Server, 0, len(srvs))
 	leaderAddr, leaderID := m.Store.GetLeader()
+	zones := m.Store.Zones()
 	for _, node := range srvs {
 		protoServers = append(protoServers, &dkvv1.Server{
 			Id:          string(node.ID),
 			RaftAddress: string(node.Address),
 			RpcAddress:  m.AdvertiseNodes[node.ID],
 			IsLeader:    node.ID == leaderID && node.Address == leaderAddr,
+			Zone:        zones[node.ID],
+			IsVoter:     node.Suffrage == raft.Voter,
 		})
 	}
 
 	return &connect.Response[dkvv1.GetServersResponse]{
 		Msg: &dkvv1.GetServersResponse{
-			Servers: protoServers,
+			Servers:      protoServers,
+			ClusterToken: m.Store.ClusterToken(),
 		},
 	}, nil
 }
 
 func (m *MembershipAPIHandler) JoinServer(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[dkvv1.JoinServerRequest],
 ) (*connect.Response[dkvv1.JoinServerResponse], error) {
-	return &connect.Response[dkvv1.JoinServerResponse]{}, m.Store.Join(
-		raft.ServerID(req.Msg.GetId()),
-		raft.ServerAddress(req.Msg.GetAddress()),
-	)
+	var index uint64
+	var err error
+	if token := req.Msg.GetClusterToken(); token != "" && token != m.Store.ClusterToken() {
+		err = fmt.Errorf(
+			"%w: the cluster token is %q, got %q",
+			distributed.ErrClusterTokenMismatch,
+			m.Store.ClusterToken(),
+			token,
+		)
+	} else {
+		index, err = m.Store.Join(
+			raft.ServerID(req.Msg.GetId()),
+			raft.ServerAddress(req.Msg.GetAddress()),
+		)
+	}
+	if m.Audit != nil {
+		m.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "JoinServer", req.Msg.GetId(), index, err,
+		))
+	}
+	if errors.Is(err, distributed.ErrClusterTokenMismatch) {
+		err = connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	return &connect.Response[dkvv1.JoinServerResponse]{}, leaderError(err)
 }
 
 func (m *MembershipAPIHandler) LeaveServer(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[dkvv1.LeaveServerRequest],
 ) (*connect.Response[dkvv1.LeaveServerResponse], error) {
-	return &connect.Response[dkvv1.LeaveServerResponse]{}, m.Store.Leave(
-		raft.ServerID(req.Msg.GetId()),
-	)
+	index, err := m.Store.Leave(raft.ServerID(req.Msg.GetId()))
+	if m.Audit != nil {
+		m.Audit.Record(audit.NewEntry(
+			CallerIdentity(ctx, req.Peer()), "LeaveServer", req.Msg.GetId(), index, err,
+		))
+	}
+	return &connect.Response[dkvv1.LeaveServerResponse]{}, leaderError(err)
+}
+
+func (m *MembershipAPIHandler) GetClusterHealth(
+	context.Context,
+	*connect.Request[dkvv1.GetClusterHealthRequest],
+) (*connect.Response[dkvv1.GetClusterHealthResponse], error) {
+	health, err := m.Store.ClusterHealth()
+	if err != nil {
+		return nil, leaderError(err)
+	}
+	protoServers := make([]*dkvv1.ServerHealth, 0, len(health.Servers))
+	for _, srv := range health.Servers {
+		protoServer := &dkvv1.ServerHealth{
+			Id:          string(srv.ID),
+			RaftAddress: string(srv.Address),
+			IsLeader:    srv.Leader,
+			IsVoter:     srv.Suffrage == raft.Voter,
+			LastContact: durationpb.New(srv.LastContact),
+			LastIndex:   srv.LastIndex,
+			LastTerm:    srv.LastTerm,
+			Healthy:     srv.Healthy,
+		}
+		if !srv.StableSince.IsZero() {
+			protoServer.StableSince = timestamppb.New(srv.StableSince)
+		}
+		protoServers = append(protoServers, protoServer)
+	}
+
+	return &connect.Response[dkvv1.GetClusterHealthResponse]{
+		Msg: &dkvv1.GetClusterHealthResponse{
+			Healthy:          health.Healthy,
+			FailureTolerance: int32(health.FailureTolerance),
+			Servers:          protoServers,
+		},
+	}, nil
+}
+
+func (m *MembershipAPIHandler) GetReadIndex(
+	context.Context,
+	*connect.Request[dkvv1.GetReadIndexRequest],
+) (*connect.Response[dkvv1.GetReadIndexResponse], error) {
+	index, err := m.Store.LeaderReadIndex()
+	if err != nil {
+		return nil, leaderError(err)
+	}
+	return connect.NewResponse(&dkvv1.GetReadIndexResponse{Index: index}), nil
 }
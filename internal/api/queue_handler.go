@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+const (
+	// defaultVisibilityTimeout is the duration of the claims of the dequeues
+	// without visibility timeout.
+	defaultVisibilityTimeout = 30 * time.Second
+	// maxDequeueWait is the maximum duration a dequeue waits for a message.
+	maxDequeueWait = time.Minute
+	// maxPeekLimit is the maximum number of messages returned by a peek.
+	maxPeekLimit = 1000
+)
+
+var _ dkvv1connect.QueueAPIHandler = (*QueueAPIHandler)(nil)
+
+// QueueAPIHandler serves the queues. The waiting dequeues hold the store, so
+// the handler must be closed before the store is shut down.
+type QueueAPIHandler struct {
+	Store *distributed.Store
+	// Audit records the write operations, if set.
+	Audit audit.Sink
+
+	// mu guards closed, so that no wait starts once closed.
+	mu     sync.Mutex
+	closed bool
+	waits  sync.WaitGroup
+}
+
+// Close waits for the waiting dequeues to return and refuses the next ones.
+// The connections must be closed first, so that the dequeues are canceled.
+func (q *QueueAPIHandler) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.waits.Wait()
+}
+
+// begin registers a waiting dequeue. It returns false if the handler is closed,
+// otherwise the dequeue must call q.waits.Done.
+func (q *QueueAPIHandler) begin() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	q.waits.Add(1)
+	return true
+}
+
+func (q *QueueAPIHandler) Enqueue(
+	ctx context.Context,
+	req *connect.Request[dkvv1.EnqueueRequest],
+) (*connect.Response[dkvv1.EnqueueResponse], error) {
+	if err := validateQueue(req.Msg.GetQueue()); err != nil {
+		return nil, err
+	}
+	index, err := q.Store.Enqueue(req.Msg.GetQueue(), req.Msg.GetBody())
+	q.record(ctx, req.Peer(), "Enqueue", req.Msg.GetQueue(), index, err)
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return connect.NewResponse(&dkvv1.EnqueueResponse{Index: index}), nil
+}
+
+// Dequeue waits at most maxDequeueWait for a message.
+func (q *QueueAPIHandler) Dequeue(
+	ctx context.Context,
+	req *connect.Request[dkvv1.DequeueRequest],
+) (*connect.Response[dkvv1.DequeueResponse], error) {
+	if err := validateQueue(req.Msg.GetQueue()); err != nil {
+		return nil, err
+	}
+	visibilityTimeout := defaultVisibilityTimeout
+	if req.Msg.GetVisibilityTimeout() != nil {
+		visibilityTimeout = req.Msg.GetVisibilityTimeout().AsDuration()
+	}
+	if visibilityTimeout <= 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			errors.New("visibility timeout must be positive"),
+		)
+	}
+	if !q.begin() {
+		return nil, errServerClosed()
+	}
+	defer q.waits.Done()
+	wait := min(req.Msg.GetWait().AsDuration(), maxDequeueWait)
+	index, message, err := q.Store.Dequeue(ctx, req.Msg.GetQueue(), visibilityTimeout, wait)
+	if message != nil || err != nil {
+		q.record(ctx, req.Peer(), "Dequeue", req.Msg.GetQueue(), index, err)
+	}
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return connect.NewResponse(&dkvv1.DequeueResponse{Message: message, Index: index}), nil
+}
+
+func (q *QueueAPIHandler) Ack(
+	ctx context.Context,
+	req *connect.Request[dkvv1.AckRequest],
+) (*connect.Response[dkvv1.AckResponse], error) {
+	if err := validateQueue(req.Msg.GetQueue()); err != nil {
+		return nil, err
+	}
+	index, err := q.Store.Ack(req.Msg.GetQueue(), req.Msg.GetId(), req.Msg.GetClaim())
+	q.record(ctx, req.Peer(), "Ack", req.Msg.GetQueue(), index, err)
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return connect.NewResponse(&dkvv1.AckResponse{Index: index}), nil
+}
+
+func (q *QueueAPIHandler) Nack(
+	ctx context.Context,
+	req *connect.Request[dkvv1.NackRequest],
+) (*connect.Response[dkvv1.NackResponse], error) {
+	if err := validateQueue(req.Msg.GetQueue()); err != nil {
+		return nil, err
+	}
+	index, err := q.Store.Nack(req.Msg.GetQueue(), req.Msg.GetId(), req.Msg.GetClaim())
+	q.record(ctx, req.Peer(), "Nack", req.Msg.GetQueue(), index, err)
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return connect.NewResponse(&dkvv1.NackResponse{Index: index}), nil
+}
+
+// Peek returns at most maxPeekLimit messages.
+func (q *QueueAPIHandler) Peek(
+	ctx context.Context,
+	req *connect.Request[dkvv1.PeekRequest],
+) (*connect.Response[dkvv1.PeekResponse], error) {
+	if err := validateQueue(req.Msg.GetQueue()); err != nil {
+		return nil, err
+	}
+	limit := int(req.Msg.GetLimit())
+	if limit <= 0 {
+		limit = 1
+	}
+	opts := store.ReadOptions{MinIndex: req.Msg.GetMinIndex()}
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.Timeout = time.Until(deadline)
+	}
+	messages, ready, claimed, err := q.Store.Peek(req.Msg.GetQueue(), min(limit, maxPeekLimit), opts)
+	if errors.Is(err, store.ErrStaleRead) {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	} else if err != nil {
+		return nil, leaderError(err)
+	}
+	return connect.NewResponse(&dkvv1.PeekResponse{
+		Messages: messages,
+		Ready:    ready,
+		Claimed:  claimed,
+	}), nil
+}
+
+func (q *QueueAPIHandler) record(
+	ctx context.Context,
+	peer connect.Peer,
+	operation, queue string,
+	index uint64,
+	err error,
+) {
+	if q.Audit != nil {
+		q.Audit.Record(audit.NewEntry(CallerIdentity(ctx, peer), operation, queue, index, err))
+	}
+}
+
+func validateQueue(queue string) error {
+	if queue == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("queue is required"))
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Package audit records the mutating and administrative operations.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OutcomeSuccess is the outcome of a successful operation.
+	OutcomeSuccess = "success"
+	// OutcomeFailure is the outcome of a failed operation.
+	OutcomeFailure = "failure"
+
+	backupTimeFormat = "20060102T150405.000000000"
+	// recentIndexes is the number of committed indexes remembered to avoid
+	// duplicated entries.
+	recentIndexes = 1024
+)
+
+// Entry is an audit log entry.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Caller is the identity of the caller: the Common Name of its client
+	// certificate, or its address.
+	Caller string `json:"caller"`
+	// Operation is the name of the operation (Set, Delete, JoinServer...).
+	Operation string `json:"operation"`
+	// Key is the key or the server ID targeted by the operation.
+	Key string `json:"key,omitempty"`
+	// Index is the Raft index at which the operation was committed, or the index
+	// of the snapshot.
+	Index   uint64 `json:"index,omitempty"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewEntry creates an entry with an outcome depending on err.
+func NewEntry(caller, operation, key string, index uint64, err error) Entry {
+	e := Entry{
+		Time:      time.Now().UTC(),
+		Caller:    caller,
+		Operation: operation,
+		Key:       key,
+		Index:     index,
+		Outcome:   OutcomeSuccess,
+	}
+	if err != nil {
+		e.Outcome = OutcomeFailure
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// Sink receives the audit log entries.
+type Sink interface {
+	Record(e Entry)
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// FileSink appends the entries as JSON lines to a file.
+//
+// When the file exceeds MaxSize, it is renamed with a timestamp suffix and a new
+// file is created. Only the MaxBackups most recent files are kept.
+//
+// Entries are recorded by the node which received the request, with the index
+// of the committed write. Commands forwarded to the leader are not recorded
+// by the leader, and a committed index is recorded only once.
+type FileSink struct {
+	// Path is the path of the audit log file.
+	Path string
+	// MaxSize is the maximum size in bytes of the file before rotation.
+	// Zero means no rotation.
+	MaxSize int64
+	// MaxBackups is the maximum number of rotated files to keep.
+	// Zero means all files are kept.
+	MaxBackups int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	indexes map[recordedIndex]struct{}
+	order   []recordedIndex
+}
+
+// recordedIndex identifies an operation committed at an index.
+type recordedIndex struct {
+	operation string
+	index     uint64
+}
+
+// NewFileSink opens the audit log file.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		indexes:    make(map[recordedIndex]struct{}, recentIndexes),
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// Record appends the entry to the file.
+//
+// Failures are logged, as an audit log must not block the operations.
+func (s *FileSink) Record(e Entry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("failed to marshal audit entry", "error", err)
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.Index != 0 && e.Outcome == OutcomeSuccess && !s.remember(recordedIndex{e.Operation, e.Index}) {
+		return
+	}
+	if s.MaxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.MaxSize {
+		if err := s.rotate(); err != nil {
+			slog.Error("failed to rotate audit log", "error", err)
+		}
+	}
+	if s.file == nil {
+		slog.Error("audit log is closed, entry dropped", "entry", string(b))
+		return
+	}
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	if err != nil {
+		slog.Error("failed to write audit entry", "error", err)
+	}
+}
+
+// remember returns false if the index has already been recorded.
+func (s *FileSink) remember(index recordedIndex) bool {
+	if _, ok := s.indexes[index]; ok {
+		return false
+	}
+	if len(s.order) >= recentIndexes {
+		delete(s.indexes, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.indexes[index] = struct{}{}
+	s.order = append(s.order, index)
+	return true
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+	backup := fmt.Sprintf("%s.%s", s.Path, time.Now().UTC().Format(backupTimeFormat))
+	if err := os.Rename(s.Path, backup); err != nil {
+		return errors.Join(err, s.open())
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+func (s *FileSink) prune() error {
+	if s.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(s.Path + ".*")
+	if err != nil {
+		return err
+	}
+	// Filter out unrelated files.
+	n := 0
+	for _, b := range backups {
+		suffix := strings.TrimPrefix(b, s.Path+".")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups[n] = b
+			n++
+		}
+	}
+	backups = backups[:n]
+	if len(backups) <= s.MaxBackups {
+		return nil
+	}
+	// The timestamps are sortable.
+	sort.Strings(backups)
+	for _, b := range backups[:len(backups)-s.MaxBackups] {
+		if err := os.Remove(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,119 @@
+package audit_test
+
+import (
+	"bufio"
+	"distributed-kv/internal/audit"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readEntries(t *testing.T, path string) []audit.Entry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Record", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := audit.NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+
+		// Act
+		sink.Record(audit.NewEntry("client", "Set", "key", 10, nil))
+		sink.Record(audit.NewEntry("client", "Delete", "key", 0, errors.New("no leader")))
+		require.NoError(t, sink.Close())
+
+		// Assert
+		entries := readEntries(t, path)
+		require.Len(t, entries, 2)
+		require.Equal(t, "client", entries[0].Caller)
+		require.Equal(t, "Set", entries[0].Operation)
+		require.Equal(t, "key", entries[0].Key)
+		require.Equal(t, uint64(10), entries[0].Index)
+		require.Equal(t, audit.OutcomeSuccess, entries[0].Outcome)
+		require.False(t, entries[0].Time.IsZero())
+		require.Equal(t, audit.OutcomeFailure, entries[1].Outcome)
+		require.Equal(t, "no leader", entries[1].Error)
+	})
+
+	t.Run("Append", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := audit.NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		sink.Record(audit.NewEntry("client", "Set", "key", 1, nil))
+		require.NoError(t, sink.Close())
+
+		// Act
+		sink, err = audit.NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+		sink.Record(audit.NewEntry("client", "Set", "key", 2, nil))
+		require.NoError(t, sink.Close())
+
+		// Assert
+		require.Len(t, readEntries(t, path), 2)
+	})
+
+	t.Run("Committed index is recorded once", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := audit.NewFileSink(path, 0, 0)
+		require.NoError(t, err)
+
+		// Act
+		sink.Record(audit.NewEntry("client", "Set", "key", 2, nil))
+		sink.Record(audit.NewEntry("client", "Set", "key", 1, nil))
+		sink.Record(audit.NewEntry("client", "Set", "key", 2, nil))
+		require.NoError(t, sink.Close())
+
+		// Assert
+		entries := readEntries(t, path)
+		require.Len(t, entries, 2)
+		require.Equal(t, uint64(2), entries[0].Index)
+		require.Equal(t, uint64(1), entries[1].Index)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		path := filepath.Join(dir, "audit.log")
+		sink, err := audit.NewFileSink(path, 200, 2)
+		require.NoError(t, err)
+
+		// Act
+		for i := uint64(1); i <= 10; i++ {
+			sink.Record(audit.NewEntry("client", "Set", "key", i, nil))
+		}
+		require.NoError(t, sink.Close())
+
+		// Assert
+		backups, err := filepath.Glob(path + ".*")
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(200))
+		entries := readEntries(t, path)
+		require.Equal(t, uint64(10), entries[len(entries)-1].Index)
+	})
+}
@@ -0,0 +1,199 @@
+// Package backup reads and writes the point-in-time backups of the FSM.
+//
+// A backup file is made of:
+//
+//   - A header line: the JSON encoded Header.
+//   - The FSM snapshot, as persisted by the Raft snapshots.
+//   - A trailer line: the JSON encoded Trailer, with the checksum of the snapshot.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"distributed-kv/internal/store"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Version is the version of the backup format.
+const Version = 1
+
+// maxTrailerSize is the maximum size of the trailer line.
+const maxTrailerSize = 4096
+
+// ErrChecksumMismatch is returned when the snapshot does not match the checksum
+// of the backup.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Header is the first line of a backup.
+type Header struct {
+	Version int `json:"version"`
+	// Index is the Raft index of the last log entry included in the snapshot.
+	Index uint64 `json:"index"`
+	// Term is the Raft term of the last log entry included in the snapshot.
+	Term uint64 `json:"term"`
+}
+
+// Trailer is the last line of a backup.
+type Trailer struct {
+	// Keys is the number of keys of the snapshot. The reserved records, such
+	// as the entries of the collections and the metadata of the store and of
+	// the servers, are not keys.
+	Keys int64 `json:"keys"`
+	// Size is the size in bytes of the snapshot.
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded checksum of the snapshot.
+	SHA256 string `json:"sha256"`
+}
+
+// Info describes a backup.
+type Info struct {
+	Header
+	Trailer
+}
+
+// Write writes a backup of the FSM snapshot data to w.
+func Write(w io.Writer, index, term uint64, data io.Reader) (*Info, error) {
+	header := Header{Version: Version, Index: index, Term: term}
+	if err := writeLine(w, header); err != nil {
+		return nil, err
+	}
+
+	// The snapshot is copied while it is parsed to count the keys.
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(w, h)}
+	r := csv.NewReader(io.TeeReader(data, cw))
+	var keys int64
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(record[0], store.ReservedPrefix) {
+			keys++
+		}
+	}
+	if cw.err != nil {
+		return nil, cw.err
+	}
+
+	trailer := Trailer{
+		Keys:   keys,
+		Size:   cw.n,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}
+	if err := writeLine(w, trailer); err != nil {
+		return nil, err
+	}
+	return &Info{Header: header, Trailer: trailer}, nil
+}
+
+// Open opens a backup file and verifies its checksum.
+//
+// The returned reader reads the FSM snapshot data.
+func Open(path string) (*Info, io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, body, err := parse(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("invalid backup %s: %w", path, err)
+	}
+	return info, struct {
+		io.Reader
+		io.Closer
+	}{body, f}, nil
+}
+
+func parse(f *os.File) (*Info, io.Reader, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Header
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	var info Info
+	if err := json.Unmarshal(line, &info.Header); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse header: %w", err)
+	}
+	if info.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported version %d", info.Version)
+	}
+	bodyStart := int64(len(line))
+
+	// Trailer
+	tailSize := min(stat.Size()-bodyStart, maxTrailerSize)
+	tail := make([]byte, tailSize)
+	if _, err := f.ReadAt(tail, stat.Size()-tailSize); err != nil {
+		return nil, nil, fmt.Errorf("failed to read trailer: %w", err)
+	}
+	tail = bytes.TrimSuffix(tail, []byte{'\n'})
+	trailerLine := tail[bytes.LastIndexByte(tail, '\n')+1:]
+	if err := json.Unmarshal(trailerLine, &info.Trailer); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse trailer: %w", err)
+	}
+	bodySize := stat.Size() - bodyStart - int64(len(trailerLine)) - 1
+	if bodySize != info.Size {
+		return nil, nil, fmt.Errorf(
+			"%w: expected %d bytes, got %d",
+			ErrChecksumMismatch,
+			info.Size,
+			bodySize,
+		)
+	}
+
+	// Checksum
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, bodyStart, bodySize)); err != nil {
+		return nil, nil, err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != info.SHA256 {
+		return nil, nil, fmt.Errorf(
+			"%w: expected %s, got %s",
+			ErrChecksumMismatch,
+			info.SHA256,
+			sum,
+		)
+	}
+	return &info, io.NewSectionReader(f, bodyStart, bodySize), nil
+}
+
+func writeLine(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
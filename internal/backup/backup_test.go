@@ -0,0 +1,109 @@
+package backup_test
+
+import (
+	"bytes"
+	"distributed-kv/internal/backup"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeBackup(t *testing.T, data string) (string, *backup.Info) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	info, err := backup.Write(&buf, 10, 2, strings.NewReader(data))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return path, info
+}
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title string
+		data  string
+		keys  int64
+	}{
+		{
+			title: "Empty",
+			data:  "",
+			keys:  0,
+		},
+		{
+			title: "Keys",
+			data:  "a,1\nb,\"multi\nline\"\n",
+			keys:  2,
+		},
+		{
+			title: "Reserved records",
+			data:  "a,1\n\x00revision/a,1:1:1\n\x00collection/1/hhfield,value\n\x00applied,3\n",
+			keys:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Arrange
+			path, written := writeBackup(t, tt.data)
+
+			// Act
+			info, snapshot, err := backup.Open(path)
+			require.NoError(t, err)
+			defer snapshot.Close()
+			data, err := io.ReadAll(snapshot)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, tt.data, string(data))
+			require.Equal(t, written, info)
+			require.Equal(t, uint64(10), info.Index)
+			require.Equal(t, uint64(2), info.Term)
+			require.Equal(t, tt.keys, info.Keys)
+			require.Equal(t, int64(len(tt.data)), info.Size)
+		})
+	}
+
+	t.Run("Corrupted", func(t *testing.T) {
+		// Arrange
+		path, _ := writeBackup(t, "a,1\nb,2\n")
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		b[bytes.Index(b, []byte("b,2"))+2] = '3'
+		require.NoError(t, os.WriteFile(path, b, 0o600))
+
+		// Act
+		_, _, err = backup.Open(path)
+
+		// Assert
+		require.ErrorIs(t, err, backup.ErrChecksumMismatch)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		// Arrange
+		path, _ := writeBackup(t, "a,1\nb,2\n")
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b[:len(b)-10], 0o600))
+
+		// Act
+		_, _, err = backup.Open(path)
+
+		// Assert
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid snapshot", func(t *testing.T) {
+		// Act
+		_, err := backup.Write(io.Discard, 10, 2, strings.NewReader("a,\"1\n"))
+
+		// Assert
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Destination stores the backups.
+//
+// Implementations may target a local directory or an object store.
+type Destination interface {
+	// Put stores a backup under name.
+	//
+	// A failed Put must not leave a partial backup under name.
+	Put(ctx context.Context, name string, r io.Reader) error
+	// List returns the names of the stored backups.
+	List(ctx context.Context) ([]string, error)
+	// Delete deletes a stored backup.
+	Delete(ctx context.Context, name string) error
+}
+
+var _ Destination = (*LocalDestination)(nil)
+
+// LocalDestination stores the backups in a local directory.
+type LocalDestination struct {
+	Dir string
+}
+
+// partialSuffix is the suffix of the backups being written.
+const partialSuffix = ".part"
+
+func (d *LocalDestination) Put(_ context.Context, name string, r io.Reader) (err error) {
+	if err := os.MkdirAll(d.Dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(d.Dir, name)
+	f, err := os.OpenFile(path+partialSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(path + partialSuffix)
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+partialSuffix, path)
+}
+
+func (d *LocalDestination) List(context.Context) ([]string, error) {
+	entries, err := os.ReadDir(d.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) == partialSuffix {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *LocalDestination) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(d.Dir, name))
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namePrefix     = "dkv-snapshot-"
+	nameSuffix     = ".db"
+	nameTimeFormat = "20060102T150405Z"
+)
+
+var lastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "dkv",
+	Subsystem: "backup",
+	Name:      "last_success_timestamp_seconds",
+	Help:      "Unix time of the last successful scheduled backup.",
+})
+
+// ErrInvalidInterval is returned when the interval between two backups is not
+// positive.
+var ErrInvalidInterval = errors.New("invalid backup interval")
+
+// Source takes the snapshots of the FSM.
+type Source interface {
+	// Snapshot opens a snapshot of the FSM.
+	Snapshot() (*raft.SnapshotMeta, io.ReadCloser, error)
+	// IsLeader returns true if the local node is the leader.
+	IsLeader() bool
+}
+
+// Scheduler periodically stores backups of the leader.
+//
+// The backups have the same format as the snapshots saved by dkvctl.
+type Scheduler struct {
+	Source      Source
+	Destination Destination
+	// Interval is the duration between two backups.
+	Interval time.Duration
+	// Retention is the number of backups to keep. Zero means all backups are kept.
+	Retention int
+}
+
+// Run takes a backup at each interval until the context is canceled.
+// ErrInvalidInterval is returned if the interval is not positive.
+//
+// Followers skip the backups.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidInterval, s.Interval)
+	}
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !s.Source.IsLeader() {
+				continue
+			}
+			name, err := s.Backup(ctx)
+			if err != nil {
+				slog.Error("scheduled backup failed", "error", err)
+				continue
+			}
+			slog.Info("scheduled backup succeeded", "name", name)
+		}
+	}
+}
+
+// Backup stores a backup, then applies the retention policy.
+func (s *Scheduler) Backup(ctx context.Context) (string, error) {
+	meta, snapshot, err := s.Source.Snapshot()
+	if err != nil {
+		return "", err
+	}
+	defer snapshot.Close()
+
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s%s-%d%s", namePrefix, now.Format(nameTimeFormat), meta.Index, nameSuffix)
+	pr, pw := io.Pipe()
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		_, err := Write(pw, meta.Index, meta.Term, snapshot)
+		_ = pw.CloseWithError(err)
+	}()
+	err = s.Destination.Put(ctx, name, pr)
+	_ = pr.CloseWithError(err)
+	// The snapshot is read by the writer until it returns, which the closed
+	// pipe ensures, and must not be closed before.
+	<-written
+	if err != nil {
+		return "", fmt.Errorf("failed to store backup %s: %w", name, err)
+	}
+	lastSuccess.Set(float64(now.Unix()))
+
+	if err := s.prune(ctx); err != nil {
+		return name, fmt.Errorf("failed to apply retention: %w", err)
+	}
+	return name, nil
+}
+
+// prune deletes the oldest backups beyond the retention.
+func (s *Scheduler) prune(ctx context.Context) error {
+	if s.Retention <= 0 {
+		return nil
+	}
+	names, err := s.Destination.List(ctx)
+	if err != nil {
+		return err
+	}
+	// Ignore the files which are not scheduled backups.
+	backups := make([]scheduledBackup, 0, len(names))
+	for _, name := range names {
+		if b, ok := parseName(name); ok {
+			backups = append(backups, b)
+		}
+	}
+	if len(backups) <= s.Retention {
+		return nil
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.Before(backups[j].time)
+		}
+		return backups[i].index < backups[j].index
+	})
+	for _, b := range backups[:len(backups)-s.Retention] {
+		if err := s.Destination.Delete(ctx, b.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type scheduledBackup struct {
+	name  string
+	time  time.Time
+	index uint64
+}
+
+func parseName(name string) (scheduledBackup, bool) {
+	trimmed, ok := strings.CutPrefix(name, namePrefix)
+	if !ok {
+		return scheduledBackup{}, false
+	}
+	trimmed, ok = strings.CutSuffix(trimmed, nameSuffix)
+	if !ok {
+		return scheduledBackup{}, false
+	}
+	ts, idx, ok := strings.Cut(trimmed, "-")
+	if !ok {
+		return scheduledBackup{}, false
+	}
+	t, err := time.Parse(nameTimeFormat, ts)
+	if err != nil {
+		return scheduledBackup{}, false
+	}
+	index, err := strconv.ParseUint(idx, 10, 64)
+	if err != nil {
+		return scheduledBackup{}, false
+	}
+	return scheduledBackup{name: name, time: t, index: index}, true
+}
@@ -0,0 +1,118 @@
+package backup_test
+
+import (
+	"context"
+	"distributed-kv/internal/backup"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	index  atomic.Uint64
+	leader atomic.Bool
+}
+
+func (s *fakeSource) Snapshot() (*raft.SnapshotMeta, io.ReadCloser, error) {
+	return &raft.SnapshotMeta{Index: s.index.Add(1), Term: 1},
+		io.NopCloser(strings.NewReader("key,value\n")),
+		nil
+}
+
+func (s *fakeSource) IsLeader() bool {
+	return s.leader.Load()
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Backup applies the retention", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "manual.db"), nil, 0o600))
+		s := &backup.Scheduler{
+			Source:      &fakeSource{},
+			Destination: &backup.LocalDestination{Dir: dir},
+			Retention:   2,
+		}
+
+		// Act
+		var names []string
+		for range 3 {
+			name, err := s.Backup(context.Background())
+			require.NoError(t, err)
+			names = append(names, name)
+		}
+
+		// Assert
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		got := make([]string, 0, len(entries))
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+		require.ElementsMatch(t, append([]string{"manual.db"}, names[1:]...), got)
+
+		info, snapshot, err := backup.Open(filepath.Join(dir, names[2]))
+		require.NoError(t, err)
+		defer snapshot.Close()
+		require.Equal(t, uint64(3), info.Index)
+		require.Equal(t, int64(1), info.Keys)
+	})
+
+	t.Run("Run without interval", func(t *testing.T) {
+		// Arrange
+		s := &backup.Scheduler{
+			Source:      &fakeSource{},
+			Destination: &backup.LocalDestination{Dir: t.TempDir()},
+		}
+
+		// Act
+		err := s.Run(context.Background())
+
+		// Assert
+		require.ErrorIs(t, err, backup.ErrInvalidInterval)
+	})
+
+	t.Run("Run skips followers", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		source := &fakeSource{}
+		s := &backup.Scheduler{
+			Source:      source,
+			Destination: &backup.LocalDestination{Dir: dir},
+			Interval:    10 * time.Millisecond,
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		// The backups must be written before the directory is removed.
+		defer func() {
+			cancel()
+			<-done
+		}()
+
+		// Act
+		go func() {
+			defer close(done)
+			_ = s.Run(ctx)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		followerBackups, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		source.leader.Store(true)
+
+		// Assert
+		require.Empty(t, followerBackups)
+		require.Eventually(t, func() bool {
+			entries, err := os.ReadDir(dir)
+			return err == nil && len(entries) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
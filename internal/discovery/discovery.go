@@ -0,0 +1,132 @@
+// Package discovery discovers the peers of the cluster.
+//
+// Peers can be listed statically, or discovered with DNS SRV records or with
+// the A/AAAA records of the pods of a Kubernetes headless service.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/raft"
+)
+
+// IndexPlaceholder is replaced by the ordinal of the peer in a name pattern.
+const IndexPlaceholder = "{i}"
+
+// Resolver resolves DNS records. It is implemented by net.Resolver.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+var _ Resolver = (*net.Resolver)(nil)
+
+// Discoverer returns the peers of the cluster, by ID.
+type Discoverer interface {
+	Discover(ctx context.Context) (map[raft.ServerID]raft.ServerAddress, error)
+}
+
+var (
+	_ Discoverer = Static(nil)
+	_ Discoverer = (*SRV)(nil)
+	_ Discoverer = (*Headless)(nil)
+	_ Discoverer = Multi(nil)
+)
+
+// Static is a fixed list of peers.
+type Static map[raft.ServerID]raft.ServerAddress
+
+func (s Static) Discover(context.Context) (map[raft.ServerID]raft.ServerAddress, error) {
+	return s, nil
+}
+
+// SRV discovers the peers with the SRV records of a domain name, like
+// _peer._tcp.dkv.default.svc.cluster.local.
+//
+// The ID of a peer is the first label of its target.
+type SRV struct {
+	Resolver Resolver
+	Name     string
+}
+
+func (d *SRV) Discover(ctx context.Context) (map[raft.ServerID]raft.ServerAddress, error) {
+	_, records, err := d.Resolver.LookupSRV(ctx, "", "", d.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup SRV %s: %w", d.Name, err)
+	}
+	peers := make(map[raft.ServerID]raft.ServerAddress, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		id, _, _ := strings.Cut(target, ".")
+		peers[raft.ServerID(id)] = raft.ServerAddress(
+			net.JoinHostPort(target, strconv.Itoa(int(record.Port))),
+		)
+	}
+	return peers, nil
+}
+
+// Headless discovers the pods of a StatefulSet behind a headless service.
+//
+// The Pattern is the address of a peer, where IndexPlaceholder is replaced by
+// the ordinal of the pod, like dkv-{i}.dkv.default.svc.cluster.local:2380.
+// The ordinals from 0 to MaxPeers-1 are resolved, and the hosts which have
+// A/AAAA records are the peers.
+//
+// The ID of a peer is the first label of its host.
+type Headless struct {
+	Resolver Resolver
+	Pattern  string
+	MaxPeers int
+}
+
+func (d *Headless) Discover(ctx context.Context) (map[raft.ServerID]raft.ServerAddress, error) {
+	if !strings.Contains(d.Pattern, IndexPlaceholder) {
+		return nil, fmt.Errorf("pattern %q does not contain %s", d.Pattern, IndexPlaceholder)
+	}
+	peers := make(map[raft.ServerID]raft.ServerAddress)
+	for i := 0; i < d.MaxPeers; i++ {
+		addr := strings.ReplaceAll(d.Pattern, IndexPlaceholder, strconv.Itoa(i))
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", d.Pattern, err)
+		}
+		if _, err := d.Resolver.LookupHost(ctx, host); err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("failed to lookup %s: %w", host, err)
+		}
+		id, _, _ := strings.Cut(host, ".")
+		peers[raft.ServerID(id)] = raft.ServerAddress(addr)
+	}
+	return peers, nil
+}
+
+// Multi merges the peers of several discoverers.
+//
+// If a peer is discovered several times, the first discoverer wins.
+type Multi []Discoverer
+
+func (m Multi) Discover(ctx context.Context) (map[raft.ServerID]raft.ServerAddress, error) {
+	peers := make(map[raft.ServerID]raft.ServerAddress)
+	var errs []error
+	for _, d := range m {
+		discovered, err := d.Discover(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for id, addr := range discovered {
+			if _, ok := peers[id]; !ok {
+				peers[id] = addr
+			}
+		}
+	}
+	return peers, errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package discovery_test
+
+import (
+	"context"
+	"distributed-kv/internal/discovery"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	srv   map[string][]*net.SRV
+	hosts map[string][]string
+	err   error
+}
+
+func (r *fakeResolver) LookupSRV(
+	_ context.Context,
+	_, _, name string,
+) (string, []*net.SRV, error) {
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	records, ok := r.srv[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, records, nil
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestDiscoverers(t *testing.T) {
+	t.Parallel()
+
+	resolver := &fakeResolver{
+		srv: map[string][]*net.SRV{
+			"_peer._tcp.dkv.default.svc.cluster.local": {
+				{Target: "dkv-0.dkv.default.svc.cluster.local.", Port: 2380},
+				{Target: "dkv-1.dkv.default.svc.cluster.local.", Port: 2380},
+			},
+		},
+		hosts: map[string][]string{
+			"dkv-0.dkv.default.svc.cluster.local": {"10.0.0.1"},
+			"dkv-2.dkv.default.svc.cluster.local": {"10.0.0.3"},
+		},
+	}
+
+	tests := []struct {
+		title      string
+		discoverer discovery.Discoverer
+		expected   map[raft.ServerID]raft.ServerAddress
+		isError    bool
+	}{
+		{
+			title:      "SRV",
+			discoverer: &discovery.SRV{Resolver: resolver, Name: "_peer._tcp.dkv.default.svc.cluster.local"},
+			expected: map[raft.ServerID]raft.ServerAddress{
+				"dkv-0": "dkv-0.dkv.default.svc.cluster.local:2380",
+				"dkv-1": "dkv-1.dkv.default.svc.cluster.local:2380",
+			},
+		},
+		{
+			title:      "SRV not found",
+			discoverer: &discovery.SRV{Resolver: resolver, Name: "_peer._tcp.unknown"},
+			isError:    true,
+		},
+		{
+			title: "Headless skips the missing ordinals",
+			discoverer: &discovery.Headless{
+				Resolver: resolver,
+				Pattern:  "dkv-{i}.dkv.default.svc.cluster.local:2380",
+				MaxPeers: 4,
+			},
+			expected: map[raft.ServerID]raft.ServerAddress{
+				"dkv-0": "dkv-0.dkv.default.svc.cluster.local:2380",
+				"dkv-2": "dkv-2.dkv.default.svc.cluster.local:2380",
+			},
+		},
+		{
+			title: "Headless fails on resolver errors",
+			discoverer: &discovery.Headless{
+				Resolver: &fakeResolver{err: errors.New("timeout")},
+				Pattern:  "dkv-{i}.dkv:2380",
+				MaxPeers: 1,
+			},
+			isError: true,
+		},
+		{
+			title: "Headless without placeholder",
+			discoverer: &discovery.Headless{
+				Resolver: resolver,
+				Pattern:  "dkv.default.svc.cluster.local:2380",
+				MaxPeers: 1,
+			},
+			isError: true,
+		},
+		{
+			title: "Multi",
+			discoverer: discovery.Multi{
+				discovery.Static{"dkv-0": "localhost:2380"},
+				&discovery.SRV{Resolver: resolver, Name: "_peer._tcp.dkv.default.svc.cluster.local"},
+			},
+			expected: map[raft.ServerID]raft.ServerAddress{
+				"dkv-0": "localhost:2380",
+				"dkv-1": "dkv-1.dkv.default.svc.cluster.local:2380",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Act
+			peers, err := tt.discoverer.Discover(context.Background())
+
+			// Assert
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, peers)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// Tracker computes the membership changes from the discovered peers.
+//
+// It is used by the leader, which joins the new peers and optionally removes
+// the members which have not been discovered for RemoveAfter.
+type Tracker struct {
+	// RemoveAfter is the duration after which a vanished member is removed.
+	// Zero disables the removal.
+	RemoveAfter time.Duration
+
+	lastSeen map[raft.ServerID]time.Time
+}
+
+// Changes returns the peers to join and the members to remove.
+//
+// The local server is never removed.
+func (t *Tracker) Changes(
+	now time.Time,
+	self raft.ServerID,
+	members []raft.Server,
+	discovered map[raft.ServerID]raft.ServerAddress,
+) (join map[raft.ServerID]raft.ServerAddress, remove []raft.ServerID) {
+	if t.lastSeen == nil {
+		t.lastSeen = make(map[raft.ServerID]time.Time)
+	}
+
+	join = make(map[raft.ServerID]raft.ServerAddress)
+	isMember := make(map[raft.ServerID]bool, len(members))
+	for _, member := range members {
+		isMember[member.ID] = true
+	}
+	for id, addr := range discovered {
+		t.lastSeen[id] = now
+		if id != self && !isMember[id] {
+			join[id] = addr
+		}
+	}
+
+	for _, member := range members {
+		seen, ok := t.lastSeen[member.ID]
+		if !ok {
+			// Start the grace period when the member is first observed.
+			t.lastSeen[member.ID] = now
+			continue
+		}
+		if t.RemoveAfter > 0 && member.ID != self && now.Sub(seen) > t.RemoveAfter {
+			remove = append(remove, member.ID)
+		}
+	}
+
+	// Forget the peers which are neither members nor discovered.
+	for id := range t.lastSeen {
+		if _, ok := discovered[id]; !ok && !isMember[id] {
+			delete(t.lastSeen, id)
+		}
+	}
+	return join, remove
+}
@@ -0,0 +1,68 @@
+package discovery_test
+
+import (
+	"distributed-kv/internal/discovery"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	members := []raft.Server{
+		{ID: "dkv-0", Address: "dkv-0:2380"},
+		{ID: "dkv-1", Address: "dkv-1:2380"},
+	}
+
+	t.Run("Join the new peers", func(t *testing.T) {
+		// Arrange
+		tracker := &discovery.Tracker{}
+
+		// Act
+		join, remove := tracker.Changes(now, "dkv-0", members, map[raft.ServerID]raft.ServerAddress{
+			"dkv-0": "dkv-0:2380",
+			"dkv-1": "dkv-1:2380",
+			"dkv-2": "dkv-2:2380",
+		})
+
+		// Assert
+		require.Equal(t, map[raft.ServerID]raft.ServerAddress{"dkv-2": "dkv-2:2380"}, join)
+		require.Empty(t, remove)
+	})
+
+	t.Run("Remove the vanished members after the delay", func(t *testing.T) {
+		// Arrange
+		tracker := &discovery.Tracker{RemoveAfter: time.Minute}
+		discovered := map[raft.ServerID]raft.ServerAddress{"dkv-0": "dkv-0:2380"}
+
+		// Act
+		_, removeFirst := tracker.Changes(now, "dkv-0", members, discovered)
+		_, removeBefore := tracker.Changes(now.Add(time.Minute), "dkv-0", members, discovered)
+		_, removeAfter := tracker.Changes(now.Add(2*time.Minute), "dkv-0", members, discovered)
+
+		// Assert
+		require.Empty(t, removeFirst)
+		require.Empty(t, removeBefore)
+		require.Equal(t, []raft.ServerID{"dkv-1"}, removeAfter)
+	})
+
+	t.Run("Never remove when disabled or self", func(t *testing.T) {
+		// Arrange
+		tracker := &discovery.Tracker{}
+		selfTracker := &discovery.Tracker{RemoveAfter: time.Minute}
+
+		// Act
+		_, _ = tracker.Changes(now, "dkv-0", members, nil)
+		_, remove := tracker.Changes(now.Add(time.Hour), "dkv-0", members, nil)
+		_, _ = selfTracker.Changes(now, "dkv-0", members[:1], nil)
+		_, removeSelf := selfTracker.Changes(now.Add(time.Hour), "dkv-0", members[:1], nil)
+
+		// Assert
+		require.Empty(t, remove)
+		require.Empty(t, removeSelf)
+	})
+}
@@ -0,0 +1,332 @@
+package etcd
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxTxnOps is the maximum number of operations of a branch of a transaction,
+// which is the default of etcd.
+const maxTxnOps = 128
+
+// Range serves the keys of a range from the local state. A linearizable range
+// waits for the read index of the leader, and a serializable range is served
+// immediately.
+func (s *Server) Range(
+	ctx context.Context,
+	r *etcdserverpb.RangeRequest,
+) (*etcdserverpb.RangeResponse, error) {
+	op, err := rangeOperation(r)
+	if err != nil {
+		return nil, err
+	}
+	var opts store.ReadOptions
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.Timeout = time.Until(deadline)
+	}
+	if !r.GetSerializable() {
+		index, err := s.Store.ReadIndex()
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		opts.MinIndex = index
+	}
+	rev, result, err := s.Store.Range(op.GetRange(), opts)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rangeResponse(s.header(rev), result), nil
+}
+
+// Put sets the value of a key.
+func (s *Server) Put(
+	ctx context.Context,
+	r *etcdserverpb.PutRequest,
+) (*etcdserverpb.PutResponse, error) {
+	op, err := putOperation(r)
+	if err != nil {
+		return nil, err
+	}
+	index, result, err := s.Store.Txn(&dkvv1.TxnCommand{Success: []*dkvv1.Operation{op}})
+	s.record(ctx, "Put", string(r.GetKey()), index, err)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return putResponse(s.header(index), result.GetResults()[0].GetPut()), nil
+}
+
+// DeleteRange deletes the keys of a range.
+func (s *Server) DeleteRange(
+	ctx context.Context,
+	r *etcdserverpb.DeleteRangeRequest,
+) (*etcdserverpb.DeleteRangeResponse, error) {
+	op, err := deleteRangeOperation(r)
+	if err != nil {
+		return nil, err
+	}
+	index, result, err := s.Store.Txn(&dkvv1.TxnCommand{Success: []*dkvv1.Operation{op}})
+	s.record(ctx, "DeleteRange", string(r.GetKey()), index, err)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return deleteRangeResponse(s.header(index), result.GetResults()[0].GetDeleteRange()), nil
+}
+
+// Txn executes a transaction atomically. Its ranges read the state at the
+// revision of the transaction.
+func (s *Server) Txn(
+	ctx context.Context,
+	r *etcdserverpb.TxnRequest,
+) (*etcdserverpb.TxnResponse, error) {
+	txn, err := txnCommand(r)
+	if err != nil {
+		return nil, err
+	}
+	index, result, err := s.Store.Txn(txn)
+	s.record(ctx, "Txn", strings.Join(txnKeys(r), " "), index, err)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return txnResponse(s.header(index), result), nil
+}
+
+func rangeOperation(r *etcdserverpb.RangeRequest) (*dkvv1.Operation, error) {
+	if len(r.GetKey()) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	if r.GetMinModRevision() != 0 || r.GetMaxModRevision() != 0 ||
+		r.GetMinCreateRevision() != 0 || r.GetMaxCreateRevision() != 0 {
+		return nil, status.Error(codes.Unimplemented, "revision filters are not supported")
+	}
+	return &dkvv1.Operation{Operation: &dkvv1.Operation_Range{Range: &dkvv1.RangeOperation{
+		Key:      r.GetKey(),
+		RangeEnd: r.GetRangeEnd(),
+		Limit:    r.GetLimit(),
+		// The enums have the same numbers.
+		SortOrder:  dkvv1.RangeOperation_SortOrder(r.GetSortOrder()),
+		SortTarget: dkvv1.RangeOperation_SortTarget(r.GetSortTarget()),
+		KeysOnly:   r.GetKeysOnly(),
+		CountOnly:  r.GetCountOnly(),
+		Revision:   r.GetRevision(),
+	}}}, nil
+}
+
+func putOperation(r *etcdserverpb.PutRequest) (*dkvv1.Operation, error) {
+	if len(r.GetKey()) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	if r.GetLease() != 0 {
+		// There are no leases.
+		return nil, rpctypes.ErrGRPCLeaseNotFound
+	}
+	if r.GetIgnoreValue() && len(r.GetValue()) != 0 {
+		return nil, rpctypes.ErrGRPCValueProvided
+	}
+	return &dkvv1.Operation{Operation: &dkvv1.Operation_Put{Put: &dkvv1.PutOperation{
+		Key:         r.GetKey(),
+		Value:       r.GetValue(),
+		PrevKv:      r.GetPrevKv(),
+		IgnoreValue: r.GetIgnoreValue(),
+	}}}, nil
+}
+
+func deleteRangeOperation(r *etcdserverpb.DeleteRangeRequest) (*dkvv1.Operation, error) {
+	if len(r.GetKey()) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	return &dkvv1.Operation{Operation: &dkvv1.Operation_DeleteRange{
+		DeleteRange: &dkvv1.DeleteRangeOperation{
+			Key:      r.GetKey(),
+			RangeEnd: r.GetRangeEnd(),
+			PrevKv:   r.GetPrevKv(),
+		},
+	}}, nil
+}
+
+func txnCommand(r *etcdserverpb.TxnRequest) (*dkvv1.TxnCommand, error) {
+	if len(r.GetSuccess()) > maxTxnOps || len(r.GetFailure()) > maxTxnOps {
+		return nil, rpctypes.ErrGRPCTooManyOps
+	}
+	txn := &dkvv1.TxnCommand{}
+	for _, c := range r.GetCompare() {
+		comparison, err := comparison(c)
+		if err != nil {
+			return nil, err
+		}
+		txn.Compare = append(txn.Compare, comparison)
+	}
+	var err error
+	if txn.Success, err = operations(r.GetSuccess()); err != nil {
+		return nil, err
+	}
+	if txn.Failure, err = operations(r.GetFailure()); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func comparison(c *etcdserverpb.Compare) (*dkvv1.Comparison, error) {
+	if len(c.GetKey()) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	comparison := &dkvv1.Comparison{
+		Key:      c.GetKey(),
+		RangeEnd: c.GetRangeEnd(),
+		// The enums have the same numbers.
+		Result: dkvv1.Comparison_Result(c.GetResult()),
+	}
+	switch c.GetTarget() {
+	case etcdserverpb.Compare_VERSION:
+		comparison.Target = dkvv1.Comparison_TARGET_VERSION
+		comparison.Number = c.GetVersion()
+	case etcdserverpb.Compare_CREATE:
+		comparison.Target = dkvv1.Comparison_TARGET_CREATE_REVISION
+		comparison.Number = c.GetCreateRevision()
+	case etcdserverpb.Compare_MOD:
+		comparison.Target = dkvv1.Comparison_TARGET_MOD_REVISION
+		comparison.Number = c.GetModRevision()
+	case etcdserverpb.Compare_VALUE:
+		comparison.Target = dkvv1.Comparison_TARGET_VALUE
+		comparison.Value = c.GetValue()
+	default:
+		return nil, status.Errorf(codes.Unimplemented, "comparison target %s is not supported", c.GetTarget())
+	}
+	return comparison, nil
+}
+
+func operations(requests []*etcdserverpb.RequestOp) ([]*dkvv1.Operation, error) {
+	ops := make([]*dkvv1.Operation, 0, len(requests))
+	for _, req := range requests {
+		var op *dkvv1.Operation
+		var err error
+		switch req := req.GetRequest().(type) {
+		case *etcdserverpb.RequestOp_RequestRange:
+			op, err = rangeOperation(req.RequestRange)
+		case *etcdserverpb.RequestOp_RequestPut:
+			op, err = putOperation(req.RequestPut)
+		case *etcdserverpb.RequestOp_RequestDeleteRange:
+			op, err = deleteRangeOperation(req.RequestDeleteRange)
+		case *etcdserverpb.RequestOp_RequestTxn:
+			var txn *dkvv1.TxnCommand
+			if txn, err = txnCommand(req.RequestTxn); err == nil {
+				op = &dkvv1.Operation{Operation: &dkvv1.Operation_Txn{Txn: txn}}
+			}
+		default:
+			err = status.Error(codes.InvalidArgument, "empty operation")
+		}
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// txnKeys returns the keys written by the operations of a transaction.
+func txnKeys(r *etcdserverpb.TxnRequest) []string {
+	var keys []string
+	for _, ops := range [][]*etcdserverpb.RequestOp{r.GetSuccess(), r.GetFailure()} {
+		for _, req := range ops {
+			switch req := req.GetRequest().(type) {
+			case *etcdserverpb.RequestOp_RequestPut:
+				keys = append(keys, string(req.RequestPut.GetKey()))
+			case *etcdserverpb.RequestOp_RequestDeleteRange:
+				keys = append(keys, string(req.RequestDeleteRange.GetKey()))
+			case *etcdserverpb.RequestOp_RequestTxn:
+				keys = append(keys, txnKeys(req.RequestTxn)...)
+			}
+		}
+	}
+	return keys
+}
+
+func rangeResponse(
+	header *etcdserverpb.ResponseHeader,
+	result *dkvv1.RangeResult,
+) *etcdserverpb.RangeResponse {
+	return &etcdserverpb.RangeResponse{
+		Header: header,
+		Kvs:    keyValues(result.GetKvs()),
+		More:   result.GetMore(),
+		Count:  result.GetCount(),
+	}
+}
+
+func putResponse(header *etcdserverpb.ResponseHeader, result *dkvv1.PutResult) *etcdserverpb.PutResponse {
+	return &etcdserverpb.PutResponse{Header: header, PrevKv: keyValue(result.GetPrevKv())}
+}
+
+func deleteRangeResponse(
+	header *etcdserverpb.ResponseHeader,
+	result *dkvv1.DeleteRangeResult,
+) *etcdserverpb.DeleteRangeResponse {
+	return &etcdserverpb.DeleteRangeResponse{
+		Header:  header,
+		Deleted: result.GetDeleted(),
+		PrevKvs: keyValues(result.GetPrevKvs()),
+	}
+}
+
+func txnResponse(header *etcdserverpb.ResponseHeader, result *dkvv1.TxnResult) *etcdserverpb.TxnResponse {
+	resp := &etcdserverpb.TxnResponse{
+		Header:    header,
+		Succeeded: result.GetSucceeded(),
+		Responses: make([]*etcdserverpb.ResponseOp, 0, len(result.GetResults())),
+	}
+	for _, res := range result.GetResults() {
+		op := &etcdserverpb.ResponseOp{}
+		switch res := res.GetResult().(type) {
+		case *dkvv1.OperationResult_Range:
+			op.Response = &etcdserverpb.ResponseOp_ResponseRange{
+				ResponseRange: rangeResponse(header, res.Range),
+			}
+		case *dkvv1.OperationResult_Put:
+			op.Response = &etcdserverpb.ResponseOp_ResponsePut{
+				ResponsePut: putResponse(header, res.Put),
+			}
+		case *dkvv1.OperationResult_DeleteRange:
+			op.Response = &etcdserverpb.ResponseOp_ResponseDeleteRange{
+				ResponseDeleteRange: deleteRangeResponse(header, res.DeleteRange),
+			}
+		case *dkvv1.OperationResult_Txn:
+			op.Response = &etcdserverpb.ResponseOp_ResponseTxn{
+				ResponseTxn: txnResponse(header, res.Txn),
+			}
+		}
+		resp.Responses = append(resp.Responses, op)
+	}
+	return resp
+}
+
+func keyValue(kv *dkvv1.RevisionedKeyValue) *mvccpb.KeyValue {
+	if kv == nil {
+		return nil
+	}
+	return &mvccpb.KeyValue{
+		Key:            kv.GetKey(),
+		Value:          kv.GetValue(),
+		CreateRevision: kv.GetCreateRevision(),
+		ModRevision:    kv.GetModRevision(),
+		Version:        kv.GetVersion(),
+	}
+}
+
+func keyValues(kvs []*dkvv1.RevisionedKeyValue) []*mvccpb.KeyValue {
+	if len(kvs) == 0 {
+		return nil
+	}
+	converted := make([]*mvccpb.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		converted = append(converted, keyValue(kv))
+	}
+	return converted
+}
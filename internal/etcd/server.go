@@ -0,0 +1,131 @@
+// Package etcd serves the key-value store with the KV and Watch services of
+// the etcd v3 API, so that the etcd clients can use it.
+//
+// The revisions are the Raft indexes: the revision of a key is the index of
+// its last write, and the revision of the store is the last applied index,
+// which also counts the entries which do not change the keys. The history of
+// the keys is not kept: a past revision can only be read if no key changed
+// since, and the watches are served from the recent events of the node.
+// Leases and compactions are not supported.
+package etcd
+
+import (
+	"context"
+	"distributed-kv/internal/api"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Paths are the HTTP paths of the gRPC services, to route them to the server.
+var Paths = []string{"/etcdserverpb.KV/", "/etcdserverpb.Watch/"}
+
+var (
+	_ etcdserverpb.KVServer    = (*Server)(nil)
+	_ etcdserverpb.WatchServer = (*Server)(nil)
+)
+
+// Server implements the KV and Watch services of etcd.
+type Server struct {
+	etcdserverpb.UnimplementedKVServer
+	etcdserverpb.UnimplementedWatchServer
+
+	// Store is the Raft store serving the requests.
+	Store *distributed.Store
+	// Audit records the writes, if set.
+	Audit audit.Sink
+
+	// mu guards closed, so that no watch starts once closed.
+	mu      sync.Mutex
+	closed  bool
+	watches sync.WaitGroup
+}
+
+// Close waits for the watches to return and refuses the next ones. The
+// connections must be closed first, so that the watches are canceled.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.watches.Wait()
+}
+
+// NewGRPCServer returns a gRPC server of the services. It is meant to be
+// served by the HTTP/2 handler of the client API, with its ServeHTTP method.
+func NewGRPCServer(s *Server) *grpc.Server {
+	srv := grpc.NewServer()
+	etcdserverpb.RegisterKVServer(srv, s)
+	etcdserverpb.RegisterWatchServer(srv, s)
+	return srv
+}
+
+// header returns the header of a response at the revision.
+func (s *Server) header(rev uint64) *etcdserverpb.ResponseHeader {
+	return &etcdserverpb.ResponseHeader{
+		ClusterId: hash(s.Store.ClusterToken()),
+		MemberId:  hash(s.Store.RaftID),
+		Revision:  int64(rev),
+	}
+}
+
+// record records a write in the audit log.
+func (s *Server) record(ctx context.Context, op, key string, index uint64, err error) {
+	if s.Audit == nil {
+		return
+	}
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	s.Audit.Record(audit.NewEntry(
+		api.CallerIdentity(ctx, connect.Peer{Addr: addr}), op, key, index, err,
+	))
+}
+
+// hash returns the etcd ID of a name.
+func hash(name string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// grpcError converts an error of the store to the error of etcd, which is
+// understood by the etcd clients.
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, store.ErrNotFound):
+		return rpctypes.ErrGRPCKeyNotFound
+	case errors.Is(err, store.ErrReservedKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, distributed.ErrCompacted):
+		return rpctypes.ErrGRPCCompacted
+	case errors.Is(err, distributed.ErrFutureRevision):
+		return rpctypes.ErrGRPCFutureRev
+	case errors.Is(err, store.ErrNoLeader),
+		errors.Is(err, distributed.ErrNotLeader),
+		errors.Is(err, raft.ErrNotLeader),
+		errors.Is(err, raft.ErrLeadershipLost),
+		errors.Is(err, raft.ErrLeadershipTransferInProgress):
+		return rpctypes.ErrGRPCNoLeader
+	case errors.Is(err, store.ErrStaleRead):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
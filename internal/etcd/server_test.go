@@ -0,0 +1,332 @@
+package etcd_test
+
+import (
+	"context"
+	"distributed-kv/internal/testcluster"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const timeout = 10 * time.Second
+
+// newClients starts a cluster, and returns an etcd client of the leader and
+// an etcd client of a follower.
+func newClients(t *testing.T) (leader, follower *clientv3.Client) {
+	t.Helper()
+
+	c := testcluster.New(t, 3)
+	i, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	return newClient(t, c, i), newClient(t, c, (i+1)%c.Size())
+}
+
+// newClient returns an etcd client of the i-th node of a cluster.
+func newClient(t *testing.T, c *testcluster.Cluster, i int) *clientv3.Client {
+	t.Helper()
+
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{c.Server(i).ClientAddress()},
+		DialTimeout: timeout,
+		Logger:      zap.NewNop(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cli.Close() })
+	return cli
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	leader, follower := newClients(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+
+	t.Run("Put and Get", func(t *testing.T) {
+		// Act
+		created, err := follower.Put(ctx, "kv/key", "1")
+		require.NoError(t, err)
+		updated, err := follower.Put(ctx, "kv/key", "2", clientv3.WithPrevKV())
+		require.NoError(t, err)
+		get, err := follower.Get(ctx, "kv/key")
+		require.NoError(t, err)
+		missing, err := follower.Get(ctx, "kv/missing")
+		require.NoError(t, err)
+
+		// Assert
+		require.Equal(t, "1", string(updated.PrevKv.Value))
+		require.Len(t, get.Kvs, 1)
+		kv := get.Kvs[0]
+		require.Equal(t, "2", string(kv.Value))
+		require.Equal(t, created.Header.Revision, kv.CreateRevision)
+		require.Equal(t, updated.Header.Revision, kv.ModRevision)
+		require.Equal(t, int64(2), kv.Version)
+		require.GreaterOrEqual(t, get.Header.Revision, kv.ModRevision)
+		require.Empty(t, missing.Kvs)
+		require.Zero(t, missing.Count)
+	})
+
+	t.Run("Get prefix", func(t *testing.T) {
+		// Arrange
+		for i := range 5 {
+			_, err := leader.Put(ctx, fmt.Sprintf("prefix/%d", i), fmt.Sprint(4-i))
+			require.NoError(t, err)
+		}
+
+		// Act
+		all, err := follower.Get(ctx, "prefix/", clientv3.WithPrefix())
+		require.NoError(t, err)
+		limited, err := follower.Get(
+			ctx,
+			"prefix/",
+			clientv3.WithPrefix(),
+			clientv3.WithLimit(2),
+			clientv3.WithSort(clientv3.SortByValue, clientv3.SortAscend),
+			clientv3.WithKeysOnly(),
+		)
+		require.NoError(t, err)
+		count, err := follower.Get(ctx, "prefix/", clientv3.WithPrefix(), clientv3.WithCountOnly())
+		require.NoError(t, err)
+		serializable, err := follower.Get(ctx, "prefix/", clientv3.WithPrefix(), clientv3.WithSerializable())
+		require.NoError(t, err)
+
+		// Assert
+		require.Len(t, all.Kvs, 5)
+		require.Equal(t, "prefix/0", string(all.Kvs[0].Key))
+		require.False(t, all.More)
+		require.Len(t, limited.Kvs, 2)
+		require.Equal(t, "prefix/4", string(limited.Kvs[0].Key))
+		require.Equal(t, "prefix/3", string(limited.Kvs[1].Key))
+		require.Empty(t, limited.Kvs[0].Value)
+		require.True(t, limited.More)
+		require.Equal(t, int64(5), limited.Count)
+		require.Empty(t, count.Kvs)
+		require.Equal(t, int64(5), count.Count)
+		require.Len(t, serializable.Kvs, 5)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Arrange
+		for _, key := range []string{"delete/1", "delete/2", "kept"} {
+			_, err := leader.Put(ctx, key, "value")
+			require.NoError(t, err)
+		}
+
+		// Act
+		deleted, err := follower.Delete(ctx, "delete/", clientv3.WithPrefix(), clientv3.WithPrevKV())
+		require.NoError(t, err)
+		remaining, err := follower.Get(ctx, "delete/", clientv3.WithPrefix())
+		require.NoError(t, err)
+		kept, err := follower.Get(ctx, "kept")
+		require.NoError(t, err)
+
+		// Assert
+		require.Equal(t, int64(2), deleted.Deleted)
+		require.Len(t, deleted.PrevKvs, 2)
+		require.Zero(t, remaining.Count)
+		require.Equal(t, int64(1), kept.Count)
+	})
+
+	t.Run("Txn", func(t *testing.T) {
+		// Arrange
+		putIfAbsent := func(value string) (*clientv3.TxnResponse, error) {
+			return follower.Txn(ctx).
+				If(clientv3.Compare(clientv3.CreateRevision("txn/key"), "=", 0)).
+				Then(clientv3.OpPut("txn/key", value)).
+				Else(clientv3.OpGet("txn/key")).
+				Commit()
+		}
+
+		// Act
+		created, err := putIfAbsent("1")
+		require.NoError(t, err)
+		existing, err := putIfAbsent("2")
+		require.NoError(t, err)
+		swapped, err := follower.Txn(ctx).
+			If(clientv3.Compare(clientv3.Value("txn/key"), "=", "1")).
+			Then(
+				clientv3.OpPut("txn/key", "3"),
+				clientv3.OpTxn(
+					[]clientv3.Cmp{clientv3.Compare(clientv3.Version("txn/key"), ">", 0)},
+					[]clientv3.Op{clientv3.OpPut("txn/nested", "value")},
+					nil,
+				),
+			).
+			Commit()
+		require.NoError(t, err)
+		get, err := follower.Get(ctx, "txn/", clientv3.WithPrefix())
+		require.NoError(t, err)
+
+		// Assert
+		require.True(t, created.Succeeded)
+		require.False(t, existing.Succeeded)
+		require.Equal(t, "1", string(existing.Responses[0].GetResponseRange().Kvs[0].Value))
+		require.True(t, swapped.Succeeded)
+		require.True(t, swapped.Responses[1].GetResponseTxn().Succeeded)
+		require.Len(t, get.Kvs, 2)
+		require.Equal(t, "3", string(get.Kvs[0].Value))
+		require.Equal(t, swapped.Header.Revision, get.Kvs[0].ModRevision)
+		require.Equal(t, swapped.Header.Revision, get.Kvs[1].ModRevision)
+	})
+
+	t.Run("Past revision", func(t *testing.T) {
+		// Arrange
+		put, err := leader.Put(ctx, "rev/key", "1")
+		require.NoError(t, err)
+
+		// Act
+		unchanged, err := follower.Get(ctx, "rev/key", clientv3.WithRev(put.Header.Revision))
+		require.NoError(t, err)
+		_, err = leader.Put(ctx, "rev/key", "2")
+		require.NoError(t, err)
+		_, compactedErr := follower.Get(ctx, "rev/key", clientv3.WithRev(put.Header.Revision))
+		_, futureErr := follower.Get(ctx, "rev/key", clientv3.WithRev(1<<40))
+
+		// Assert
+		require.Equal(t, "1", string(unchanged.Kvs[0].Value))
+		require.ErrorIs(t, compactedErr, rpctypes.ErrCompacted)
+		require.ErrorIs(t, futureErr, rpctypes.ErrFutureRev)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		// Act
+		_, leaseErr := follower.Put(ctx, "lease", "value", clientv3.WithLease(1))
+		_, emptyErr := follower.Put(ctx, "", "value")
+		_, reservedErr := follower.Put(ctx, "\x00applied", "1")
+
+		// Assert
+		require.Equal(t, codes.InvalidArgument, status.Code(reservedErr))
+		require.ErrorIs(t, leaseErr, rpctypes.ErrLeaseNotFound)
+		require.ErrorIs(t, emptyErr, rpctypes.ErrEmptyKey)
+	})
+}
+
+func TestServerAllKeys(t *testing.T) {
+	t.Parallel()
+
+	leader, follower := newClients(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+	for _, key := range []string{"a", "b/c"} {
+		_, err := leader.Put(ctx, key, "value")
+		require.NoError(t, err)
+	}
+
+	// Act
+	all, err := follower.Get(ctx, "", clientv3.WithPrefix())
+	require.NoError(t, err)
+	fromKey, err := follower.Get(ctx, "", clientv3.WithFromKey(), clientv3.WithKeysOnly())
+	require.NoError(t, err)
+	deleted, err := follower.Delete(ctx, "", clientv3.WithPrefix())
+	require.NoError(t, err)
+	remaining, err := follower.Get(ctx, "", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, all.Kvs, 2)
+	require.Equal(t, "a", string(all.Kvs[0].Key))
+	require.Equal(t, "b/c", string(all.Kvs[1].Key))
+	require.Len(t, fromKey.Kvs, 2)
+	require.Equal(t, int64(2), deleted.Deleted)
+	require.Zero(t, remaining.Count)
+}
+
+func TestServerWatch(t *testing.T) {
+	t.Parallel()
+
+	leader, follower := newClients(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+
+	t.Run("Events", func(t *testing.T) {
+		// Arrange
+		wch := follower.Watch(ctx, "watch/", clientv3.WithPrefix(), clientv3.WithPrevKV())
+
+		// Act
+		put, err := leader.Put(ctx, "watch/key", "1")
+		require.NoError(t, err)
+		_, err = leader.Put(ctx, "other", "value")
+		require.NoError(t, err)
+		_, err = leader.Put(ctx, "watch/key", "2")
+		require.NoError(t, err)
+		_, err = leader.Delete(ctx, "watch/key")
+		require.NoError(t, err)
+
+		// Assert
+		var events []*clientv3.Event
+		for len(events) < 3 {
+			resp := <-wch
+			require.NoError(t, resp.Err())
+			events = append(events, resp.Events...)
+		}
+		require.Len(t, events, 3)
+		require.Equal(t, mvccpb.PUT, events[0].Type)
+		require.Equal(t, put.Header.Revision, events[0].Kv.ModRevision)
+		require.Nil(t, events[0].PrevKv)
+		require.Equal(t, "2", string(events[1].Kv.Value))
+		require.Equal(t, "1", string(events[1].PrevKv.Value))
+		require.Equal(t, mvccpb.DELETE, events[2].Type)
+		require.Equal(t, "watch/key", string(events[2].Kv.Key))
+		require.Equal(t, "2", string(events[2].PrevKv.Value))
+	})
+
+	t.Run("From a past revision", func(t *testing.T) {
+		// Arrange
+		first, err := leader.Put(ctx, "replay/key", "1")
+		require.NoError(t, err)
+		_, err = leader.Put(ctx, "replay/key", "2")
+		require.NoError(t, err)
+
+		// Act
+		wch := follower.Watch(ctx, "replay/key", clientv3.WithRev(first.Header.Revision))
+
+		// Assert
+		var values []string
+		for len(values) < 2 {
+			resp := <-wch
+			require.NoError(t, resp.Err())
+			for _, e := range resp.Events {
+				values = append(values, string(e.Kv.Value))
+			}
+		}
+		require.Equal(t, []string{"1", "2"}, values)
+	})
+}
+
+func TestServerWatchCompacted(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	c := testcluster.New(t, 3)
+	i, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	f := (i + 1) % c.Size()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+	_, err = newClient(t, c, i).Put(ctx, "compacted", "value")
+	require.NoError(t, err)
+	_, err = newClient(t, c, f).Get(ctx, "compacted")
+	require.NoError(t, err)
+	// The history of the follower starts after its snapshot once restarted.
+	_, snapshot, err := c.Server(f).Store().Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Close())
+	require.NoError(t, c.Kill(f))
+	require.NoError(t, c.Restart(f))
+
+	// Act
+	wch := newClient(t, c, f).Watch(ctx, "compacted", clientv3.WithRev(1))
+
+	// Assert
+	resp := <-wch
+	require.ErrorIs(t, resp.Err(), rpctypes.ErrCompacted)
+	require.Greater(t, resp.CompactRevision, int64(1))
+}
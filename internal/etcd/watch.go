@@ -0,0 +1,273 @@
+package etcd
+
+import (
+	"context"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// progressInterval is the interval of the progress notifications of the
+// watches requesting them.
+const progressInterval = 10 * time.Minute
+
+// Watch serves the watches of a stream from the recent events of the node.
+func (s *Server) Watch(stream etcdserverpb.Watch_WatchServer) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return status.Error(codes.Unavailable, "server closed")
+	}
+	s.watches.Add(1)
+	s.mu.Unlock()
+	defer s.watches.Done()
+
+	w := &watchStream{
+		server:  s,
+		stream:  stream,
+		watches: make(map[int64]*watch),
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer w.wg.Wait()
+	defer cancel()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.notifyProgress(ctx)
+	}()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch req := req.GetRequestUnion().(type) {
+		case *etcdserverpb.WatchRequest_CreateRequest:
+			err = w.create(ctx, req.CreateRequest)
+		case *etcdserverpb.WatchRequest_CancelRequest:
+			err = w.cancel(req.CancelRequest.GetWatchId(), "")
+		case *etcdserverpb.WatchRequest_ProgressRequest:
+			err = w.send(&etcdserverpb.WatchResponse{
+				Header:  s.header(uint64(w.synced())),
+				WatchId: -1,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// watchStream is the watches of a stream.
+type watchStream struct {
+	server *Server
+	stream etcdserverpb.Watch_WatchServer
+	wg     sync.WaitGroup
+
+	// sendMu serializes the responses.
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	watches map[int64]*watch
+}
+
+// watch is a watch of a stream.
+type watch struct {
+	cancel         context.CancelFunc
+	progressNotify bool
+	// synced is the revision up to which the events were sent.
+	synced atomic.Int64
+	// lastSent is the time of the last response, in Unix nanoseconds.
+	lastSent atomic.Int64
+}
+
+func (w *watchStream) send(resp *etcdserverpb.WatchResponse) error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	return w.stream.Send(resp)
+}
+
+// create starts a watch. The watch is created at the current revision if it
+// has no start revision, and its events are sent in the background.
+func (w *watchStream) create(ctx context.Context, r *etcdserverpb.WatchCreateRequest) error {
+	rev := int64(w.server.Store.AppliedIndex())
+	w.mu.Lock()
+	id := r.GetWatchId()
+	if id == 0 {
+		for w.watches[w.nextID] != nil {
+			w.nextID++
+		}
+		id = w.nextID
+		w.nextID++
+	} else if w.watches[id] != nil {
+		w.mu.Unlock()
+		return w.send(&etcdserverpb.WatchResponse{
+			Header:       w.server.header(uint64(rev)),
+			WatchId:      -1,
+			Created:      true,
+			Canceled:     true,
+			CancelReason: "watch ID already in use",
+		})
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	wa := &watch{cancel: cancel, progressNotify: r.GetProgressNotify()}
+	wa.lastSent.Store(time.Now().UnixNano())
+	w.watches[id] = wa
+	w.mu.Unlock()
+
+	start := r.GetStartRevision()
+	if start <= 0 {
+		start = rev + 1
+	}
+	wa.synced.Store(start - 1)
+	if err := w.send(&etcdserverpb.WatchResponse{
+		Header:  w.server.header(uint64(rev)),
+		WatchId: id,
+		Created: true,
+	}); err != nil {
+		w.remove(id)
+		return err
+	}
+
+	var noPut, noDelete bool
+	for _, filter := range r.GetFilters() {
+		switch filter {
+		case etcdserverpb.WatchCreateRequest_NOPUT:
+			noPut = true
+		case etcdserverpb.WatchCreateRequest_NODELETE:
+			noDelete = true
+		}
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		err := w.server.Store.Watch(
+			ctx,
+			r.GetKey(),
+			r.GetRangeEnd(),
+			start,
+			r.GetPrevKv(),
+			func(events []distributed.WatchEvent, rev int64) error {
+				resp := &etcdserverpb.WatchResponse{
+					Header:  w.server.header(uint64(rev)),
+					WatchId: id,
+				}
+				for _, e := range events {
+					event := &mvccpb.Event{Kv: keyValue(e.KV), PrevKv: keyValue(e.PrevKV)}
+					switch {
+					case e.Type == distributed.WatchEventPut && !noPut:
+						event.Type = mvccpb.PUT
+					case e.Type == distributed.WatchEventDelete && !noDelete:
+						event.Type = mvccpb.DELETE
+					default:
+						continue
+					}
+					resp.Events = append(resp.Events, event)
+				}
+				if len(resp.Events) > 0 {
+					if err := w.send(resp); err != nil {
+						return err
+					}
+					wa.lastSent.Store(time.Now().UnixNano())
+				}
+				wa.synced.Store(rev)
+				return nil
+			},
+		)
+		if errors.Is(err, distributed.ErrCompacted) {
+			_ = w.send(&etcdserverpb.WatchResponse{
+				Header:          w.server.header(w.server.Store.AppliedIndex()),
+				WatchId:         id,
+				Canceled:        true,
+				CompactRevision: w.server.Store.CompactRevision(),
+			})
+			w.remove(id)
+		} else if err != nil && ctx.Err() == nil {
+			_ = w.cancel(id, err.Error())
+		}
+	}()
+	return nil
+}
+
+// cancel cancels a watch, with a reason if it failed.
+func (w *watchStream) cancel(id int64, reason string) error {
+	if !w.remove(id) {
+		return nil
+	}
+	return w.send(&etcdserverpb.WatchResponse{
+		Header:       w.server.header(w.server.Store.AppliedIndex()),
+		WatchId:      id,
+		Canceled:     true,
+		CancelReason: reason,
+	})
+}
+
+// remove stops a watch, and returns false if it does not exist.
+func (w *watchStream) remove(id int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wa, ok := w.watches[id]
+	if !ok {
+		return false
+	}
+	wa.cancel()
+	delete(w.watches, id)
+	return true
+}
+
+// synced returns the revision up to which the events of all the watches were
+// sent.
+func (w *watchStream) synced() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	synced := int64(w.server.Store.AppliedIndex())
+	for _, wa := range w.watches {
+		synced = min(synced, wa.synced.Load())
+	}
+	return synced
+}
+
+// notifyProgress sends a response without events to the watches requesting
+// progress notifications, when no response was sent for an interval.
+func (w *watchStream) notifyProgress(ctx context.Context) {
+	ticker := time.NewTicker(progressInterval / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.mu.Lock()
+		var notified []*etcdserverpb.WatchResponse
+		for id, wa := range w.watches {
+			if !wa.progressNotify ||
+				time.Since(time.Unix(0, wa.lastSent.Load())) < progressInterval {
+				continue
+			}
+			wa.lastSent.Store(time.Now().UnixNano())
+			notified = append(notified, &etcdserverpb.WatchResponse{
+				Header:  w.server.header(uint64(wa.synced.Load())),
+				WatchId: id,
+			})
+		}
+		w.mu.Unlock()
+		for _, resp := range notified {
+			if err := w.send(resp); err != nil {
+				return
+			}
+		}
+	}
+}
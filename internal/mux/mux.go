@@ -0,0 +1,230 @@
+// Package mux multiplexes the Raft traffic and the client HTTP traffic on a single listener.
+//
+// Over TLS, the Raft connections are identified with the ALPN protocol RaftProtocol.
+// Over plain TCP, the Raft connections start with the RaftMagicByte.
+package mux
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// RaftProtocol is the ALPN protocol negotiated by the Raft connections over TLS.
+	RaftProtocol = "dkv-raft"
+	// RaftMagicByte is the first byte sent by the Raft connections over plain TCP.
+	//
+	// It cannot be confused with HTTP/1 or HTTP/2 (prior knowledge) which start
+	// with an ASCII letter.
+	RaftMagicByte byte = 0xDB
+
+	defaultHandshakeTimeout = 10 * time.Second
+)
+
+// Mux dispatches the connections of a listener to a Raft listener and a HTTP listener.
+type Mux struct {
+	// TLSConfig is the configuration used to terminate the TLS connections.
+	// If nil, the connections are plain TCP.
+	TLSConfig *tls.Config
+	// HandshakeTimeout is the maximum duration to detect the protocol of a connection.
+	HandshakeTimeout time.Duration
+
+	root net.Listener
+	http *listener
+	raft *listener
+}
+
+// New creates a multiplexer over l.
+//
+// The TLS configuration is extended to advertise RaftProtocol.
+func New(l net.Listener, tlsConfig *tls.Config) *Mux {
+	if tlsConfig != nil {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.NextProtos = append([]string{RaftProtocol}, tlsConfig.NextProtos...)
+	}
+	return &Mux{
+		TLSConfig:        tlsConfig,
+		HandshakeTimeout: defaultHandshakeTimeout,
+		root:             l,
+		http:             newListener(l.Addr()),
+		raft:             newListener(l.Addr()),
+	}
+}
+
+// HTTPListener returns the listener receiving the HTTP connections.
+func (m *Mux) HTTPListener() net.Listener {
+	return m.http
+}
+
+// RaftListener returns the listener receiving the Raft connections.
+func (m *Mux) RaftListener() net.Listener {
+	return m.raft
+}
+
+// Serve accepts the connections and dispatches them until the listener is closed.
+func (m *Mux) Serve() error {
+	defer func() {
+		_ = m.http.Close()
+		_ = m.raft.Close()
+	}()
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go m.dispatch(conn)
+	}
+}
+
+// Close closes the underlying listener.
+func (m *Mux) Close() error {
+	return m.root.Close()
+}
+
+func (m *Mux) dispatch(conn net.Conn) {
+	if err := conn.SetDeadline(time.Now().Add(m.HandshakeTimeout)); err != nil {
+		_ = conn.Close()
+		return
+	}
+	if m.TLSConfig != nil {
+		tlsConn := tls.Server(conn, m.TLSConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			slog.Debug("tls handshake failed", "remote", conn.RemoteAddr(), "error", err)
+			_ = conn.Close()
+			return
+		}
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			_ = conn.Close()
+			return
+		}
+		if tlsConn.ConnectionState().NegotiatedProtocol == RaftProtocol {
+			m.raft.deliver(tlsConn)
+		} else {
+			m.http.deliver(tlsConn)
+		}
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	b, err := r.Peek(1)
+	if err != nil {
+		slog.Debug("failed to detect protocol", "remote", conn.RemoteAddr(), "error", err)
+		_ = conn.Close()
+		return
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return
+	}
+	if b[0] == RaftMagicByte {
+		_, _ = r.Discard(1)
+		m.raft.deliver(&bufferedConn{Conn: conn, r: r})
+	} else {
+		m.http.deliver(&bufferedConn{Conn: conn, r: r})
+	}
+}
+
+// DialRaft opens a Raft connection to a multiplexed listener.
+func DialRaft(address string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		if _, err := conn.Write([]byte{RaftMagicByte}); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	serverName, _, err := net.SplitHostPort(address)
+	if err != nil {
+		serverName = address
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = serverName
+	tlsConfig.NextProtos = []string{RaftProtocol}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if p := tlsConn.ConnectionState().NegotiatedProtocol; p != RaftProtocol {
+		_ = conn.Close()
+		return nil, fmt.Errorf("peer %s does not multiplex raft traffic (protocol: %q)", address, p)
+	}
+	return tlsConn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+var _ net.Listener = (*listener)(nil)
+
+type listener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newListener(addr net.Addr) *listener {
+	return &listener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *listener) deliver(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,177 @@
+package mux_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"distributed-kv/internal/mux"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSConfigs(t *testing.T) (server *tls.Config, client *tls.Config) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	certificate := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+	}
+}
+
+func newMux(t *testing.T, tlsConfig *tls.Config) *mux.Mux {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	m := mux.New(l, tlsConfig)
+	go func() {
+		_ = m.Serve()
+	}()
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+
+	// Echo server on the Raft listener.
+	go func() {
+		for {
+			conn, err := m.RaftListener().Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	// HTTP server on the HTTP listener.
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("http"))
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() {
+		_ = srv.Serve(m.HTTPListener())
+	}()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+	return m
+}
+
+func TestMux(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title string
+		tls   bool
+	}{
+		{title: "Plain TCP"},
+		{title: "TLS", tls: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Arrange
+			var serverTLSConfig, clientTLSConfig *tls.Config
+			if tt.tls {
+				serverTLSConfig, clientTLSConfig = newTLSConfigs(t)
+			}
+			m := newMux(t, serverTLSConfig)
+			addr := m.RaftListener().Addr().String()
+			client := &http.Client{Timeout: 5 * time.Second}
+			scheme := "http://"
+			if tt.tls {
+				client.Transport = &http.Transport{TLSClientConfig: clientTLSConfig}
+				scheme = "https://"
+			}
+
+			t.Run("Raft", func(t *testing.T) {
+				// Act
+				conn, err := mux.DialRaft(addr, time.Second, clientTLSConfig)
+				require.NoError(t, err)
+				defer conn.Close()
+				_, err = conn.Write([]byte("raft"))
+				require.NoError(t, err)
+				buf := make([]byte, 4)
+				_, err = io.ReadFull(conn, buf)
+
+				// Assert
+				require.NoError(t, err)
+				require.Equal(t, "raft", string(buf))
+			})
+
+			t.Run("HTTP", func(t *testing.T) {
+				// Act
+				resp, err := client.Get(scheme + addr)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+
+				// Assert
+				require.NoError(t, err)
+				require.Equal(t, "http", string(body))
+			})
+		})
+	}
+
+	t.Run("Raft dial to a non-multiplexed TLS server", func(t *testing.T) {
+		// Arrange
+		serverTLSConfig, clientTLSConfig := newTLSConfigs(t)
+		serverTLSConfig.NextProtos = []string{"h2"}
+		l, err := tls.Listen("tcp", "localhost:0", serverTLSConfig)
+		require.NoError(t, err)
+		defer l.Close()
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}()
+
+		// Act
+		_, err = mux.DialRaft(l.Addr().String(), time.Second, clientTLSConfig)
+
+		// Assert
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,237 @@
+package redis
+
+import (
+	"distributed-kv/internal/store"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func (c *conn) typeOf(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	t, err := c.server.Store.Type(args[0], opts)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeSimple(t.String())
+}
+
+func (c *conn) hset(args []string) {
+	if len(args)%2 != 1 {
+		c.w.writeError("ERR wrong number of arguments for 'hset' command")
+		return
+	}
+	fields := make([]store.KeyValue, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		fields = append(fields, store.KeyValue{Key: args[i], Value: args[i+1]})
+	}
+	index, created, err := c.server.Store.HashSet(args[0], fields...)
+	c.record("HSET", args[:1], index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(created)
+}
+
+func (c *conn) hget(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	value, err := c.server.Store.HashGet(args[0], args[1], opts)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.w.writeNull()
+	case err != nil:
+		c.writeStoreError(err)
+	default:
+		c.w.writeBulk(value)
+	}
+}
+
+func (c *conn) hdel(args []string) {
+	c.removeMembers("HDEL", store.TypeHash, args)
+}
+
+func (c *conn) hgetall(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	fields, err := c.server.Store.HashGetAll(args[0], opts)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeMap(len(fields))
+	for _, field := range fields {
+		c.w.writeBulk(field.Key)
+		c.w.writeBulk(field.Value)
+	}
+}
+
+func (c *conn) sadd(args []string) {
+	index, added, err := c.server.Store.SetAdd(args[0], args[1:]...)
+	c.record("SADD", args[:1], index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(added)
+}
+
+func (c *conn) srem(args []string) {
+	c.removeMembers("SREM", store.TypeSet, args)
+}
+
+func (c *conn) smembers(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	members, err := c.server.Store.SetMembers(args[0], opts)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeSet(len(members))
+	for _, member := range members {
+		c.w.writeBulk(member)
+	}
+}
+
+// zadd does not support the options, such as NX or INCR.
+func (c *conn) zadd(args []string) {
+	if len(args)%2 != 1 {
+		c.w.writeError("ERR syntax error")
+		return
+	}
+	members := make([]store.ScoredMember, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(args[i], 64)
+		if err != nil || math.IsNaN(score) {
+			c.w.writeError("ERR value is not a valid float")
+			return
+		}
+		members = append(members, store.ScoredMember{Member: args[i+1], Score: score})
+	}
+	index, added, err := c.server.Store.SortedSetAdd(args[0], members...)
+	c.record("ZADD", args[:1], index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(added)
+}
+
+func (c *conn) zrem(args []string) {
+	c.removeMembers("ZREM", store.TypeSortedSet, args)
+}
+
+// zrangebyscore supports the exclusive bounds, and the WITHSCORES and LIMIT
+// options.
+func (c *conn) zrangebyscore(args []string) {
+	minimum, minErr := parseScoreBound(args[1], math.Inf(1))
+	maximum, maxErr := parseScoreBound(args[2], math.Inf(-1))
+	if minErr != nil || maxErr != nil {
+		c.w.writeError("ERR min or max is not a float")
+		return
+	}
+	withScores, offset, count := false, 0, -1
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(args) {
+				c.w.writeError("ERR syntax error")
+				return
+			}
+			var offsetErr, countErr error
+			offset, offsetErr = strconv.Atoi(args[i+1])
+			count, countErr = strconv.Atoi(args[i+2])
+			if offsetErr != nil || countErr != nil {
+				c.w.writeError("ERR value is not an integer or out of range")
+				return
+			}
+			i += 2
+		default:
+			c.w.writeError("ERR syntax error")
+			return
+		}
+	}
+
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	var members []store.ScoredMember
+	if offset >= 0 && count != 0 {
+		limit := 0
+		if count > 0 {
+			limit = offset + count
+		}
+		var err error
+		members, err = c.server.Store.SortedSetRangeByScore(args[0], minimum, maximum, limit, opts)
+		if err != nil {
+			c.writeStoreError(err)
+			return
+		}
+		members = members[min(offset, len(members)):]
+	}
+	switch {
+	case !withScores:
+		c.w.writeArray(len(members))
+		for _, member := range members {
+			c.w.writeBulk(member.Member)
+		}
+	case c.w.proto >= 3:
+		// The members and their scores are pairs.
+		c.w.writeArray(len(members))
+		for _, member := range members {
+			c.w.writeArray(2)
+			c.w.writeBulk(member.Member)
+			c.w.writeDouble(member.Score)
+		}
+	default:
+		c.w.writeArray(2 * len(members))
+		for _, member := range members {
+			c.w.writeBulk(member.Member)
+			c.w.writeDouble(member.Score)
+		}
+	}
+}
+
+// removeMembers deletes members of a collection of the type t.
+func (c *conn) removeMembers(operation string, t store.Type, args []string) {
+	index, deleted, err := c.server.Store.RemoveMembers(t, args[0], args[1:]...)
+	c.record(operation, args[:1], index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(deleted)
+}
+
+// parseScoreBound parses a bound of a score range. An exclusive bound, which
+// starts with '(', is replaced by the next score towards the direction.
+func parseScoreBound(s string, direction float64) (float64, error) {
+	exclusive := strings.HasPrefix(s, "(")
+	score, err := strconv.ParseFloat(strings.TrimPrefix(s, "("), 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(score) {
+		return 0, errors.New("score is not a number")
+	}
+	if exclusive {
+		score = math.Nextafter(score, direction)
+	}
+	return score, nil
+}
@@ -0,0 +1,459 @@
+package redis
+
+import (
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+const (
+	// serverVersion is the version of Redis whose commands are implemented,
+	// reported to the clients.
+	serverVersion = "7.0.0"
+	// defaultScanCount is the default number of keys returned by SCAN.
+	defaultScanCount = 10
+	// maxTTL is the maximum time to live of a key, so that its expiration time
+	// fits in Unix nanoseconds.
+	maxTTL = 100 * 365 * 24 * time.Hour
+)
+
+// command is a Redis command.
+type command struct {
+	// arity is the number of arguments including the command name, or the
+	// opposite of the minimum number of arguments if negative, like Redis.
+	arity   int
+	handler func(c *conn, args []string)
+}
+
+var commands = map[string]command{
+	"PING":   {arity: -1, handler: (*conn).ping},
+	"ECHO":   {arity: 2, handler: (*conn).echo},
+	"HELLO":  {arity: -1, handler: (*conn).hello},
+	"SELECT": {arity: 2, handler: (*conn).selectDB},
+	"CLIENT": {arity: -2, handler: (*conn).client},
+	"GET":    {arity: 2, handler: (*conn).get},
+	"SET":    {arity: -3, handler: (*conn).set},
+	"DEL":    {arity: -2, handler: (*conn).del},
+	"EXISTS": {arity: -2, handler: (*conn).exists},
+	"MGET":   {arity: -2, handler: (*conn).mget},
+	"MSET":   {arity: -3, handler: (*conn).mset},
+	"INCR":   {arity: 2, handler: (*conn).incr},
+	"KEYS":   {arity: 2, handler: (*conn).keys},
+	"SCAN":   {arity: -2, handler: (*conn).scan},
+	"TYPE":   {arity: 2, handler: (*conn).typeOf},
+
+	"HSET":          {arity: -4, handler: (*conn).hset},
+	"HGET":          {arity: 3, handler: (*conn).hget},
+	"HDEL":          {arity: -3, handler: (*conn).hdel},
+	"HGETALL":       {arity: 2, handler: (*conn).hgetall},
+	"SADD":          {arity: -3, handler: (*conn).sadd},
+	"SREM":          {arity: -3, handler: (*conn).srem},
+	"SMEMBERS":      {arity: 2, handler: (*conn).smembers},
+	"ZADD":          {arity: -4, handler: (*conn).zadd},
+	"ZREM":          {arity: -3, handler: (*conn).zrem},
+	"ZRANGEBYSCORE": {arity: -4, handler: (*conn).zrangebyscore},
+}
+
+func (c *conn) ping(args []string) {
+	switch len(args) {
+	case 0:
+		c.w.writeSimple("PONG")
+	case 1:
+		c.w.writeBulk(args[0])
+	default:
+		c.w.writeError("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+func (c *conn) echo(args []string) {
+	c.w.writeBulk(args[0])
+}
+
+// hello switches the version of the protocol, and replies the properties of
+// the server.
+func (c *conn) hello(args []string) {
+	proto := c.w.proto
+	if len(args) > 0 {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 2 || version > 3 {
+			c.w.writeError("NOPROTO unsupported protocol version")
+			return
+		}
+		proto = version
+	}
+	for i := 1; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "SETNAME":
+			if i+1 >= len(args) {
+				c.w.writeError("ERR syntax error")
+				return
+			}
+			i++
+			c.name = args[i]
+		case "AUTH":
+			c.w.writeError("ERR authentication is not supported, use TLS client certificates")
+			return
+		default:
+			c.w.writeError("ERR syntax error")
+			return
+		}
+	}
+	c.w.proto = proto
+
+	role := "replica"
+	if c.server.Store.IsLeader() {
+		role = "master"
+	}
+	c.w.writeMap(7)
+	c.w.writeBulk("server")
+	c.w.writeBulk("dkv")
+	c.w.writeBulk("version")
+	c.w.writeBulk(serverVersion)
+	c.w.writeBulk("proto")
+	c.w.writeInt(int64(proto))
+	c.w.writeBulk("id")
+	c.w.writeInt(0)
+	c.w.writeBulk("mode")
+	c.w.writeBulk("standalone")
+	c.w.writeBulk("role")
+	c.w.writeBulk(role)
+	c.w.writeBulk("modules")
+	c.w.writeArray(0)
+}
+
+// selectDB only accepts the database 0.
+func (c *conn) selectDB(args []string) {
+	if args[0] != "0" {
+		c.w.writeError("ERR DB index is out of range")
+		return
+	}
+	c.w.writeSimple("OK")
+}
+
+func (c *conn) client(args []string) {
+	switch strings.ToUpper(args[0]) {
+	case "SETNAME":
+		if len(args) != 2 {
+			c.w.writeError("ERR wrong number of arguments for 'client|setname' command")
+			return
+		}
+		c.name = args[1]
+		c.w.writeSimple("OK")
+	case "GETNAME":
+		if c.name == "" {
+			c.w.writeNull()
+			return
+		}
+		c.w.writeBulk(c.name)
+	case "SETINFO":
+		// The library name and version are ignored.
+		c.w.writeSimple("OK")
+	default:
+		c.w.writeError("ERR unknown subcommand '" + args[0] + "'")
+	}
+}
+
+func (c *conn) get(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	value, err := c.server.Store.Get(args[0], opts)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.w.writeNull()
+	case err != nil:
+		c.writeStoreError(err)
+	default:
+		c.w.writeBulk(value)
+	}
+}
+
+// set supports the NX, XX, EX and PX options. The reply is null if the
+// condition does not hold.
+func (c *conn) set(args []string) {
+	var opts store.SetOptions
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); {
+		case option == "NX" && !opts.IfExists:
+			opts.IfAbsent = true
+		case option == "XX" && !opts.IfAbsent:
+			opts.IfExists = true
+		case (option == "EX" || option == "PX") && opts.TTL == 0 && i+1 < len(args):
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil {
+				c.w.writeError("ERR value is not an integer or out of range")
+				return
+			}
+			unit := time.Second
+			if option == "PX" {
+				unit = time.Millisecond
+			}
+			if n <= 0 || n > int64(maxTTL/unit) {
+				c.w.writeError("ERR invalid expire time in 'set' command")
+				return
+			}
+			opts.TTL = time.Duration(n) * unit
+		default:
+			c.w.writeError("ERR syntax error")
+			return
+		}
+	}
+	index, written, err := c.server.Store.SetKeys(
+		[]store.KeyValue{{Key: args[0], Value: args[1]}},
+		opts,
+	)
+	c.record("SET", []string{args[0]}, index, err)
+	switch {
+	case err != nil:
+		c.writeStoreError(err)
+	case !written:
+		c.w.writeNull()
+	default:
+		c.w.writeSimple("OK")
+	}
+}
+
+// del deletes the keys of any type.
+func (c *conn) del(args []string) {
+	index, deleted, err := c.server.Store.DeleteKeys(args...)
+	c.record("DEL", args, index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(deleted)
+}
+
+// exists counts the existing keys of any type. A key is counted as many times as it is
+// repeated.
+func (c *conn) exists(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	var count int64
+	for _, key := range args {
+		t, err := c.server.Store.Type(key, opts)
+		if err != nil {
+			c.writeStoreError(err)
+			return
+		}
+		if t != store.TypeNone {
+			count++
+		}
+	}
+	c.w.writeInt(count)
+}
+
+func (c *conn) mget(args []string) {
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	values := make([]*string, len(args))
+	for i, key := range args {
+		value, err := c.server.Store.Get(key, opts)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			c.writeStoreError(err)
+			return
+		}
+		values[i] = &value
+	}
+	c.w.writeArray(len(values))
+	for _, value := range values {
+		if value == nil {
+			c.w.writeNull()
+			continue
+		}
+		c.w.writeBulk(*value)
+	}
+}
+
+func (c *conn) mset(args []string) {
+	if len(args)%2 != 0 {
+		c.w.writeError("ERR wrong number of arguments for 'mset' command")
+		return
+	}
+	entries := make([]store.KeyValue, 0, len(args)/2)
+	keys := make([]string, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		entries = append(entries, store.KeyValue{Key: args[i], Value: args[i+1]})
+		keys = append(keys, args[i])
+	}
+	index, _, err := c.server.Store.SetKeys(entries, store.SetOptions{})
+	c.record("MSET", keys, index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeSimple("OK")
+}
+
+func (c *conn) incr(args []string) {
+	index, value, err := c.server.Store.Increment(args[0], 1, store.IncrementOptions{})
+	c.record("INCR", args, index, err)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	c.w.writeInt(value)
+}
+
+// keys only supports the patterns matching a prefix.
+func (c *conn) keys(args []string) {
+	prefix, exact, err := parsePattern(args[0])
+	if err != nil {
+		c.w.writeError("ERR " + err.Error())
+		return
+	}
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	keys, err := c.server.Store.Keys(prefix, "", 0, opts)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	if exact {
+		keys = matchExact(keys, prefix)
+	}
+	c.w.writeArray(len(keys))
+	for _, key := range keys {
+		c.w.writeBulk(key)
+	}
+}
+
+// scan supports the MATCH option with the patterns matching a prefix, and the
+// COUNT option. The keys existing during the whole scan are returned once.
+func (c *conn) scan(args []string) {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		c.w.writeError("ERR invalid cursor")
+		return
+	}
+	var after string
+	if id != 0 {
+		var ok bool
+		if after, ok = c.server.cursors.load(id); !ok {
+			c.w.writeError("ERR invalid cursor")
+			return
+		}
+	}
+	prefix, exact, count := "", false, defaultScanCount
+	for i := 1; i < len(args); i++ {
+		if i+1 >= len(args) {
+			c.w.writeError("ERR syntax error")
+			return
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			if prefix, exact, err = parsePattern(args[i+1]); err != nil {
+				c.w.writeError("ERR " + err.Error())
+				return
+			}
+		case "COUNT":
+			if count, err = strconv.Atoi(args[i+1]); err != nil || count < 1 {
+				c.w.writeError("ERR value is not an integer or out of range")
+				return
+			}
+		default:
+			c.w.writeError("ERR syntax error")
+			return
+		}
+		i++
+	}
+
+	opts, ok := c.readOptions()
+	if !ok {
+		return
+	}
+	keys, err := c.server.Store.Keys(prefix, after, count, opts)
+	if err != nil {
+		c.writeStoreError(err)
+		return
+	}
+	var next uint64
+	if len(keys) == count {
+		next = c.server.cursors.save(keys[len(keys)-1])
+	}
+	if exact {
+		keys = matchExact(keys, prefix)
+	}
+	c.w.writeArray(2)
+	c.w.writeBulk(strconv.FormatUint(next, 10))
+	c.w.writeArray(len(keys))
+	for _, key := range keys {
+		c.w.writeBulk(key)
+	}
+}
+
+// readOptions returns the options of a linearizable read: the read waits for
+// the read index of the leader. An error is replied on failure.
+func (c *conn) readOptions() (store.ReadOptions, bool) {
+	index, err := c.server.Store.ReadIndex()
+	if err != nil {
+		c.writeStoreError(err)
+		return store.ReadOptions{}, false
+	}
+	return store.ReadOptions{MinIndex: index}, true
+}
+
+// writeStoreError replies an error of the store. The errors caused by a
+// missing leader, which happen before the command is proposed, are marked
+// TRYAGAIN so that the clients retry them.
+func (c *conn) writeStoreError(err error) {
+	switch {
+	case errors.Is(err, store.ErrNotInteger):
+		c.w.writeError("ERR value is not an integer or out of range")
+	case errors.Is(err, store.ErrOverflow):
+		c.w.writeError("ERR increment or decrement would overflow")
+	case errors.Is(err, store.ErrWrongType):
+		c.w.writeError("WRONGTYPE Operation against a key holding the wrong kind of value")
+	case errors.Is(err, store.ErrNoLeader),
+		errors.Is(err, raft.ErrNotLeader),
+		errors.Is(err, distributed.ErrNotLeader):
+		c.w.writeError("TRYAGAIN " + err.Error())
+	default:
+		c.w.writeError("ERR " + err.Error())
+	}
+}
+
+// record records a write in the audit log.
+func (c *conn) record(operation string, keys []string, index uint64, err error) {
+	if c.server.Audit == nil {
+		return
+	}
+	c.server.Audit.Record(audit.NewEntry(c.caller, operation, strings.Join(keys, " "), index, err))
+}
+
+// parsePattern parses a glob-style pattern which is a literal prefix followed
+// by '*', or a literal key if exact is true.
+func parsePattern(pattern string) (prefix string, exact bool, err error) {
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	if strings.ContainsAny(prefix, `*?[\`) {
+		return "", false, errors.New("only the patterns matching a prefix, such as 'prefix*', are supported")
+	}
+	return prefix, !wildcard, nil
+}
+
+// matchExact returns the keys equal to the key.
+func matchExact(keys []string, key string) []string {
+	for _, k := range keys {
+		if k == key {
+			return []string{key}
+		}
+	}
+	return nil
+}
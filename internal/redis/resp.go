@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxBulkLength is the maximum length of a bulk string of a command.
+	maxBulkLength = 512 << 20
+	// maxArrayLength is the maximum number of arguments of a command.
+	maxArrayLength = 1 << 20
+)
+
+// errProtocol is returned when a command is not a valid RESP array of bulk
+// strings or inline command. The connection is closed.
+var errProtocol = errors.New("protocol error")
+
+// readCommand reads a command: an array of bulk strings, or an inline command
+// made of words separated by spaces.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArrayLength {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	args := make([]string, 0, max(n, 0))
+	for range n {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("%w: expected '$', got '%.1s'", errProtocol, line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLength {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if string(buf[size:]) != "\r\n" {
+			return nil, fmt.Errorf("%w: expected CRLF after bulk string", errProtocol)
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+// readLine reads a line terminated by CRLF, or by LF for inline commands.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	line = strings.TrimSuffix(line[:len(line)-1], "\r")
+	return line, nil
+}
+
+// writer writes the replies in the version of the protocol of the connection.
+//
+// RESP3 types are downgraded to their RESP2 equivalent on RESP2 connections.
+// The errors are sticky, and returned by Flush.
+type writer struct {
+	*bufio.Writer
+	// proto is the version of the protocol, 2 or 3.
+	proto int
+}
+
+func (w *writer) writeSimple(s string) {
+	_, _ = w.WriteString("+" + s + "\r\n")
+}
+
+// writeError writes an error. The message starts with an error code, such as
+// ERR.
+func (w *writer) writeError(msg string) {
+	// The line cannot be broken by the message.
+	msg = strings.NewReplacer("\r", " ", "\n", " ").Replace(msg)
+	_, _ = w.WriteString("-" + msg + "\r\n")
+}
+
+func (w *writer) writeInt(n int64) {
+	_, _ = w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *writer) writeBulk(s string) {
+	_, _ = w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (w *writer) writeNull() {
+	if w.proto >= 3 {
+		_, _ = w.WriteString("_\r\n")
+		return
+	}
+	_, _ = w.WriteString("$-1\r\n")
+}
+
+// writeArray writes the header of an array of n elements.
+func (w *writer) writeArray(n int) {
+	_, _ = w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// writeMap writes the header of a map of n pairs. With RESP2, a map is an
+// array of keys and values.
+func (w *writer) writeMap(n int) {
+	if w.proto >= 3 {
+		_, _ = w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.writeArray(2 * n)
+}
+
+// writeSet writes the header of a set of n elements. With RESP2, a set is an
+// array.
+func (w *writer) writeSet(n int) {
+	if w.proto >= 3 {
+		_, _ = w.WriteString("~" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.writeArray(n)
+}
+
+// writeDouble writes a floating point number. With RESP2, it is a bulk string.
+func (w *writer) writeDouble(f float64) {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if math.IsInf(f, 0) {
+		s = strings.ToLower(strings.TrimPrefix(s, "+"))
+	}
+	if w.proto >= 3 {
+		_, _ = w.WriteString("," + s + "\r\n")
+		return
+	}
+	w.writeBulk(s)
+}
@@ -0,0 +1,263 @@
+// Package redis serves the key-value store with the Redis protocol, RESP2 and
+// RESP3, so that Redis clients can use it.
+//
+// The writes are replicated through Raft, like the writes of the RPC API, and
+// the reads are linearizable on any node: each read waits for the read index
+// of the leader.
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCursors is the maximum number of SCAN cursors remembered by the
+	// server. The oldest cursor is forgotten when the limit is reached.
+	maxCursors = 1024
+	// cursorTTL is the duration after which a SCAN cursor is forgotten.
+	cursorTTL = 10 * time.Minute
+)
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("redis: server closed")
+
+// Server serves the Redis protocol.
+type Server struct {
+	// Store is the Raft store serving the commands.
+	Store *distributed.Store
+	// Audit records the writes, if set.
+	Audit audit.Sink
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+	cursors   cursors
+}
+
+// Serve accepts the connections of the listener until the server is closed.
+// The listener is closed by Close.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.track(l) {
+		_ = l.Close()
+		return ErrServerClosed
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.serveConn(conn)
+	}
+}
+
+// track records a listener, or returns false if the server is closed.
+func (s *Server) track(l net.Listener) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		_ = nc.Close()
+		return
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+	}
+	s.conns[nc] = struct{}{}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.conns, nc)
+			s.mu.Unlock()
+			_ = nc.Close()
+		}()
+		c := &conn{
+			Conn:   nc,
+			server: s,
+			r:      bufio.NewReader(nc),
+			w:      &writer{Writer: bufio.NewWriter(nc), proto: 2},
+		}
+		c.serve()
+	}()
+}
+
+// Close closes the listeners and the connections, and waits for the commands
+// being served.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	for l := range s.listeners {
+		err = errors.Join(err, l.Close())
+	}
+	for nc := range s.conns {
+		_ = nc.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// conn is a client connection.
+type conn struct {
+	net.Conn
+	server *Server
+	r      *bufio.Reader
+	w      *writer
+	// caller is the identity of the client for the audit log.
+	caller string
+	// name is set by CLIENT SETNAME.
+	name string
+}
+
+func (c *conn) serve() {
+	if tc, ok := c.Conn.(*tls.Conn); ok {
+		if err := tc.Handshake(); err != nil {
+			slog.Debug("redis TLS handshake failed", "remote", c.RemoteAddr(), "error", err)
+			return
+		}
+	}
+	c.caller = callerIdentity(c.Conn)
+	for {
+		args, err := readCommand(c.r)
+		if errors.Is(err, errProtocol) {
+			c.w.writeError("ERR " + err.Error())
+			_ = c.w.Flush()
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Debug("redis connection failed", "remote", c.RemoteAddr(), "error", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		quit := c.dispatch(args)
+		// The replies of pipelined commands are flushed together.
+		if c.r.Buffered() == 0 || quit {
+			if err := c.w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// dispatch executes a command, and returns true if the connection must be
+// closed.
+func (c *conn) dispatch(args []string) (quit bool) {
+	name := strings.ToUpper(args[0])
+	if name == "QUIT" {
+		c.w.writeSimple("OK")
+		return true
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		c.w.writeError("ERR unknown command '" + args[0] + "'")
+		return false
+	}
+	if n := len(args); (cmd.arity > 0 && n != cmd.arity) || n < -cmd.arity {
+		c.w.writeError("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+		return false
+	}
+	cmd.handler(c, args[1:])
+	return false
+}
+
+// callerIdentity returns the Common Name of the client certificate of the
+// connection, or the address of the client if it is not authenticated.
+func callerIdentity(nc net.Conn) string {
+	if tc, ok := nc.(*tls.Conn); ok {
+		if certs := tc.ConnectionState().PeerCertificates; len(certs) > 0 {
+			return certs[0].Subject.CommonName
+		}
+	}
+	return nc.RemoteAddr().String()
+}
+
+// cursors are the SCAN cursors: the last key returned by a scan, by cursor.
+//
+// The Redis clients expect integer cursors, which cannot encode the position
+// of a scan in an ordered key space. The cursors are only known by the node
+// which returned them, until it restarts: a scan must be continued on the same
+// node, otherwise it fails with an invalid cursor.
+type cursors struct {
+	mu   sync.Mutex
+	last uint64
+	byID map[uint64]cursor
+}
+
+type cursor struct {
+	after   string
+	created time.Time
+}
+
+// save returns a new cursor resuming a scan after the key.
+func (cs *cursors) save(after string) uint64 {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.byID == nil {
+		cs.byID = make(map[uint64]cursor)
+	}
+	now := time.Now()
+	var oldest uint64
+	for id, c := range cs.byID {
+		if now.Sub(c.created) > cursorTTL {
+			delete(cs.byID, id)
+		} else if oldest == 0 || c.created.Before(cs.byID[oldest].created) {
+			oldest = id
+		}
+	}
+	if len(cs.byID) >= maxCursors {
+		delete(cs.byID, oldest)
+	}
+	cs.last++
+	cs.byID[cs.last] = cursor{after: after, created: now}
+	return cs.last
+}
+
+// load returns the key after which the scan of a cursor resumes.
+func (cs *cursors) load(id uint64) (string, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c, ok := cs.byID[id]
+	if !ok || time.Since(c.created) > cursorTTL {
+		return "", false
+	}
+	return c.after, true
+}
@@ -0,0 +1,280 @@
+package redis_test
+
+import (
+	"bufio"
+	"context"
+	"distributed-kv/internal/testcluster"
+	"distributed-kv/pkg/server"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 10 * time.Second
+
+// newCluster starts a cluster serving the Redis protocol, and returns the
+// Redis address of a follower.
+func newCluster(t *testing.T) string {
+	t.Helper()
+
+	c := testcluster.New(t, 3, testcluster.WithConfig(func(_ int, config *server.Config) {
+		config.ListenRedisAddress = "127.0.0.1:0"
+	}))
+	leader, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	return c.Server((leader + 1) % c.Size()).RedisAddress()
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	addr := newCluster(t)
+
+	for _, protocol := range []int{2, 3} {
+		t.Run(fmt.Sprintf("RESP%d", protocol), func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctx := context.Background()
+			rdb := redis.NewClient(&redis.Options{Addr: addr, Protocol: protocol})
+			t.Cleanup(func() { _ = rdb.Close() })
+			prefix := fmt.Sprintf("resp%d/", protocol)
+			key := func(name string) string { return prefix + name }
+
+			t.Run("Set and Get", func(t *testing.T) {
+				// Act
+				err := rdb.Set(ctx, key("key"), "value", 0).Err()
+				require.NoError(t, err)
+				value, err := rdb.Get(ctx, key("key")).Result()
+				_, missingErr := rdb.Get(ctx, key("missing")).Result()
+
+				// Assert
+				require.NoError(t, err)
+				require.Equal(t, "value", value)
+				require.ErrorIs(t, missingErr, redis.Nil)
+			})
+
+			t.Run("Set NX and XX", func(t *testing.T) {
+				// Arrange
+				setArgs := func(name, value, mode string) error {
+					return rdb.SetArgs(ctx, key(name), value, redis.SetArgs{Mode: mode}).Err()
+				}
+
+				// Act
+				createErr := setArgs("nx", "1", "NX")
+				recreateErr := setArgs("nx", "2", "NX")
+				updateErr := setArgs("nx", "3", "XX")
+				missingErr := setArgs("xx", "1", "XX")
+				value, err := rdb.Get(ctx, key("nx")).Result()
+
+				// Assert
+				require.NoError(t, createErr)
+				require.ErrorIs(t, recreateErr, redis.Nil)
+				require.NoError(t, updateErr)
+				require.ErrorIs(t, missingErr, redis.Nil)
+				require.NoError(t, err)
+				require.Equal(t, "3", value)
+			})
+
+			t.Run("Set EX", func(t *testing.T) {
+				// Act
+				err := rdb.Set(ctx, key("ex"), "value", time.Second).Err()
+
+				// Assert
+				require.NoError(t, err)
+				require.Equal(t, int64(1), rdb.Exists(ctx, key("ex")).Val())
+				require.Eventually(t, func() bool {
+					return rdb.Exists(ctx, key("ex")).Val() == 0
+				}, timeout, 100*time.Millisecond, "the key expires")
+			})
+
+			t.Run("MSet, MGet, Exists and Del", func(t *testing.T) {
+				// Act
+				err := rdb.MSet(ctx, key("m1"), "1", key("m2"), "2").Err()
+				require.NoError(t, err)
+				values, err := rdb.MGet(ctx, key("m1"), key("m2"), key("m3")).Result()
+				require.NoError(t, err)
+				exists, err := rdb.Exists(ctx, key("m1"), key("m2"), key("m3")).Result()
+				require.NoError(t, err)
+				deleted, err := rdb.Del(ctx, key("m1"), key("m3")).Result()
+				require.NoError(t, err)
+				remaining, err := rdb.Exists(ctx, key("m1"), key("m2")).Result()
+
+				// Assert
+				require.Equal(t, []any{"1", "2", nil}, values)
+				require.Equal(t, int64(2), exists)
+				require.Equal(t, int64(1), deleted)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), remaining)
+			})
+
+			t.Run("Incr", func(t *testing.T) {
+				// Arrange
+				require.NoError(t, rdb.Set(ctx, key("text"), "value", 0).Err())
+
+				// Act
+				first, err := rdb.Incr(ctx, key("counter")).Result()
+				require.NoError(t, err)
+				second, err := rdb.Incr(ctx, key("counter")).Result()
+				require.NoError(t, err)
+				textErr := rdb.Incr(ctx, key("text")).Err()
+
+				// Assert
+				require.Equal(t, int64(1), first)
+				require.Equal(t, int64(2), second)
+				require.EqualError(t, textErr, "ERR value is not an integer or out of range")
+			})
+
+			t.Run("Reserved keys", func(t *testing.T) {
+				// Act
+				setErr := rdb.Set(ctx, "\x00applied", "1", 0).Err()
+				hashErr := rdb.HSet(ctx, "\x00collection/1/ahb", "a", "1").Err()
+
+				// Assert
+				require.ErrorContains(t, setErr, "reserved prefix")
+				require.ErrorContains(t, hashErr, "reserved prefix")
+			})
+
+			t.Run("Hashes", func(t *testing.T) {
+				// Act
+				created, err := rdb.HSet(ctx, key("hash"), "a", "1", "b", "2").Result()
+				require.NoError(t, err)
+				value, err := rdb.HGet(ctx, key("hash"), "a").Result()
+				require.NoError(t, err)
+				_, missingErr := rdb.HGet(ctx, key("hash"), "c").Result()
+				deleted, err := rdb.HDel(ctx, key("hash"), "a", "c").Result()
+				require.NoError(t, err)
+				fields, err := rdb.HGetAll(ctx, key("hash")).Result()
+				require.NoError(t, err)
+				typ, err := rdb.Type(ctx, key("hash")).Result()
+				require.NoError(t, err)
+				wrongTypeErr := rdb.SAdd(ctx, key("hash"), "a").Err()
+
+				// Assert
+				require.Equal(t, int64(2), created)
+				require.Equal(t, "1", value)
+				require.ErrorIs(t, missingErr, redis.Nil)
+				require.Equal(t, int64(1), deleted)
+				require.Equal(t, map[string]string{"b": "2"}, fields)
+				require.Equal(t, "hash", typ)
+				require.EqualError(t, wrongTypeErr, "WRONGTYPE Operation against a key holding the wrong kind of value")
+			})
+
+			t.Run("Sets", func(t *testing.T) {
+				// Act
+				added, err := rdb.SAdd(ctx, key("set"), "b", "a", "b").Result()
+				require.NoError(t, err)
+				removed, err := rdb.SRem(ctx, key("set"), "b").Result()
+				require.NoError(t, err)
+				members, err := rdb.SMembers(ctx, key("set")).Result()
+				require.NoError(t, err)
+				exists, err := rdb.Exists(ctx, key("set")).Result()
+				require.NoError(t, err)
+				deleted, err := rdb.Del(ctx, key("set")).Result()
+
+				// Assert
+				require.Equal(t, int64(2), added)
+				require.Equal(t, int64(1), removed)
+				require.Equal(t, []string{"a"}, members)
+				require.Equal(t, int64(1), exists)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), deleted)
+			})
+
+			t.Run("Sorted sets", func(t *testing.T) {
+				// Act
+				added, err := rdb.ZAdd(ctx, key("zset"),
+					redis.Z{Score: 3, Member: "c"},
+					redis.Z{Score: 1, Member: "a"},
+					redis.Z{Score: 2, Member: "b"},
+				).Result()
+				require.NoError(t, err)
+				removed, err := rdb.ZRem(ctx, key("zset"), "c").Result()
+				require.NoError(t, err)
+				all, err := rdb.ZRangeByScore(ctx, key("zset"), &redis.ZRangeBy{Min: "-inf", Max: "+inf"}).Result()
+				require.NoError(t, err)
+				scored, err := rdb.ZRangeByScoreWithScores(ctx, key("zset"), &redis.ZRangeBy{Min: "(1", Max: "2"}).Result()
+				require.NoError(t, err)
+				limited, err := rdb.ZRangeByScore(ctx, key("zset"), &redis.ZRangeBy{
+					Min:    "0",
+					Max:    "10",
+					Offset: 1,
+					Count:  1,
+				}).Result()
+
+				// Assert
+				require.Equal(t, int64(3), added)
+				require.Equal(t, int64(1), removed)
+				require.Equal(t, []string{"a", "b"}, all)
+				require.Equal(t, []redis.Z{{Score: 2, Member: "b"}}, scored)
+				require.NoError(t, err)
+				require.Equal(t, []string{"b"}, limited)
+			})
+
+			t.Run("Keys and Scan", func(t *testing.T) {
+				// Arrange
+				expected := make([]string, 0, 25)
+				for i := range 25 {
+					expected = append(expected, key(fmt.Sprintf("scan/%02d", i)))
+					require.NoError(t, rdb.Set(ctx, expected[i], "value", 0).Err())
+				}
+
+				// Act
+				keys, err := rdb.Keys(ctx, key("scan/*")).Result()
+				require.NoError(t, err)
+				var scanned []string
+				iter := rdb.Scan(ctx, 0, key("scan/*"), 10).Iterator()
+				for iter.Next(ctx) {
+					scanned = append(scanned, iter.Val())
+				}
+				require.NoError(t, iter.Err())
+				globErr := rdb.Keys(ctx, key("scan/?")).Err()
+
+				// Assert
+				require.Equal(t, expected, keys)
+				require.Equal(t, expected, scanned)
+				require.Error(t, globErr, "only the prefix patterns are supported")
+			})
+		})
+	}
+}
+
+func TestServerRawProtocol(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	addr := newCluster(t)
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	r := bufio.NewReader(conn)
+
+	// Act
+	// An inline command, followed by pipelined commands.
+	_, err = io.WriteString(conn, "PING\r\n"+
+		"*3\r\n$3\r\nSET\r\n$3\r\nraw\r\n$5\r\nvalue\r\n"+
+		"*2\r\n$3\r\nGET\r\n$3\r\nraw\r\n"+
+		"*1\r\n$7\r\nUNKNOWN\r\n"+
+		"*1\r\n$$\r\n")
+	require.NoError(t, err)
+
+	// Assert
+	for _, expected := range []string{
+		"+PONG\r\n",
+		"+OK\r\n",
+		"$5\r\n", "value\r\n",
+		"-ERR unknown command 'UNKNOWN'\r\n",
+		"-ERR protocol error: invalid bulk length\r\n",
+	} {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, expected, line)
+	}
+	_, err = r.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF, "the connection is closed after a protocol error")
+}
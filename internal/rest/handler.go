@@ -0,0 +1,387 @@
+// Package rest serves the keys of the store with a plain HTTP API, so that the
+// shell scripts and the web applications can use it without the Connect
+// protocol:
+//
+//	GET    /v1/kv/{key}              value of a key
+//	GET    /v1/kv/{prefix}?prefix    keys with a prefix, as a JSON array
+//	GET    /v1/kv/{prefix}?recurse   keys with a prefix and their values
+//	PUT    /v1/kv/{key}              set the value of a key to the body
+//	DELETE /v1/kv/{key}              delete a key, or a prefix with ?recurse
+//
+// The values are served with the media type of their write. The ETag of a key
+// is its revision, the Raft index of its last write: a write with ?cas=<revision>
+// only succeeds if the key was not written since, and ?cas=0 only creates the
+// key. A read with ?wait=<duration> waits until a key of the read is written
+// after the revision ?index=<revision>, or the current revision if it is not
+// set, or until the duration elapses.
+//
+// The reads are linearizable on any node, unless they are ?stale.
+package rest
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/api"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
+)
+
+const (
+	// Path is the HTTP path of the handler, to route the keys to it.
+	Path = "/v1/kv/"
+	// IndexHeader is the response header of the revision of the store.
+	IndexHeader = "X-Dkv-Index"
+
+	// maxValueSize is the maximum size of a written value.
+	maxValueSize = 1 << 20
+	// defaultWait is the duration of a ?wait without duration.
+	defaultWait = time.Minute
+	// maxWait is the maximum duration of a ?wait.
+	maxWait = 10 * time.Minute
+)
+
+// errChanged stops a wait when a key changed.
+var errChanged = errors.New("changed")
+
+// Handler serves the keys under Path.
+type Handler struct {
+	// Store is the Raft store serving the requests.
+	Store *distributed.Store
+	// Audit records the writes, if set.
+	Audit audit.Sink
+
+	// mu guards closed, so that no wait starts once closed.
+	mu     sync.Mutex
+	closed bool
+	waits  sync.WaitGroup
+}
+
+// Close waits for the waiting reads to return and refuses the next ones. The
+// connections must be closed first, so that the reads are canceled.
+func (h *Handler) Close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	h.waits.Wait()
+}
+
+// Entry is a key of a recursive listing.
+type Entry struct {
+	Key string `json:"key"`
+	// Value is encoded in base64.
+	Value          []byte `json:"value"`
+	ContentType    string `json:"content_type,omitempty"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, Path)
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, key, query)
+	case http.MethodPut:
+		h.put(w, r, key, query)
+	case http.MethodDelete:
+		h.delete(w, r, key, query)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	list := query.Has("prefix") || query.Has("recurse")
+	if key == "" && !list {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+	op := &dkvv1.RangeOperation{Key: []byte(key)}
+	if list {
+		op.RangeEnd = prefixEnd(key)
+		op.KeysOnly = !query.Has("recurse")
+	}
+	if query.Has("wait") && !h.wait(w, r, op, query) {
+		return
+	}
+
+	var opts store.ReadOptions
+	if !query.Has("stale") {
+		index, err := h.Store.ReadIndex()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		opts.MinIndex = index
+	}
+	rev, result, err := h.Store.Range(op, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set(IndexHeader, strconv.FormatUint(rev, 10))
+	switch {
+	case op.GetKeysOnly():
+		keys := make([]string, 0, len(result.GetKvs()))
+		for _, kv := range result.GetKvs() {
+			keys = append(keys, string(kv.GetKey()))
+		}
+		writeJSON(w, keys)
+	case list:
+		entries := make([]Entry, 0, len(result.GetKvs()))
+		for _, kv := range result.GetKvs() {
+			entries = append(entries, Entry{
+				Key:            string(kv.GetKey()),
+				Value:          kv.GetValue(),
+				ContentType:    kv.GetContentType(),
+				CreateRevision: kv.GetCreateRevision(),
+				ModRevision:    kv.GetModRevision(),
+				Version:        kv.GetVersion(),
+			})
+		}
+		writeJSON(w, entries)
+	case len(result.GetKvs()) == 0:
+		http.Error(w, store.ErrNotFound.Error(), http.StatusNotFound)
+	default:
+		kv := result.GetKvs()[0]
+		contentType := kv.GetContentType()
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", etag(kv.GetModRevision()))
+		_, _ = w.Write(kv.GetValue())
+	}
+}
+
+// wait waits until a key of the range is written after the revision of the
+// request, or until the duration of the request elapses. It returns false if
+// the request failed.
+func (h *Handler) wait(
+	w http.ResponseWriter,
+	r *http.Request,
+	op *dkvv1.RangeOperation,
+	query url.Values,
+) bool {
+	timeout := defaultWait
+	if s := query.Get("wait"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid wait: "+s, http.StatusBadRequest)
+			return false
+		}
+		timeout = min(d, maxWait)
+	}
+	var start int64
+	if s := query.Get("index"); s != "" {
+		index, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || index < 0 {
+			http.Error(w, "invalid index: "+s, http.StatusBadRequest)
+			return false
+		}
+		start = index + 1
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		http.Error(w, "server closed", http.StatusServiceUnavailable)
+		return false
+	}
+	h.waits.Add(1)
+	h.mu.Unlock()
+	defer h.waits.Done()
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	err := h.Store.Watch(
+		ctx,
+		op.GetKey(),
+		op.GetRangeEnd(),
+		start,
+		false,
+		func(events []distributed.WatchEvent, _ int64) error {
+			if len(events) > 0 {
+				return errChanged
+			}
+			return nil
+		},
+	)
+	switch {
+	case errors.Is(err, errChanged),
+		// The keys may have changed since a compacted revision.
+		errors.Is(err, distributed.ErrCompacted),
+		errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == nil:
+		return true
+	case r.Context().Err() != nil:
+		// The client is gone.
+		return false
+	default:
+		writeError(w, err)
+		return false
+	}
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	if key == "" {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+	value, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxValueSize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	txn := &dkvv1.TxnCommand{Success: []*dkvv1.Operation{{
+		Operation: &dkvv1.Operation_Put{Put: &dkvv1.PutOperation{
+			Key:         []byte(key),
+			Value:       value,
+			ContentType: r.Header.Get("Content-Type"),
+		}},
+	}}}
+	if err := compare(txn, key, query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if index, ok := h.write(w, r, "Put", key, txn); ok {
+		// The revision of the key is the index of its write.
+		w.Header().Set("ETag", etag(int64(index)))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	recurse := query.Has("recurse")
+	if key == "" && !recurse {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+	if recurse && query.Has("cas") {
+		http.Error(w, "cas is not supported with recurse", http.StatusBadRequest)
+		return
+	}
+	del := &dkvv1.DeleteRangeOperation{Key: []byte(key)}
+	if recurse {
+		del.RangeEnd = prefixEnd(key)
+	}
+	txn := &dkvv1.TxnCommand{Success: []*dkvv1.Operation{{
+		Operation: &dkvv1.Operation_DeleteRange{DeleteRange: del},
+	}}}
+	if err := compare(txn, key, query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := h.write(w, r, "Delete", key, txn); ok {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// write applies a write, and returns its index and true if it succeeded.
+// Otherwise, the error is written.
+func (h *Handler) write(
+	w http.ResponseWriter,
+	r *http.Request,
+	op, key string,
+	txn *dkvv1.TxnCommand,
+) (uint64, bool) {
+	index, result, err := h.Store.Txn(txn)
+	if h.Audit != nil {
+		h.Audit.Record(audit.NewEntry(
+			api.CallerIdentity(r.Context(), connect.Peer{Addr: r.RemoteAddr}), op, key, index, err,
+		))
+	}
+	if err != nil {
+		writeError(w, err)
+		return 0, false
+	}
+	w.Header().Set(IndexHeader, strconv.FormatUint(index, 10))
+	if !result.GetSucceeded() {
+		http.Error(w, "the revision of the key does not match", http.StatusPreconditionFailed)
+		return 0, false
+	}
+	return index, true
+}
+
+// compare makes a write conditional on the revision of ?cas, if set.
+func compare(txn *dkvv1.TxnCommand, key string, query url.Values) error {
+	if !query.Has("cas") {
+		return nil
+	}
+	s := query.Get("cas")
+	rev, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || rev < 0 {
+		return errors.New("invalid cas: " + s)
+	}
+	// A missing key has a zero revision.
+	txn.Compare = []*dkvv1.Comparison{{
+		Key:    []byte(key),
+		Target: dkvv1.Comparison_TARGET_MOD_REVISION,
+		Result: dkvv1.Comparison_RESULT_EQUAL,
+		Number: rev,
+	}}
+	return nil
+}
+
+// prefixEnd returns the range end of the keys with the prefix, like etcd.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// All the keys.
+	return []byte{0}
+}
+
+func etag(rev int64) string {
+	return `"` + strconv.FormatInt(rev, 10) + `"`
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error of the store with its HTTP status. The errors
+// caused by a missing or changing leader are unavailable, so that the clients
+// retry them.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, store.ErrReservedKey):
+		code = http.StatusBadRequest
+	case errors.Is(err, store.ErrNoLeader),
+		errors.Is(err, distributed.ErrNotLeader),
+		errors.Is(err, raft.ErrNotLeader),
+		errors.Is(err, raft.ErrLeadershipLost),
+		errors.Is(err, raft.ErrLeadershipTransferInProgress),
+		errors.Is(err, store.ErrStaleRead):
+		code = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		code = http.StatusGatewayTimeout
+	}
+	http.Error(w, err.Error(), code)
+}
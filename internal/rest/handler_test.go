@@ -0,0 +1,200 @@
+package rest_test
+
+import (
+	"distributed-kv/internal/rest"
+	"distributed-kv/internal/testcluster"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 10 * time.Second
+
+// newURL starts a cluster, and returns the URL of the keys on a follower.
+func newURL(t *testing.T) string {
+	t.Helper()
+
+	c := testcluster.New(t, 3)
+	i, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	return "http://" + c.Server((i+1)%c.Size()).ClientAddress() + rest.Path
+}
+
+// do sends a request, and returns the response with its body.
+func do(t *testing.T, method, url, body string, header http.Header) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	url := newURL(t)
+
+	t.Run("Put and Get", func(t *testing.T) {
+		// Act
+		put, _ := do(t, http.MethodPut, url+"config/app", `{"debug":true}`, http.Header{
+			"Content-Type": {"application/json"},
+		})
+		get, body := do(t, http.MethodGet, url+"config/app", "", nil)
+
+		// Assert
+		require.Equal(t, http.StatusNoContent, put.StatusCode)
+		require.Equal(t, http.StatusOK, get.StatusCode)
+		require.Equal(t, `{"debug":true}`, body)
+		require.Equal(t, "application/json", get.Header.Get("Content-Type"))
+		require.Equal(t, put.Header.Get("ETag"), get.Header.Get("ETag"))
+		require.NotEmpty(t, get.Header.Get(rest.IndexHeader))
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			url      string
+			expected int
+		}{
+			{title: "Missing key", url: url + "missing", expected: http.StatusNotFound},
+			{title: "Empty key", url: url, expected: http.StatusBadRequest},
+			{title: "Stale", url: url + "missing?stale", expected: http.StatusNotFound},
+			{title: "Invalid wait", url: url + "missing?wait=forever", expected: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				// Act
+				resp, _ := do(t, http.MethodGet, tt.url, "", nil)
+
+				// Assert
+				require.Equal(t, tt.expected, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("Compare and swap", func(t *testing.T) {
+		// Act
+		created, _ := do(t, http.MethodPut, url+"cas?cas=0", "1", nil)
+		exists, _ := do(t, http.MethodPut, url+"cas?cas=0", "2", nil)
+		rev := strings.Trim(created.Header.Get("ETag"), `"`)
+		swapped, _ := do(t, http.MethodPut, url+"cas?cas="+rev, "3", nil)
+		stale, _ := do(t, http.MethodDelete, url+"cas?cas="+rev, "", nil)
+		_, body := do(t, http.MethodGet, url+"cas", "", nil)
+
+		// Assert
+		require.Equal(t, http.StatusNoContent, created.StatusCode)
+		require.Equal(t, http.StatusPreconditionFailed, exists.StatusCode)
+		require.Equal(t, http.StatusNoContent, swapped.StatusCode)
+		require.Equal(t, http.StatusPreconditionFailed, stale.StatusCode)
+		require.Equal(t, "3", body)
+	})
+
+	t.Run("Reserved key", func(t *testing.T) {
+		// Act
+		resp, _ := do(t, http.MethodPut, url+"%00applied", "1", nil)
+
+		// Assert
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		// Arrange
+		for _, key := range []string{"list/a", "list/b", "other"} {
+			resp, _ := do(t, http.MethodPut, url+key, "value", http.Header{
+				"Content-Type": {"text/plain"},
+			})
+			require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		// Act
+		keysResp, keysBody := do(t, http.MethodGet, url+"list/?prefix", "", nil)
+		entriesResp, entriesBody := do(t, http.MethodGet, url+"list/?recurse", "", nil)
+
+		// Assert
+		require.Equal(t, http.StatusOK, keysResp.StatusCode)
+		var keys []string
+		require.NoError(t, json.Unmarshal([]byte(keysBody), &keys))
+		require.Equal(t, []string{"list/a", "list/b"}, keys)
+		require.Equal(t, http.StatusOK, entriesResp.StatusCode)
+		var entries []rest.Entry
+		require.NoError(t, json.Unmarshal([]byte(entriesBody), &entries))
+		require.Len(t, entries, 2)
+		require.Equal(t, "list/a", entries[0].Key)
+		require.Equal(t, "value", string(entries[0].Value))
+		require.Equal(t, "text/plain", entries[0].ContentType)
+		require.Equal(t, int64(1), entries[0].Version)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Arrange
+		for _, key := range []string{"delete/a", "delete/b", "deleted"} {
+			resp, _ := do(t, http.MethodPut, url+key, "value", nil)
+			require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		// Act
+		deleted, _ := do(t, http.MethodDelete, url+"deleted", "", nil)
+		recursive, _ := do(t, http.MethodDelete, url+"delete/?recurse", "", nil)
+		_, body := do(t, http.MethodGet, url+"delete?prefix", "", nil)
+
+		// Assert
+		require.Equal(t, http.StatusNoContent, deleted.StatusCode)
+		require.Equal(t, http.StatusNoContent, recursive.StatusCode)
+		require.JSONEq(t, `[]`, body)
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		// Arrange
+		put, _ := do(t, http.MethodPut, url+"wait", "1", nil)
+		index := put.Header.Get(rest.IndexHeader)
+		done := make(chan *http.Response, 1)
+		go func() {
+			resp, err := http.Get(url + "wait?wait=5s&index=" + index)
+			if err == nil {
+				done <- resp
+			}
+		}()
+
+		// Act
+		time.Sleep(100 * time.Millisecond)
+		_, _ = do(t, http.MethodPut, url+"wait", "2", nil)
+		timedOut, timedOutBody := do(t, http.MethodGet, url+"wait?wait=10ms", "", nil)
+
+		// Assert
+		select {
+		case resp := <-done:
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "2", string(body))
+		case <-time.After(timeout):
+			require.Fail(t, "the wait did not return")
+		}
+		require.Equal(t, http.StatusOK, timedOut.StatusCode)
+		require.Equal(t, "2", timedOutBody)
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		// Act
+		resp, _ := do(t, http.MethodPost, url+"key", "value", nil)
+
+		// Assert
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		require.Equal(t, "GET, HEAD, PUT, DELETE", resp.Header.Get("Allow"))
+	})
+}
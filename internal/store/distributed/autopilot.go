@@ -0,0 +1,477 @@
+package distributed
+
+import (
+	"distributed-kv/internal/store"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// autopilotInterval is the interval between two reconciliations of the
+	// autopilot.
+	autopilotInterval = time.Second
+
+	defaultLastContactThreshold = 10 * time.Second
+	defaultMaxTrailingLogs      = 250
+)
+
+// ErrNotLeader is returned by the operations which require the leader.
+var ErrNotLeader = errors.New("not leader")
+
+// ErrMinQuorum is returned when removing a voter would leave the cluster with
+// less voters than the minimum quorum.
+var ErrMinQuorum = errors.New("the cluster would have less voters than the minimum quorum")
+
+var deadMembersRemoved = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "dkv",
+	Subsystem: "autopilot",
+	Name:      "dead_members_removed_total",
+	Help:      "Number of members removed by the leader after being unreachable.",
+})
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	// EventDeadMemberRemoved is emitted when the leader removes an unreachable member.
+	EventDeadMemberRemoved EventType = "DeadMemberRemoved"
+	// EventServerPromoted is emitted when the leader promotes a stable
+	// non-voter to voter.
+	EventServerPromoted EventType = "ServerPromoted"
+	// EventServerDemoted is emitted when the leader demotes an extra voter of
+	// a zone to non-voter.
+	EventServerDemoted EventType = "ServerDemoted"
+)
+
+// Event is a membership change decided by the leader.
+type Event struct {
+	Time     time.Time
+	Type     EventType
+	ServerID raft.ServerID
+	// Index is the Raft index of the configuration change.
+	Index   uint64
+	Message string
+}
+
+// AutopilotConfig configures the membership reconciliation of the leader.
+type AutopilotConfig struct {
+	// DeadMemberTimeout is the duration after which an unreachable member is
+	// removed. Zero disables the removal.
+	DeadMemberTimeout time.Duration
+	// MinQuorum is the minimum number of voters. Dead voters are not removed
+	// if the cluster would have less voters.
+	MinQuorum int
+	// ServerStabilizationTime is the duration a new server must be healthy
+	// before being promoted to voter. If set, new servers join as non-voters.
+	ServerStabilizationTime time.Duration
+	// LastContactThreshold is the maximum duration since the last contact of
+	// a healthy server.
+	LastContactThreshold time.Duration
+	// MaxTrailingLogs is the maximum number of log entries a healthy server
+	// can trail the leader by.
+	MaxTrailingLogs uint64
+	// OnEvent is called for each membership change, if set.
+	OnEvent func(Event)
+}
+
+// WithAutopilot enables the membership reconciliation of the leader.
+func WithAutopilot(config AutopilotConfig) StoreOption {
+	return func(o *StoreOptions) {
+		o.autopilot = &config
+	}
+}
+
+// ServerHealth is the health of a server, as seen by the leader.
+type ServerHealth struct {
+	ID       raft.ServerID
+	Address  raft.ServerAddress
+	Suffrage raft.ServerSuffrage
+	Leader   bool
+	// LastContact is the duration since the last response of the server.
+	LastContact time.Duration
+	// LastIndex is the last log index of the server.
+	LastIndex uint64
+	// LastTerm is the term of the server.
+	LastTerm uint64
+	// Healthy is true if the server responds and follows the leader closely.
+	Healthy bool
+	// StableSince is the time since which the server is healthy.
+	StableSince time.Time
+}
+
+// ClusterHealth is the health of the cluster, as seen by the leader.
+type ClusterHealth struct {
+	Healthy bool
+	// FailureTolerance is the number of voters which can fail without
+	// losing the quorum.
+	FailureTolerance int
+	Servers          []ServerHealth
+}
+
+// ClusterHealth returns the health of the cluster. It is computed by the
+// leader at each reconciliation of the autopilot.
+func (s *Store) ClusterHealth() (*ClusterHealth, error) {
+	if s.autopilot == nil {
+		return nil, ErrNotLeader
+	}
+	return s.autopilot.clusterHealth()
+}
+
+// autopilot reconciles the membership while the local node is the leader. It
+// also deletes the expired keys.
+type autopilot struct {
+	store  *Store
+	config AutopilotConfig
+	stop   chan struct{}
+	done   chan struct{}
+
+	// leaderSince is the time at which the local node became the leader.
+	leaderSince time.Time
+
+	mu sync.RWMutex
+	// health is nil when the local node is not the leader.
+	health *ClusterHealth
+}
+
+func newAutopilot(s *Store, config AutopilotConfig) *autopilot {
+	if config.LastContactThreshold <= 0 {
+		config.LastContactThreshold = defaultLastContactThreshold
+	}
+	if config.MaxTrailingLogs == 0 {
+		config.MaxTrailingLogs = defaultMaxTrailingLogs
+	}
+	return &autopilot{
+		store:  s,
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (a *autopilot) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(autopilotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case now := <-ticker.C:
+			a.reconcile(now)
+		}
+	}
+}
+
+func (a *autopilot) reconcile(now time.Time) {
+	ra := a.store.raft
+	if ra == nil {
+		return
+	}
+	a.store.announceZone()
+	if ra.State() != raft.Leader {
+		a.leaderSince = time.Time{}
+		a.setHealth(nil)
+		return
+	}
+	if a.leaderSince.IsZero() {
+		a.leaderSince = now
+	}
+	a.store.deleteExpired(now)
+	a.store.requeueExpired(now)
+	servers, err := a.store.GetServers()
+	if err != nil {
+		slog.Error("autopilot failed to get servers", "error", err)
+		return
+	}
+	health := a.computeHealth(now, ra, servers)
+	a.setHealth(health)
+	if a.removeDeadMembers(now, servers) {
+		return
+	}
+	a.reconcileVoters(now, health)
+}
+
+func (a *autopilot) setHealth(health *ClusterHealth) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.health = health
+}
+
+// clusterHealth returns the last computed health of the cluster.
+func (a *autopilot) clusterHealth() (*ClusterHealth, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.health == nil {
+		return nil, ErrNotLeader
+	}
+	return a.health, nil
+}
+
+func (a *autopilot) computeHealth(
+	now time.Time,
+	ra *raft.Raft,
+	servers []raft.Server,
+) *ClusterHealth {
+	a.mu.RLock()
+	previous := make(map[raft.ServerID]time.Time)
+	if a.health != nil {
+		for _, srv := range a.health.Servers {
+			previous[srv.ID] = srv.StableSince
+		}
+	}
+	a.mu.RUnlock()
+
+	leaderIndex := ra.LastIndex()
+	// hashicorp/raft only exposes the current term in its stats.
+	term, _ := strconv.ParseUint(ra.Stats()["term"], 10, 64)
+	health := &ClusterHealth{Servers: make([]ServerHealth, 0, len(servers))}
+	healthyVoters, voters := 0, 0
+	for _, srv := range servers {
+		h := ServerHealth{
+			ID:       srv.ID,
+			Address:  srv.Address,
+			Suffrage: srv.Suffrage,
+		}
+		if srv.ID == raft.ServerID(a.store.RaftID) {
+			h.Leader = true
+			h.LastIndex = leaderIndex
+			h.LastTerm = term
+			h.Healthy = true
+		} else {
+			stats, _ := a.store.stats.Stats(srv.ID)
+			h.LastContact = now.Sub(a.lastContact(srv.ID))
+			h.LastIndex = stats.LastIndex
+			h.LastTerm = stats.Term
+			h.Healthy = !stats.LastContact.Before(a.leaderSince) &&
+				h.LastContact <= a.config.LastContactThreshold &&
+				h.LastTerm == term &&
+				h.LastIndex+a.config.MaxTrailingLogs >= leaderIndex
+		}
+		if h.Healthy {
+			h.StableSince = previous[srv.ID]
+			if h.StableSince.IsZero() {
+				h.StableSince = now
+			}
+		}
+		if srv.Suffrage == raft.Voter {
+			voters++
+			if h.Healthy {
+				healthyVoters++
+			}
+		}
+		health.Servers = append(health.Servers, h)
+	}
+	health.FailureTolerance = max(healthyVoters-(voters/2+1), 0)
+	health.Healthy = healthyVoters == voters
+	return health
+}
+
+// reconcileVoters promotes or demotes at most one server.
+//
+// The cluster is zone-aware once a zone is replicated, whatever the zone of
+// the leader. In a zone-aware cluster, there is one voter per zone, and the
+// other servers of the zone are standbys, promoted once stable if the zone has
+// no healthy voter. A server without zone is alone in its zone. Otherwise, the
+// non-voters are promoted once stable.
+func (a *autopilot) reconcileVoters(now time.Time, health *ClusterHealth) {
+	stable := func(srv ServerHealth) bool {
+		return srv.Suffrage == raft.Nonvoter && srv.Healthy &&
+			now.Sub(srv.StableSince) >= a.config.ServerStabilizationTime
+	}
+	zones := a.store.fsm.Zones()
+	if len(zones) == 0 {
+		if a.config.ServerStabilizationTime <= 0 {
+			return
+		}
+		for _, srv := range health.Servers {
+			if stable(srv) {
+				a.changeSuffrage(now, srv, EventServerPromoted, "stable")
+				return
+			}
+		}
+		return
+	}
+
+	// zoneOf returns the key of the zone of a server. The servers without zone
+	// are keyed by their reserved-prefixed ID.
+	zoneOf := func(id raft.ServerID) string {
+		if zone := zones[id]; zone != "" {
+			return zone
+		}
+		return store.ReservedPrefix + string(id)
+	}
+	zoneName := func(zone string) string {
+		if id, ok := strings.CutPrefix(zone, store.ReservedPrefix); ok {
+			return "of server " + id
+		}
+		return zone
+	}
+	voters := make(map[string][]ServerHealth)
+	for _, srv := range health.Servers {
+		if srv.Suffrage == raft.Voter {
+			voters[zoneOf(srv.ID)] = append(voters[zoneOf(srv.ID)], srv)
+		}
+	}
+	// Promote a standby of a zone without healthy voter.
+	for _, srv := range health.Servers {
+		if !stable(srv) {
+			continue
+		}
+		zone := zoneOf(srv.ID)
+		if !slices.ContainsFunc(voters[zone], func(v ServerHealth) bool { return v.Healthy }) {
+			a.changeSuffrage(now, srv, EventServerPromoted, "no healthy voter in zone "+zoneName(zone))
+			return
+		}
+	}
+	// Demote the extra voters of a zone, unhealthy first. The leader is never
+	// demoted.
+	for zone, zoneVoters := range voters {
+		if len(zoneVoters) <= 1 {
+			continue
+		}
+		rank := func(srv ServerHealth) int {
+			switch {
+			case srv.Leader:
+				return 2
+			case srv.Healthy:
+				return 1
+			default:
+				return 0
+			}
+		}
+		slices.SortStableFunc(zoneVoters, func(x, y ServerHealth) int {
+			return rank(x) - rank(y)
+		})
+		a.changeSuffrage(now, zoneVoters[0], EventServerDemoted, "extra voter in zone "+zoneName(zone))
+		return
+	}
+}
+
+// changeSuffrage promotes or demotes a server.
+func (a *autopilot) changeSuffrage(now time.Time, srv ServerHealth, typ EventType, reason string) {
+	var future raft.IndexFuture
+	if typ == EventServerPromoted {
+		slog.Info("promoting server", "id", srv.ID, "reason", reason)
+		future = a.store.raft.AddVoter(srv.ID, srv.Address, 0, 0)
+	} else {
+		slog.Warn("demoting server", "id", srv.ID, "reason", reason)
+		future = a.store.raft.DemoteVoter(srv.ID, 0, 0)
+	}
+	if err := future.Error(); err != nil {
+		slog.Error("failed to change the suffrage of server", "id", srv.ID, "error", err)
+		return
+	}
+	a.emit(Event{
+		Time:     now,
+		Type:     typ,
+		ServerID: srv.ID,
+		Index:    future.Index(),
+		Message:  reason,
+	})
+}
+
+// lastContact returns the last contact of a follower.
+//
+// The followers which did not respond since the local node became the leader
+// are considered contacted at that time.
+func (a *autopilot) lastContact(id raft.ServerID) time.Time {
+	stats, ok := a.store.stats.Stats(id)
+	if !ok || stats.LastContact.Before(a.leaderSince) {
+		return a.leaderSince
+	}
+	return stats.LastContact
+}
+
+// removeDeadMembers removes at most one unreachable member, and returns true
+// if a member was removed.
+func (a *autopilot) removeDeadMembers(now time.Time, servers []raft.Server) bool {
+	if a.config.DeadMemberTimeout <= 0 {
+		return false
+	}
+	for _, srv := range servers {
+		if srv.ID == raft.ServerID(a.store.RaftID) {
+			continue
+		}
+		unreachable := now.Sub(a.lastContact(srv.ID))
+		if unreachable <= a.config.DeadMemberTimeout {
+			continue
+		}
+		if !a.keepsMinQuorum(servers, srv) {
+			slog.Warn(
+				"dead member not removed to keep the minimum quorum",
+				"id", srv.ID,
+				"unreachable", unreachable,
+				"min-quorum", a.config.MinQuorum,
+			)
+			continue
+		}
+
+		slog.Warn("removing dead member", "id", srv.ID, "addr", srv.Address, "unreachable", unreachable)
+		index, err := a.store.Leave(srv.ID)
+		if err != nil {
+			slog.Error("failed to remove dead member", "id", srv.ID, "error", err)
+			return false
+		}
+		deadMembersRemoved.Inc()
+		a.emit(Event{
+			Time:     now,
+			Type:     EventDeadMemberRemoved,
+			ServerID: srv.ID,
+			Index:    index,
+			Message:  "unreachable for " + unreachable.Round(time.Second).String(),
+		})
+		return true
+	}
+	return false
+}
+
+// keepsMinQuorum returns true if the cluster keeps at least the minimum quorum
+// of voters once the server is removed.
+func (a *autopilot) keepsMinQuorum(servers []raft.Server, srv raft.Server) bool {
+	if srv.Suffrage != raft.Voter {
+		return true
+	}
+	voters := 0
+	for _, server := range servers {
+		if server.Suffrage == raft.Voter {
+			voters++
+		}
+	}
+	return voters-1 >= a.config.MinQuorum
+}
+
+// LeaveKeepingQuorum removes a server like Leave, unless the cluster would have
+// less voters than the minimum quorum of the autopilot. ErrMinQuorum is
+// returned in this case.
+func (s *Store) LeaveKeepingQuorum(id raft.ServerID) (uint64, error) {
+	servers, err := s.GetServers()
+	if err != nil {
+		return 0, err
+	}
+	i := slices.IndexFunc(servers, func(srv raft.Server) bool { return srv.ID == id })
+	if i >= 0 && !s.autopilot.keepsMinQuorum(servers, servers[i]) {
+		return 0, ErrMinQuorum
+	}
+	return s.Leave(id)
+}
+
+// shutdown stops the autopilot and waits for the running reconciliation.
+func (a *autopilot) shutdown() {
+	close(a.stop)
+	<-a.done
+}
+
+func (a *autopilot) emit(e Event) {
+	if a.config.OnEvent != nil {
+		a.config.OnEvent(e)
+	}
+}
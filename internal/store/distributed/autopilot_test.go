@@ -0,0 +1,341 @@
+package distributed_test
+
+import (
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/store/persisted"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+// newCluster starts a plaintext cluster where node0 is the leader.
+//
+// Each node can be shut down once by the test.
+func newCluster(t *testing.T, nodes int, opts ...distributed.StoreOption) []*distributed.Store {
+	t.Helper()
+
+	stores := make([]*distributed.Store, nodes)
+	for i := 0; i < nodes; i++ {
+		stores[i] = newNode(t, i, opts...)
+		if i > 0 {
+			_, err := stores[0].Join(raft.ServerID(stores[i].RaftID), stores[i].RaftAdvertisedAddr)
+			require.NoError(t, err)
+		}
+	}
+	return stores
+}
+
+// newNode opens the plaintext node i. node0 bootstraps the cluster.
+func newNode(t *testing.T, i int, opts ...distributed.StoreOption) *distributed.Store {
+	t.Helper()
+
+	dir := t.TempDir()
+	addr := getRandomAddress(t)
+	storer := persisted.New(dir)
+	t.Cleanup(func() {
+		require.NoError(t, storer.Close())
+	})
+	s := distributed.NewStore(
+		dir,
+		addr,
+		fmt.Sprintf("node%d", i),
+		raft.ServerAddress(addr),
+		storer,
+		opts...,
+	)
+	t.Cleanup(func() {
+		require.NoError(t, s.Shutdown())
+	})
+	require.NoError(t, s.Open(i == 0))
+	if i == 0 {
+		_, err := s.WaitForLeader(5 * time.Second)
+		require.NoError(t, err)
+	}
+	return s
+}
+
+func TestStoreLeaveKeepingQuorum(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 3, distributed.WithAutopilot(distributed.AutopilotConfig{MinQuorum: 2}))
+
+	// Act
+	_, removeErr := stores[0].LeaveKeepingQuorum("node2")
+	_, quorumErr := stores[0].LeaveKeepingQuorum("node1")
+
+	// Assert
+	require.NoError(t, removeErr)
+	require.ErrorIs(t, quorumErr, distributed.ErrMinQuorum)
+	servers, err := stores[0].GetServers()
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+}
+
+func TestAutopilotDeadMembers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title     string
+		minQuorum int
+		removed   bool
+	}{
+		{
+			title:     "Remove the dead member",
+			minQuorum: 2,
+			removed:   true,
+		},
+		{
+			title:     "Keep the minimum quorum",
+			minQuorum: 3,
+			removed:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			events := make(chan distributed.Event, 10)
+			stores := newCluster(t, 3, distributed.WithAutopilot(distributed.AutopilotConfig{
+				DeadMemberTimeout: 2 * time.Second,
+				MinQuorum:         tt.minQuorum,
+				OnEvent: func(e distributed.Event) {
+					events <- e
+				},
+			}))
+
+			// Act
+			require.NoError(t, stores[2].Shutdown())
+
+			// Assert
+			if !tt.removed {
+				time.Sleep(4 * time.Second)
+				servers, err := stores[0].GetServers()
+				require.NoError(t, err)
+				require.Len(t, servers, 3)
+				require.Empty(t, events)
+				return
+			}
+			select {
+			case e := <-events:
+				require.Equal(t, distributed.EventDeadMemberRemoved, e.Type)
+				require.Equal(t, raft.ServerID("node2"), e.ServerID)
+				require.NotZero(t, e.Index)
+			case <-time.After(10 * time.Second):
+				require.Fail(t, "dead member not removed")
+			}
+			servers, err := stores[0].GetServers()
+			require.NoError(t, err)
+			require.Len(t, servers, 2)
+		})
+	}
+}
+
+func TestAutopilotServerStabilization(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	events := make(chan distributed.Event, 10)
+	stores := newCluster(t, 2, distributed.WithAutopilot(distributed.AutopilotConfig{
+		ServerStabilizationTime: 2 * time.Second,
+		OnEvent: func(e distributed.Event) {
+			events <- e
+		},
+	}))
+
+	// Act
+	servers, err := stores[0].GetServers()
+	require.NoError(t, err)
+	var e distributed.Event
+	select {
+	case e = <-events:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "server not promoted")
+	}
+
+	// Assert
+	require.Equal(t, raft.Nonvoter, servers[1].Suffrage)
+	require.Equal(t, distributed.EventServerPromoted, e.Type)
+	require.Equal(t, raft.ServerID("node1"), e.ServerID)
+	servers, err = stores[0].GetServers()
+	require.NoError(t, err)
+	require.Equal(t, raft.Voter, servers[1].Suffrage)
+	health, err := stores[0].ClusterHealth()
+	require.NoError(t, err)
+	require.Len(t, health.Servers, 2)
+	for _, srv := range health.Servers {
+		require.True(t, srv.Healthy, srv.ID)
+	}
+	_, err = stores[1].ClusterHealth()
+	require.ErrorIs(t, err, distributed.ErrNotLeader)
+}
+
+func TestAutopilotZones(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	events := make(chan distributed.Event, 10)
+	autopilot := distributed.WithAutopilot(distributed.AutopilotConfig{
+		LastContactThreshold: 2 * time.Second,
+		OnEvent: func(e distributed.Event) {
+			events <- e
+		},
+	})
+	nextEvent := func() distributed.Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(15 * time.Second):
+			require.Fail(t, "no autopilot event")
+			return distributed.Event{}
+		}
+	}
+	// The quorum of two voters would be lost with the voter of a zone.
+	leader := newNode(t, 0, autopilot, distributed.WithZone("a"))
+	stores := []*distributed.Store{
+		newNode(t, 1, autopilot, distributed.WithZone("b")),
+		newNode(t, 2, autopilot, distributed.WithZone("c")),
+		newNode(t, 3, autopilot, distributed.WithZone("c")),
+	}
+	promoted := make([]raft.ServerID, 0, 2)
+	for _, s := range stores {
+		_, err := leader.Join(raft.ServerID(s.RaftID), s.RaftAdvertisedAddr)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return leader.Zones()[raft.ServerID(s.RaftID)] != ""
+		}, 5*time.Second, 100*time.Millisecond)
+		if s.RaftID != "node3" {
+			promoted = append(promoted, nextEvent().ServerID)
+		}
+	}
+	time.Sleep(2 * time.Second)
+
+	// Act
+	require.NoError(t, stores[1].Shutdown())
+
+	// Assert
+	require.Equal(t, []raft.ServerID{"node1", "node2"}, promoted)
+	e := nextEvent()
+	require.Equal(t, distributed.EventServerPromoted, e.Type)
+	require.Equal(t, raft.ServerID("node3"), e.ServerID)
+	e = nextEvent()
+	require.Equal(t, distributed.EventServerDemoted, e.Type)
+	require.Equal(t, raft.ServerID("node2"), e.ServerID)
+	servers, err := leader.GetServers()
+	require.NoError(t, err)
+	suffrages := make(map[raft.ServerID]raft.ServerSuffrage)
+	for _, srv := range servers {
+		suffrages[srv.ID] = srv.Suffrage
+	}
+	require.Equal(t, map[raft.ServerID]raft.ServerSuffrage{
+		"node0": raft.Voter,
+		"node1": raft.Voter,
+		"node2": raft.Nonvoter,
+		"node3": raft.Voter,
+	}, suffrages)
+	require.Equal(t, map[raft.ServerID]string{
+		"node0": "a",
+		"node1": "b",
+		"node2": "c",
+		"node3": "c",
+	}, leader.Zones())
+}
+
+func TestAutopilotZoneLessLeader(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	autopilot := distributed.WithAutopilot(distributed.AutopilotConfig{
+		LastContactThreshold: 2 * time.Second,
+	})
+	// node1 joins as a voter, before any zone is replicated.
+	leader := newNode(t, 0, autopilot)
+	stores := []*distributed.Store{
+		newNode(t, 1, autopilot, distributed.WithZone("b")),
+		newNode(t, 2, autopilot, distributed.WithZone("c")),
+		newNode(t, 3, autopilot, distributed.WithZone("c")),
+	}
+	for _, s := range stores {
+		_, err := leader.Join(raft.ServerID(s.RaftID), s.RaftAdvertisedAddr)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return leader.Zones()[raft.ServerID(s.RaftID)] != ""
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+	suffrages := func() map[raft.ServerID]raft.ServerSuffrage {
+		servers, err := leader.GetServers()
+		require.NoError(t, err)
+		suffrages := make(map[raft.ServerID]raft.ServerSuffrage)
+		for _, srv := range servers {
+			suffrages[srv.ID] = srv.Suffrage
+		}
+		return suffrages
+	}
+	// One of the servers of the zone c is the voter, the other is a standby.
+	require.Eventually(t, func() bool {
+		s := suffrages()
+		return s["node2"] != s["node3"]
+	}, 15*time.Second, 100*time.Millisecond)
+	time.Sleep(2 * time.Second)
+	before := suffrages()
+	voter, standby := stores[1], stores[2]
+	if before["node3"] == raft.Voter {
+		voter, standby = standby, voter
+	}
+
+	// Act
+	require.NoError(t, voter.Shutdown())
+
+	// Assert
+	require.Equal(t, raft.Voter, before["node0"])
+	require.Equal(t, raft.Voter, before["node1"])
+	require.NotEqual(t, before["node2"], before["node3"])
+	require.Eventually(t, func() bool {
+		return suffrages()[raft.ServerID(standby.RaftID)] == raft.Voter
+	}, 15*time.Second, 100*time.Millisecond)
+}
+
+func TestAutopilotZoneLessServer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	events := make(chan distributed.Event, 10)
+	autopilot := distributed.WithAutopilot(distributed.AutopilotConfig{
+		OnEvent: func(e distributed.Event) {
+			events <- e
+		},
+	})
+	leader := newNode(t, 0, autopilot, distributed.WithZone("a"))
+	zoned := newNode(t, 1, autopilot, distributed.WithZone("b"))
+	zoneLess := newNode(t, 2, autopilot)
+	_, err := leader.Join(raft.ServerID(zoned.RaftID), zoned.RaftAdvertisedAddr)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return leader.Zones()["node1"] == "b"
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// Act
+	_, err = leader.Join(raft.ServerID(zoneLess.RaftID), zoneLess.RaftAdvertisedAddr)
+	require.NoError(t, err)
+	promoted := make([]raft.ServerID, 0, 2)
+	for range 2 {
+		select {
+		case e := <-events:
+			require.Equal(t, distributed.EventServerPromoted, e.Type)
+			promoted = append(promoted, e.ServerID)
+		case <-time.After(10 * time.Second):
+			require.Fail(t, "server not promoted")
+		}
+	}
+	_, err = leader.Leave(raft.ServerID(zoned.RaftID))
+	require.NoError(t, err)
+
+	// Assert
+	require.ElementsMatch(t, []raft.ServerID{"node1", "node2"}, promoted)
+	require.Equal(t, map[raft.ServerID]string{"node0": "a"}, leader.Zones())
+}
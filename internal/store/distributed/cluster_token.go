@@ -0,0 +1,140 @@
+package distributed
+
+import (
+	"distributed-kv/internal/raftpebble"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// maxClusterTokenLength is the maximum length of a cluster token.
+	maxClusterTokenLength = 255
+	// clusterTokenTimeout is the maximum duration of the cluster token exchange.
+	clusterTokenTimeout = 10 * time.Second
+)
+
+// keyClusterToken is the key of the cluster token in the stable store.
+var keyClusterToken = []byte("ClusterToken")
+
+// ErrClusterTokenMismatch is returned when a peer belongs to another cluster.
+var ErrClusterTokenMismatch = errors.New("cluster token mismatch")
+
+// loadClusterToken persists the configured cluster token, or returns the
+// persisted token if none is configured.
+func loadClusterToken(stable *raftpebble.PebbleKVStore, token string) (string, error) {
+	b, err := stable.Get(keyClusterToken)
+	if errors.Is(err, raftpebble.ErrKeyNotFound) {
+		if token == "" {
+			return "", nil
+		}
+		return token, stable.Set(keyClusterToken, []byte(token))
+	}
+	if err != nil {
+		return "", err
+	}
+	stored := string(b)
+	if token != "" && token != stored {
+		return "", fmt.Errorf(
+			"%w: the data directory belongs to cluster %q, but the configured token is %q",
+			ErrClusterTokenMismatch,
+			stored,
+			token,
+		)
+	}
+	return stored, nil
+}
+
+func writeClusterToken(conn net.Conn, token string) error {
+	_, err := conn.Write(append([]byte{byte(len(token))}, token...))
+	return err
+}
+
+func readClusterToken(conn net.Conn) (string, error) {
+	var length [1]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return "", err
+	}
+	token := make([]byte, length[0])
+	if _, err := io.ReadFull(conn, token); err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// exchangeClusterToken sends the cluster token of a dialed connection and
+// verifies the token of the peer.
+func exchangeClusterToken(conn net.Conn, token string) error {
+	if err := conn.SetDeadline(time.Now().Add(clusterTokenTimeout)); err != nil {
+		return err
+	}
+	if err := writeClusterToken(conn, token); err != nil {
+		return err
+	}
+	peerToken, err := readClusterToken(conn)
+	if err != nil {
+		return fmt.Errorf("failed to exchange cluster token with %s: %w", conn.RemoteAddr(), err)
+	}
+	if peerToken != token {
+		return fmt.Errorf(
+			"%w: peer %s belongs to cluster %q, expected %q",
+			ErrClusterTokenMismatch,
+			conn.RemoteAddr(),
+			peerToken,
+			token,
+		)
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// clusterTokenConn verifies the cluster token of an accepted connection
+// before its first read.
+//
+// The exchange is lazy so that a slow peer does not block the listener.
+type clusterTokenConn struct {
+	net.Conn
+	token string
+
+	once sync.Once
+	err  error
+}
+
+func (c *clusterTokenConn) Read(b []byte) (int, error) {
+	c.once.Do(func() {
+		c.err = c.exchange()
+		if c.err != nil {
+			slog.Error("rejected peer", "remote", c.RemoteAddr(), "error", c.err)
+		}
+	})
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *clusterTokenConn) exchange() error {
+	if err := c.SetDeadline(time.Now().Add(clusterTokenTimeout)); err != nil {
+		return err
+	}
+	peerToken, err := readClusterToken(c.Conn)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster token: %w", err)
+	}
+	// The local token is always sent, so that the peer can report the mismatch.
+	if err := writeClusterToken(c.Conn, c.token); err != nil {
+		return err
+	}
+	if peerToken != c.token {
+		return fmt.Errorf(
+			"%w: peer belongs to cluster %q, expected %q",
+			ErrClusterTokenMismatch,
+			peerToken,
+			c.token,
+		)
+	}
+	return c.SetDeadline(time.Time{})
+}
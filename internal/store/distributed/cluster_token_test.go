@@ -0,0 +1,218 @@
+package distributed_test
+
+import (
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/store/persisted"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamLayer(t *testing.T, token string) *distributed.TLSStreamLayer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	layer := &distributed.TLSStreamLayer{
+		Listener:          l,
+		AdvertizedAddress: raft.ServerAddress(l.Addr().String()),
+		ClusterToken:      token,
+	}
+	// Echo server
+	go func() {
+		for {
+			conn, err := layer.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return layer
+}
+
+func TestTLSStreamLayerClusterToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title       string
+		serverToken string
+		clientToken string
+		expectedErr error
+	}{
+		{
+			title:       "Same token",
+			serverToken: "cluster-a",
+			clientToken: "cluster-a",
+		},
+		{
+			title:       "Different token",
+			serverToken: "cluster-a",
+			clientToken: "cluster-b",
+			expectedErr: distributed.ErrClusterTokenMismatch,
+		},
+		{
+			title:       "Missing token",
+			serverToken: "cluster-a",
+			clientToken: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := newStreamLayer(t, tt.serverToken)
+			client := newStreamLayer(t, tt.clientToken)
+
+			// Act
+			conn, err := client.Dial(server.AdvertizedAddress, time.Second)
+			if tt.expectedErr != nil {
+				// Assert
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte("raft"))
+			require.NoError(t, err)
+			buf := make([]byte, 4)
+			require.NoError(t, conn.SetDeadline(time.Now().Add(time.Second)))
+			_, err = io.ReadFull(conn, buf)
+
+			// Assert
+			if tt.clientToken != tt.serverToken {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "raft", string(buf))
+		})
+	}
+}
+
+func TestStoreClusterToken(t *testing.T) {
+	t.Parallel()
+
+	newStore := func(t *testing.T, dir, id, token string) *distributed.Store {
+		addr := getRandomAddress(t)
+		// The FSM is not persisted across restarts.
+		storer := persisted.New(t.TempDir())
+		t.Cleanup(func() {
+			require.NoError(t, storer.Close())
+		})
+		return distributed.NewStore(
+			dir,
+			addr,
+			id,
+			raft.ServerAddress(addr),
+			storer,
+			distributed.WithClusterToken(token),
+		)
+	}
+
+	t.Run("Join a node of another cluster", func(t *testing.T) {
+		// Arrange
+		leader := newStore(t, t.TempDir(), "node0", "cluster-a")
+		t.Cleanup(func() {
+			require.NoError(t, leader.Shutdown())
+		})
+		require.NoError(t, leader.Open(true))
+		_, err := leader.WaitForLeader(5 * time.Second)
+		require.NoError(t, err)
+		other := newStore(t, t.TempDir(), "node1", "cluster-b")
+		t.Cleanup(func() {
+			require.NoError(t, other.Shutdown())
+		})
+		require.NoError(t, other.Open(false))
+
+		// Act
+		_, err = leader.Join("node1", other.RaftAdvertisedAddr)
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrClusterTokenMismatch)
+		servers, err := leader.GetServers()
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+		require.Equal(t, "cluster-a", leader.ClusterToken())
+	})
+
+	t.Run("Replace a member by a node of another cluster", func(t *testing.T) {
+		// Arrange
+		leader := newStore(t, t.TempDir(), "node0", "cluster-a")
+		t.Cleanup(func() {
+			require.NoError(t, leader.Shutdown())
+		})
+		require.NoError(t, leader.Open(true))
+		_, err := leader.WaitForLeader(5 * time.Second)
+		require.NoError(t, err)
+		member := newStore(t, t.TempDir(), "node1", "cluster-a")
+		t.Cleanup(func() {
+			require.NoError(t, member.Shutdown())
+		})
+		require.NoError(t, member.Open(false))
+		_, err = leader.Join("node1", member.RaftAdvertisedAddr)
+		require.NoError(t, err)
+		before, err := leader.GetServers()
+		require.NoError(t, err)
+		other := newStore(t, t.TempDir(), "node1", "cluster-b")
+		t.Cleanup(func() {
+			require.NoError(t, other.Shutdown())
+		})
+		require.NoError(t, other.Open(false))
+
+		// Act
+		_, err = leader.Join("node1", other.RaftAdvertisedAddr)
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrClusterTokenMismatch)
+		servers, err := leader.GetServers()
+		require.NoError(t, err)
+		require.Equal(t, before, servers, "the member is not removed")
+	})
+
+	t.Run("Reopen with another token", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		s := newStore(t, dir, "node0", "cluster-a")
+		require.NoError(t, s.Open(true))
+		require.NoError(t, s.Shutdown())
+
+		// Act
+		s = newStore(t, dir, "node0", "cluster-b")
+		err := s.Open(false)
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrClusterTokenMismatch)
+	})
+
+	t.Run("Reopen without token", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		s := newStore(t, dir, "node0", "cluster-a")
+		require.NoError(t, s.Open(true))
+		require.NoError(t, s.Shutdown())
+
+		// Act
+		s = newStore(t, dir, "node0", "")
+		err := s.Open(false)
+		t.Cleanup(func() {
+			require.NoError(t, s.Shutdown())
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "cluster-a", s.ClusterToken())
+	})
+}
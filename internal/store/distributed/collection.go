@@ -0,0 +1,274 @@
+package distributed
+
+import (
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"fmt"
+	"time"
+)
+
+// collectionTypes are the types of the collections, by protobuf type.
+var collectionTypes = map[dkvv1.CollectionType]store.Type{
+	dkvv1.CollectionType_COLLECTION_TYPE_HASH:       store.TypeHash,
+	dkvv1.CollectionType_COLLECTION_TYPE_SET:        store.TypeSet,
+	dkvv1.CollectionType_COLLECTION_TYPE_SORTED_SET: store.TypeSortedSet,
+}
+
+// typeOf returns the type of a key at the time now. A key is a string if it
+// has a value which has not expired, and otherwise the type of its
+// collection.
+func (f *FSM) typeOf(key string, now int64) (store.Type, error) {
+	exists, err := f.exists(key, now)
+	if err != nil {
+		return store.TypeNone, err
+	}
+	if exists {
+		return store.TypeString, nil
+	}
+	return f.storer.CollectionType(key)
+}
+
+// checkType returns store.ErrWrongType if a key holds another type than t at
+// the time now.
+func (f *FSM) checkType(key string, t store.Type, now int64) error {
+	actual, err := f.typeOf(key, now)
+	if err != nil {
+		return err
+	}
+	if actual != store.TypeNone && actual != t {
+		return store.ErrWrongType
+	}
+	return nil
+}
+
+// prepareCollection checks the type of a key like checkType before a write of
+// its collection, and deletes its expired value at the index, so that a key
+// never holds both a value and a collection.
+func (f *FSM) prepareCollection(key string, t store.Type, index, now int64) error {
+	if err := f.checkType(key, t, now); err != nil {
+		return err
+	}
+	if !f.expired(key, now) {
+		return nil
+	}
+	_, err := f.deleteKey(key, index, now, false)
+	return err
+}
+
+func (f *FSM) hashSet(cmd *dkvv1.HashSetCommand, index, now int64) interface{} {
+	if err := f.prepareCollection(cmd.GetKey(), store.TypeHash, index, now); err != nil {
+		return err
+	}
+	var count int64
+	for _, field := range cmd.GetFields() {
+		created, err := f.storer.HashSet(cmd.GetKey(), field.GetKey(), field.GetValue())
+		if err != nil {
+			return err
+		}
+		if created {
+			count++
+		}
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+func (f *FSM) setAdd(cmd *dkvv1.SetAddCommand, index, now int64) interface{} {
+	if err := f.prepareCollection(cmd.GetKey(), store.TypeSet, index, now); err != nil {
+		return err
+	}
+	var count int64
+	for _, member := range cmd.GetMembers() {
+		added, err := f.storer.SetAdd(cmd.GetKey(), member)
+		if err != nil {
+			return err
+		}
+		if added {
+			count++
+		}
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+func (f *FSM) sortedSetAdd(cmd *dkvv1.SortedSetAddCommand, index, now int64) interface{} {
+	if err := f.prepareCollection(cmd.GetKey(), store.TypeSortedSet, index, now); err != nil {
+		return err
+	}
+	var count int64
+	for _, member := range cmd.GetMembers() {
+		added, err := f.storer.SortedSetAdd(cmd.GetKey(), member.GetMember(), member.GetScore())
+		if err != nil {
+			return err
+		}
+		if added {
+			count++
+		}
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+func (f *FSM) removeMembers(cmd *dkvv1.RemoveMembersCommand, now int64) interface{} {
+	t, ok := collectionTypes[cmd.GetType()]
+	if !ok {
+		return fmt.Errorf("unknown collection type: %s", cmd.GetType())
+	}
+	if err := f.checkType(cmd.GetKey(), t, now); err != nil {
+		return err
+	}
+	var count int64
+	for _, member := range cmd.GetMembers() {
+		deleted, err := f.storer.DeleteMember(t, cmd.GetKey(), member)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			count++
+		}
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+// HashSet sets the values of fields of a hash, and returns the Raft index of
+// the write and the number of created fields.
+func (s *Store) HashSet(key string, fields ...store.KeyValue) (uint64, int64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, 0, err
+	}
+	entries := make([]*dkvv1.KeyValue, 0, len(fields))
+	for _, field := range fields {
+		entries = append(entries, &dkvv1.KeyValue{Key: field.Key, Value: field.Value})
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_HashSet{
+			HashSet: &dkvv1.HashSetCommand{Key: key, Fields: entries},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// SetAdd adds members to a set, and returns the Raft index of the write and
+// the number of added members.
+func (s *Store) SetAdd(key string, members ...string) (uint64, int64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, 0, err
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_SetAdd{
+			SetAdd: &dkvv1.SetAddCommand{Key: key, Members: members},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// SortedSetAdd sets the scores of members of a sorted set, and returns the
+// Raft index of the write and the number of added members.
+func (s *Store) SortedSetAdd(key string, members ...store.ScoredMember) (uint64, int64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, 0, err
+	}
+	scored := make([]*dkvv1.ScoredMember, 0, len(members))
+	for _, member := range members {
+		scored = append(scored, &dkvv1.ScoredMember{Member: member.Member, Score: member.Score})
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_SortedSetAdd{
+			SortedSetAdd: &dkvv1.SortedSetAddCommand{Key: key, Members: scored},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// RemoveMembers deletes fields of a hash, or members of a set or of a sorted
+// set, and returns the Raft index of the write and the number of deleted
+// members.
+func (s *Store) RemoveMembers(t store.Type, key string, members ...string) (uint64, int64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, 0, err
+	}
+	var collectionType dkvv1.CollectionType
+	switch t {
+	case store.TypeHash:
+		collectionType = dkvv1.CollectionType_COLLECTION_TYPE_HASH
+	case store.TypeSet:
+		collectionType = dkvv1.CollectionType_COLLECTION_TYPE_SET
+	case store.TypeSortedSet:
+		collectionType = dkvv1.CollectionType_COLLECTION_TYPE_SORTED_SET
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_RemoveMembers{
+			RemoveMembers: &dkvv1.RemoveMembersCommand{
+				Type:    collectionType,
+				Key:     key,
+				Members: members,
+			},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// Type returns the type of a key from the local state.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) Type(key string, opts store.ReadOptions) (store.Type, error) {
+	if err := s.prepareRead(opts); err != nil {
+		return store.TypeNone, err
+	}
+	return s.fsm.typeOf(key, time.Now().UnixNano())
+}
+
+// HashGet gets the value of a field of a hash from the local state.
+// store.ErrNotFound is returned if the field does not exist.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) HashGet(key, field string, opts store.ReadOptions) (string, error) {
+	if err := s.readCollection(key, store.TypeHash, opts); err != nil {
+		return "", err
+	}
+	return s.fsm.storer.HashGet(key, field)
+}
+
+// HashGetAll returns the fields of a hash and their values from the local
+// state, in lexicographic order.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) HashGetAll(key string, opts store.ReadOptions) ([]store.KeyValue, error) {
+	if err := s.readCollection(key, store.TypeHash, opts); err != nil {
+		return nil, err
+	}
+	return s.fsm.storer.HashGetAll(key)
+}
+
+// SetMembers returns the members of a set from the local state, in
+// lexicographic order.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) SetMembers(key string, opts store.ReadOptions) ([]string, error) {
+	if err := s.readCollection(key, store.TypeSet, opts); err != nil {
+		return nil, err
+	}
+	return s.fsm.storer.SetMembers(key)
+}
+
+// SortedSetRangeByScore returns the members of a sorted set whose score is in
+// [minimum, maximum] from the local state, ordered by score and then by
+// member. At most limit members are returned if limit is positive.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) SortedSetRangeByScore(
+	key string,
+	minimum, maximum float64,
+	limit int,
+	opts store.ReadOptions,
+) ([]store.ScoredMember, error) {
+	if err := s.readCollection(key, store.TypeSortedSet, opts); err != nil {
+		return nil, err
+	}
+	return s.fsm.storer.SortedSetRangeByScore(key, minimum, maximum, limit)
+}
+
+// readCollection prepares the read of a collection of the type t.
+func (s *Store) readCollection(key string, t store.Type, opts store.ReadOptions) error {
+	if err := s.prepareRead(opts); err != nil {
+		return err
+	}
+	return s.fsm.checkType(key, t, time.Now().UnixNano())
+}
@@ -0,0 +1,18 @@
+package distributed
+
+import (
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// Leases returns the number of leases of a name in the local state.
+func (s *Store) Leases(name string) (int, error) {
+	_, leases, err := s.leases(name, 0)
+	return len(leases), err
+}
+
+// Draw draws the faults of a write to the peer.
+func (f *FaultInjector) Draw(peer raft.ServerAddress) (time.Duration, bool, bool) {
+	return f.draw(peer)
+}
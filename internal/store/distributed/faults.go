@@ -0,0 +1,211 @@
+package distributed
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// ErrPartitioned is returned when dialing a peer which is partitioned by the
+// FaultInjector.
+var ErrPartitioned = errors.New("partitioned from peer")
+
+// errInjectedReset is returned by the writes of a connection reset by the
+// FaultInjector.
+var errInjectedReset = errors.New("connection reset by fault injection")
+
+// Faults are the network faults injected on the connections to a peer.
+//
+// Raft RPCs and their responses flow on the connections of the sender, so the
+// faults on the connections to a peer affect the RPCs sent to this peer. A
+// partition in one direction only is asymmetric.
+type Faults struct {
+	// Partitioned refuses the connections to the peer, and closes the open
+	// ones.
+	Partitioned bool
+	// DropRate is the probability of dropping a write. The write and the next
+	// ones on the connection are discarded, so that the RPC times out.
+	DropRate float64
+	// ResetRate is the probability of resetting the connection on a write.
+	ResetRate float64
+	// Delay is the latency added to each write.
+	Delay time.Duration
+	// Jitter is the maximum random latency added to Delay.
+	Jitter time.Duration
+}
+
+// FaultInjector injects network faults in the Raft transport, in order to
+// test partitions and slow peers.
+//
+// The faults are kept when the wrapped stream layer is closed, so that they
+// apply to a restarted node. The random faults are drawn from a generator
+// which can be seeded, so that a sequence of writes gets the same faults.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults map[raft.ServerAddress]Faults
+	conns  map[raft.ServerAddress]map[*faultConn]struct{}
+	rand   *rand.Rand
+}
+
+// NewFaultInjector creates a FaultInjector without faults, with a random seed.
+func NewFaultInjector() *FaultInjector {
+	f := &FaultInjector{
+		faults: make(map[raft.ServerAddress]Faults),
+		conns:  make(map[raft.ServerAddress]map[*faultConn]struct{}),
+	}
+	f.Seed(rand.Uint64())
+	return f
+}
+
+// Seed resets the generator of the random faults with the seed.
+func (f *FaultInjector) Seed(seed uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rand = rand.New(rand.NewPCG(seed, seed))
+}
+
+// draw draws the faults of a write to the peer: the latency, and whether the
+// connection is reset or the write is dropped.
+func (f *FaultInjector) draw(peer raft.ServerAddress) (delay time.Duration, reset, drop bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	faults := f.faults[peer]
+	delay = faults.Delay
+	if faults.Jitter > 0 {
+		delay += time.Duration(f.rand.Int64N(int64(faults.Jitter)))
+	}
+	if faults.ResetRate > 0 && f.rand.Float64() < faults.ResetRate {
+		return delay, true, false
+	}
+	drop = faults.DropRate > 0 && f.rand.Float64() < faults.DropRate
+	return delay, false, drop
+}
+
+// Wrap returns a stream layer injecting the faults on the connections dialed
+// by layer.
+func (f *FaultInjector) Wrap(layer raft.StreamLayer) raft.StreamLayer {
+	return &faultStreamLayer{StreamLayer: layer, injector: f}
+}
+
+// SetFaults sets the faults on the connections to the peer. The open
+// connections are closed if the peer is partitioned.
+func (f *FaultInjector) SetFaults(peer raft.ServerAddress, faults Faults) {
+	f.mu.Lock()
+	if faults == (Faults{}) {
+		delete(f.faults, peer)
+	} else {
+		f.faults[peer] = faults
+	}
+	f.mu.Unlock()
+	if faults.Partitioned {
+		f.Reset(peer)
+	}
+}
+
+// Faults returns the faults on the connections to the peer.
+func (f *FaultInjector) Faults(peer raft.ServerAddress) Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faults[peer]
+}
+
+// Clear removes the faults on the connections to all the peers.
+func (f *FaultInjector) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.faults)
+}
+
+// Reset closes the open connections to the peer.
+func (f *FaultInjector) Reset(peer raft.ServerAddress) {
+	f.mu.Lock()
+	conns := f.conns[peer]
+	delete(f.conns, peer)
+	f.mu.Unlock()
+	for conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+// track records an open connection to the peer, or closes it if the peer is
+// partitioned.
+func (f *FaultInjector) track(peer raft.ServerAddress, conn net.Conn) (net.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.faults[peer].Partitioned {
+		_ = conn.Close()
+		return nil, ErrPartitioned
+	}
+	fc := &faultConn{Conn: conn, injector: f, peer: peer}
+	if f.conns[peer] == nil {
+		f.conns[peer] = make(map[*faultConn]struct{})
+	}
+	f.conns[peer][fc] = struct{}{}
+	return fc, nil
+}
+
+func (f *FaultInjector) untrack(fc *faultConn) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.conns[fc.peer], fc)
+}
+
+type faultStreamLayer struct {
+	raft.StreamLayer
+	injector *FaultInjector
+}
+
+func (l *faultStreamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	if l.injector.Faults(address).Partitioned {
+		return nil, ErrPartitioned
+	}
+	conn, err := l.StreamLayer.Dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return l.injector.track(address, conn)
+}
+
+type faultConn struct {
+	net.Conn
+	injector *FaultInjector
+	peer     raft.ServerAddress
+
+	// mu serializes the writes, so that the delays preserve their order.
+	mu          sync.Mutex
+	blackholed  bool
+	closeOnce   sync.Once
+	closeResult error
+}
+
+func (c *faultConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delay, reset, drop := c.injector.draw(c.peer)
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if reset {
+		_ = c.Close()
+		return 0, errInjectedReset
+	}
+	if drop {
+		c.blackholed = true
+	}
+	if c.blackholed {
+		return len(p), nil
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *faultConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.injector.untrack(c)
+		c.closeResult = c.Conn.Close()
+	})
+	return c.closeResult
+}
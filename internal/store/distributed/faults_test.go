@@ -0,0 +1,162 @@
+package distributed_test
+
+import (
+	"distributed-kv/internal/store/distributed"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+// newFaultyLayer returns a stream layer with fault injection, and the address
+// of a peer echoing the data of its connections.
+func newFaultyLayer(t *testing.T) (*distributed.FaultInjector, raft.StreamLayer, raft.ServerAddress) {
+	t.Helper()
+
+	peer, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = peer.Close() })
+	go func() {
+		for {
+			conn, err := peer.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	injector := distributed.NewFaultInjector()
+	layer := injector.Wrap(&distributed.TLSStreamLayer{
+		Listener:          l,
+		AdvertizedAddress: raft.ServerAddress(l.Addr().String()),
+	})
+	return injector, layer, raft.ServerAddress(peer.Addr().String())
+}
+
+// echo writes a message on the connection and reads it back.
+func echo(conn net.Conn, timeout time.Duration) error {
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(conn, make([]byte, 4))
+	return err
+}
+
+func TestFaultInjector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Partition", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		injector, layer, peer := newFaultyLayer(t)
+		conn, err := layer.Dial(peer, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, echo(conn, time.Second))
+
+		// Act
+		injector.SetFaults(peer, distributed.Faults{Partitioned: true})
+		_, dialErr := layer.Dial(peer, time.Second)
+		echoErr := echo(conn, time.Second)
+		injector.Clear()
+		healed, err := layer.Dial(peer, time.Second)
+
+		// Assert
+		require.ErrorIs(t, dialErr, distributed.ErrPartitioned)
+		require.ErrorIs(t, echoErr, net.ErrClosed)
+		require.NoError(t, err)
+		require.NoError(t, echo(healed, time.Second))
+	})
+
+	t.Run("Seed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		const peer = raft.ServerAddress("peer")
+		faults := distributed.Faults{DropRate: 0.3, ResetRate: 0.2, Jitter: time.Second}
+		draws := func(seed uint64) []string {
+			injector := distributed.NewFaultInjector()
+			injector.SetFaults(peer, faults)
+			injector.Seed(seed)
+			var draws []string
+			for range 100 {
+				delay, reset, drop := injector.Draw(peer)
+				draws = append(draws, fmt.Sprint(delay, reset, drop))
+			}
+			return draws
+		}
+
+		// Act
+		first, second, other := draws(42), draws(42), draws(43)
+
+		// Assert
+		require.Equal(t, first, second, "the same seed draws the same faults")
+		require.NotEqual(t, first, other)
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		injector, layer, peer := newFaultyLayer(t)
+		conn, err := layer.Dial(peer, time.Second)
+		require.NoError(t, err)
+		injector.SetFaults(peer, distributed.Faults{Delay: 100 * time.Millisecond})
+
+		// Act
+		start := time.Now()
+		err = echo(conn, time.Second)
+
+		// Assert
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		injector, layer, peer := newFaultyLayer(t)
+		conn, err := layer.Dial(peer, time.Second)
+		require.NoError(t, err)
+		injector.SetFaults(peer, distributed.Faults{DropRate: 1})
+
+		// Act
+		err = echo(conn, 100*time.Millisecond)
+
+		// Assert
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded, "the message is never echoed")
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		injector, layer, peer := newFaultyLayer(t)
+		conn, err := layer.Dial(peer, time.Second)
+		require.NoError(t, err)
+		injector.SetFaults(peer, distributed.Faults{ResetRate: 1})
+
+		// Act
+		err = echo(conn, time.Second)
+
+		// Assert
+		require.Error(t, err)
+		require.ErrorIs(t, echo(conn, time.Second), net.ErrClosed)
+	})
+}
@@ -2,9 +2,16 @@ package distributed
 
 import (
 	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/raft"
 	"google.golang.org/protobuf/proto"
@@ -16,20 +23,424 @@ type Storer interface {
 	Get(key string) (string, error)
 	Delete(key string) error
 	Set(key, value string) error
+	// Range returns the keys in [start, end) in lexicographic order. An empty
+	// end is no upper bound. At most limit keys are returned if limit is
+	// positive.
+	Range(start, end string, limit int) ([]string, error)
 	Dump() map[string]string
 	Clear()
+
+	// CollectionType returns the type of the collection of a key, or
+	// store.TypeNone if there is none.
+	CollectionType(key string) (store.Type, error)
+	// HashSet sets the value of a field of a hash, and returns true if the
+	// field was created.
+	HashSet(key, field, value string) (bool, error)
+	HashGet(key, field string) (string, error)
+	HashGetAll(key string) ([]store.KeyValue, error)
+	// SetAdd adds a member to a set, and returns true if it was added.
+	SetAdd(key, member string) (bool, error)
+	SetMembers(key string) ([]string, error)
+	// SortedSetAdd sets the score of a member of a sorted set, and returns true
+	// if the member was added.
+	SortedSetAdd(key, member string, score float64) (bool, error)
+	// SortedSetRangeByScore returns the members of a sorted set whose score is
+	// in [minimum, maximum], ordered by score and then by member. At most limit
+	// members are returned if limit is positive.
+	SortedSetRangeByScore(key string, minimum, maximum float64, limit int) ([]store.ScoredMember, error)
+	// DeleteMember deletes a member of a collection of the type, and returns
+	// true if it was stored.
+	DeleteMember(t store.Type, key, member string) (bool, error)
+	// DeleteCollection deletes the collection of a key, and returns true if
+	// there was one.
+	DeleteCollection(key string) (bool, error)
+	// CollectionKeys returns the keys of the collections in [start, end). An
+	// empty end is no upper bound.
+	CollectionKeys(start, end string) ([]string, error)
+}
+
+// serverMetadataPrefix is the reserved key prefix of the server metadata in
+// the snapshots.
+const serverMetadataPrefix = store.ReservedPrefix + "server/"
+
+// expirationPrefix is the reserved key prefix of the expiration times in the
+// snapshots.
+const expirationPrefix = store.ReservedPrefix + "expiration/"
+
+// revisionPrefix is the reserved key prefix of the revisions of the keys in the
+// snapshots.
+const revisionPrefix = store.ReservedPrefix + "revision/"
+
+// contentTypePrefix is the reserved key prefix of the media types of the values
+// in the snapshots.
+const contentTypePrefix = store.ReservedPrefix + "content-type/"
+
+// queuePrefix is the reserved key prefix of the messages of the queues in the
+// snapshots, followed by the ID of the message and the name of its queue.
+const queuePrefix = store.ReservedPrefix + "queue/"
+
+// appliedIndexKey is the reserved key of the index of the last log entry
+// applied by the FSM in the snapshots.
+const appliedIndexKey = store.ReservedPrefix + "applied"
+
+// revision is the revisions of a key, like etcd: the Raft index of its
+// creation, the Raft index of its last write, and its number of writes since
+// its creation.
+type revision struct {
+	create, mod, version int64
+}
+
+// unknownRevision is the revision of the keys of the snapshots without
+// revisions, which is not persisted.
+var unknownRevision = revision{create: 1, mod: 1, version: 1}
+
+// keyValue returns the key and the value with the revision.
+func (r revision) keyValue(key, value string) *dkvv1.RevisionedKeyValue {
+	return &dkvv1.RevisionedKeyValue{
+		Key:            []byte(key),
+		Value:          []byte(value),
+		CreateRevision: r.create,
+		ModRevision:    r.mod,
+		Version:        r.version,
+	}
 }
 
 type FSM struct {
 	storer Storer
+
+	mu sync.RWMutex
+	// zones are the zones of the servers, by ID.
+	zones map[raft.ServerID]string
+	// expirations are the expiration times of the keys, in Unix nanoseconds.
+	expirations map[string]int64
+	// revisions are the revisions of the stored keys.
+	revisions map[string]revision
+	// contentTypes are the media types of the values, if they were set.
+	contentTypes map[string]string
+	// queues are the queues which have messages, by name.
+	queues map[string]*queue
+	// history is the last events of the keys, in revision order.
+	history []WatchEvent
+	// historyStart is the first revision whose events are all in the history,
+	// or zero if no entry was applied since the state was created or restored.
+	historyStart int64
+	// prevKVWatches is the number of watches of the previous keys, which are
+	// only recorded in the events if they are watched.
+	prevKVWatches int
+	// appliedIndex is the index of the last log entry applied by the FSM.
+	appliedIndex uint64
+	// appliedCh is closed and replaced when a log entry is applied.
+	appliedCh chan struct{}
 }
 
 func NewFSM(storer Storer) *FSM {
-	return &FSM{storer: storer}
+	return &FSM{
+		storer:       storer,
+		zones:        make(map[raft.ServerID]string),
+		expirations:  make(map[string]int64),
+		revisions:    make(map[string]revision),
+		contentTypes: make(map[string]string),
+		queues:       make(map[string]*queue),
+		// The log is replayed from the first entry, unless a snapshot is
+		// restored.
+		historyStart: 1,
+		appliedCh:    make(chan struct{}),
+	}
+}
+
+// applied returns the index of the last log entry applied by the FSM, and a
+// channel closed when the next entry is applied. The configuration entries are
+// applied by StoreConfiguration.
+//
+// The index of a restored snapshot is unknown to the FSM, unless the snapshot
+// recorded its last applied entry.
+func (f *FSM) applied() (uint64, <-chan struct{}) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.appliedIndex, f.appliedCh
+}
+
+func (f *FSM) setApplied(index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if index > f.appliedIndex {
+		f.appliedIndex = index
+	}
+	close(f.appliedCh)
+	f.appliedCh = make(chan struct{})
+}
+
+var _ raft.ConfigurationStore = (*FSM)(nil)
+
+// StoreConfiguration applies a configuration entry, which only changes the
+// applied index of the FSM.
+func (f *FSM) StoreConfiguration(index uint64, _ raft.Configuration) {
+	f.startHistory(index)
+	f.setApplied(index)
+}
+
+// Zones returns the zones of the servers, by ID.
+func (f *FSM) Zones() map[raft.ServerID]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	zones := make(map[raft.ServerID]string, len(f.zones))
+	for id, zone := range f.zones {
+		zones[id] = zone
+	}
+	return zones
+}
+
+func (f *FSM) setZone(id raft.ServerID, zone string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if zone == "" {
+		delete(f.zones, id)
+		return
+	}
+	f.zones[id] = zone
+}
+
+// expired returns true if the key has expired at the time now, in Unix
+// nanoseconds.
+func (f *FSM) expired(key string, now int64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	expireAt, ok := f.expirations[key]
+	return ok && expireAt <= now
+}
+
+// setExpiration sets the expiration time of a key. Zero removes it.
+func (f *FSM) setExpiration(key string, expireAt int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if expireAt == 0 {
+		delete(f.expirations, key)
+		return
+	}
+	f.expirations[key] = expireAt
+}
+
+// expiredKeys returns at most limit keys expired at the time now.
+func (f *FSM) expiredKeys(now int64, limit int) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var keys []string
+	for key, expireAt := range f.expirations {
+		if len(keys) >= limit {
+			break
+		}
+		if expireAt <= now {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// get returns the value of a key which has not expired at the time now.
+func (f *FSM) get(key string, now int64) (string, error) {
+	value, err := f.storer.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if f.expired(key, now) {
+		return "", store.ErrNotFound
+	}
+	return value, nil
+}
+
+// exists returns true if the key exists and has not expired at the time now.
+func (f *FSM) exists(key string, now int64) (bool, error) {
+	_, err := f.get(key, now)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// keys returns the keys with the prefix which have not expired at the time
+// now, in lexicographic order, starting after the key after. At most limit
+// keys are returned if limit is positive.
+func (f *FSM) keys(prefix, after string, limit int, now int64) ([]string, error) {
+	start := prefix
+	if after != "" && after >= prefix {
+		// The smallest key greater than after.
+		start = after + "\x00"
+	}
+	return f.scan(start, prefixEnd(prefix), limit, now)
+}
+
+// scan returns the keys in [start, end) which have not expired at the time
+// now, in lexicographic order. An empty end is no upper bound. At most limit
+// keys are returned if limit is positive.
+//
+// A scan from the first key skips the reserved keys, which are only scanned
+// from a reserved start.
+func (f *FSM) scan(start, end string, limit int, now int64) ([]string, error) {
+	if start == "" {
+		start = prefixEnd(store.ReservedPrefix)
+		if end != "" && end <= start {
+			return nil, nil
+		}
+	}
+	var keys []string
+	for {
+		page, err := f.storer.Range(start, end, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range page {
+			if f.expired(key, now) {
+				continue
+			}
+			keys = append(keys, key)
+			if limit > 0 && len(keys) == limit {
+				return keys, nil
+			}
+		}
+		if limit <= 0 || len(page) < limit {
+			return keys, nil
+		}
+		start = page[len(page)-1] + "\x00"
+	}
+}
+
+// prefixEnd returns the smallest key greater than all the keys with the
+// prefix, or an empty key if there is none.
+func prefixEnd(prefix string) string {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			return prefix[:i] + string([]byte{prefix[i] + 1})
+		}
+	}
+	return ""
+}
+
+// keyValue returns a stored key with its revisions, and its value if
+// withValue is true.
+func (f *FSM) keyValue(key string, withValue bool) (*dkvv1.RevisionedKeyValue, error) {
+	var value string
+	if withValue {
+		var err error
+		if value, err = f.storer.Get(key); err != nil {
+			return nil, err
+		}
+	}
+	f.mu.RLock()
+	rev, ok := f.revisions[key]
+	contentType := f.contentTypes[key]
+	f.mu.RUnlock()
+	if !ok {
+		rev = unknownRevision
+	}
+	kv := rev.keyValue(key, value)
+	kv.ContentType = contentType
+	return kv, nil
+}
+
+// setContentType sets the media type of the value of a key. An empty media
+// type removes it.
+func (f *FSM) setContentType(key, contentType string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if contentType == "" {
+		delete(f.contentTypes, key)
+		return
+	}
+	f.contentTypes[key] = contentType
+}
+
+// previous returns a key before its write, or nil if it does not exist or has
+// expired at the time now.
+func (f *FSM) previous(key string, now int64) (*dkvv1.RevisionedKeyValue, error) {
+	if f.expired(key, now) {
+		return nil, nil
+	}
+	kv, err := f.keyValue(key, true)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	return kv, err
+}
+
+// setKey sets the value of a key written at the index, updates its revisions
+// and records the event. It returns the previous key if withPrev is true or if
+// it is watched. The expiration time of the key is not changed, and its media
+// type is removed.
+func (f *FSM) setKey(
+	key, value string,
+	index, now int64,
+	withPrev bool,
+) (*dkvv1.RevisionedKeyValue, error) {
+	var prev *dkvv1.RevisionedKeyValue
+	if withPrev || f.watchesPrevKV() {
+		var err error
+		if prev, err = f.previous(key, now); err != nil {
+			return nil, err
+		}
+	}
+	// The value replaces the collection of the key, like the SET of Redis.
+	if _, err := f.storer.DeleteCollection(key); err != nil {
+		return nil, err
+	}
+	if err := f.storer.Set(key, value); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rev, ok := f.revisions[key]
+	if expireAt, expiring := f.expirations[key]; !ok || (expiring && expireAt <= now) {
+		// An expired key is created again.
+		rev = revision{create: index}
+	}
+	rev.mod = index
+	rev.version++
+	f.revisions[key] = rev
+	delete(f.contentTypes, key)
+	f.record(WatchEvent{Type: WatchEventPut, KV: rev.keyValue(key, value), PrevKV: prev})
+	return prev, nil
+}
+
+// deleteKey deletes a key and its collection at the index, and records the
+// event if it was stored. It returns the previous key if withPrev is true or if
+// it is watched.
+func (f *FSM) deleteKey(key string, index, now int64, withPrev bool) (*dkvv1.RevisionedKeyValue, error) {
+	var prev *dkvv1.RevisionedKeyValue
+	if withPrev || f.watchesPrevKV() {
+		var err error
+		if prev, err = f.previous(key, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.storer.Delete(key); err != nil {
+		return nil, err
+	}
+	if _, err := f.storer.DeleteCollection(key); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.expirations, key)
+	delete(f.contentTypes, key)
+	if _, ok := f.revisions[key]; ok {
+		delete(f.revisions, key)
+		f.record(WatchEvent{
+			Type:   WatchEventDelete,
+			KV:     &dkvv1.RevisionedKeyValue{Key: []byte(key), ModRevision: index},
+			PrevKV: prev,
+		})
+	}
+	return prev, nil
 }
 
 // Apply execute the command from the Raft log entry.
+//
+// The commands with a result return a partial CommandResult.
 func (f *FSM) Apply(l *raft.Log) interface{} {
+	defer f.setApplied(l.Index)
+	f.startHistory(l.Index)
+
 	// Unpack the data
 	var cmd dkvv1.Command
 	if err := proto.Unmarshal(l.Data, &cmd); err != nil {
@@ -37,19 +448,192 @@ func (f *FSM) Apply(l *raft.Log) interface{} {
 	}
 
 	// Apply the command
+	index, now := int64(l.Index), cmd.GetTime()
 	switch c := cmd.Command.(type) {
 	case *dkvv1.Command_Set:
-		return f.storer.Set(c.Set.Key, c.Set.Value)
+		if _, err := f.setKey(c.Set.Key, c.Set.Value, index, now, false); err != nil {
+			return err
+		}
+		f.setExpiration(c.Set.Key, 0)
+		return nil
 	case *dkvv1.Command_Delete:
-		return f.storer.Delete(c.Delete.Key)
+		_, err := f.deleteKey(c.Delete.Key, index, now, false)
+		return err
+	case *dkvv1.Command_ServerMetadata:
+		f.setZone(raft.ServerID(c.ServerMetadata.GetId()), c.ServerMetadata.GetZone())
+		return nil
+	case *dkvv1.Command_Put:
+		return f.put(c.Put, index, now)
+	case *dkvv1.Command_DeleteKeys:
+		return f.deleteKeys(c.DeleteKeys, index, now)
+	case *dkvv1.Command_Increment:
+		return f.increment(c.Increment, index, now)
+	case *dkvv1.Command_Txn:
+		return f.txn(c.Txn, index, now)
+	case *dkvv1.Command_KeepAlive:
+		return f.keepAlive(c.KeepAlive, now)
+	case *dkvv1.Command_Enqueue:
+		return f.enqueue(c.Enqueue, index)
+	case *dkvv1.Command_Dequeue:
+		return f.dequeue(c.Dequeue, index)
+	case *dkvv1.Command_Ack:
+		return f.ack(c.Ack)
+	case *dkvv1.Command_Nack:
+		return f.nack(c.Nack, now)
+	case *dkvv1.Command_HashSet:
+		return f.hashSet(c.HashSet, index, now)
+	case *dkvv1.Command_SetAdd:
+		return f.setAdd(c.SetAdd, index, now)
+	case *dkvv1.Command_SortedSetAdd:
+		return f.sortedSetAdd(c.SortedSetAdd, index, now)
+	case *dkvv1.Command_RemoveMembers:
+		return f.removeMembers(c.RemoveMembers, now)
+	case *dkvv1.Command_Barrier:
+		return nil
 	}
 
 	return errors.New("unknown command")
 }
 
+func (f *FSM) put(put *dkvv1.PutCommand, index, now int64) interface{} {
+	if condition := put.GetCondition(); condition != dkvv1.PutCommand_CONDITION_UNSPECIFIED {
+		for _, entry := range put.GetEntries() {
+			// A collection exists, like the keys of Redis.
+			t, err := f.typeOf(entry.GetKey(), now)
+			if err != nil {
+				return err
+			}
+			if (t != store.TypeNone) != (condition == dkvv1.PutCommand_CONDITION_EXISTS) {
+				return &dkvv1.CommandResult{}
+			}
+		}
+	}
+	for _, entry := range put.GetEntries() {
+		if _, err := f.setKey(entry.GetKey(), entry.GetValue(), index, now, false); err != nil {
+			return err
+		}
+		f.setExpiration(entry.GetKey(), put.GetExpireAt())
+	}
+	return &dkvv1.CommandResult{Count: int64(len(put.GetEntries()))}
+}
+
+func (f *FSM) deleteKeys(del *dkvv1.DeleteKeysCommand, index, now int64) interface{} {
+	var count int64
+	for _, key := range del.GetKeys() {
+		if del.GetExpiredOnly() && !f.expired(key, now) {
+			continue
+		}
+		t, err := f.typeOf(key, now)
+		if err != nil {
+			return err
+		}
+		if _, err := f.deleteKey(key, index, now, false); err != nil {
+			return err
+		}
+		if t != store.TypeNone {
+			count++
+		}
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+func (f *FSM) keepAlive(keepAlive *dkvv1.KeepAliveCommand, now int64) interface{} {
+	var count int64
+	for _, key := range keepAlive.GetKeys() {
+		exists, err := f.exists(key, now)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		f.setExpiration(key, keepAlive.GetExpireAt())
+		count++
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+func (f *FSM) increment(inc *dkvv1.IncrementCommand, index, now int64) interface{} {
+	n := inc.GetInitial()
+	value, err := f.get(inc.GetKey(), now)
+	missing := errors.Is(err, store.ErrNotFound)
+	switch {
+	case missing:
+		if err := f.checkType(inc.GetKey(), store.TypeString, now); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if n, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return store.ErrNotInteger
+		}
+	}
+	delta := inc.GetDelta()
+	if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+		return store.ErrOverflow
+	}
+	n += delta
+	if (inc.Min != nil && n < inc.GetMin()) || (inc.Max != nil && n > inc.GetMax()) {
+		return store.ErrOutOfRange
+	}
+	if _, err := f.setKey(inc.GetKey(), strconv.FormatInt(n, 10), index, now, false); err != nil {
+		return err
+	}
+	if missing {
+		// An expired key is replaced by a key which does not expire.
+		f.setExpiration(inc.GetKey(), 0)
+	}
+	return &dkvv1.CommandResult{Count: 1, Value: n}
+}
+
+var _ raft.FSM = (*resultFSM)(nil)
+
+// resultFSM encodes the responses of the FSM into a CommandResult.
+//
+// Errors cannot be serialized by the Raft transport, which means they would be
+// lost when a command is forwarded to the leader.
+type resultFSM struct {
+	*FSM
+}
+
+func (f *resultFSM) Apply(l *raft.Log) interface{} {
+	result := &dkvv1.CommandResult{}
+	switch res := f.FSM.Apply(l).(type) {
+	case *dkvv1.CommandResult:
+		result = res
+	case error:
+		result.Error = res.Error()
+	}
+	result.Index = l.Index
+	b, err := proto.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return b
+}
+
 // Restore restores the state of the FSM from a snapshot.
+//
+// The records whose key has the reserved server metadata prefix are the zones
+// of the servers, the ones with the reserved expiration prefix are the
+// expiration times of the keys, the ones with the reserved revision prefix are
+// the revisions of the keys, the ones with the reserved content type prefix are
+// the media types of the values, and the ones with the reserved queue prefix are
+// the messages of the queues. The entries of the collections are stored like
+// the keys, without revision. The history of the events is lost, and starts
+// after the last entry applied before the snapshot.
 func (f *FSM) Restore(snapshot io.ReadCloser) error {
 	f.storer.Clear()
+	f.mu.Lock()
+	clear(f.zones)
+	clear(f.expirations)
+	clear(f.revisions)
+	clear(f.contentTypes)
+	clear(f.queues)
+	f.history = nil
+	f.historyStart = 0
+	f.mu.Unlock()
 	r := csv.NewReader(snapshot)
 	for {
 		record, err := r.Read()
@@ -59,24 +643,200 @@ func (f *FSM) Restore(snapshot io.ReadCloser) error {
 		if err != nil {
 			return err
 		}
+		if id, ok := strings.CutPrefix(record[0], serverMetadataPrefix); ok {
+			f.setZone(raft.ServerID(id), record[1])
+			continue
+		}
+		if key, ok := strings.CutPrefix(record[0], expirationPrefix); ok {
+			expireAt, err := strconv.ParseInt(record[1], 10, 64)
+			if err != nil {
+				return err
+			}
+			f.setExpiration(key, expireAt)
+			continue
+		}
+		if key, ok := strings.CutPrefix(record[0], revisionPrefix); ok {
+			rev, err := parseRevision(record[1])
+			if err != nil {
+				return err
+			}
+			f.mu.Lock()
+			f.revisions[key] = rev
+			f.mu.Unlock()
+			continue
+		}
+		if key, ok := strings.CutPrefix(record[0], contentTypePrefix); ok {
+			f.setContentType(key, record[1])
+			continue
+		}
+		if key, ok := strings.CutPrefix(record[0], queuePrefix); ok {
+			if err := f.restoreMessage(key, record[1]); err != nil {
+				return err
+			}
+			continue
+		}
+		if record[0] == appliedIndexKey {
+			index, err := strconv.ParseUint(record[1], 10, 64)
+			if err != nil {
+				return err
+			}
+			f.mu.Lock()
+			f.historyStart = int64(index) + 1
+			f.mu.Unlock()
+			f.setApplied(index)
+			continue
+		}
 		if err := f.storer.Set(record[0], record[1]); err != nil {
 			return err
 		}
+		if strings.HasPrefix(record[0], store.CollectionPrefix) {
+			// The entries of the collections have no revision.
+			continue
+		}
+		f.mu.Lock()
+		if _, ok := f.revisions[record[0]]; !ok {
+			f.revisions[record[0]] = unknownRevision
+		}
+		f.mu.Unlock()
+	}
+	f.mu.Lock()
+	for _, q := range f.queues {
+		slices.Sort(q.ready)
+		slices.SortFunc(q.claims, compareClaimExpiry)
 	}
+	f.mu.Unlock()
 	return nil
 }
 
+// restoreMessage restores a message of a snapshot record. The ready messages
+// and the claims must be sorted once restored.
+func (f *FSM) restoreMessage(key, value string) error {
+	rawID, name, ok := strings.Cut(key, "/")
+	fields := strings.SplitN(value, " ", 4)
+	if !ok || len(fields) != 4 {
+		return fmt.Errorf("invalid message: %q", key)
+	}
+	var numbers [4]int64
+	for i, s := range append([]string{rawID}, fields[:3]...) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		numbers[i] = n
+	}
+	id := numbers[0]
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.queues[name]
+	if !ok {
+		q = &queue{messages: make(map[int64]*queueMessage)}
+		f.queues[name] = q
+	}
+	q.messages[id] = &queueMessage{
+		body:       fields[3],
+		claim:      numbers[1],
+		claimUntil: numbers[2],
+		deliveries: numbers[3],
+	}
+	if numbers[1] == 0 {
+		q.ready = append(q.ready, id)
+	} else {
+		q.claims = append(q.claims, claimExpiry{claimUntil: numbers[2], id: id})
+	}
+	return nil
+}
+
+// formatMessage formats the key and the value of the snapshot record of a
+// message.
+func formatMessage(name string, id int64, m *queueMessage) []string {
+	return []string{
+		queuePrefix + strconv.FormatInt(id, 10) + "/" + name,
+		strconv.FormatInt(m.claim, 10) + " " +
+			strconv.FormatInt(m.claimUntil, 10) + " " +
+			strconv.FormatInt(m.deliveries, 10) + " " +
+			m.body,
+	}
+}
+
+// formatRevision formats a revision of a snapshot record.
+func formatRevision(rev revision) string {
+	return strconv.FormatInt(rev.create, 10) + " " +
+		strconv.FormatInt(rev.mod, 10) + " " +
+		strconv.FormatInt(rev.version, 10)
+}
+
+// parseRevision parses a revision of a snapshot record.
+func parseRevision(s string) (revision, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return revision{}, fmt.Errorf("invalid revision: %q", s)
+	}
+	var numbers [3]int64
+	for i, field := range fields {
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return revision{}, err
+		}
+		numbers[i] = n
+	}
+	return revision{create: numbers[0], mod: numbers[1], version: numbers[2]}, nil
+}
+
 // Snapshot dumps the state of the FSM to a snapshot.
 //
 // nolint: ireturn
 func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
-	return &fsmSnapshot{store: f.storer.Dump()}, nil
+	f.mu.RLock()
+	expirations := make(map[string]int64, len(f.expirations))
+	for key, expireAt := range f.expirations {
+		expirations[key] = expireAt
+	}
+	revisions := make(map[string]revision, len(f.revisions))
+	for key, rev := range f.revisions {
+		if rev != unknownRevision {
+			revisions[key] = rev
+		}
+	}
+	contentTypes := make(map[string]string, len(f.contentTypes))
+	for key, contentType := range f.contentTypes {
+		contentTypes[key] = contentType
+	}
+	var messages [][]string
+	for name, q := range f.queues {
+		for id, m := range q.messages {
+			messages = append(messages, formatMessage(name, id, m))
+		}
+	}
+	// The applied index is stale if a snapshot was restored since.
+	var appliedIndex uint64
+	if f.historyStart > 0 {
+		appliedIndex = f.appliedIndex
+	}
+	f.mu.RUnlock()
+	return &fsmSnapshot{
+		store:        f.storer.Dump(),
+		zones:        f.Zones(),
+		expirations:  expirations,
+		revisions:    revisions,
+		contentTypes: contentTypes,
+		messages:     messages,
+		appliedIndex: appliedIndex,
+	}, nil
 }
 
 var _ raft.FSMSnapshot = (*fsmSnapshot)(nil)
 
 type fsmSnapshot struct {
-	store map[string]string
+	store        map[string]string
+	zones        map[raft.ServerID]string
+	expirations  map[string]int64
+	revisions    map[string]revision
+	contentTypes map[string]string
+	// messages are the records of the messages of the queues.
+	messages [][]string
+	// appliedIndex is the index of the last log entry applied by the FSM, or
+	// zero if it is unknown.
+	appliedIndex uint64
 }
 
 // Persist should dump all necessary state to the WriteCloser 'sink',
@@ -89,6 +849,38 @@ func (f *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
 				return err
 			}
 		}
+		for id, zone := range f.zones {
+			if err := csvWriter.Write([]string{serverMetadataPrefix + string(id), zone}); err != nil {
+				return err
+			}
+		}
+		for key, expireAt := range f.expirations {
+			record := []string{expirationPrefix + key, strconv.FormatInt(expireAt, 10)}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+		for key, rev := range f.revisions {
+			if err := csvWriter.Write([]string{revisionPrefix + key, formatRevision(rev)}); err != nil {
+				return err
+			}
+		}
+		for key, contentType := range f.contentTypes {
+			if err := csvWriter.Write([]string{contentTypePrefix + key, contentType}); err != nil {
+				return err
+			}
+		}
+		for _, record := range f.messages {
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+		if f.appliedIndex > 0 {
+			record := []string{appliedIndexKey, strconv.FormatUint(f.appliedIndex, 10)}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
 		csvWriter.Flush()
 		if err := csvWriter.Error(); err != nil {
 			return err
@@ -2,11 +2,15 @@ package distributed_test
 
 import (
 	dkvv1 "distributed-kv/gen/dkv/v1"
+	kvstore "distributed-kv/internal/store"
 	"distributed-kv/internal/store/distributed"
 	"distributed-kv/mocks/mockdistributed"
 	"io"
+	"math"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/require"
@@ -39,6 +43,7 @@ func TestFSM(t *testing.T) {
 					},
 				},
 				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().DeleteCollection("key").Return(false, nil).Once()
 					s.EXPECT().Set("key", "value").Return(nil)
 				},
 				assertFn: func(_ *testing.T, res interface{}) {
@@ -56,11 +61,167 @@ func TestFSM(t *testing.T) {
 				},
 				expectFn: func(s *mockdistributed.Storer) {
 					s.EXPECT().Delete("key").Return(nil)
+					s.EXPECT().DeleteCollection("key").Return(false, nil).Once()
 				},
 				assertFn: func(t *testing.T, res interface{}) {
 					require.Nil(t, res)
 				},
 			},
+			{
+				title: "Put if absent",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Put{
+						Put: &dkvv1.PutCommand{
+							Entries: []*dkvv1.KeyValue{
+								{Key: "key1", Value: "value1"},
+								{Key: "key2", Value: "value2"},
+							},
+							Condition: dkvv1.PutCommand_CONDITION_ABSENT,
+						},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("key1").Return("", kvstore.ErrNotFound).Once()
+					s.EXPECT().Get("key2").Return("", kvstore.ErrNotFound).Once()
+					s.EXPECT().CollectionType("key1").Return(kvstore.TypeNone, nil).Once()
+					s.EXPECT().CollectionType("key2").Return(kvstore.TypeNone, nil).Once()
+					s.EXPECT().DeleteCollection("key1").Return(false, nil).Once()
+					s.EXPECT().DeleteCollection("key2").Return(false, nil).Once()
+					s.EXPECT().Set("key1", "value1").Return(nil).Once()
+					s.EXPECT().Set("key2", "value2").Return(nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Equal(t, int64(2), res.(*dkvv1.CommandResult).GetCount())
+				},
+			},
+			{
+				title: "Put if exists",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Put{
+						Put: &dkvv1.PutCommand{
+							Entries:   []*dkvv1.KeyValue{{Key: "key", Value: "value"}},
+							Condition: dkvv1.PutCommand_CONDITION_EXISTS,
+						},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("key").Return("", kvstore.ErrNotFound).Once()
+					s.EXPECT().CollectionType("key").Return(kvstore.TypeNone, nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Zero(t, res.(*dkvv1.CommandResult).GetCount())
+				},
+			},
+			{
+				title: "Delete keys",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_DeleteKeys{
+						DeleteKeys: &dkvv1.DeleteKeysCommand{Keys: []string{"key1", "key2"}},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("key1").Return("value1", nil).Once()
+					s.EXPECT().Get("key2").Return("", kvstore.ErrNotFound).Once()
+					s.EXPECT().CollectionType("key2").Return(kvstore.TypeHash, nil).Once()
+					s.EXPECT().Delete("key1").Return(nil).Once()
+					s.EXPECT().Delete("key2").Return(nil).Once()
+					s.EXPECT().DeleteCollection("key1").Return(false, nil).Once()
+					s.EXPECT().DeleteCollection("key2").Return(true, nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Equal(t, int64(2), res.(*dkvv1.CommandResult).GetCount())
+				},
+			},
+			{
+				title: "Increment",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Increment{
+						Increment: &dkvv1.IncrementCommand{Key: "counter", Delta: 2},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("counter").Return("40", nil).Once()
+					s.EXPECT().DeleteCollection("counter").Return(false, nil).Once()
+					s.EXPECT().Set("counter", "42").Return(nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Equal(t, int64(42), res.(*dkvv1.CommandResult).GetValue())
+				},
+			},
+			{
+				title: "Increment not an integer",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Increment{
+						Increment: &dkvv1.IncrementCommand{Key: "text", Delta: 1},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("text").Return("value", nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.ErrorIs(t, res.(error), kvstore.ErrNotInteger)
+				},
+			},
+			{
+				title: "Increment overflow",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Increment{
+						Increment: &dkvv1.IncrementCommand{Key: "counter", Delta: 1},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("counter").Return(strconv.FormatInt(math.MaxInt64, 10), nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.ErrorIs(t, res.(error), kvstore.ErrOverflow)
+				},
+			},
+			{
+				title: "Increment missing key",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Increment{
+						Increment: &dkvv1.IncrementCommand{Key: "missing", Delta: 1, Initial: 10},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("missing").Return("", kvstore.ErrNotFound).Twice()
+					s.EXPECT().CollectionType("missing").Return(kvstore.TypeNone, nil).Once()
+					s.EXPECT().DeleteCollection("missing").Return(false, nil).Once()
+					s.EXPECT().Set("missing", "11").Return(nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Equal(t, int64(11), res.(*dkvv1.CommandResult).GetValue())
+				},
+			},
+			{
+				title: "Increment out of range",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_Increment{
+						Increment: &dkvv1.IncrementCommand{Key: "counter", Delta: 1, Max: proto.Int64(40)},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("counter").Return("40", nil).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.ErrorIs(t, res.(error), kvstore.ErrOutOfRange)
+				},
+			},
+			{
+				title: "KeepAlive",
+				command: &dkvv1.Command{
+					Command: &dkvv1.Command_KeepAlive{
+						KeepAlive: &dkvv1.KeepAliveCommand{Keys: []string{"lease", "missing"}, ExpireAt: 10},
+					},
+				},
+				expectFn: func(s *mockdistributed.Storer) {
+					s.EXPECT().Get("lease").Return("value", nil).Once()
+					s.EXPECT().Get("missing").Return("", kvstore.ErrNotFound).Once()
+				},
+				assertFn: func(t *testing.T, res interface{}) {
+					require.Equal(t, int64(1), res.(*dkvv1.CommandResult).GetCount())
+				},
+			},
 			{
 				title:   "Invalid command",
 				command: &dkvv1.Command{},
@@ -135,6 +296,175 @@ func TestFSM(t *testing.T) {
 		require.Equal(t, 1, sink.callCloseCounter)
 		require.Equal(t, "key1,value1\n", res.String())
 	})
+
+	t.Run("Zones", func(t *testing.T) {
+		// Arrange
+		storer := mockdistributed.NewStorer(t)
+		fsm := distributed.NewFSM(storer)
+		data, err := proto.Marshal(&dkvv1.Command{
+			Command: &dkvv1.Command_ServerMetadata{
+				ServerMetadata: &dkvv1.ServerMetadata{Id: "node0", Zone: "a"},
+			},
+		})
+		require.NoError(t, err)
+		storer.EXPECT().Dump().Return(map[string]string{})
+		storer.EXPECT().Clear()
+
+		// Act
+		res := fsm.Apply(&raft.Log{Data: data})
+		snapshot, err := fsm.Snapshot()
+		require.NoError(t, err)
+		persisted := &strings.Builder{}
+		require.NoError(t, snapshot.Persist(&MockSnapshotSink{Writer: persisted}))
+		restored := distributed.NewFSM(storer)
+		err = restored.Restore(io.NopCloser(strings.NewReader(persisted.String())))
+
+		// Assert
+		require.Nil(t, res)
+		require.NoError(t, err)
+		require.Equal(t, map[raft.ServerID]string{"node0": "a"}, fsm.Zones())
+		require.Equal(t, fsm.Zones(), restored.Zones())
+	})
+}
+
+func TestFSMExpirations(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	storer := mockdistributed.NewStorer(t)
+	fsm := distributed.NewFSM(storer)
+	now := time.Now()
+	data, err := proto.Marshal(&dkvv1.Command{
+		Command: &dkvv1.Command_Put{
+			Put: &dkvv1.PutCommand{
+				Entries:  []*dkvv1.KeyValue{{Key: "key", Value: "value"}},
+				ExpireAt: now.Add(time.Minute).UnixNano(),
+			},
+		},
+		Time: now.UnixNano(),
+	})
+	require.NoError(t, err)
+	storer.EXPECT().DeleteCollection("key").Return(false, nil)
+	storer.EXPECT().Set("key", "value").Return(nil)
+	storer.EXPECT().Get("key").Return("value", nil)
+	storer.EXPECT().CollectionType("key").Return(kvstore.TypeNone, nil)
+	storer.EXPECT().Dump().Return(map[string]string{"key": "value"})
+	storer.EXPECT().Clear()
+
+	// Act
+	res := fsm.Apply(&raft.Log{Data: data})
+	snapshot, err := fsm.Snapshot()
+	require.NoError(t, err)
+	persisted := &strings.Builder{}
+	require.NoError(t, snapshot.Persist(&MockSnapshotSink{Writer: persisted}))
+	restored := distributed.NewFSM(storer)
+	err = restored.Restore(io.NopCloser(strings.NewReader(persisted.String())))
+
+	// Assert
+	require.Equal(t, int64(1), res.(*dkvv1.CommandResult).GetCount())
+	require.NoError(t, err)
+	for _, f := range []*distributed.FSM{fsm, restored} {
+		// A write if absent only holds once the key is expired.
+		require.Zero(t, putIfAbsent(t, f, now).GetCount())
+		require.Equal(t, int64(1), putIfAbsent(t, f, now.Add(time.Minute)).GetCount())
+	}
+}
+
+func TestFSMQueue(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	storer := mockdistributed.NewStorer(t)
+	fsm := distributed.NewFSM(storer)
+	now := time.Now()
+	storer.EXPECT().Dump().Return(map[string]string{})
+	storer.EXPECT().Clear()
+	claim := func(id, claim int64) *dkvv1.QueueClaim {
+		return &dkvv1.QueueClaim{Queue: "jobs", Id: id, Claim: claim}
+	}
+	dequeue := &dkvv1.Command{
+		Command: &dkvv1.Command_Dequeue{
+			Dequeue: &dkvv1.DequeueCommand{Queue: "jobs", ClaimUntil: now.Add(time.Minute).UnixNano()},
+		},
+	}
+	requeue := &dkvv1.Command{
+		Command: &dkvv1.Command_Nack{
+			Nack: &dkvv1.NackCommand{Claims: []*dkvv1.QueueClaim{claim(1, 5)}, ExpiredOnly: true},
+		},
+	}
+	for i, body := range []string{"a", "b"} {
+		applyCommand(t, fsm, uint64(i+1), &dkvv1.Command{
+			Command: &dkvv1.Command_Enqueue{Enqueue: &dkvv1.EnqueueCommand{Queue: "jobs", Body: body}},
+		})
+	}
+
+	// Act
+	first := applyCommand(t, fsm, 3, dequeue)
+	nacked := applyCommand(t, fsm, 4, &dkvv1.Command{
+		Command: &dkvv1.Command_Nack{Nack: &dkvv1.NackCommand{Claims: []*dkvv1.QueueClaim{claim(1, 3)}}},
+	})
+	redelivered := applyCommand(t, fsm, 5, dequeue)
+	staleAck := applyCommand(t, fsm, 6, &dkvv1.Command{
+		Command: &dkvv1.Command_Ack{Ack: &dkvv1.AckCommand{Claim: claim(1, 3)}},
+	})
+	snapshot, err := fsm.Snapshot()
+	require.NoError(t, err)
+	persisted := &strings.Builder{}
+	require.NoError(t, snapshot.Persist(&MockSnapshotSink{Writer: persisted}))
+	restored := distributed.NewFSM(storer)
+	require.NoError(t, restored.Restore(io.NopCloser(strings.NewReader(persisted.String()))))
+	requeue.Time = now.UnixNano()
+	notExpired := applyCommand(t, restored, 7, requeue)
+	requeue.Time = now.Add(time.Minute).UnixNano()
+	expired := applyCommand(t, restored, 8, requeue)
+	third := applyCommand(t, restored, 9, dequeue)
+	ack := applyCommand(t, restored, 10, &dkvv1.Command{
+		Command: &dkvv1.Command_Ack{Ack: &dkvv1.AckCommand{Claim: claim(1, 9)}},
+	})
+	second := applyCommand(t, restored, 11, dequeue)
+	empty := applyCommand(t, restored, 12, dequeue)
+
+	// Assert
+	require.True(t, proto.Equal(
+		&dkvv1.QueueMessage{Id: 1, Body: "a", Claim: 3, Deliveries: 1},
+		first.(*dkvv1.CommandResult).GetMessage(),
+	))
+	require.Equal(t, int64(1), nacked.(*dkvv1.CommandResult).GetCount())
+	require.True(t, proto.Equal(
+		&dkvv1.QueueMessage{Id: 1, Body: "a", Claim: 5, Deliveries: 2},
+		redelivered.(*dkvv1.CommandResult).GetMessage(),
+	), "a requeued message keeps its position")
+	require.ErrorIs(t, staleAck.(error), distributed.ErrClaimExpired)
+	require.Zero(t, notExpired.(*dkvv1.CommandResult).GetCount())
+	require.Equal(t, int64(1), expired.(*dkvv1.CommandResult).GetCount())
+	require.Equal(t, int64(3), third.(*dkvv1.CommandResult).GetMessage().GetDeliveries())
+	require.Equal(t, int64(1), ack.(*dkvv1.CommandResult).GetCount())
+	require.Equal(t, "b", second.(*dkvv1.CommandResult).GetMessage().GetBody())
+	require.Nil(t, empty.(*dkvv1.CommandResult).GetMessage())
+}
+
+// applyCommand applies a command at the index.
+func applyCommand(t *testing.T, fsm *distributed.FSM, index uint64, cmd *dkvv1.Command) interface{} {
+	t.Helper()
+	data, err := proto.Marshal(cmd)
+	require.NoError(t, err)
+	return fsm.Apply(&raft.Log{Index: index, Data: data})
+}
+
+// putIfAbsent applies a write of the key if it is absent at the time now.
+func putIfAbsent(t *testing.T, fsm *distributed.FSM, now time.Time) *dkvv1.CommandResult {
+	t.Helper()
+	data, err := proto.Marshal(&dkvv1.Command{
+		Command: &dkvv1.Command_Put{
+			Put: &dkvv1.PutCommand{
+				Entries:   []*dkvv1.KeyValue{{Key: "key", Value: "value"}},
+				Condition: dkvv1.PutCommand_CONDITION_ABSENT,
+			},
+		},
+		Time: now.UnixNano(),
+	})
+	require.NoError(t, err)
+	return fsm.Apply(&raft.Log{Data: data}).(*dkvv1.CommandResult)
 }
 
 var _ raft.SnapshotSink = (*MockSnapshotSink)(nil)
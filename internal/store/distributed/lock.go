@@ -0,0 +1,293 @@
+package distributed
+
+import (
+	"context"
+	"crypto/rand"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrLeaseExpired is returned when a lease expired, or was deleted, before it
+// was released.
+var ErrLeaseExpired = errors.New("lease expired")
+
+// errLeasesChanged stops a watch when the leases of a name changed.
+var errLeasesChanged = errors.New("leases changed")
+
+// KeepAlive postpones the expiration of the keys which exist by the TTL, without
+// changing their revisions. It returns the Raft index of the write, and the
+// number of keys kept alive.
+func (s *Store) KeepAlive(ttl time.Duration, keys ...string) (uint64, int64, error) {
+	if err := checkKeys(keys...); err != nil {
+		return 0, 0, err
+	}
+	return s.keepAlive(ttl, keys...)
+}
+
+// keepAlive is KeepAlive without the check of the keys.
+func (s *Store) keepAlive(ttl time.Duration, keys ...string) (uint64, int64, error) {
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_KeepAlive{
+			KeepAlive: &dkvv1.KeepAliveCommand{
+				Keys:     keys,
+				ExpireAt: time.Now().Add(ttl).UnixNano(),
+			},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// leaseKeyPrefix is the prefix of the stored keys of the leases, which are
+// reserved so that they are only written by their holders.
+const leaseKeyPrefix = store.ReservedPrefix + "lease/"
+
+// leaseKey returns the stored key of a lease from its key, which is
+// <name>/<id>. The ID has no slash.
+func leaseKey(key string) string {
+	i := strings.LastIndexByte(key, '/')
+	return leasePrefix(key[:max(i, 0)]) + key[i+1:]
+}
+
+// leasePrefix returns the stored key prefix of the leases of a lock or of an
+// election. The name is prefixed by its length, like the keys of the
+// collections, so that the leases of a/b are not leases of a.
+func leasePrefix(name string) string {
+	return leaseKeyPrefix + strconv.Itoa(len(name)) + "/" + name + "/"
+}
+
+// newLease returns the lease of a name from its stored key.
+func newLease(name string, kv *dkvv1.RevisionedKeyValue) *dkvv1.Lease {
+	return &dkvv1.Lease{
+		Key:      name + "/" + strings.TrimPrefix(string(kv.GetKey()), leasePrefix(name)),
+		Revision: kv.GetCreateRevision(),
+		Value:    string(kv.GetValue()),
+	}
+}
+
+// RenewLease postpones the expiration of a lease by the TTL. It returns the
+// Raft index of the write, or ErrLeaseExpired if the lease does not exist.
+func (s *Store) RenewLease(key string, ttl time.Duration) (uint64, error) {
+	index, kept, err := s.keepAlive(ttl, leaseKey(key))
+	if err == nil && kept == 0 {
+		err = ErrLeaseExpired
+	}
+	return index, err
+}
+
+// Release deletes a lease if its revision is the revision of its holder, and
+// returns the Raft index of the deletion. ErrLeaseExpired is returned if the
+// lease does not exist, or was recreated since.
+func (s *Store) Release(key string, revision int64) (uint64, error) {
+	key = leaseKey(key)
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Txn{
+			Txn: &dkvv1.TxnCommand{
+				Compare: []*dkvv1.Comparison{{
+					Key:    []byte(key),
+					Target: dkvv1.Comparison_TARGET_CREATE_REVISION,
+					Result: dkvv1.Comparison_RESULT_EQUAL,
+					Number: revision,
+				}},
+				Success: []*dkvv1.Operation{{
+					Operation: &dkvv1.Operation_DeleteRange{
+						DeleteRange: &dkvv1.DeleteRangeOperation{Key: []byte(key)},
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		return res.GetIndex(), err
+	}
+	if !res.GetTxn().GetSucceeded() {
+		return res.GetIndex(), ErrLeaseExpired
+	}
+	return res.GetIndex(), nil
+}
+
+// Acquire creates a lease of the name with the value and the TTL, and waits
+// until it is the oldest lease of the name: the holder of the lock, or the
+// leader of the election. The waiters are served in the order of creation of
+// their leases, which is the order of their revisions.
+//
+// The lease is kept alive while waiting, and deleted if the context is done or
+// the wait fails. ErrLeaseExpired is returned if it expired meanwhile.
+func (s *Store) Acquire(ctx context.Context, name, value string, ttl time.Duration) (*dkvv1.Lease, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	leaseID := hex.EncodeToString(id)
+	key := leasePrefix(name) + leaseID
+	index, created, err := s.setKeys(
+		[]store.KeyValue{{Key: key, Value: value}},
+		store.SetOptions{IfAbsent: true, TTL: ttl},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.New("lease already exists")
+	}
+	lease := &dkvv1.Lease{
+		Key:      name + "/" + leaseID,
+		Revision: int64(index),
+		Value:    value,
+	}
+
+	waitCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.keepLeaseAlive(waitCtx, cancel, key, ttl)
+	}()
+	err = s.waitOldest(waitCtx, name, lease)
+	if err != nil && waitCtx.Err() != nil {
+		err = context.Cause(waitCtx)
+	}
+	cancel(nil)
+	wg.Wait()
+	if err != nil {
+		if _, _, delErr := s.deleteKeys(key); delErr != nil {
+			slog.Error("failed to delete lease", "key", key, "error", delErr)
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+// keepLeaseAlive keeps a lease alive until the context is done. The context is
+// canceled with ErrLeaseExpired if the lease expired.
+func (s *Store) keepLeaseAlive(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	key string,
+	ttl time.Duration,
+) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// A failed keep-alive is retried at the next tick, before the lease
+		// expires.
+		_, count, err := s.keepAlive(ttl, key)
+		if err != nil {
+			slog.Warn("failed to keep lease alive", "key", key, "error", err)
+		} else if count == 0 {
+			cancel(ErrLeaseExpired)
+			return
+		}
+	}
+}
+
+// waitOldest waits until the lease is the oldest lease of the name.
+//
+// Only the deletion of the previous lease is watched, so that a release only
+// wakes up the next waiter.
+func (s *Store) waitOldest(ctx context.Context, name string, lease *dkvv1.Lease) error {
+	for {
+		rev, leases, err := s.leases(name, uint64(lease.GetRevision()))
+		if err != nil {
+			return err
+		}
+		key := leaseKey(lease.GetKey())
+		i := slices.IndexFunc(leases, func(kv *dkvv1.RevisionedKeyValue) bool {
+			return string(kv.GetKey()) == key
+		})
+		switch i {
+		case -1:
+			return ErrLeaseExpired
+		case 0:
+			return nil
+		}
+		err = s.watch(
+			ctx,
+			leases[i-1].GetKey(),
+			nil,
+			int64(rev)+1,
+			false,
+			func(events []WatchEvent, _ int64) error {
+				for _, e := range events {
+					if e.Type == WatchEventDelete {
+						return errLeasesChanged
+					}
+				}
+				return nil
+			},
+		)
+		if !errors.Is(err, errLeasesChanged) && !errors.Is(err, ErrCompacted) {
+			return err
+		}
+	}
+}
+
+// leases returns the leases of a name from the local state once the index is
+// applied, in the order of their creation, and the revision of the read.
+func (s *Store) leases(name string, index uint64) (uint64, []*dkvv1.RevisionedKeyValue, error) {
+	prefix := leasePrefix(name)
+	rev, result, err := s.rangeKeys(&dkvv1.RangeOperation{
+		Key:        []byte(prefix),
+		RangeEnd:   []byte(prefixEnd(prefix)),
+		SortOrder:  dkvv1.RangeOperation_SORT_ORDER_ASCEND,
+		SortTarget: dkvv1.RangeOperation_SORT_TARGET_CREATE_REVISION,
+	}, store.ReadOptions{MinIndex: index})
+	return rev, result.GetKvs(), err
+}
+
+// Observe calls fn with the oldest lease of the name, or nil if there is none,
+// and then each time it changes, until the context is done or fn fails.
+//
+// The leases are read from the local state. An expired lease is observed until
+// it is deleted.
+func (s *Store) Observe(ctx context.Context, name string, fn func(leader *dkvv1.Lease) error) error {
+	prefix := leasePrefix(name)
+	var last *dkvv1.Lease
+	for first := true; ; first = false {
+		rev, leases, err := s.leases(name, 0)
+		if err != nil {
+			return err
+		}
+		var leader *dkvv1.Lease
+		if len(leases) > 0 {
+			leader = newLease(name, leases[0])
+		}
+		if first || !proto.Equal(leader, last) {
+			if err := fn(leader); err != nil {
+				return err
+			}
+			last = leader
+		}
+		err = s.watch(
+			ctx,
+			[]byte(prefix),
+			[]byte(prefixEnd(prefix)),
+			int64(rev)+1,
+			false,
+			func(events []WatchEvent, _ int64) error {
+				if len(events) > 0 {
+					return errLeasesChanged
+				}
+				return nil
+			},
+		)
+		if !errors.Is(err, errLeasesChanged) && !errors.Is(err, ErrCompacted) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package distributed
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// PeerStats are the replication statistics of a follower, as seen by the leader.
+type PeerStats struct {
+	// LastContact is the time of the last response of the follower.
+	LastContact time.Time
+	// LastIndex is the last log index of the follower.
+	LastIndex uint64
+	// Term is the term of the follower.
+	Term uint64
+}
+
+var _ raft.Transport = (*statsTransport)(nil)
+
+// statsTransport records the responses of the followers to AppendEntries.
+//
+// hashicorp/raft does not expose the replication state of the followers.
+type statsTransport struct {
+	*raft.NetworkTransport
+
+	mu    sync.RWMutex
+	stats map[raft.ServerID]PeerStats
+}
+
+func newStatsTransport(t *raft.NetworkTransport) *statsTransport {
+	return &statsTransport{
+		NetworkTransport: t,
+		stats:            make(map[raft.ServerID]PeerStats),
+	}
+}
+
+func (t *statsTransport) AppendEntries(
+	id raft.ServerID,
+	target raft.ServerAddress,
+	args *raft.AppendEntriesRequest,
+	resp *raft.AppendEntriesResponse,
+) error {
+	if err := t.NetworkTransport.AppendEntries(id, target, args, resp); err != nil {
+		return err
+	}
+	t.record(id, resp)
+	return nil
+}
+
+// nolint: ireturn
+func (t *statsTransport) AppendEntriesPipeline(
+	id raft.ServerID,
+	target raft.ServerAddress,
+) (raft.AppendPipeline, error) {
+	p, err := t.NetworkTransport.AppendEntriesPipeline(id, target)
+	if err != nil {
+		return nil, err
+	}
+	return newStatsPipeline(p, func(resp *raft.AppendEntriesResponse) {
+		t.record(id, resp)
+	}), nil
+}
+
+func (t *statsTransport) record(id raft.ServerID, resp *raft.AppendEntriesResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats[id] = PeerStats{
+		LastContact: time.Now(),
+		LastIndex:   resp.LastLog,
+		Term:        resp.Term,
+	}
+}
+
+// Stats returns the statistics of a follower, if it responded at least once.
+func (t *statsTransport) Stats(id raft.ServerID) (PeerStats, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	stats, ok := t.stats[id]
+	return stats, ok
+}
+
+var _ raft.AppendPipeline = (*statsPipeline)(nil)
+
+// statsPipeline records the responses of a pipeline before passing them to Raft.
+type statsPipeline struct {
+	raft.AppendPipeline
+	consumer chan raft.AppendFuture
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newStatsPipeline(
+	p raft.AppendPipeline,
+	record func(*raft.AppendEntriesResponse),
+) *statsPipeline {
+	sp := &statsPipeline{
+		AppendPipeline: p,
+		consumer:       make(chan raft.AppendFuture),
+		done:           make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case future := <-p.Consumer():
+				if future.Error() == nil {
+					record(future.Response())
+				}
+				select {
+				case sp.consumer <- future:
+				case <-sp.done:
+					return
+				}
+			case <-sp.done:
+				return
+			}
+		}
+	}()
+	return sp
+}
+
+func (p *statsPipeline) Consumer() <-chan raft.AppendFuture {
+	return p.consumer
+}
+
+func (p *statsPipeline) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	return p.AppendPipeline.Close()
+}
@@ -0,0 +1,114 @@
+package distributed
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+// ErrUnknownPeer is returned when a peer presents a certificate which does not
+// belong to a member of the cluster.
+var ErrUnknownPeer = errors.New("unknown peer")
+
+// PeerVerifier checks that the certificate presented by a peer belongs to a
+// member of the current Raft configuration or to an allowed identity.
+//
+// A certificate matches an identity if its Common Name is equal to the
+// identity, or if one of its SANs (DNS names or IP addresses) matches it.
+type PeerVerifier struct {
+	// AllowedPeers is a list of identities (IDs, hostnames or IP addresses)
+	// which are always accepted, even if they are not a member of the cluster.
+	//
+	// This is needed to let a node be joined by the leader while it does not
+	// know the cluster yet.
+	AllowedPeers []string
+	// Servers returns the servers of the current Raft configuration.
+	Servers func() ([]raft.Server, error)
+
+	mu sync.RWMutex
+}
+
+// Allow adds identities to the allowed peers.
+func (v *PeerVerifier) Allow(identities ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, identity := range identities {
+		if !slices.Contains(v.AllowedPeers, identity) {
+			v.AllowedPeers = append(v.AllowedPeers, identity)
+		}
+	}
+}
+
+// VerifyConnection can be used as tls.Config.VerifyConnection.
+func (v *PeerVerifier) VerifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		slog.Error("rejected peer without certificate")
+		return fmt.Errorf("%w: no certificate", ErrUnknownPeer)
+	}
+	cert := cs.PeerCertificates[0]
+	if v.isKnown(cert) {
+		return nil
+	}
+	slog.Error(
+		"rejected unknown peer",
+		"cn", cert.Subject.CommonName,
+		"dns", cert.DNSNames,
+		"ips", cert.IPAddresses,
+	)
+	return fmt.Errorf("%w: %s", ErrUnknownPeer, cert.Subject.CommonName)
+}
+
+func (v *PeerVerifier) isKnown(cert *x509.Certificate) bool {
+	if v.isAllowed(cert) {
+		return true
+	}
+	if v.Servers == nil {
+		return false
+	}
+	servers, err := v.Servers()
+	if err != nil {
+		slog.Error("failed to get servers", "error", err)
+		return false
+	}
+	for _, srv := range servers {
+		if certificateMatches(cert, string(srv.ID)) {
+			return true
+		}
+		host, _, err := net.SplitHostPort(string(srv.Address))
+		if err != nil {
+			host = string(srv.Address)
+		}
+		if certificateMatches(cert, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *PeerVerifier) isAllowed(cert *x509.Certificate) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, identity := range v.AllowedPeers {
+		if certificateMatches(cert, identity) {
+			return true
+		}
+	}
+	return false
+}
+
+func certificateMatches(cert *x509.Certificate, identity string) bool {
+	if identity == "" {
+		return false
+	}
+	if cert.Subject.CommonName == identity {
+		return true
+	}
+	return cert.VerifyHostname(identity) == nil
+}
@@ -0,0 +1,148 @@
+package distributed_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"distributed-kv/internal/store/distributed"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func newPeerCertificate(t *testing.T, cn string, hosts ...string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestPeerVerifier(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	verifier := &distributed.PeerVerifier{
+		AllowedPeers: []string{"dkv-3"},
+		Servers: func() ([]raft.Server, error) {
+			return []raft.Server{
+				{ID: "dkv-0", Address: "dkv-0.dkv.default.svc.cluster.local:2380"},
+				{ID: "dkv-1", Address: "10.0.0.1:2380"},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		title    string
+		cert     *x509.Certificate
+		expected error
+	}{
+		{
+			title: "Member by address",
+			cert:  newPeerCertificate(t, "dkv", "dkv-0.dkv.default.svc.cluster.local"),
+		},
+		{
+			title: "Member by wildcard address",
+			cert:  newPeerCertificate(t, "dkv", "*.dkv.default.svc.cluster.local"),
+		},
+		{
+			title: "Member by IP",
+			cert:  newPeerCertificate(t, "dkv", "10.0.0.1"),
+		},
+		{
+			title: "Member by ID",
+			cert:  newPeerCertificate(t, "dkv-1"),
+		},
+		{
+			title: "Allowed peer",
+			cert:  newPeerCertificate(t, "client", "dkv-3"),
+		},
+		{
+			title:    "Unknown peer",
+			cert:     newPeerCertificate(t, "client", "dkv-4.dkv.default.svc.cluster.local"),
+			expected: distributed.ErrUnknownPeer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Act
+			err := verifier.VerifyConnection(tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{tt.cert},
+			})
+
+			// Assert
+			if tt.expected != nil {
+				require.ErrorIs(t, err, tt.expected)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("No certificate", func(t *testing.T) {
+		// Act
+		err := verifier.VerifyConnection(tls.ConnectionState{})
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrUnknownPeer)
+	})
+
+	t.Run("Servers error", func(t *testing.T) {
+		// Arrange
+		verifier := &distributed.PeerVerifier{
+			Servers: func() ([]raft.Server, error) {
+				return nil, errors.New("no configuration")
+			},
+		}
+
+		// Act
+		err := verifier.VerifyConnection(tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{newPeerCertificate(t, "dkv-0")},
+		})
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrUnknownPeer)
+	})
+	t.Run("Allowed at runtime", func(t *testing.T) {
+		// Arrange
+		verifier := &distributed.PeerVerifier{}
+		state := tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{newPeerCertificate(t, "dkv-3")},
+		}
+		errBefore := verifier.VerifyConnection(state)
+
+		// Act
+		verifier.Allow("dkv-3")
+		err := verifier.VerifyConnection(state)
+
+		// Assert
+		require.ErrorIs(t, errBefore, distributed.ErrUnknownPeer)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,349 @@
+package distributed
+
+import (
+	"cmp"
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// maxRequeuedClaims is the maximum number of expired claims requeued by a
+// command.
+const maxRequeuedClaims = 1000
+
+// ErrClaimExpired is returned when acknowledging a message whose claim expired
+// and was requeued, or which was already acknowledged.
+var ErrClaimExpired = errors.New("claim expired")
+
+// queue is the messages of a queue.
+type queue struct {
+	// messages are the messages of the queue, by ID.
+	messages map[int64]*queueMessage
+	// ready are the IDs of the messages which are not claimed, in ascending
+	// order.
+	ready []int64
+	// claims are the claimed messages, in ascending order of expiration of
+	// their claims.
+	claims []claimExpiry
+}
+
+// claimExpiry is a claimed message and the expiration time of its claim.
+type claimExpiry struct {
+	claimUntil int64
+	id         int64
+}
+
+// compareClaimExpiry orders the claims by expiration, and then by ID.
+func compareClaimExpiry(a, b claimExpiry) int {
+	if c := cmp.Compare(a.claimUntil, b.claimUntil); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// queueMessage is a message of a queue. Its ID is the Raft index of its
+// enqueue.
+type queueMessage struct {
+	body string
+	// claim is the Raft index of the dequeue which claimed the message, or
+	// zero if the message is not claimed.
+	claim int64
+	// claimUntil is the expiration time of the claim, in Unix nanoseconds.
+	claimUntil int64
+	// deliveries is the number of times the message was claimed.
+	deliveries int64
+}
+
+func (m *queueMessage) proto(id int64) *dkvv1.QueueMessage {
+	return &dkvv1.QueueMessage{
+		Id:         id,
+		Body:       m.body,
+		Claim:      m.claim,
+		Deliveries: m.deliveries,
+	}
+}
+
+// requeue inserts the ID of a message in the ready messages, at its position.
+func (q *queue) requeue(id int64) {
+	i, _ := slices.BinarySearch(q.ready, id)
+	q.ready = slices.Insert(q.ready, i, id)
+}
+
+// claim inserts a claimed message in the claims, at its position.
+func (q *queue) claim(id int64, m *queueMessage) {
+	e := claimExpiry{claimUntil: m.claimUntil, id: id}
+	i, _ := slices.BinarySearchFunc(q.claims, e, compareClaimExpiry)
+	q.claims = slices.Insert(q.claims, i, e)
+}
+
+// unclaim deletes a claimed message from the claims.
+func (q *queue) unclaim(id int64, m *queueMessage) {
+	e := claimExpiry{claimUntil: m.claimUntil, id: id}
+	if i, ok := slices.BinarySearchFunc(q.claims, e, compareClaimExpiry); ok {
+		q.claims = slices.Delete(q.claims, i, i+1)
+	}
+}
+
+func (f *FSM) enqueue(enq *dkvv1.EnqueueCommand, index int64) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.queues[enq.GetQueue()]
+	if !ok {
+		q = &queue{messages: make(map[int64]*queueMessage)}
+		f.queues[enq.GetQueue()] = q
+	}
+	q.messages[index] = &queueMessage{body: enq.GetBody()}
+	// The index is greater than the IDs of the other messages.
+	q.ready = append(q.ready, index)
+	return &dkvv1.CommandResult{Count: 1}
+}
+
+func (f *FSM) dequeue(deq *dkvv1.DequeueCommand, index int64) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.queues[deq.GetQueue()]
+	if !ok || len(q.ready) == 0 {
+		return &dkvv1.CommandResult{}
+	}
+	id := q.ready[0]
+	q.ready = q.ready[1:]
+	m := q.messages[id]
+	m.claim = index
+	m.claimUntil = deq.GetClaimUntil()
+	m.deliveries++
+	q.claim(id, m)
+	return &dkvv1.CommandResult{Count: 1, Message: m.proto(id)}
+}
+
+// claimed returns the queue and the message of a claim, or nil if the claim
+// expired. The lock must be held.
+func (f *FSM) claimed(c *dkvv1.QueueClaim) (*queue, *queueMessage) {
+	q, ok := f.queues[c.GetQueue()]
+	if !ok {
+		return nil, nil
+	}
+	m, ok := q.messages[c.GetId()]
+	if !ok || m.claim == 0 || m.claim != c.GetClaim() {
+		return nil, nil
+	}
+	return q, m
+}
+
+// ack deletes a claimed message. A message whose claim expired can still be
+// acknowledged until it is requeued.
+func (f *FSM) ack(ack *dkvv1.AckCommand) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := ack.GetClaim()
+	q, m := f.claimed(c)
+	if m == nil {
+		return ErrClaimExpired
+	}
+	q.unclaim(c.GetId(), m)
+	delete(q.messages, c.GetId())
+	if len(q.messages) == 0 {
+		delete(f.queues, c.GetQueue())
+	}
+	return &dkvv1.CommandResult{Count: 1}
+}
+
+// nack requeues claimed messages. Unless only the expired claims are requeued,
+// no message is requeued if a claim expired.
+func (f *FSM) nack(nack *dkvv1.NackCommand, now int64) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !nack.GetExpiredOnly() {
+		for _, c := range nack.GetClaims() {
+			if _, m := f.claimed(c); m == nil {
+				return ErrClaimExpired
+			}
+		}
+	}
+	var count int64
+	for _, c := range nack.GetClaims() {
+		q, m := f.claimed(c)
+		if m == nil || (nack.GetExpiredOnly() && m.claimUntil > now) {
+			continue
+		}
+		q.unclaim(c.GetId(), m)
+		m.claim, m.claimUntil = 0, 0
+		q.requeue(c.GetId())
+		count++
+	}
+	return &dkvv1.CommandResult{Count: count}
+}
+
+// expiredClaims returns at most limit claims expired at the time now. Only the
+// expired claims are read, since the claims are sorted by expiration.
+func (f *FSM) expiredClaims(now int64, limit int) []*dkvv1.QueueClaim {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var claims []*dkvv1.QueueClaim
+	for name, q := range f.queues {
+		for _, e := range q.claims {
+			if len(claims) >= limit {
+				return claims
+			}
+			if e.claimUntil > now {
+				break
+			}
+			claims = append(claims, &dkvv1.QueueClaim{
+				Queue: name,
+				Id:    e.id,
+				Claim: q.messages[e.id].claim,
+			})
+		}
+	}
+	return claims
+}
+
+// peek returns at most limit messages of a queue which are not claimed, and
+// the numbers of ready and claimed messages.
+func (f *FSM) peek(name string, limit int) ([]*dkvv1.QueueMessage, int64, int64) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	q, ok := f.queues[name]
+	if !ok {
+		return nil, 0, 0
+	}
+	messages := make([]*dkvv1.QueueMessage, 0, min(limit, len(q.ready)))
+	for _, id := range q.ready[:min(limit, len(q.ready))] {
+		messages = append(messages, q.messages[id].proto(id))
+	}
+	ready := int64(len(q.ready))
+	return messages, ready, int64(len(q.messages)) - ready
+}
+
+// Enqueue appends a message to a queue, and returns the Raft index of the
+// write, which is the ID of the message.
+func (s *Store) Enqueue(queue, body string) (uint64, error) {
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Enqueue{
+			Enqueue: &dkvv1.EnqueueCommand{Queue: queue, Body: body},
+		},
+	})
+	return res.GetIndex(), err
+}
+
+// Dequeue claims the first message of a queue which is not claimed, for the
+// visibility timeout. It returns the Raft index of the write, and the message
+// or nil if the queue is empty.
+//
+// If the queue is empty, Dequeue waits until a message is enqueued, the wait
+// elapses or the context is done. The queue is read from the local state once
+// the read index of the leader is applied, so that an empty queue is not
+// written to, and the index of an empty dequeue is this read index.
+//
+// Like Increment, the dequeue is not idempotent: a message whose dequeue is
+// retried stays claimed until its visibility timeout expires.
+func (s *Store) Dequeue(
+	ctx context.Context,
+	queue string,
+	visibilityTimeout, wait time.Duration,
+) (uint64, *dkvv1.QueueMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	index, err := s.ReadIndex()
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := s.waitForIndex(index, defaultReadTimeout); err != nil {
+		return 0, nil, err
+	}
+	for {
+		if s.waitForMessage(ctx, queue) != nil {
+			return index, nil, nil
+		}
+		res, err := s.apply(&dkvv1.Command{
+			Command: &dkvv1.Command_Dequeue{
+				Dequeue: &dkvv1.DequeueCommand{
+					Queue:      queue,
+					ClaimUntil: time.Now().Add(visibilityTimeout).UnixNano(),
+				},
+			},
+		})
+		if err != nil || res.GetMessage() != nil {
+			return res.GetIndex(), res.GetMessage(), err
+		}
+		// The message was claimed by another consumer meanwhile.
+		index = res.GetIndex()
+	}
+}
+
+// waitForMessage waits until the local state has a message of the queue which
+// is not claimed, or until the context is done.
+func (s *Store) waitForMessage(ctx context.Context, queue string) error {
+	for {
+		_, appliedCh := s.fsm.applied()
+		if _, ready, _ := s.fsm.peek(queue, 0); ready > 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-appliedCh:
+		}
+	}
+}
+
+// Ack deletes a claimed message, and returns the Raft index of the write.
+// ErrClaimExpired is returned if the message was requeued or acknowledged.
+func (s *Store) Ack(queue string, id, claim int64) (uint64, error) {
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Ack{
+			Ack: &dkvv1.AckCommand{
+				Claim: &dkvv1.QueueClaim{Queue: queue, Id: id, Claim: claim},
+			},
+		},
+	})
+	return res.GetIndex(), err
+}
+
+// Nack requeues a claimed message at its position, and returns the Raft index
+// of the write. ErrClaimExpired is returned if the message was requeued or
+// acknowledged.
+func (s *Store) Nack(queue string, id, claim int64) (uint64, error) {
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Nack{
+			Nack: &dkvv1.NackCommand{
+				Claims: []*dkvv1.QueueClaim{{Queue: queue, Id: id, Claim: claim}},
+			},
+		},
+	})
+	return res.GetIndex(), err
+}
+
+// Peek returns at most limit messages of a queue which are not claimed from the
+// local state, and the numbers of ready and claimed messages.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) Peek(
+	queue string,
+	limit int,
+	opts store.ReadOptions,
+) ([]*dkvv1.QueueMessage, int64, int64, error) {
+	if err := s.prepareRead(opts); err != nil {
+		return nil, 0, 0, err
+	}
+	messages, ready, claimed := s.fsm.peek(queue, limit)
+	return messages, ready, claimed, nil
+}
+
+// requeueExpired requeues the messages whose claim expired at the time now.
+func (s *Store) requeueExpired(now time.Time) {
+	claims := s.fsm.expiredClaims(now.UnixNano(), maxRequeuedClaims)
+	if len(claims) == 0 {
+		return
+	}
+	if _, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Nack{
+			Nack: &dkvv1.NackCommand{Claims: claims, ExpiredOnly: true},
+		},
+	}); err != nil {
+		slog.Error("failed to requeue expired claims", "error", err)
+	}
+}
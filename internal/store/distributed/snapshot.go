@@ -0,0 +1,105 @@
+package distributed
+
+import (
+	"distributed-kv/internal/raftpebble"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/raft"
+)
+
+// ErrExistingState is returned when restoring a backup over an existing Raft state.
+var ErrExistingState = errors.New("raft state already exists")
+
+// Snapshot takes a snapshot of the FSM and opens it.
+//
+// If nothing changed since the last snapshot, the last snapshot is opened.
+func (s *Store) Snapshot() (*raft.SnapshotMeta, io.ReadCloser, error) {
+	future := s.raft.Snapshot()
+	err := future.Error()
+	if err == nil {
+		return future.Open()
+	}
+	if !errors.Is(err, raft.ErrNothingNewToSnapshot) {
+		return nil, nil, err
+	}
+
+	snapshots, err := s.snapshots.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil, raft.ErrNothingNewToSnapshot
+	}
+	// The snapshots are sorted from the most recent.
+	return s.snapshots.Open(snapshots[0].ID)
+}
+
+// Restore initializes the Raft state of a node from a snapshot of the FSM.
+//
+// The node is restored as the single voter of a new cluster, at the index and
+// term of the snapshot. The other nodes must join it with an empty state.
+func Restore(
+	raftDir string,
+	id raft.ServerID,
+	addr raft.ServerAddress,
+	index, term uint64,
+	data io.Reader,
+) (err error) {
+	fss, err := raft.NewFileSnapshotStore(raftDir, retainSnapshotCount, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("file snapshot store: %s", err)
+	}
+	ldb, err := raftpebble.New(raftpebble.WithDBDirPath(filepath.Join(raftDir, "logs.dat")))
+	if err != nil {
+		return fmt.Errorf("new pebble: %s", err)
+	}
+	defer func() {
+		err = errors.Join(err, ldb.Close())
+	}()
+	sdb, err := raftpebble.New(raftpebble.WithDBDirPath(filepath.Join(raftDir, "stable.dat")))
+	if err != nil {
+		return fmt.Errorf("new pebble: %s", err)
+	}
+	defer func() {
+		err = errors.Join(err, sdb.Close())
+	}()
+
+	hasState, err := raft.HasExistingState(ldb, sdb, fss)
+	if err != nil {
+		return err
+	}
+	if hasState {
+		return fmt.Errorf("%w in %s", ErrExistingState, raftDir)
+	}
+
+	configuration := raft.Configuration{
+		Servers: []raft.Server{
+			{
+				Suffrage: raft.Voter,
+				ID:       id,
+				Address:  addr,
+			},
+		},
+	}
+	// The transport only encodes the legacy peers of the snapshot, which
+	// NetworkTransport encodes the same way.
+	_, trans := raft.NewInmemTransport(addr)
+	defer trans.Close()
+	sink, err := fss.Create(raft.SnapshotVersionMax, index, term, configuration, index, trans)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(sink, data); err != nil {
+		return errors.Join(err, sink.Cancel())
+	}
+	if err := sink.Close(); err != nil {
+		return err
+	}
+
+	// The current term cannot be older than the term of the snapshot.
+	return sdb.SetUint64([]byte("CurrentTerm"), term)
+}
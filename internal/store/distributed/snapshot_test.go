@@ -0,0 +1,103 @@
+package distributed_test
+
+import (
+	"bytes"
+	"distributed-kv/internal/store"
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/store/persisted"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func newSingleNodeStore(t *testing.T, dir, addr string, bootstrap bool) *distributed.Store {
+	t.Helper()
+
+	store := persisted.New(dir)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	s := distributed.NewStore(dir, addr, "node0", raft.ServerAddress(addr), store)
+	t.Cleanup(func() {
+		require.NoError(t, s.Shutdown())
+	})
+	require.NoError(t, s.Open(bootstrap))
+	_, err := s.WaitForLeader(5 * time.Second)
+	require.NoError(t, err)
+	return s
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	s := newSingleNodeStore(t, t.TempDir(), getRandomAddress(t), true)
+	index, err := s.Set("key", "value")
+	require.NoError(t, err)
+
+	// Act
+	meta, snapshot, err := s.Snapshot()
+	require.NoError(t, err)
+	data, err := io.ReadAll(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Close())
+
+	// Assert
+	require.NotZero(t, meta.Index)
+	require.Equal(t, fmt.Sprintf(
+		"key,value\n\x00revision/key,%[1]d %[1]d 1\n\x00applied,%[1]d\n", index,
+	), string(data))
+
+	t.Run("Nothing new to snapshot", func(t *testing.T) {
+		// Act
+		again, snapshot, err := s.Snapshot()
+		require.NoError(t, err)
+		require.NoError(t, snapshot.Close())
+
+		// Assert
+		require.Equal(t, meta.Index, again.Index)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		addr := getRandomAddress(t)
+
+		// Act
+		err := distributed.Restore(
+			dir,
+			"node0",
+			raft.ServerAddress(addr),
+			meta.Index,
+			meta.Term,
+			bytes.NewReader(data),
+		)
+		require.NoError(t, err)
+		restored := newSingleNodeStore(t, dir, addr, false)
+
+		// Assert
+		got, err := restored.Get("key", store.ReadOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "value", got)
+		index, err := restored.Set("key", "value2")
+		require.NoError(t, err)
+		require.Greater(t, index, meta.Index)
+	})
+
+	t.Run("Restore over an existing state", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		err := distributed.Restore(dir, "node0", "", meta.Index, meta.Term, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		// Act
+		err = distributed.Restore(dir, "node0", "", meta.Index, meta.Term, bytes.NewReader(data))
+
+		// Assert
+		require.ErrorIs(t, err, distributed.ErrExistingState)
+	})
+}
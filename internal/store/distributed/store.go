@@ -3,13 +3,18 @@ package distributed
 import (
 	"crypto/tls"
 	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/mux"
 	"distributed-kv/internal/raftpebble"
+	"distributed-kv/internal/store"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/raft"
@@ -18,8 +23,31 @@ import (
 
 const (
 	retainSnapshotCount = 2
+	// defaultReadTimeout is the default maximum duration to wait for the
+	// consistency token of a read.
+	defaultReadTimeout = 5 * time.Second
+	// maxExpiredKeys is the maximum number of expired keys deleted by a
+	// command.
+	maxExpiredKeys = 1000
 )
 
+// commandErrors are the errors of the FSM which are identified by their
+// message, since the results of the commands are serialized.
+var commandErrors = []error{
+	store.ErrNotInteger,
+	store.ErrOverflow,
+	store.ErrOutOfRange,
+	store.ErrNotFound,
+	ErrCompacted,
+	ErrFutureRevision,
+	ErrClaimExpired,
+	store.ErrWrongType,
+}
+
+// ErrNoLeaderAPI is returned by the function set by WithLeaderReadIndex if the
+// API address of the leader is unknown.
+var ErrNoLeaderAPI = errors.New("unknown API address of the leader")
+
 type Store struct {
 	// RaftDir is the directory where the Stable and Logs data is stored.
 	RaftDir string
@@ -30,8 +58,18 @@ type Store struct {
 	// RaftAdvertisedAddr is the address other nodes should use to communicate with this node.
 	RaftAdvertisedAddr raft.ServerAddress
 
-	fsm  *FSM
-	raft *raft.Raft
+	fsm       *FSM
+	raft      *raft.Raft
+	transport *raft.NetworkTransport
+	snapshots raft.SnapshotStore
+	logs      *raftpebble.PebbleKVStore
+	stable    *raftpebble.PebbleKVStore
+	layer     raft.StreamLayer
+	verifier  *PeerVerifier
+	stats     *statsTransport
+	autopilot *autopilot
+	// clusterToken is the persisted cluster token.
+	clusterToken string
 
 	shutdownCh chan struct{}
 
@@ -42,6 +80,15 @@ type StoreOptions struct {
 	serverTLSConfig *tls.Config
 	clientTLSConfig *tls.Config
 	raftConfig      *raft.Config
+	allowedPeers    []string
+	mux             *mux.Mux
+	forceNewCluster bool
+	clusterToken    string
+	autopilot       *AutopilotConfig
+	zone            string
+	maxReadLag      uint64
+	wrapLayer       func(raft.StreamLayer) raft.StreamLayer
+	leaderReadIndex func(id raft.ServerID, timeout time.Duration) (uint64, error)
 }
 
 type StoreOption func(*StoreOptions)
@@ -64,6 +111,78 @@ func WithRaftConfig(config *raft.Config) StoreOption {
 	}
 }
 
+// WithAllowedPeers sets the peer identities which are accepted even if they are
+// not members of the cluster.
+//
+// Peer identities are only verified if the server TLS configuration verifies
+// client certificates.
+func WithAllowedPeers(peers ...string) StoreOption {
+	return func(o *StoreOptions) {
+		o.allowedPeers = peers
+	}
+}
+
+// WithMux tunnels the Raft traffic through the multiplexer instead of
+// listening on RaftBind.
+//
+// The TLS connections are terminated by the multiplexer, which means that
+// the server TLS configuration is ignored.
+func WithMux(m *mux.Mux) StoreOption {
+	return func(o *StoreOptions) {
+		o.mux = m
+	}
+}
+
+// WithForceNewCluster rewrites the Raft configuration to contain only the
+// local node when opening the store.
+//
+// This recovers a cluster which permanently lost its quorum. The other nodes
+// must join again with an empty state. The configuration is only rewritten
+// once: the next openings with the option keep it, until the store is opened
+// without the option.
+func WithForceNewCluster(force bool) StoreOption {
+	return func(o *StoreOptions) {
+		o.forceNewCluster = force
+	}
+}
+
+// WithClusterToken sets the token identifying the cluster.
+//
+// The token is persisted when the store is opened for the first time, and
+// exchanged with the peers, which are rejected if their token differs.
+func WithClusterToken(token string) StoreOption {
+	return func(o *StoreOptions) {
+		o.clusterToken = token
+	}
+}
+
+// WithMaxReadLag sets the maximum number of committed entries that a follower
+// may not have applied when serving a read with a maximum staleness.
+func WithMaxReadLag(entries uint64) StoreOption {
+	return func(o *StoreOptions) {
+		o.maxReadLag = entries
+	}
+}
+
+// WithStreamLayer wraps the stream layer of the Raft transport, for example to
+// inject network faults in tests.
+func WithStreamLayer(wrap func(raft.StreamLayer) raft.StreamLayer) StoreOption {
+	return func(o *StoreOptions) {
+		o.wrapLayer = wrap
+	}
+}
+
+// WithLeaderReadIndex sets how a follower gets the read index of the leader,
+// usually through the API of the leader, which calls LeaderReadIndex.
+//
+// If get returns ErrNoLeaderAPI, or without this option, the follower commits
+// a barrier through the leader instead, which costs a write to the Raft log.
+func WithLeaderReadIndex(get func(id raft.ServerID, timeout time.Duration) (uint64, error)) StoreOption {
+	return func(o *StoreOptions) {
+		o.leaderReadIndex = get
+	}
+}
+
 func applyStoreOptions(opts []StoreOption) StoreOptions {
 	options := StoreOptions{
 		raftConfig: raft.DefaultConfig(),
@@ -93,6 +212,10 @@ func NewStore(
 }
 
 func (s *Store) Open(bootstrap bool) error {
+	if len(s.StoreOptions.clusterToken) > maxClusterTokenLength {
+		return fmt.Errorf("cluster token is longer than %d bytes", maxClusterTokenLength)
+	}
+
 	// Setup Raft configuration.
 	config := s.raftConfig
 	config.LocalID = raft.ServerID(s.RaftID)
@@ -112,25 +235,73 @@ func (s *Store) Open(bootstrap bool) error {
 	if err != nil {
 		return fmt.Errorf("new pebble: %s", err)
 	}
+	s.clusterToken, err = loadClusterToken(sdb, s.StoreOptions.clusterToken)
+	if err != nil {
+		return errors.Join(err, ldb.Close(), sdb.Close())
+	}
 
 	// Instantiate the transport.
-	lis, err := net.Listen("tcp", s.RaftBind)
-	if err != nil {
-		return err
+	var lis net.Listener
+	serverTLSConfig := s.serverTLSConfig
+	if s.mux != nil {
+		lis = s.mux.RaftListener()
+		serverTLSConfig = s.mux.TLSConfig
+	} else {
+		lis, err = net.Listen("tcp", s.RaftBind)
+		if err != nil {
+			return err
+		}
+	}
+	var rp atomic.Pointer[raft.Raft]
+	var verifier *PeerVerifier
+	if serverTLSConfig != nil && serverTLSConfig.ClientAuth >= tls.VerifyClientCertIfGiven {
+		verifier = &PeerVerifier{
+			AllowedPeers: s.allowedPeers,
+			Servers: func() ([]raft.Server, error) {
+				ra := rp.Load()
+				if ra == nil {
+					return nil, nil
+				}
+				configFuture := ra.GetConfiguration()
+				if err := configFuture.Error(); err != nil {
+					return nil, err
+				}
+				return configFuture.Configuration().Servers, nil
+			},
+		}
 	}
-	transport := raft.NewNetworkTransport(&TLSStreamLayer{
+	s.verifier = verifier
+	s.layer = &TLSStreamLayer{
 		Listener:          lis,
 		AdvertizedAddress: raft.ServerAddress(s.RaftAdvertisedAddr),
 		ServerTLSConfig:   s.serverTLSConfig,
 		ClientTLSConfig:   s.clientTLSConfig,
-	}, 3, 10*time.Second, os.Stderr)
+		PeerVerifier:      verifier,
+		Multiplexed:       s.mux != nil,
+		ClusterToken:      s.clusterToken,
+	}
+	if s.wrapLayer != nil {
+		s.layer = s.wrapLayer(s.layer)
+	}
+	transport := raft.NewNetworkTransport(s.layer, 3, 10*time.Second, os.Stderr)
+
+	s.transport = transport
+	s.stats = newStatsTransport(transport)
+	s.snapshots = fss
+	s.logs = ldb
+	s.stable = sdb
+
+	if err := s.forceNewClusterOnce(ldb, sdb, fss); err != nil {
+		return fmt.Errorf("force new cluster: %w", err)
+	}
 
 	// Instantiate the Raft systems.
-	ra, err := raft.NewRaft(config, s.fsm, ldb, sdb, fss, transport)
+	ra, err := raft.NewRaft(config, &resultFSM{s.fsm}, ldb, sdb, fss, s.stats)
 	if err != nil {
 		return fmt.Errorf("new raft: %s", err)
 	}
 	s.raft = ra
+	rp.Store(ra)
 
 	// Check if there is an existing state, if not bootstrap.
 	hasState, err := raft.HasExistingState(
@@ -157,18 +328,116 @@ func (s *Store) Open(bootstrap bool) error {
 				},
 			},
 		}
-		err = s.raft.BootstrapCluster(config).Error()
+		if err := s.raft.BootstrapCluster(config).Error(); err != nil {
+			return err
+		}
+	}
+
+	// The autopilot always tracks the health of the cluster. The removal and
+	// promotion of servers are only enabled by WithAutopilot.
+	var autopilotConfig AutopilotConfig
+	if s.StoreOptions.autopilot != nil {
+		autopilotConfig = *s.StoreOptions.autopilot
+	}
+	s.autopilot = newAutopilot(s, autopilotConfig)
+	go s.autopilot.run()
+	return nil
+}
+
+// keyForcedNewCluster is the key of the marker of a forced new cluster in the
+// stable store.
+var keyForcedNewCluster = []byte("ForcedNewCluster")
+
+// forceNewClusterOnce recovers the cluster if a new cluster is forced, and
+// marks the recovery in the stable store. While the marker is set, the node
+// restarts without recovering the cluster again, which would discard the
+// members which joined since. The marker is cleared once the node starts
+// without forcing a new cluster.
+func (s *Store) forceNewClusterOnce(
+	logs raft.LogStore,
+	stable *raftpebble.PebbleKVStore,
+	snaps raft.SnapshotStore,
+) error {
+	forced, err := stable.GetUint64(keyForcedNewCluster)
+	if err != nil && !errors.Is(err, raftpebble.ErrKeyNotFound) {
+		return err
+	}
+	switch {
+	case !s.forceNewCluster && forced != 0:
+		return stable.SetUint64(keyForcedNewCluster, 0)
+	case !s.forceNewCluster:
+		return nil
+	case forced != 0:
+		slog.Warn(
+			"a new cluster was already forced, the raft configuration is kept: " +
+				"restart without forcing a new cluster to allow another recovery",
+		)
+		return nil
+	}
+	if err := s.recoverCluster(logs, stable, snaps); err != nil {
+		return err
+	}
+	return stable.SetUint64(keyForcedNewCluster, 1)
+}
+
+// recoverCluster replaces the Raft configuration by the local node.
+func (s *Store) recoverCluster(
+	logs raft.LogStore,
+	stable raft.StableStore,
+	snaps raft.SnapshotStore,
+) error {
+	slog.Warn(
+		"FORCING A NEW CLUSTER: the raft configuration is replaced by the local node only",
+		"id", s.RaftID,
+		"addr", s.transport.LocalAddr(),
+	)
+	slog.Warn(
+		"FORCING A NEW CLUSTER: uncommitted log entries are committed, other nodes must rejoin with an empty state",
+	)
+	configuration := raft.Configuration{
+		Servers: []raft.Server{
+			{
+				Suffrage: raft.Voter,
+				ID:       raft.ServerID(s.RaftID),
+				Address:  s.transport.LocalAddr(),
+			},
+		},
+	}
+	// The FSM is left in an unusable state, but it is cleared when Raft
+	// restores the recovered snapshot.
+	if err := raft.RecoverCluster(
+		s.raftConfig,
+		s.fsm,
+		logs,
+		stable,
+		snaps,
+		s.transport,
+		configuration,
+	); err != nil {
+		return err
 	}
-	return err
+	slog.Warn("FORCED A NEW CLUSTER: the local node is the only member", "id", s.RaftID)
+	return nil
 }
 
-func (s *Store) Join(id raft.ServerID, addr raft.ServerAddress) error {
+// Join adds a server to the cluster and returns the Raft index of the
+// configuration change.
+//
+// The server joins as a non-voter if a server stabilization time is configured
+// or a zone is replicated, and is promoted by the autopilot once stable.
+func (s *Store) Join(id raft.ServerID, addr raft.ServerAddress) (uint64, error) {
 	slog.Info("request node to join", "id", id, "addr", addr)
 
+	// The token is checked before any change of the configuration, so that a
+	// server of another cluster cannot replace a member.
+	if err := s.verifyClusterToken(addr); err != nil {
+		return 0, err
+	}
+
 	configFuture := s.raft.GetConfiguration()
 	if err := configFuture.Error(); err != nil {
 		slog.Error("failed to get raft configuration", "error", err)
-		return err
+		return 0, err
 	}
 	// Check if the server has already joined
 	for _, srv := range configFuture.Configuration().Servers {
@@ -185,22 +454,74 @@ func (s *Store) Join(id raft.ServerID, addr raft.ServerAddress) error {
 					"addr",
 					addr,
 				)
-				return nil
+				return 0, nil
 			}
 
 			if err := s.raft.RemoveServer(id, 0, 0).Error(); err != nil {
-				return fmt.Errorf("error removing existing node %s at %s: %s", id, addr, err)
+				return 0, fmt.Errorf("error removing existing node %s at %s: %s", id, addr, err)
 			}
 		}
 	}
 
 	// Add the new server
-	return s.raft.AddVoter(id, addr, 0, 0).Error()
+	var future raft.IndexFuture
+	if s.autopilot != nil && (s.autopilot.config.ServerStabilizationTime > 0 || len(s.fsm.Zones()) > 0) {
+		future = s.raft.AddNonvoter(id, addr, 0, 0)
+	} else {
+		future = s.raft.AddVoter(id, addr, 0, 0)
+	}
+	if err := future.Error(); err != nil {
+		return 0, err
+	}
+	return future.Index(), nil
+}
+
+// verifyClusterToken checks that the server at addr belongs to the cluster.
+func (s *Store) verifyClusterToken(addr raft.ServerAddress) error {
+	if s.clusterToken == "" {
+		return nil
+	}
+	conn, err := s.layer.Dial(addr, clusterTokenTimeout)
+	if err != nil {
+		return fmt.Errorf("refusing to join %s: %w", addr, err)
+	}
+	return conn.Close()
+}
+
+// AllowPeers adds peer identities which are accepted even if they are not
+// members of the cluster, for example discovered peers which are about to be
+// joined.
+func (s *Store) AllowPeers(identities ...string) {
+	if s.verifier != nil {
+		s.verifier.Allow(identities...)
+	}
+}
+
+// ClusterToken returns the token identifying the cluster, if any.
+func (s *Store) ClusterToken() string {
+	return s.clusterToken
 }
 
-func (s *Store) Leave(id raft.ServerID) error {
+// Leave removes a server from the cluster and returns the Raft index of the
+// configuration change.
+//
+// The replicated zone of the server is deleted too.
+func (s *Store) Leave(id raft.ServerID) (uint64, error) {
 	slog.Info("request node to leave", "id", id)
-	return s.raft.RemoveServer(id, 0, 0).Error()
+	future := s.raft.RemoveServer(id, 0, 0)
+	if err := future.Error(); err != nil {
+		return 0, err
+	}
+	if _, ok := s.fsm.Zones()[id]; ok {
+		if _, err := s.apply(&dkvv1.Command{
+			Command: &dkvv1.Command_ServerMetadata{
+				ServerMetadata: &dkvv1.ServerMetadata{Id: string(id)},
+			},
+		}); err != nil {
+			slog.Error("failed to delete the zone of the removed server", "id", id, "error", err)
+		}
+	}
+	return future.Index(), nil
 }
 
 func (s *Store) WaitForLeader(timeout time.Duration) (raft.ServerID, error) {
@@ -231,12 +552,25 @@ func (s *Store) Shutdown() error {
 	default:
 	}
 
+	if s.autopilot != nil {
+		s.autopilot.shutdown()
+		s.autopilot = nil
+	}
 	if s.raft != nil {
 		if err := s.raft.Shutdown().Error(); err != nil {
 			return err
 		}
 		s.raft = nil
 	}
+	// The stores are closed so that the node can be reopened.
+	for _, db := range []*raftpebble.PebbleKVStore{s.logs, s.stable} {
+		if db != nil {
+			if err := db.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	s.logs, s.stable = nil, nil
 	s.fsm.storer.Clear()
 	return nil
 }
@@ -245,35 +579,144 @@ func (s *Store) ShutdownCh() <-chan struct{} {
 	return s.shutdownCh
 }
 
-func (s *Store) apply(req *dkvv1.Command) (any, error) {
+func (s *Store) apply(req *dkvv1.Command) (*dkvv1.CommandResult, error) {
+	req.Time = time.Now().UnixNano()
 	b, err := proto.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 	addr, id := s.raft.LeaderWithID()
 	if addr == "" || id == "" {
-		return nil, errors.New("no leader")
+		return nil, store.ErrNoLeader
 	}
 	timeout := 10 * time.Second
 
+	var res any
 	if id != raft.ServerID(s.RaftID) {
 		slog.Warn("forwarding apply to leader", "leader", id, "addr", addr)
-		return nil, s.raft.ForwardApply(id, addr, b, timeout)
+		res, err = s.forwardApply(id, addr, b, timeout)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		future := s.raft.Apply(b, timeout)
+		if err := future.Error(); err != nil {
+			return nil, err
+		}
+		res = future.Response()
 	}
+	return decodeCommandResult(res)
+}
 
-	future := s.raft.Apply(b, timeout)
-	if err := future.Error(); err != nil {
+// forwardApply forwards the command to the leader.
+//
+// Unlike raft.ForwardApply, the response of the FSM is returned.
+func (s *Store) forwardApply(
+	id raft.ServerID,
+	addr raft.ServerAddress,
+	command []byte,
+	timeout time.Duration,
+) (any, error) {
+	var resp raft.ForwardApplyResponse
+	if err := s.transport.ForwardApply(id, addr, &raft.ForwardApplyRequest{
+		RPCHeader: raft.RPCHeader{
+			ProtocolVersion: s.raftConfig.ProtocolVersion,
+			ID:              []byte(s.RaftID),
+			Addr:            s.transport.EncodePeer(raft.ServerID(s.RaftID), s.transport.LocalAddr()),
+		},
+		Command: command,
+		Timeout: timeout,
+	}, &resp); err != nil {
 		return nil, err
 	}
-	res := future.Response()
-	if err, ok := res.(error); ok {
-		return nil, err
+	return resp.Response, nil
+}
+
+// checkKeys returns store.ErrReservedKey if a key has the reserved prefix, so
+// that the keys cannot be confused with the metadata of the snapshots, the
+// entries of the collections or the leases.
+func checkKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, store.ReservedPrefix) {
+			return fmt.Errorf("%w: %q", store.ErrReservedKey, key)
+		}
 	}
-	return res, nil
+	return nil
+}
+
+// checkRange checks the key of a range like checkKeys. The key "\0" with a
+// range end is the first key, which the etcd clients send for the ranges from
+// the first key, and is allowed.
+func checkRange(key, rangeEnd []byte) error {
+	if len(rangeEnd) > 0 && string(key) == store.ReservedPrefix {
+		return nil
+	}
+	return checkKeys(string(key))
 }
 
-func (s *Store) Set(key string, value string) error {
-	_, err := s.apply(&dkvv1.Command{
+// checkTxnKeys checks the keys of the comparisons and of the operations of a
+// transaction and of its nested transactions, like checkKeys and checkRange.
+// The ranges starting before the reserved keys skip them.
+func checkTxnKeys(txn *dkvv1.TxnCommand) error {
+	for _, c := range txn.GetCompare() {
+		if err := checkRange(c.GetKey(), c.GetRangeEnd()); err != nil {
+			return err
+		}
+	}
+	for _, op := range append(slices.Clone(txn.GetSuccess()), txn.GetFailure()...) {
+		switch op := op.GetOperation().(type) {
+		case *dkvv1.Operation_Range:
+			if err := checkRange(op.Range.GetKey(), op.Range.GetRangeEnd()); err != nil {
+				return err
+			}
+		case *dkvv1.Operation_Put:
+			if err := checkKeys(string(op.Put.GetKey())); err != nil {
+				return err
+			}
+		case *dkvv1.Operation_DeleteRange:
+			if err := checkRange(op.DeleteRange.GetKey(), op.DeleteRange.GetRangeEnd()); err != nil {
+				return err
+			}
+		case *dkvv1.Operation_Txn:
+			if err := checkTxnKeys(op.Txn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeCommandResult(res any) (*dkvv1.CommandResult, error) {
+	switch res := res.(type) {
+	case error:
+		return nil, res
+	case []byte:
+		var result dkvv1.CommandResult
+		if err := proto.Unmarshal(res, &result); err != nil {
+			return nil, err
+		}
+		if msg := result.GetError(); msg != "" {
+			for _, err := range commandErrors {
+				if err.Error() == msg {
+					return &result, err
+				}
+			}
+			return &result, errors.New(msg)
+		}
+		return &result, nil
+	default:
+		// Errors of the leader are not serializable and are received as empty
+		// values.
+		return nil, fmt.Errorf("apply failed on leader: %v", res)
+	}
+}
+
+// Set sets the value of a key and returns the Raft index of the write.
+func (s *Store) Set(key string, value string) (uint64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, err
+	}
+	res, err := s.apply(&dkvv1.Command{
 		Command: &dkvv1.Command_Set{
 			Set: &dkvv1.SetRequest{
 				Key:   key,
@@ -281,28 +724,342 @@ func (s *Store) Set(key string, value string) error {
 			},
 		},
 	})
-	return err
+	return res.GetIndex(), err
 }
 
-func (s *Store) Delete(key string) error {
-	_, err := s.apply(&dkvv1.Command{
+// Delete deletes a key and returns the Raft index of the write.
+func (s *Store) Delete(key string) (uint64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, err
+	}
+	res, err := s.apply(&dkvv1.Command{
 		Command: &dkvv1.Command_Delete{
 			Delete: &dkvv1.DeleteRequest{
 				Key: key,
 			},
 		},
 	})
-	return err
+	return res.GetIndex(), err
+}
+
+// SetKeys sets the values of keys atomically if the condition of the options
+// holds. It returns the Raft index of the write, and whether the keys were
+// written.
+func (s *Store) SetKeys(entries []store.KeyValue, opts store.SetOptions) (uint64, bool, error) {
+	for _, entry := range entries {
+		if err := checkKeys(entry.Key); err != nil {
+			return 0, false, err
+		}
+	}
+	return s.setKeys(entries, opts)
+}
+
+// setKeys is SetKeys without the check of the keys.
+func (s *Store) setKeys(entries []store.KeyValue, opts store.SetOptions) (uint64, bool, error) {
+	put := &dkvv1.PutCommand{Entries: make([]*dkvv1.KeyValue, 0, len(entries))}
+	for _, entry := range entries {
+		put.Entries = append(put.Entries, &dkvv1.KeyValue{Key: entry.Key, Value: entry.Value})
+	}
+	switch {
+	case opts.IfAbsent && opts.IfExists:
+		return 0, false, errors.New("a write cannot require keys to be both absent and existing")
+	case opts.IfAbsent:
+		put.Condition = dkvv1.PutCommand_CONDITION_ABSENT
+	case opts.IfExists:
+		put.Condition = dkvv1.PutCommand_CONDITION_EXISTS
+	}
+	if opts.TTL > 0 {
+		put.ExpireAt = time.Now().Add(opts.TTL).UnixNano()
+	}
+	res, err := s.apply(&dkvv1.Command{Command: &dkvv1.Command_Put{Put: put}})
+	return res.GetIndex(), res.GetCount() > 0, err
+}
+
+// DeleteKeys deletes keys and their collections atomically. It returns the Raft
+// index of the write, and the number of deleted keys which existed.
+func (s *Store) DeleteKeys(keys ...string) (uint64, int64, error) {
+	if err := checkKeys(keys...); err != nil {
+		return 0, 0, err
+	}
+	return s.deleteKeys(keys...)
+}
+
+// deleteKeys is DeleteKeys without the check of the keys.
+func (s *Store) deleteKeys(keys ...string) (uint64, int64, error) {
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_DeleteKeys{
+			DeleteKeys: &dkvv1.DeleteKeysCommand{Keys: keys},
+		},
+	})
+	return res.GetIndex(), res.GetCount(), err
+}
+
+// Increment adds delta to the integer value of a key, and returns the Raft
+// index of the write and the new value. A missing key is the initial value of
+// the options.
+//
+// The increment is not idempotent: it must not be retried if its outcome is
+// unknown. store.ErrNoLeader is only returned before the increment is
+// proposed.
+func (s *Store) Increment(key string, delta int64, opts store.IncrementOptions) (uint64, int64, error) {
+	if err := checkKeys(key); err != nil {
+		return 0, 0, err
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Increment{
+			Increment: &dkvv1.IncrementCommand{
+				Key:     key,
+				Delta:   delta,
+				Initial: opts.Initial,
+				Min:     opts.Min,
+				Max:     opts.Max,
+			},
+		},
+	})
+	return res.GetIndex(), res.GetValue(), err
+}
+
+// Txn executes a transaction atomically, like etcd, and returns the Raft index
+// of the write, which is the revision of the written keys.
+//
+// store.ErrNotFound is returned if a put ignoring the value targets a missing
+// key, and nothing is written.
+func (s *Store) Txn(txn *dkvv1.TxnCommand) (uint64, *dkvv1.TxnResult, error) {
+	if err := checkTxnKeys(txn); err != nil {
+		return 0, nil, err
+	}
+	res, err := s.apply(&dkvv1.Command{Command: &dkvv1.Command_Txn{Txn: txn}})
+	return res.GetIndex(), res.GetTxn(), err
+}
+
+// ReadIndex returns the read index of the leader. A read served by any node
+// once this index is applied is linearizable.
+//
+// A follower gets the index from the leader with the function set by
+// WithLeaderReadIndex. Otherwise, it commits a no-op command through the
+// leader, and returns its index.
+func (s *Store) ReadIndex() (uint64, error) {
+	_, id := s.raft.LeaderWithID()
+	if id == "" {
+		return 0, store.ErrNoLeader
+	}
+	if id == raft.ServerID(s.RaftID) {
+		return s.LeaderReadIndex()
+	}
+	if s.leaderReadIndex != nil {
+		index, err := s.leaderReadIndex(id, defaultReadTimeout)
+		if !errors.Is(err, ErrNoLeaderAPI) {
+			return index, err
+		}
+	}
+	res, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_Barrier{Barrier: &dkvv1.BarrierCommand{}},
+	})
+	return res.GetIndex(), err
 }
 
-func (s *Store) Get(key string) (string, error) {
-	return s.fsm.storer.Get(key)
+// LeaderReadIndex confirms the leadership of the local node with a quorum, and
+// returns its read index, without writing to the Raft log. raft.ErrNotLeader
+// is returned by the followers.
+//
+// The read index is the last index of the log when the read started, which
+// includes the entries committed by the previous leaders, unlike the commit
+// index of a new leader. It is applied once it is committed.
+func (s *Store) LeaderReadIndex() (uint64, error) {
+	index, err := s.lastFSMIndex(s.raft.LastIndex())
+	if err != nil {
+		return 0, err
+	}
+	if err := s.raft.VerifyLeader().Error(); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// lastFSMIndex returns the index of the last log entry up to the index which
+// is applied by the FSM. The no-op entries of the new leaders are skipped:
+// Raft never applies them to the FSM, and the state is the same without them.
+// The entries compacted into a snapshot were applied by the FSM.
+func (s *Store) lastFSMIndex(index uint64) (uint64, error) {
+	for ; index > 0; index-- {
+		var l raft.Log
+		err := s.logs.GetLog(index, &l)
+		if errors.Is(err, raft.ErrLogNotFound) {
+			return index, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if l.Type == raft.LogCommand || l.Type == raft.LogConfiguration {
+			return index, nil
+		}
+	}
+	return 0, nil
+}
+
+// deleteExpired deletes the keys expired at the time now. Expired keys are
+// ignored by the reads until they are deleted.
+func (s *Store) deleteExpired(now time.Time) {
+	keys := s.fsm.expiredKeys(now.UnixNano(), maxExpiredKeys)
+	if len(keys) == 0 {
+		return
+	}
+	if _, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_DeleteKeys{
+			DeleteKeys: &dkvv1.DeleteKeysCommand{Keys: keys, ExpiredOnly: true},
+		},
+	}); err != nil {
+		slog.Error("failed to delete expired keys", "error", err)
+	}
+}
+
+// Get gets the value of a key from the local state.
+//
+// With a maximum staleness, a follower only serves the read if it was contacted
+// by the leader within this duration, and if it applied the entries committed
+// by the leader, up to the maximum read lag. With a consistency token, the read
+// is served once the token is applied. Otherwise, store.ErrStaleRead is
+// returned.
+//
+// A linearizable read is served by the leader once it confirmed its leadership
+// and applied its read index. Otherwise, raft.ErrNotLeader is returned.
+//
+// store.ErrNotFound is returned if the key does not exist or is expired, and
+// store.ErrReservedKey if it is reserved.
+func (s *Store) Get(key string, opts store.ReadOptions) (string, error) {
+	if err := checkKeys(key); err != nil {
+		return "", err
+	}
+	if err := s.prepareRead(opts); err != nil {
+		return "", err
+	}
+	return s.fsm.get(key, time.Now().UnixNano())
+}
+
+// Keys returns the keys with the prefix from the local state, in
+// lexicographic order, starting after the key after if it is not empty. At
+// most limit keys are returned if limit is positive. The reserved keys are
+// never listed.
+//
+// The consistency requirements of the read are the ones of Get.
+func (s *Store) Keys(prefix, after string, limit int, opts store.ReadOptions) ([]string, error) {
+	if err := checkKeys(prefix, after); err != nil {
+		return nil, err
+	}
+	if err := s.prepareRead(opts); err != nil {
+		return nil, err
+	}
+	return s.fsm.keys(prefix, after, limit, time.Now().UnixNano())
+}
+
+// Range returns the keys of a range from the local state, like etcd, and the
+// revision of the read.
+//
+// A past revision is only served if no key changed since, otherwise
+// ErrCompacted is returned. The consistency requirements of the read are the
+// ones of Get, and the reserved keys are never listed, like Keys.
+func (s *Store) Range(r *dkvv1.RangeOperation, opts store.ReadOptions) (uint64, *dkvv1.RangeResult, error) {
+	if err := checkRange(r.GetKey(), r.GetRangeEnd()); err != nil {
+		return 0, nil, err
+	}
+	return s.rangeKeys(r, opts)
+}
+
+// rangeKeys is Range without the check of the range.
+func (s *Store) rangeKeys(r *dkvv1.RangeOperation, opts store.ReadOptions) (uint64, *dkvv1.RangeResult, error) {
+	if err := s.prepareRead(opts); err != nil {
+		return 0, nil, err
+	}
+	rev := s.AppliedIndex()
+	result, err := s.fsm.rangeKeys(r, int64(rev), time.Now().UnixNano())
+	return rev, result, err
+}
+
+// prepareRead waits until the local state meets the consistency requirements
+// of a read.
+func (s *Store) prepareRead(opts store.ReadOptions) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultReadTimeout
+	}
+	if opts.Linearizable {
+		index, err := s.LeaderReadIndex()
+		if err != nil {
+			return err
+		}
+		return s.waitForIndex(index, timeout)
+	}
+	if opts.MinIndex > 0 {
+		if err := s.waitForIndex(opts.MinIndex, timeout); err != nil {
+			return err
+		}
+	}
+	if opts.MaxStaleness > 0 && s.raft.State() != raft.Leader {
+		return s.checkStaleness(opts.MaxStaleness)
+	}
+	return nil
+}
+
+// waitForIndex waits until the index is applied by the FSM.
+func (s *Store) waitForIndex(index uint64, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		applied, appliedCh := s.fsm.applied()
+		if applied >= index {
+			return nil
+		}
+		select {
+		case <-appliedCh:
+		case <-timer.C:
+			return fmt.Errorf(
+				"%w: index %d not applied within %s",
+				store.ErrStaleRead,
+				index,
+				timeout,
+			)
+		}
+	}
+}
+
+func (s *Store) checkStaleness(maxStaleness time.Duration) error {
+	lastContact := s.raft.LastContact()
+	if lastContact.IsZero() {
+		return fmt.Errorf("%w: no contact with the leader", store.ErrStaleRead)
+	}
+	if since := time.Since(lastContact); since > maxStaleness {
+		return fmt.Errorf(
+			"%w: last contact with the leader %s ago, max staleness is %s",
+			store.ErrStaleRead,
+			since.Round(time.Millisecond),
+			maxStaleness,
+		)
+	}
+	commitIndex, err := s.lastFSMIndex(s.raft.CommitIndex())
+	if err != nil {
+		return err
+	}
+	appliedIndex, _ := s.fsm.applied()
+	if appliedIndex+s.maxReadLag < commitIndex {
+		return fmt.Errorf(
+			"%w: applied index %d is behind the commit index %d",
+			store.ErrStaleRead,
+			appliedIndex,
+			commitIndex,
+		)
+	}
+	return nil
 }
 
 func (s *Store) GetLeader() (raft.ServerAddress, raft.ServerID) {
 	return s.raft.LeaderWithID()
 }
 
+// IsLeader returns true if the local node is the leader.
+func (s *Store) IsLeader() bool {
+	return s.raft.State() == raft.Leader
+}
+
 func (s *Store) GetServers() ([]raft.Server, error) {
 	configFuture := s.raft.GetConfiguration()
 	if err := configFuture.Error(); err != nil {
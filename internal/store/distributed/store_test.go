@@ -1,17 +1,24 @@
 package distributed_test
 
 import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/mux"
+	"distributed-kv/internal/store"
 	"distributed-kv/internal/store/distributed"
 	"distributed-kv/internal/store/persisted"
 	internaltls "distributed-kv/internal/tls"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -65,6 +72,75 @@ func TestStore(t *testing.T) {
 		require.Equal(t, raft.ServerID("node1"), id)
 	})
 
+	t.Run("Multiplexed", func(t *testing.T) {
+		nodes := 2
+		stores := make([]*distributed.Store, nodes)
+
+		// Arrange
+		for i := 0; i < nodes; i++ {
+			tmp, err := os.MkdirTemp("", "raft-test")
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = os.RemoveAll(tmp)
+			})
+			l, err := net.Listen("tcp", "localhost:0")
+			require.NoError(t, err)
+			m := mux.New(l, nil)
+			go func() {
+				_ = m.Serve()
+			}()
+			t.Cleanup(func() {
+				_ = m.Close()
+			})
+			store := persisted.New(tmp)
+			t.Cleanup(func() {
+				err = store.Close()
+				require.NoError(t, err)
+			})
+			s := distributed.NewStore(
+				tmp,
+				"",
+				fmt.Sprintf("node%d", i),
+				raft.ServerAddress(l.Addr().String()),
+				store,
+				distributed.WithMux(m),
+			)
+			t.Cleanup(func() {
+				err = s.Shutdown()
+				require.NoError(t, err)
+			})
+			stores[i] = s
+		}
+
+		// Act
+		err := stores[0].Open(true)
+		require.NoError(t, err)
+		_, err = stores[0].WaitForLeader(5 * time.Second)
+		require.NoError(t, err)
+		err = stores[1].Open(false)
+		require.NoError(t, err)
+		_, err = stores[0].Join("node1", stores[1].RaftAdvertisedAddr)
+		require.NoError(t, err)
+		index, err := stores[0].Set("key", "value")
+		require.NoError(t, err)
+
+		// Assert
+		require.NotZero(t, index)
+		require.Eventually(t, func() bool {
+			got, err := stores[1].Get("key", store.ReadOptions{})
+			return err == nil && got == "value"
+		}, 5*time.Second, 50*time.Millisecond)
+
+		t.Run("Forwarded apply returns the index", func(t *testing.T) {
+			// Act
+			forwardedIndex, err := stores[1].Set("key", "value2")
+
+			// Assert
+			require.NoError(t, err)
+			require.Greater(t, forwardedIndex, index)
+		})
+	})
+
 	t.Run("Consensus", func(t *testing.T) {
 		nodes := 3
 		stores := make([]*distributed.Store, nodes)
@@ -114,7 +190,7 @@ func TestStore(t *testing.T) {
 
 				// Act & assert
 				if i > 0 {
-					err = stores[0].Join(
+					_, err = stores[0].Join(
 						raft.ServerID(fmt.Sprintf("node%d", i)),
 						raft.ServerAddress(s.RaftBind),
 					)
@@ -130,13 +206,13 @@ func TestStore(t *testing.T) {
 		t.Run("Set and Get", func(t *testing.T) {
 			t.Run("Set a key", func(t *testing.T) {
 				// Act: Set a key
-				err := stores[0].Set("key1", "value1")
+				_, err := stores[0].Set("key1", "value1")
 				require.NoError(t, err)
 
 				// Assert: Get the key from all nodes
 				require.Eventually(t, func() bool {
 					for i := 0; i < nodes; i++ {
-						got, err := stores[i].Get("key1")
+						got, err := stores[i].Get("key1", store.ReadOptions{})
 						if err != nil {
 							return false
 						}
@@ -150,7 +226,7 @@ func TestStore(t *testing.T) {
 
 			// Act: Set key as non-leader
 			t.Run("Set a key as non-leader", func(t *testing.T) {
-				err := stores[1].Set("key2", "value")
+				_, err := stores[1].Set("key2", "value")
 				require.NoError(t, err)
 
 				time.Sleep(50 * time.Millisecond)
@@ -158,7 +234,7 @@ func TestStore(t *testing.T) {
 				// Assert: Get the key from all nodes
 				require.Eventually(t, func() bool {
 					for i := 0; i < nodes; i++ {
-						got, err := stores[i].Get("key2")
+						got, err := stores[i].Get("key2", store.ReadOptions{})
 						if err != nil {
 							return false
 						}
@@ -172,19 +248,19 @@ func TestStore(t *testing.T) {
 
 			t.Run("Set a key with node1 kicked out", func(t *testing.T) {
 				// Act
-				err := stores[0].Leave("node1")
+				_, err := stores[0].Leave("node1")
 				require.NoError(t, err)
 
 				time.Sleep(50 * time.Millisecond)
 
-				err = stores[0].Set("key1", "value2")
+				_, err = stores[0].Set("key1", "value2")
 				require.NoError(t, err)
 
 				// Assert
 				require.Eventually(t, func() bool {
 					for i := 0; i < nodes; i++ {
 						if i == 1 {
-							got, err := stores[i].Get("key1")
+							got, err := stores[i].Get("key1", store.ReadOptions{})
 							if err != nil {
 								return false
 							}
@@ -192,7 +268,7 @@ func TestStore(t *testing.T) {
 								return false
 							}
 						} else {
-							got, err := stores[i].Get("key1")
+							got, err := stores[i].Get("key1", store.ReadOptions{})
 							if err != nil {
 								return false
 							}
@@ -207,12 +283,12 @@ func TestStore(t *testing.T) {
 
 			// Act: Set the key again, but with node1 back in (convergence test)
 			t.Run("Set a key with node1 back in", func(t *testing.T) {
-				err := stores[0].Join("node1", raft.ServerAddress(stores[1].RaftBind))
+				_, err := stores[0].Join("node1", raft.ServerAddress(stores[1].RaftBind))
 				require.NoError(t, err)
 
 				time.Sleep(50 * time.Millisecond)
 
-				got, err := stores[1].Get("key1")
+				got, err := stores[1].Get("key1", store.ReadOptions{})
 				require.NoError(t, err)
 				require.Equal(t, "value2", got)
 			})
@@ -239,12 +315,12 @@ func TestStore(t *testing.T) {
 					}
 				}
 
-				err = leader.Set("key1", "value3")
+				_, err = leader.Set("key1", "value3")
 				require.NoError(t, err)
 
 				require.Eventually(t, func() bool {
 					for i := 1; i < nodes; i++ {
-						got, err := stores[i].Get("key1")
+						got, err := stores[i].Get("key1", store.ReadOptions{})
 						if err != nil {
 							return false
 						}
@@ -258,3 +334,718 @@ func TestStore(t *testing.T) {
 		})
 	})
 }
+
+func TestStoreForceNewCluster(t *testing.T) {
+	t.Parallel()
+
+	// Arrange: a cluster of two nodes which lost one of them
+	nodes := 2
+	dirs := make([]string, nodes)
+	addrs := make([]string, nodes)
+	stores := make([]*distributed.Store, nodes)
+	storers := make([]*persisted.Store, nodes)
+	for i := 0; i < nodes; i++ {
+		dirs[i] = t.TempDir()
+		addrs[i] = getRandomAddress(t)
+		storers[i] = persisted.New(dirs[i])
+		stores[i] = distributed.NewStore(
+			dirs[i],
+			addrs[i],
+			fmt.Sprintf("node%d", i),
+			raft.ServerAddress(addrs[i]),
+			storers[i],
+		)
+	}
+	require.NoError(t, stores[0].Open(true))
+	_, err := stores[0].WaitForLeader(5 * time.Second)
+	require.NoError(t, err)
+	require.NoError(t, stores[1].Open(false))
+	_, err = stores[0].Join("node1", raft.ServerAddress(addrs[1]))
+	require.NoError(t, err)
+	_, err = stores[0].Set("key", "value")
+	require.NoError(t, err)
+	for i := 0; i < nodes; i++ {
+		require.NoError(t, stores[i].Shutdown())
+		require.NoError(t, storers[i].Close())
+	}
+
+	// Act
+	storer := persisted.New(dirs[0])
+	s := distributed.NewStore(
+		dirs[0],
+		addrs[0],
+		"node0",
+		raft.ServerAddress(addrs[0]),
+		storer,
+		distributed.WithForceNewCluster(true),
+	)
+	err = s.Open(false)
+	require.NoError(t, err)
+
+	// Assert
+	id, err := s.WaitForLeader(5 * time.Second)
+	require.NoError(t, err)
+	require.Equal(t, raft.ServerID("node0"), id)
+	servers, err := s.GetServers()
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	require.Eventually(t, func() bool {
+		got, err := s.Get("key", store.ReadOptions{})
+		return err == nil && got == "value"
+	}, 5*time.Second, 50*time.Millisecond)
+	_, err = s.Set("key", "value2")
+	require.NoError(t, err)
+
+	// Act: a node joins again, then the node restarts with the option
+	rejoinedDir := t.TempDir()
+	rejoinedStorer := persisted.New(rejoinedDir)
+	rejoined := distributed.NewStore(
+		rejoinedDir,
+		addrs[1],
+		"node1",
+		raft.ServerAddress(addrs[1]),
+		rejoinedStorer,
+	)
+	require.NoError(t, rejoined.Open(false))
+	t.Cleanup(func() {
+		require.NoError(t, rejoined.Shutdown())
+		require.NoError(t, rejoinedStorer.Close())
+	})
+	_, err = s.Join("node1", raft.ServerAddress(addrs[1]))
+	require.NoError(t, err)
+	require.NoError(t, s.Shutdown())
+	require.NoError(t, storer.Close())
+	restartedStorer := persisted.New(dirs[0])
+	restarted := distributed.NewStore(
+		dirs[0],
+		addrs[0],
+		"node0",
+		raft.ServerAddress(addrs[0]),
+		restartedStorer,
+		distributed.WithForceNewCluster(true),
+	)
+	require.NoError(t, restarted.Open(false))
+	t.Cleanup(func() {
+		require.NoError(t, restarted.Shutdown())
+		require.NoError(t, restartedStorer.Close())
+	})
+
+	// Assert: the cluster is only forced once
+	servers, err = restarted.GetServers()
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+}
+
+func TestStoreMaxStaleness(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	_, err := stores[0].Set("key", "value")
+	require.NoError(t, err)
+	opts := store.ReadOptions{MaxStaleness: time.Second}
+	require.Eventually(t, func() bool {
+		got, err := stores[1].Get("key", opts)
+		return err == nil && got == "value"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Act
+	require.NoError(t, stores[0].Shutdown())
+	time.Sleep(2 * time.Second)
+	_, staleErr := stores[1].Get("key", opts)
+	got, err := stores[1].Get("key", store.ReadOptions{})
+
+	// Assert
+	require.ErrorIs(t, staleErr, store.ErrStaleRead)
+	require.NoError(t, err)
+	require.Equal(t, "value", got)
+}
+
+func TestStoreConsistencyToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+
+	// Act
+	index, err := stores[0].Set("key", "value")
+	require.NoError(t, err)
+	got, err := stores[1].Get("key", store.ReadOptions{MinIndex: index})
+	require.NoError(t, err)
+	_, futureErr := stores[1].Get("key", store.ReadOptions{
+		MinIndex: index + 100,
+		Timeout:  200 * time.Millisecond,
+	})
+
+	// Assert
+	require.Equal(t, "value", got)
+	require.ErrorIs(t, futureErr, store.ErrStaleRead)
+}
+
+func TestStoreLinearizable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	opts := store.ReadOptions{Linearizable: true}
+
+	// Act
+	_, err := stores[0].Set("key", "value")
+	require.NoError(t, err)
+	got, err := stores[0].Get("key", opts)
+	require.NoError(t, err)
+	_, followerErr := stores[1].Get("key", opts)
+
+	// Assert
+	require.Equal(t, "value", got)
+	require.ErrorIs(t, followerErr, raft.ErrNotLeader)
+}
+
+func TestStoreSetKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	entries := []store.KeyValue{{Key: "a/1", Value: "1"}, {Key: "a/2", Value: "2"}}
+
+	// Act
+	_, created, err := stores[0].SetKeys(entries, store.SetOptions{IfAbsent: true})
+	require.NoError(t, err)
+	_, recreated, err := stores[0].SetKeys(entries, store.SetOptions{IfAbsent: true})
+	require.NoError(t, err)
+	_, updated, err := stores[0].SetKeys(
+		[]store.KeyValue{{Key: "a/1", Value: "one"}},
+		store.SetOptions{IfExists: true},
+	)
+	require.NoError(t, err)
+	_, expiring, err := stores[0].SetKeys(
+		[]store.KeyValue{{Key: "a/3", Value: "3"}},
+		store.SetOptions{TTL: 500 * time.Millisecond},
+	)
+	require.NoError(t, err)
+	keys, err := stores[0].Keys("a/", "", 0, store.ReadOptions{})
+	require.NoError(t, err)
+
+	// Assert
+	require.True(t, created)
+	require.False(t, recreated)
+	require.True(t, updated)
+	require.True(t, expiring)
+	require.Equal(t, []string{"a/1", "a/2", "a/3"}, keys)
+	value, err := stores[0].Get("a/1", store.ReadOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "one", value)
+	require.Eventually(t, func() bool {
+		_, err := stores[0].Get("a/3", store.ReadOptions{})
+		return errors.Is(err, store.ErrNotFound)
+	}, 5*time.Second, 100*time.Millisecond, "the key expires")
+	keys, err = stores[0].Keys("a/", "a/1", 0, store.ReadOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/2"}, keys)
+}
+
+func TestStoreReservedKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	s := newSingleNodeStore(t, t.TempDir(), getRandomAddress(t), true)
+	_, _, err := s.HashSet("hash", store.KeyValue{Key: "field", Value: "value"})
+	require.NoError(t, err)
+	applied := store.ReservedPrefix + "applied"
+	nested := &dkvv1.TxnCommand{Failure: []*dkvv1.Operation{{
+		Operation: &dkvv1.Operation_Txn{Txn: &dkvv1.TxnCommand{Success: []*dkvv1.Operation{{
+			Operation: &dkvv1.Operation_Put{Put: &dkvv1.PutOperation{Key: []byte(applied)}},
+		}}}},
+	}}}
+
+	// Act
+	_, setErr := s.Set(applied, "1")
+	_, deleteErr := s.Delete(store.CollectionPrefix + "4/hashhfield")
+	_, _, setKeysErr := s.SetKeys([]store.KeyValue{{Key: "a", Value: "1"}, {Key: applied, Value: "1"}}, store.SetOptions{})
+	_, _, incrementErr := s.Increment(applied, 1, store.IncrementOptions{})
+	_, _, txnErr := s.Txn(nested)
+	_, _, hashErr := s.HashSet(applied, store.KeyValue{Key: "field", Value: "value"})
+	_, all, allErr := s.Range(&dkvv1.RangeOperation{
+		Key:      []byte(store.ReservedPrefix),
+		RangeEnd: []byte(store.ReservedPrefix),
+	}, store.ReadOptions{})
+	_, _, rangeErr := s.Range(&dkvv1.RangeOperation{
+		Key:      []byte(applied),
+		RangeEnd: []byte(store.ReservedPrefix),
+	}, store.ReadOptions{})
+
+	// Assert
+	require.ErrorIs(t, setErr, store.ErrReservedKey)
+	require.ErrorIs(t, deleteErr, store.ErrReservedKey)
+	require.ErrorIs(t, setKeysErr, store.ErrReservedKey)
+	require.ErrorIs(t, incrementErr, store.ErrReservedKey)
+	require.ErrorIs(t, txnErr, store.ErrReservedKey)
+	require.ErrorIs(t, hashErr, store.ErrReservedKey)
+	require.NoError(t, allErr, "the key \\0 with a range end is the first key")
+	require.Empty(t, all.GetKvs(), "the reserved keys are skipped")
+	require.ErrorIs(t, rangeErr, store.ErrReservedKey)
+	value, err := s.HashGet("hash", "field", store.ReadOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "value", value, "the entries of the collections are not written")
+	_, err = s.Get("a", store.ReadOptions{})
+	require.ErrorIs(t, err, store.ErrNotFound, "nothing is written")
+}
+
+func TestStoreIncrement(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	_, err := stores[0].Set("text", "value")
+	require.NoError(t, err)
+
+	// Act
+	_, first, err := stores[1].Increment("counter", 1, store.IncrementOptions{})
+	require.NoError(t, err)
+	_, second, err := stores[1].Increment("counter", 41, store.IncrementOptions{})
+	require.NoError(t, err)
+	_, _, textErr := stores[1].Increment("text", 1, store.IncrementOptions{})
+	_, initial, err := stores[1].Increment("stock", -1, store.IncrementOptions{Initial: 10})
+	require.NoError(t, err)
+	_, _, boundErr := stores[1].Increment("stock", -10, store.IncrementOptions{Min: proto.Int64(0)})
+	_, unchanged, err := stores[1].Increment("stock", 0, store.IncrementOptions{})
+	require.NoError(t, err)
+
+	// Assert
+	require.Equal(t, int64(1), first)
+	require.Equal(t, int64(42), second)
+	require.ErrorIs(t, textErr, store.ErrNotInteger, "the error is forwarded by the leader")
+	require.Equal(t, int64(9), initial)
+	require.ErrorIs(t, boundErr, store.ErrOutOfRange)
+	require.Equal(t, int64(9), unchanged)
+}
+
+func TestStoreReadIndex(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	_, err := stores[0].Set("key", "value")
+	require.NoError(t, err)
+
+	// Act
+	index, err := stores[1].ReadIndex()
+	require.NoError(t, err)
+	got, err := stores[1].Get("key", store.ReadOptions{MinIndex: index})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "value", got)
+}
+
+func TestStoreReadIndexOfNewLeader(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	s := newSingleNodeStore(t, t.TempDir(), getRandomAddress(t), true)
+
+	// Act
+	index, err := s.LeaderReadIndex()
+	require.NoError(t, err)
+	_, getErr := s.Get("key", store.ReadOptions{Linearizable: true, Timeout: time.Second})
+
+	// Assert
+	require.Equal(t, s.AppliedIndex(), index, "the no-op of the leader is not read")
+	require.ErrorIs(t, getErr, store.ErrNotFound)
+}
+
+func TestStoreLeaderReadIndex(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var leader *distributed.Store
+	stores := newCluster(t, 2, distributed.WithLeaderReadIndex(
+		func(raft.ServerID, time.Duration) (uint64, error) {
+			return leader.LeaderReadIndex()
+		},
+	))
+	leader = stores[0]
+	written, err := leader.Set("key", "value")
+	require.NoError(t, err)
+	applied := leader.AppliedIndex()
+
+	// Act
+	leaderIndex, leaderErr := leader.ReadIndex()
+	followerIndex, followerErr := stores[1].ReadIndex()
+	got, getErr := stores[1].Get("key", store.ReadOptions{MinIndex: followerIndex})
+	_, notLeaderErr := stores[1].LeaderReadIndex()
+
+	// Assert
+	require.NoError(t, leaderErr)
+	require.NoError(t, followerErr)
+	require.GreaterOrEqual(t, leaderIndex, written)
+	require.Equal(t, leaderIndex, followerIndex)
+	require.Equal(t, applied, leader.AppliedIndex(), "nothing is written to the Raft log")
+	require.NoError(t, getErr)
+	require.Equal(t, "value", got)
+	require.ErrorIs(t, notLeaderErr, raft.ErrNotLeader)
+}
+
+func TestStoreAcquire(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	ctx := context.Background()
+	// The leader applies the writes before acknowledging them.
+	leases := func() int {
+		n, err := stores[0].Leases("lock")
+		require.NoError(t, err)
+		return n
+	}
+	first, err := stores[0].Acquire(ctx, "lock", "", time.Minute)
+	require.NoError(t, err)
+	type acquireResult struct {
+		lease *dkvv1.Lease
+		err   error
+	}
+	acquired := make(chan acquireResult, 2)
+	for i := range 2 {
+		go func() {
+			lease, err := stores[1].Acquire(ctx, "lock", fmt.Sprint(i), time.Minute)
+			acquired <- acquireResult{lease, err}
+		}()
+		require.Eventually(t, func() bool { return leases() == i+2 }, 5*time.Second, 10*time.Millisecond)
+	}
+	canceledCtx, cancel := context.WithCancel(ctx)
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := stores[1].Acquire(canceledCtx, "lock", "", time.Minute)
+		canceled <- err
+	}()
+	require.Eventually(t, func() bool { return leases() == 4 }, 5*time.Second, 10*time.Millisecond)
+
+	// Act
+	cancel()
+	canceledErr := <-canceled
+	_, deleted, err := stores[0].DeleteKeys(first.GetKey())
+	require.NoError(t, err)
+	_, err = stores[0].Release(first.GetKey(), first.GetRevision()+1)
+	require.ErrorIs(t, err, distributed.ErrLeaseExpired, "only the holder releases the lease")
+	_, err = stores[0].Release(first.GetKey(), first.GetRevision())
+	require.NoError(t, err)
+	second := <-acquired
+	require.NoError(t, second.err)
+	_, err = stores[0].Release(second.lease.GetKey(), second.lease.GetRevision())
+	require.NoError(t, err)
+	third := <-acquired
+	require.NoError(t, third.err)
+	keys, err := stores[0].Keys("", "", 0, store.ReadOptions{})
+	require.NoError(t, err)
+
+	// Assert
+	require.ErrorIs(t, canceledErr, context.Canceled)
+	require.Zero(t, deleted, "the leases are not keys")
+	require.Equal(t, "0", second.lease.GetValue(), "the waiters are served in FIFO order")
+	require.Equal(t, "1", third.lease.GetValue())
+	require.Greater(t, second.lease.GetRevision(), first.GetRevision(), "the fencing tokens increase")
+	require.Greater(t, third.lease.GetRevision(), second.lease.GetRevision())
+	require.Equal(t, 1, leases(), "the lease of the canceled waiter is deleted")
+	require.Empty(t, keys, "the leases are not listed")
+}
+
+func TestStoreAcquireNestedNames(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	s := newSingleNodeStore(t, t.TempDir(), getRandomAddress(t), true)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	nested, err := s.Acquire(ctx, "a/b", "nested", time.Minute)
+	require.NoError(t, err)
+
+	// Act
+	lease, err := s.Acquire(ctx, "a", "parent", time.Minute)
+	require.NoError(t, err)
+	n, leasesErr := s.Leases("a")
+	_, releaseErr := s.Release(nested.GetKey(), nested.GetRevision())
+
+	// Assert
+	require.NoError(t, leasesErr)
+	require.Equal(t, 1, n, "the leases of a/b are not leases of a")
+	require.Equal(t, "parent", lease.GetValue())
+	require.Contains(t, nested.GetKey(), "a/b/")
+	require.NoError(t, releaseErr)
+}
+
+func TestStoreKeepAlive(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	_, _, err := stores[0].SetKeys(
+		[]store.KeyValue{{Key: "lease", Value: "value"}},
+		store.SetOptions{TTL: 500 * time.Millisecond},
+	)
+	require.NoError(t, err)
+
+	// Act
+	_, kept, err := stores[1].KeepAlive(time.Minute, "lease", "missing")
+	require.NoError(t, err)
+	time.Sleep(time.Second)
+	value, getErr := stores[1].Get("lease", store.ReadOptions{})
+
+	// Assert
+	require.Equal(t, int64(1), kept)
+	require.NoError(t, getErr)
+	require.Equal(t, "value", value)
+}
+
+func TestStoreObserve(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	// The store is used until the goroutines return.
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	leaders := make(chan *dkvv1.Lease, 10)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = stores[1].Observe(ctx, "election", func(leader *dkvv1.Lease) error {
+			leaders <- leader
+			return nil
+		})
+	}()
+	require.Nil(t, <-leaders, "there is no leader")
+
+	// Act
+	first, err := stores[0].Acquire(ctx, "election", "first", time.Minute)
+	require.NoError(t, err)
+	elected := <-leaders
+	go func() {
+		defer wg.Done()
+		_, _ = stores[0].Acquire(ctx, "election", "second", time.Minute)
+	}()
+	require.Eventually(t, func() bool {
+		n, err := stores[1].Leases("election")
+		return err == nil && n == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = stores[0].Release(first.GetKey(), first.GetRevision())
+	require.NoError(t, err)
+	next := <-leaders
+
+	// Assert
+	require.True(t, proto.Equal(first, elected))
+	require.Equal(t, "second", next.GetValue())
+}
+
+func TestStoreQueue(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	stores := newCluster(t, 2)
+	first, err := stores[0].Enqueue("jobs", "a")
+	require.NoError(t, err)
+	_, err = stores[1].Enqueue("jobs", "b")
+	require.NoError(t, err)
+
+	t.Run("Requeue an expired claim", func(t *testing.T) {
+		// Act
+		_, claimed, err := stores[1].Dequeue(context.Background(), "jobs", 500*time.Millisecond, 0)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			_, ready, _, err := stores[0].Peek("jobs", 0, store.ReadOptions{})
+			return err == nil && ready == 2
+		}, 5*time.Second, 100*time.Millisecond, "the leader requeues the message")
+		_, ackErr := stores[1].Ack("jobs", claimed.GetId(), claimed.GetClaim())
+		_, redelivered, err := stores[1].Dequeue(context.Background(), "jobs", time.Minute, 0)
+		require.NoError(t, err)
+		_, err = stores[1].Ack("jobs", redelivered.GetId(), redelivered.GetClaim())
+		require.NoError(t, err)
+
+		// Assert
+		require.Equal(t, int64(first), claimed.GetId())
+		require.ErrorIs(t, ackErr, distributed.ErrClaimExpired)
+		require.Equal(t, claimed.GetId(), redelivered.GetId(), "the message keeps its position")
+		require.Equal(t, int64(2), redelivered.GetDeliveries())
+	})
+
+	t.Run("Wait for a message", func(t *testing.T) {
+		// Arrange
+		var message *dkvv1.QueueMessage
+		done := make(chan error, 1)
+		go func() {
+			var err error
+			_, message, err = stores[1].Dequeue(context.Background(), "waiting", time.Minute, 5*time.Second)
+			done <- err
+		}()
+		time.Sleep(200 * time.Millisecond)
+
+		// Act
+		_, err := stores[0].Enqueue("waiting", "c")
+		require.NoError(t, err)
+
+		// Assert
+		require.NoError(t, <-done)
+		require.Equal(t, "c", message.GetBody())
+	})
+
+	t.Run("Dequeue an empty queue", func(t *testing.T) {
+		// Arrange
+		applied := stores[0].AppliedIndex()
+
+		// Act
+		index, message, err := stores[0].Dequeue(context.Background(), "empty", time.Minute, 0)
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, message)
+		require.Equal(t, applied, index, "the index is the read index")
+		require.Equal(t, applied, stores[0].AppliedIndex(), "nothing is written")
+	})
+}
+
+func TestStoreCollections(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	s := newSingleNodeStore(t, t.TempDir(), getRandomAddress(t), true)
+	_, err := s.Set("string", "value")
+	require.NoError(t, err)
+	_, created, err := s.HashSet("hash", store.KeyValue{Key: "a", Value: "1"}, store.KeyValue{Key: "b", Value: "2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), created)
+	_, added, err := s.SetAdd("set", "a", "b", "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), added, "a member is added once")
+	_, _, err = s.SortedSetAdd("zset", store.ScoredMember{Member: "a", Score: 2}, store.ScoredMember{Member: "b", Score: 1})
+	require.NoError(t, err)
+
+	t.Run("Wrong type", func(t *testing.T) {
+		// Act
+		_, _, stringErr := s.HashSet("string", store.KeyValue{Key: "a", Value: "1"})
+		_, _, hashErr := s.SetAdd("hash", "a")
+		_, readErr := s.SetMembers("zset", store.ReadOptions{})
+		typ, err := s.Type("zset", store.ReadOptions{})
+
+		// Assert
+		require.ErrorIs(t, stringErr, store.ErrWrongType)
+		require.ErrorIs(t, hashErr, store.ErrWrongType)
+		require.ErrorIs(t, readErr, store.ErrWrongType)
+		require.NoError(t, err)
+		require.Equal(t, store.TypeSortedSet, typ)
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		addr := getRandomAddress(t)
+		meta, snapshot, err := s.Snapshot()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = snapshot.Close() })
+		require.NoError(t, distributed.Restore(dir, "node0", raft.ServerAddress(addr), meta.Index, meta.Term, snapshot))
+
+		// Act
+		restored := newSingleNodeStore(t, dir, addr, false)
+		fields, hashErr := restored.HashGetAll("hash", store.ReadOptions{})
+		members, setErr := restored.SetMembers("set", store.ReadOptions{})
+		scored, zsetErr := restored.SortedSetRangeByScore("zset", 0, 10, 0, store.ReadOptions{})
+		keys, keysErr := restored.Keys("", "", 0, store.ReadOptions{})
+
+		// Assert
+		require.NoError(t, hashErr)
+		require.Equal(t, []store.KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, fields)
+		require.NoError(t, setErr)
+		require.Equal(t, []string{"a", "b"}, members)
+		require.NoError(t, zsetErr)
+		require.Equal(t, []store.ScoredMember{{Member: "b", Score: 1}, {Member: "a", Score: 2}}, scored)
+		require.NoError(t, keysErr)
+		require.Equal(t, []string{"string"}, keys, "the collections are not listed")
+	})
+
+	t.Run("Replace by a value", func(t *testing.T) {
+		// Arrange
+		_, _, err := s.HashSet("replaced", store.KeyValue{Key: "a", Value: "1"})
+		require.NoError(t, err)
+
+		// Act
+		_, written, err := s.SetKeys(
+			[]store.KeyValue{{Key: "set", Value: "value"}},
+			store.SetOptions{IfAbsent: true},
+		)
+		require.NoError(t, err)
+		_, _, incrementErr := s.Increment("set", 1, store.IncrementOptions{})
+		_, _, err = s.SetKeys(
+			[]store.KeyValue{{Key: "replaced", Value: "value"}},
+			store.SetOptions{TTL: 500 * time.Millisecond},
+		)
+		require.NoError(t, err)
+		typ, err := s.Type("replaced", store.ReadOptions{})
+		require.NoError(t, err)
+
+		// Assert
+		require.False(t, written, "a collection exists")
+		require.ErrorIs(t, incrementErr, store.ErrWrongType)
+		require.Equal(t, store.TypeString, typ)
+		require.Eventually(t, func() bool {
+			typ, err := s.Type("replaced", store.ReadOptions{})
+			return err == nil && typ == store.TypeNone
+		}, 5*time.Second, 100*time.Millisecond, "the collection is deleted with the value")
+	})
+
+	t.Run("Replace an expired value", func(t *testing.T) {
+		// Arrange
+		_, _, err := s.SetKeys(
+			[]store.KeyValue{{Key: "expired", Value: "value"}},
+			store.SetOptions{TTL: 100 * time.Millisecond},
+		)
+		require.NoError(t, err)
+		time.Sleep(200 * time.Millisecond)
+
+		// Act
+		_, _, err = s.SetAdd("expired", "a")
+		require.NoError(t, err)
+		time.Sleep(2 * time.Second)
+		members, err := s.SetMembers("expired", store.ReadOptions{})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, members, "the expiration of the value keeps the collection")
+	})
+
+	t.Run("Delete a range", func(t *testing.T) {
+		// Arrange
+		_, _, err := s.HashSet("range/hash", store.KeyValue{Key: "a", Value: "1"})
+		require.NoError(t, err)
+
+		// Act
+		_, _, err = s.Txn(&dkvv1.TxnCommand{Success: []*dkvv1.Operation{{
+			Operation: &dkvv1.Operation_DeleteRange{DeleteRange: &dkvv1.DeleteRangeOperation{
+				Key:      []byte("range/"),
+				RangeEnd: []byte("range0"),
+			}},
+		}}})
+		require.NoError(t, err)
+		typ, err := s.Type("range/hash", store.ReadOptions{})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, store.TypeNone, typ)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Act
+		_, removed, err := s.RemoveMembers(store.TypeSortedSet, "zset", "a", "missing")
+		require.NoError(t, err)
+		_, deleted, err := s.DeleteKeys("string", "hash", "set", "missing")
+		require.NoError(t, err)
+		typ, err := s.Type("hash", store.ReadOptions{})
+
+		// Assert
+		require.Equal(t, int64(1), removed)
+		require.Equal(t, int64(3), deleted)
+		require.NoError(t, err)
+		require.Equal(t, store.TypeNone, typ)
+	})
+}
@@ -2,6 +2,7 @@ package distributed
 
 import (
 	"crypto/tls"
+	"distributed-kv/internal/mux"
 	"net"
 	"time"
 
@@ -15,15 +16,45 @@ type TLSStreamLayer struct {
 	AdvertizedAddress raft.ServerAddress
 	ServerTLSConfig   *tls.Config
 	ClientTLSConfig   *tls.Config
+	// PeerVerifier, if set, rejects the peers which are not members of the
+	// cluster. It requires ServerTLSConfig to verify client certificates.
+	PeerVerifier *PeerVerifier
+	// Multiplexed is true if the Listener is the Raft listener of a mux.Mux.
+	//
+	// In that case, the TLS handshake is done by the multiplexer and the
+	// ServerTLSConfig is ignored.
+	Multiplexed bool
+	// ClusterToken, if set, is exchanged on each connection. The peers of
+	// another cluster are rejected.
+	ClusterToken string
 }
 
 func (s *TLSStreamLayer) Accept() (net.Conn, error) {
+	conn, err := s.accept()
+	if err != nil {
+		return nil, err
+	}
+	if s.ClusterToken != "" {
+		return &clusterTokenConn{Conn: conn, token: s.ClusterToken}, nil
+	}
+	return conn, nil
+}
+
+func (s *TLSStreamLayer) accept() (net.Conn, error) {
+	if s.Multiplexed {
+		return s.acceptMultiplexed()
+	}
 	conn, err := s.Listener.Accept()
 	if err != nil {
 		return nil, err
 	}
 	if s.ServerTLSConfig != nil {
-		return tls.Server(conn, s.ServerTLSConfig), nil
+		config := s.ServerTLSConfig
+		if s.PeerVerifier != nil {
+			config = config.Clone()
+			config.VerifyConnection = s.PeerVerifier.VerifyConnection
+		}
+		return tls.Server(conn, config), nil
 	}
 	return conn, nil
 }
@@ -32,7 +63,40 @@ func (s *TLSStreamLayer) PublicAddress() raft.ServerAddress {
 	return s.AdvertizedAddress
 }
 
+func (s *TLSStreamLayer) acceptMultiplexed() (net.Conn, error) {
+	for {
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		tlsConn, ok := conn.(*tls.Conn)
+		if !ok || s.PeerVerifier == nil {
+			return conn, nil
+		}
+		if err := s.PeerVerifier.VerifyConnection(tlsConn.ConnectionState()); err != nil {
+			_ = conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
 func (s *TLSStreamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	conn, err := s.dial(address, timeout)
+	if err != nil || s.ClusterToken == "" {
+		return conn, err
+	}
+	if err := exchangeClusterToken(conn, s.ClusterToken); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (s *TLSStreamLayer) dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	if s.Multiplexed {
+		return mux.DialRaft(string(address), timeout, s.ClientTLSConfig)
+	}
 	dialer := &net.Dialer{Timeout: timeout}
 	conn, err := dialer.Dial("tcp", string(address))
 	if s.ClientTLSConfig != nil {
@@ -0,0 +1,323 @@
+package distributed
+
+import (
+	"bytes"
+	"cmp"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"errors"
+	"slices"
+)
+
+// txnPlan is the operations chosen by the comparisons of a transaction and
+// its nested transactions. Like etcd, all the comparisons are evaluated
+// before the execution.
+type txnPlan struct {
+	succeeded bool
+	ops       []*dkvv1.Operation
+	// nested are the plans of the nested transactions, by operation.
+	nested []*txnPlan
+}
+
+func (f *FSM) txn(txn *dkvv1.TxnCommand, index, now int64) interface{} {
+	plan, err := f.plan(txn, now)
+	if err != nil {
+		return err
+	}
+	result, err := f.execute(plan, index, now)
+	if err != nil {
+		return err
+	}
+	return &dkvv1.CommandResult{Txn: result}
+}
+
+// plan evaluates the comparisons of a transaction, and checks the operations
+// chosen so that the transaction fails before any write.
+func (f *FSM) plan(txn *dkvv1.TxnCommand, now int64) (*txnPlan, error) {
+	succeeded := true
+	for _, c := range txn.GetCompare() {
+		ok, err := f.compare(c, now)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			succeeded = false
+			break
+		}
+	}
+	p := &txnPlan{succeeded: succeeded, ops: txn.GetFailure()}
+	if succeeded {
+		p.ops = txn.GetSuccess()
+	}
+	p.nested = make([]*txnPlan, len(p.ops))
+	for i, op := range p.ops {
+		switch op := op.GetOperation().(type) {
+		case *dkvv1.Operation_Put:
+			if !op.Put.GetIgnoreValue() {
+				continue
+			}
+			exists, err := f.exists(string(op.Put.GetKey()), now)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, store.ErrNotFound
+			}
+		case *dkvv1.Operation_Txn:
+			nested, err := f.plan(op.Txn, now)
+			if err != nil {
+				return nil, err
+			}
+			p.nested[i] = nested
+		}
+	}
+	return p, nil
+}
+
+// execute executes the operations of a plan at the index.
+func (f *FSM) execute(p *txnPlan, index, now int64) (*dkvv1.TxnResult, error) {
+	result := &dkvv1.TxnResult{
+		Succeeded: p.succeeded,
+		Results:   make([]*dkvv1.OperationResult, 0, len(p.ops)),
+	}
+	for i, op := range p.ops {
+		res := &dkvv1.OperationResult{}
+		switch op := op.GetOperation().(type) {
+		case *dkvv1.Operation_Range:
+			r, err := f.rangeKeys(op.Range, index, now)
+			if err != nil {
+				return nil, err
+			}
+			res.Result = &dkvv1.OperationResult_Range{Range: r}
+		case *dkvv1.Operation_Put:
+			r, err := f.putKey(op.Put, index, now)
+			if err != nil {
+				return nil, err
+			}
+			res.Result = &dkvv1.OperationResult_Put{Put: r}
+		case *dkvv1.Operation_DeleteRange:
+			r, err := f.deleteRange(op.DeleteRange, index, now)
+			if err != nil {
+				return nil, err
+			}
+			res.Result = &dkvv1.OperationResult_DeleteRange{DeleteRange: r}
+		case *dkvv1.Operation_Txn:
+			r, err := f.execute(p.nested[i], index, now)
+			if err != nil {
+				return nil, err
+			}
+			res.Result = &dkvv1.OperationResult_Txn{Txn: r}
+		default:
+			return nil, errors.New("unknown operation")
+		}
+		result.Results = append(result.Results, res)
+	}
+	return result, nil
+}
+
+func (f *FSM) putKey(put *dkvv1.PutOperation, index, now int64) (*dkvv1.PutResult, error) {
+	key, value, contentType := string(put.GetKey()), string(put.GetValue()), put.GetContentType()
+	if put.GetIgnoreValue() {
+		kv, err := f.keyValue(key, true)
+		if err != nil {
+			return nil, err
+		}
+		value, contentType = string(kv.GetValue()), kv.GetContentType()
+	}
+	prev, err := f.setKey(key, value, index, now, put.GetPrevKv())
+	if err != nil {
+		return nil, err
+	}
+	f.setExpiration(key, 0)
+	f.setContentType(key, contentType)
+	if !put.GetPrevKv() {
+		prev = nil
+	}
+	return &dkvv1.PutResult{PrevKv: prev}, nil
+}
+
+func (f *FSM) deleteRange(
+	del *dkvv1.DeleteRangeOperation,
+	index, now int64,
+) (*dkvv1.DeleteRangeResult, error) {
+	start, end := rangeBounds(del.GetKey(), del.GetRangeEnd())
+	keys, err := f.scan(start, end, 0, now)
+	if err != nil {
+		return nil, err
+	}
+	result := &dkvv1.DeleteRangeResult{Deleted: int64(len(keys))}
+	for _, key := range keys {
+		prev, err := f.deleteKey(key, index, now, del.GetPrevKv())
+		if err != nil {
+			return nil, err
+		}
+		if del.GetPrevKv() && prev != nil {
+			result.PrevKvs = append(result.PrevKvs, prev)
+		}
+	}
+	// The collections of the range are deleted too, but are not counted since
+	// they are not read by the ranges.
+	collections, err := f.storer.CollectionKeys(start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range collections {
+		if _, err := f.storer.DeleteCollection(key); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rangeKeys returns the keys of a range which have not expired at the time
+// now, at the current revision.
+func (f *FSM) rangeKeys(r *dkvv1.RangeOperation, current, now int64) (*dkvv1.RangeResult, error) {
+	start, end := rangeBounds(r.GetKey(), r.GetRangeEnd())
+	keys, err := f.scan(start, end, 0, now)
+	if err != nil {
+		return nil, err
+	}
+	// The keys are checked after the scan, since they only change after.
+	if rev := r.GetRevision(); rev > current {
+		return nil, ErrFutureRevision
+	} else if rev > 0 && rev < current && f.changedAfter(rev) {
+		return nil, ErrCompacted
+	}
+	result := &dkvv1.RangeResult{Count: int64(len(keys))}
+	if r.GetCountOnly() {
+		return result, nil
+	}
+
+	target, order := r.GetSortTarget(), r.GetSortOrder()
+	if target != dkvv1.RangeOperation_SORT_TARGET_KEY &&
+		order == dkvv1.RangeOperation_SORT_ORDER_NONE {
+		order = dkvv1.RangeOperation_SORT_ORDER_ASCEND
+	}
+	limit := int(r.GetLimit())
+	// The keys are already sorted by key, and only the returned keys are read.
+	byKey := target == dkvv1.RangeOperation_SORT_TARGET_KEY
+	if byKey {
+		if order == dkvv1.RangeOperation_SORT_ORDER_DESCEND {
+			slices.Reverse(keys)
+		}
+		if limit > 0 && len(keys) > limit {
+			keys, result.More = keys[:limit], true
+		}
+	}
+	withValue := !r.GetKeysOnly() || target == dkvv1.RangeOperation_SORT_TARGET_VALUE
+	for _, key := range keys {
+		kv, err := f.keyValue(key, withValue)
+		if errors.Is(err, store.ErrNotFound) {
+			// The key was deleted since the scan.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Kvs = append(result.Kvs, kv)
+	}
+	if !byKey {
+		slices.SortStableFunc(result.Kvs, func(a, b *dkvv1.RevisionedKeyValue) int {
+			c := compareTarget(target, a, b)
+			if order == dkvv1.RangeOperation_SORT_ORDER_DESCEND {
+				return -c
+			}
+			return c
+		})
+		if limit > 0 && len(result.Kvs) > limit {
+			result.Kvs, result.More = result.Kvs[:limit], true
+		}
+	}
+	if r.GetKeysOnly() {
+		for _, kv := range result.Kvs {
+			kv.Value = nil
+		}
+	}
+	return result, nil
+}
+
+// compareTarget compares a property of two keys.
+func compareTarget(target dkvv1.RangeOperation_SortTarget, a, b *dkvv1.RevisionedKeyValue) int {
+	switch target {
+	case dkvv1.RangeOperation_SORT_TARGET_VERSION:
+		return cmp.Compare(a.GetVersion(), b.GetVersion())
+	case dkvv1.RangeOperation_SORT_TARGET_CREATE_REVISION:
+		return cmp.Compare(a.GetCreateRevision(), b.GetCreateRevision())
+	case dkvv1.RangeOperation_SORT_TARGET_MOD_REVISION:
+		return cmp.Compare(a.GetModRevision(), b.GetModRevision())
+	case dkvv1.RangeOperation_SORT_TARGET_VALUE:
+		return bytes.Compare(a.GetValue(), b.GetValue())
+	default:
+		return bytes.Compare(a.GetKey(), b.GetKey())
+	}
+}
+
+// compare returns true if all the keys of the range of a comparison which have
+// not expired at the time now hold.
+func (f *FSM) compare(c *dkvv1.Comparison, now int64) (bool, error) {
+	start, end := rangeBounds(c.GetKey(), c.GetRangeEnd())
+	keys, err := f.scan(start, end, 0, now)
+	if err != nil {
+		return false, err
+	}
+	if len(keys) == 0 {
+		if c.GetTarget() == dkvv1.Comparison_TARGET_VALUE {
+			return false, nil
+		}
+		return compareKey(c, &dkvv1.RevisionedKeyValue{}), nil
+	}
+	for _, key := range keys {
+		kv, err := f.keyValue(key, c.GetTarget() == dkvv1.Comparison_TARGET_VALUE)
+		if err != nil {
+			return false, err
+		}
+		if !compareKey(c, kv) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareKey returns true if the key holds the comparison.
+func compareKey(c *dkvv1.Comparison, kv *dkvv1.RevisionedKeyValue) bool {
+	var r int
+	switch c.GetTarget() {
+	case dkvv1.Comparison_TARGET_VERSION:
+		r = cmp.Compare(kv.GetVersion(), c.GetNumber())
+	case dkvv1.Comparison_TARGET_CREATE_REVISION:
+		r = cmp.Compare(kv.GetCreateRevision(), c.GetNumber())
+	case dkvv1.Comparison_TARGET_MOD_REVISION:
+		r = cmp.Compare(kv.GetModRevision(), c.GetNumber())
+	case dkvv1.Comparison_TARGET_VALUE:
+		r = bytes.Compare(kv.GetValue(), c.GetValue())
+	}
+	switch c.GetResult() {
+	case dkvv1.Comparison_RESULT_EQUAL:
+		return r == 0
+	case dkvv1.Comparison_RESULT_GREATER:
+		return r > 0
+	case dkvv1.Comparison_RESULT_LESS:
+		return r < 0
+	case dkvv1.Comparison_RESULT_NOT_EQUAL:
+		return r != 0
+	}
+	return false
+}
+
+// rangeBounds returns the bounds [start, end) of the storer for a range of
+// keys like etcd: an empty range end is the key only, and "\0" is all the keys
+// greater than or equal to the key. An empty end is no upper bound, and the key
+// "\0" with a range end is the first key, which is an empty start.
+func rangeBounds(key, rangeEnd []byte) (start, end string) {
+	if len(rangeEnd) > 0 && string(key) == store.ReservedPrefix {
+		key = nil
+	}
+	switch string(rangeEnd) {
+	case "":
+		return string(key), string(key) + "\x00"
+	case "\x00":
+		return string(key), ""
+	default:
+		return string(key), string(rangeEnd)
+	}
+}
@@ -0,0 +1,205 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/store"
+	"errors"
+	"sort"
+)
+
+// maxHistory is the maximum number of events kept for the watches. A quarter
+// of the history is dropped when it is full.
+const maxHistory = 10000
+
+// ErrCompacted is returned when watching from a revision older than the
+// history of the events.
+var ErrCompacted = errors.New("required revision has been compacted")
+
+// ErrFutureRevision is returned when reading a revision which is not applied.
+var ErrFutureRevision = errors.New("required revision is a future revision")
+
+// WatchEventType is the type of a change of a key.
+type WatchEventType int
+
+const (
+	WatchEventPut WatchEventType = iota
+	WatchEventDelete
+)
+
+// WatchEvent is a change of a key. Its revision is the modification revision
+// of the key.
+type WatchEvent struct {
+	Type WatchEventType
+	// KV is the key after the change. A deleted key only has its key and the
+	// revision of its deletion.
+	KV *dkvv1.RevisionedKeyValue
+	// PrevKV is the key before the change, or nil if it did not exist. It is
+	// only recorded while the previous keys are watched.
+	PrevKV *dkvv1.RevisionedKeyValue
+}
+
+// startHistory starts the history of the events at the index of the first
+// entry applied since a snapshot without its applied index was restored.
+func (f *FSM) startHistory(index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.historyStart == 0 {
+		f.historyStart = int64(index)
+	}
+}
+
+// record appends an event to the history. The lock must be held.
+func (f *FSM) record(e WatchEvent) {
+	f.history = append(f.history, e)
+	if len(f.history) <= maxHistory {
+		return
+	}
+	// The events of a revision are dropped together.
+	drop := len(f.history) / 4
+	last := f.history[drop-1].KV.GetModRevision()
+	for drop < len(f.history) && f.history[drop].KV.GetModRevision() == last {
+		drop++
+	}
+	f.history = append([]WatchEvent(nil), f.history[drop:]...)
+	f.historyStart = last + 1
+}
+
+// eventsFrom returns the events of the history from the revision, and the
+// first revision of the history.
+func (f *FSM) eventsFrom(rev int64) ([]WatchEvent, int64) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	i := sort.Search(len(f.history), func(i int) bool {
+		return f.history[i].KV.GetModRevision() >= rev
+	})
+	return append([]WatchEvent(nil), f.history[i:]...), f.historyStart
+}
+
+// changedAfter returns true if a key may have changed after the revision.
+func (f *FSM) changedAfter(rev int64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if n := len(f.history); n > 0 {
+		return f.history[n-1].KV.GetModRevision() > rev
+	}
+	// No key changed since the start of the history.
+	return f.historyStart == 0 || f.historyStart-1 > rev
+}
+
+// watchesPrevKV returns true if the previous keys are watched.
+func (f *FSM) watchesPrevKV() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.prevKVWatches > 0
+}
+
+func (f *FSM) addPrevKVWatches(delta int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prevKVWatches += delta
+}
+
+// AppliedIndex returns the index of the last log entry applied by the FSM,
+// which is the current revision of the keys.
+func (s *Store) AppliedIndex() uint64 {
+	applied, _ := s.fsm.applied()
+	return applied
+}
+
+// CompactRevision returns the oldest revision which can be watched.
+func (s *Store) CompactRevision() int64 {
+	_, start := s.fsm.eventsFrom(0)
+	if start == 0 {
+		return int64(s.AppliedIndex()) + 1
+	}
+	return start
+}
+
+// Watch calls fn with the events of the keys in the range [key, rangeEnd),
+// like etcd, from the revision start until the context is done or fn fails.
+// A start of zero is the next revision. The previous keys are in the events
+// if prevKV is true.
+//
+// fn is called each time entries are applied, with the events of the range,
+// possibly none, and the revision up to which all the events were passed.
+//
+// The events are served from the history of the local state, which only
+// contains the last events since the node started or restored a snapshot.
+// ErrCompacted is returned if an event of the range may be missing.
+//
+// store.ErrReservedKey is returned if the key is reserved, and the events of
+// the reserved keys are skipped, like Range.
+func (s *Store) Watch(
+	ctx context.Context,
+	key, rangeEnd []byte,
+	start int64,
+	prevKV bool,
+	fn func(events []WatchEvent, rev int64) error,
+) error {
+	if err := checkRange(key, rangeEnd); err != nil {
+		return err
+	}
+	return s.watch(ctx, key, rangeEnd, start, prevKV, fn)
+}
+
+// watch is Watch without the check of the range. A range from the first key
+// skips the reserved keys, like the scans of the FSM.
+func (s *Store) watch(
+	ctx context.Context,
+	key, rangeEnd []byte,
+	start int64,
+	prevKV bool,
+	fn func(events []WatchEvent, rev int64) error,
+) error {
+	if prevKV {
+		s.fsm.addPrevKVWatches(1)
+		defer s.fsm.addPrevKVWatches(-1)
+	}
+	if start <= 0 {
+		start = int64(s.AppliedIndex()) + 1
+	}
+	from, to := rangeBounds(key, rangeEnd)
+	if from == "" {
+		from = prefixEnd(store.ReservedPrefix)
+	}
+	for {
+		// The events of the entries applied by the FSM are all recorded.
+		applied, appliedCh := s.fsm.applied()
+		events, historyStart := s.fsm.eventsFrom(start)
+		if start <= int64(applied) && (historyStart == 0 || start < historyStart) {
+			return ErrCompacted
+		}
+		var matched []WatchEvent
+		for _, e := range events {
+			if e.KV.GetModRevision() > int64(applied) {
+				break
+			}
+			if !inRange(e.KV.GetKey(), from, to) {
+				continue
+			}
+			if !prevKV {
+				e.PrevKV = nil
+			}
+			matched = append(matched, e)
+		}
+		start = max(start, int64(applied)+1)
+		if err := fn(matched, start-1); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-appliedCh:
+		}
+	}
+}
+
+// inRange returns true if the key is in [start, end). An empty end is no upper
+// bound.
+func inRange(key []byte, start, end string) bool {
+	return bytes.Compare(key, []byte(start)) >= 0 &&
+		(end == "" || bytes.Compare(key, []byte(end)) < 0)
+}
@@ -0,0 +1,52 @@
+package distributed
+
+import (
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"log/slog"
+
+	"github.com/hashicorp/raft"
+)
+
+// WithZone sets the zone of the local node.
+//
+// The zone is replicated in the Raft log. Once a zone is replicated, the
+// cluster is zone-aware: the autopilot keeps one voter per zone, and the other
+// servers of the zone are hot standbys.
+func WithZone(zone string) StoreOption {
+	return func(o *StoreOptions) {
+		o.zone = zone
+	}
+}
+
+// Zone returns the zone of the local node.
+func (s *Store) Zone() string {
+	return s.zone
+}
+
+// Zones returns the replicated zones of the servers, by ID.
+func (s *Store) Zones() map[raft.ServerID]string {
+	return s.fsm.Zones()
+}
+
+// announceZone replicates the zone of the local node, if it is not already
+// replicated.
+func (s *Store) announceZone() {
+	if s.zone == "" || s.fsm.Zones()[raft.ServerID(s.RaftID)] == s.zone {
+		return
+	}
+	if _, id := s.raft.LeaderWithID(); id == "" {
+		return
+	}
+	if _, err := s.apply(&dkvv1.Command{
+		Command: &dkvv1.Command_ServerMetadata{
+			ServerMetadata: &dkvv1.ServerMetadata{
+				Id:   s.RaftID,
+				Zone: s.zone,
+			},
+		},
+	}); err != nil {
+		slog.Error("failed to announce the zone", "zone", s.zone, "error", err)
+		return
+	}
+	slog.Info("zone announced", "zone", s.zone)
+}
@@ -0,0 +1,319 @@
+package persisted
+
+import (
+	"bytes"
+	"distributed-kv/internal/store"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// The entries of a collection are stored under the key of the collection,
+// followed by their kind:
+//
+//	\x00collection/<length of the key>/<key>h<field> = value
+//	\x00collection/<length of the key>/<key>s<member> = ""
+//	\x00collection/<length of the key>/<key>z<member> = score
+//	\x00collection/<length of the key>/<key>Z<sortable score><member> = ""
+//
+// The length of the key separates it from the fields, and the score index of
+// the sorted sets is ordered by score and then by member. The encoding is
+// printable, so that the snapshots can store it.
+const (
+	hashField         = 'h'
+	setMember         = 's'
+	sortedSetMember   = 'z'
+	sortedSetScoreKey = 'Z'
+)
+
+// collectionKey returns the prefix of the entries of the collection of a key.
+func collectionKey(key string) string {
+	return store.CollectionPrefix + strconv.Itoa(len(key)) + "/" + key
+}
+
+// entryKey returns the key of an entry of the collection of a key.
+func entryKey(key string, kind byte, suffix string) []byte {
+	return []byte(collectionKey(key) + string(kind) + suffix)
+}
+
+// encodeScore encodes a score so that the lexicographic order of the encoded
+// scores is their numeric order.
+func encodeScore(score float64) string {
+	if score == 0 {
+		// -0 is equal to 0.
+		score = 0
+	}
+	bits := math.Float64bits(score)
+	if bits>>63 == 1 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return fmt.Sprintf("%016x", bits)
+}
+
+// decodeScore decodes a score encoded by encodeScore.
+func decodeScore(s string) (float64, error) {
+	bits, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bits>>63 == 1 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits), nil
+}
+
+// CollectionType returns the type of the collection of a key, or
+// store.TypeNone if there is none.
+func (s *Store) CollectionType(key string) (store.Type, error) {
+	prefix := []byte(collectionKey(key))
+	iter, err := s.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixUpperBound(prefix),
+	})
+	if err != nil {
+		return store.TypeNone, err
+	}
+	t := store.TypeNone
+	if iter.First() {
+		switch iter.Key()[len(prefix)] {
+		case hashField:
+			t = store.TypeHash
+		case setMember:
+			t = store.TypeSet
+		case sortedSetMember, sortedSetScoreKey:
+			t = store.TypeSortedSet
+		}
+	}
+	return t, iter.Close()
+}
+
+// has returns true if a key is stored.
+func (s *Store) has(key []byte) (bool, error) {
+	_, closer, err := s.DB.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, closer.Close()
+}
+
+// set sets an entry, and returns true if it was created.
+func (s *Store) set(key []byte, value string) (bool, error) {
+	exists, err := s.has(key)
+	if err != nil {
+		return false, err
+	}
+	return !exists, s.DB.Set(key, []byte(value), pebble.Sync)
+}
+
+// delete deletes an entry, and returns true if it was stored.
+func (s *Store) delete(key []byte) (bool, error) {
+	exists, err := s.has(key)
+	if err != nil || !exists {
+		return false, err
+	}
+	return true, s.DB.Delete(key, pebble.Sync)
+}
+
+// entries calls fn with the suffixes and the values of the entries of a kind
+// of the collection of a key, in lexicographic order, until fn returns false.
+func (s *Store) entries(key string, kind byte, fn func(suffix, value []byte) bool) error {
+	prefix := entryKey(key, kind, "")
+	iter, err := s.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixUpperBound(prefix),
+	})
+	if err != nil {
+		return err
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		if !fn(iter.Key()[len(prefix):], iter.Value()) {
+			break
+		}
+	}
+	return iter.Close()
+}
+
+// HashSet sets the value of a field of a hash, and returns true if the field
+// was created.
+func (s *Store) HashSet(key, field, value string) (bool, error) {
+	return s.set(entryKey(key, hashField, field), value)
+}
+
+// HashGet gets the value of a field of a hash.
+func (s *Store) HashGet(key, field string) (string, error) {
+	return s.Get(string(entryKey(key, hashField, field)))
+}
+
+// HashGetAll returns the fields of a hash and their values, in lexicographic
+// order.
+func (s *Store) HashGetAll(key string) ([]store.KeyValue, error) {
+	var fields []store.KeyValue
+	err := s.entries(key, hashField, func(field, value []byte) bool {
+		fields = append(fields, store.KeyValue{Key: string(field), Value: string(value)})
+		return true
+	})
+	return fields, err
+}
+
+// SetAdd adds a member to a set, and returns true if it was added.
+func (s *Store) SetAdd(key, member string) (bool, error) {
+	return s.set(entryKey(key, setMember, member), "")
+}
+
+// SetMembers returns the members of a set, in lexicographic order.
+func (s *Store) SetMembers(key string) ([]string, error) {
+	var members []string
+	err := s.entries(key, setMember, func(member, _ []byte) bool {
+		members = append(members, string(member))
+		return true
+	})
+	return members, err
+}
+
+// SortedSetAdd sets the score of a member of a sorted set, and returns true if
+// the member was added.
+func (s *Store) SortedSetAdd(key, member string, score float64) (bool, error) {
+	memberKey := entryKey(key, sortedSetMember, member)
+	previous, err := s.Get(string(memberKey))
+	added := errors.Is(err, store.ErrNotFound)
+	if err != nil && !added {
+		return false, err
+	}
+	b := s.NewBatch()
+	defer b.Close()
+	if !added {
+		if previous == strconv.FormatFloat(score, 'g', -1, 64) {
+			return false, nil
+		}
+		prev, err := strconv.ParseFloat(previous, 64)
+		if err != nil {
+			return false, err
+		}
+		if err := b.Delete(entryKey(key, sortedSetScoreKey, encodeScore(prev)+member), nil); err != nil {
+			return false, err
+		}
+	}
+	if err := b.Set(memberKey, []byte(strconv.FormatFloat(score, 'g', -1, 64)), nil); err != nil {
+		return false, err
+	}
+	if err := b.Set(entryKey(key, sortedSetScoreKey, encodeScore(score)+member), nil, nil); err != nil {
+		return false, err
+	}
+	return added, b.Commit(pebble.Sync)
+}
+
+// SortedSetRangeByScore returns the members of a sorted set whose score is in
+// [minimum, maximum], ordered by score and then by member. At most limit
+// members are returned if limit is positive.
+func (s *Store) SortedSetRangeByScore(
+	key string,
+	minimum, maximum float64,
+	limit int,
+) ([]store.ScoredMember, error) {
+	if minimum > maximum {
+		return nil, nil
+	}
+	prefix := string(entryKey(key, sortedSetScoreKey, ""))
+	iter, err := s.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(prefix + encodeScore(minimum)),
+		UpperBound: prefixUpperBound([]byte(prefix + encodeScore(maximum))),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var members []store.ScoredMember
+	for iter.First(); iter.Valid() && (limit <= 0 || len(members) < limit); iter.Next() {
+		entry := string(iter.Key()[len(prefix):])
+		score, err := decodeScore(entry[:16])
+		if err != nil {
+			return nil, errors.Join(err, iter.Close())
+		}
+		members = append(members, store.ScoredMember{Member: entry[16:], Score: score})
+	}
+	return members, iter.Close()
+}
+
+// DeleteMember deletes a field of a hash or a member of a set or of a sorted
+// set, and returns true if it was stored.
+func (s *Store) DeleteMember(t store.Type, key, member string) (bool, error) {
+	switch t {
+	case store.TypeHash:
+		return s.delete(entryKey(key, hashField, member))
+	case store.TypeSet:
+		return s.delete(entryKey(key, setMember, member))
+	case store.TypeSortedSet:
+		memberKey := entryKey(key, sortedSetMember, member)
+		score, err := s.Get(string(memberKey))
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		prev, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			return false, err
+		}
+		b := s.NewBatch()
+		defer b.Close()
+		if err := b.Delete(memberKey, nil); err != nil {
+			return false, err
+		}
+		if err := b.Delete(entryKey(key, sortedSetScoreKey, encodeScore(prev)+member), nil); err != nil {
+			return false, err
+		}
+		return true, b.Commit(pebble.Sync)
+	default:
+		return false, fmt.Errorf("%s is not a collection", t)
+	}
+}
+
+// DeleteCollection deletes the collection of a key, and returns true if there
+// was one. The entries are deleted by a range deletion, whatever their number.
+func (s *Store) DeleteCollection(key string) (bool, error) {
+	t, err := s.CollectionType(key)
+	if err != nil || t == store.TypeNone {
+		return false, err
+	}
+	prefix := []byte(collectionKey(key))
+	return true, s.DB.DeleteRange(prefix, prefixUpperBound(prefix), pebble.Sync)
+}
+
+// CollectionKeys returns the keys of the collections in [start, end), in the
+// order of the lengths of the keys. An empty end is no upper bound. Each
+// collection is read, since its entries are not ordered by key.
+func (s *Store) CollectionKeys(start, end string) ([]string, error) {
+	prefix := []byte(store.CollectionPrefix)
+	iter, err := s.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixUpperBound(prefix),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for iter.First(); iter.Valid(); {
+		rawLength, rest, ok := bytes.Cut(iter.Key()[len(prefix):], []byte("/"))
+		length, err := strconv.Atoi(string(rawLength))
+		if !ok || err != nil || length > len(rest) {
+			_ = iter.Close()
+			return nil, fmt.Errorf("invalid collection entry: %q", iter.Key())
+		}
+		key := string(rest[:length])
+		if key >= start && (end == "" || key < end) {
+			keys = append(keys, key)
+		}
+		iter.SeekGE(prefixUpperBound([]byte(collectionKey(key))))
+	}
+	return keys, iter.Close()
+}
@@ -1,6 +1,10 @@
 package persisted
 
 import (
+	"bytes"
+	"distributed-kv/internal/store"
+	"errors"
+	"fmt"
 	"path/filepath"
 
 	"github.com/cockroachdb/pebble"
@@ -21,6 +25,9 @@ func New(path string) *Store {
 
 func (s *Store) Get(key string) (string, error) {
 	v, closer, err := s.DB.Get([]byte(key))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return "", fmt.Errorf("%w: %w", store.ErrNotFound, err)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -36,6 +43,59 @@ func (s *Store) Delete(key string) error {
 	return s.DB.Delete([]byte(key), pebble.Sync)
 }
 
+// Keys returns the keys with the prefix in lexicographic order, starting after
+// the key after if it is not empty. At most limit keys are returned if limit is
+// positive.
+func (s *Store) Keys(prefix, after string, limit int) ([]string, error) {
+	start := prefix
+	if after != "" && after >= prefix {
+		// The smallest key greater than after.
+		start = after + "\x00"
+	}
+	return s.Range(start, string(prefixUpperBound([]byte(prefix))), limit)
+}
+
+// Range returns the keys in [start, end) in lexicographic order. An empty end
+// is no upper bound. At most limit keys are returned if limit is positive.
+//
+// The entries of the collections are skipped.
+func (s *Store) Range(start, end string, limit int) ([]string, error) {
+	opts := &pebble.IterOptions{LowerBound: []byte(start)}
+	if end != "" {
+		opts.UpperBound = []byte(end)
+	}
+	iter, err := s.NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	collections := []byte(store.CollectionPrefix)
+	var keys []string
+	for iter.First(); iter.Valid() && (limit <= 0 || len(keys) < limit); {
+		if bytes.HasPrefix(iter.Key(), collections) {
+			iter.SeekGE(prefixUpperBound(collections))
+			continue
+		}
+		keys = append(keys, string(iter.Key()))
+		iter.Next()
+	}
+	return keys, iter.Close()
+}
+
+// prefixUpperBound returns the smallest key greater than all the keys with the
+// prefix, or nil if there is none.
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			upper := append([]byte{}, prefix[:i+1]...)
+			upper[i]++
+			return upper
+		}
+	}
+	return nil
+}
+
+// Dump returns all the stored entries, including the reserved entries of the
+// collections, which are not keys.
 func (s *Store) Dump() map[string]string {
 	data := make(map[string]string)
 	iter, err := s.NewIter(nil)
@@ -1,9 +1,11 @@
 package persisted_test
 
 import (
+	"math"
 	"os"
 	"testing"
 
+	"distributed-kv/internal/store"
 	"distributed-kv/internal/store/persisted"
 
 	"github.com/cockroachdb/pebble"
@@ -23,6 +25,7 @@ func TestStore(t *testing.T) {
 	t.Run("Get", func(t *testing.T) {
 		_, err := s.Get("key")
 		require.ErrorIs(t, err, pebble.ErrNotFound)
+		require.ErrorIs(t, err, store.ErrNotFound)
 	})
 
 	t.Run("Set", func(t *testing.T) {
@@ -45,6 +48,61 @@ func TestStore(t *testing.T) {
 		require.ErrorIs(t, err, pebble.ErrNotFound)
 	})
 
+	t.Run("Keys", func(t *testing.T) {
+		for _, key := range []string{"a", "b/1", "b/2", "b/3", "c"} {
+			require.NoError(t, s.Set(key, "value"))
+		}
+		t.Cleanup(s.Clear)
+
+		tests := []struct {
+			title    string
+			prefix   string
+			after    string
+			limit    int
+			expected []string
+		}{
+			{title: "All", expected: []string{"a", "b/1", "b/2", "b/3", "c"}},
+			{title: "Prefix", prefix: "b/", expected: []string{"b/1", "b/2", "b/3"}},
+			{title: "After", prefix: "b/", after: "b/1", expected: []string{"b/2", "b/3"}},
+			{title: "Limit", prefix: "b/", limit: 2, expected: []string{"b/1", "b/2"}},
+			{title: "None", prefix: "d"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				keys, err := s.Keys(tt.prefix, tt.after, tt.limit)
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, keys)
+			})
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		for _, key := range []string{"a", "b/1", "b/2", "c"} {
+			require.NoError(t, s.Set(key, "value"))
+		}
+		t.Cleanup(s.Clear)
+
+		tests := []struct {
+			title    string
+			start    string
+			end      string
+			limit    int
+			expected []string
+		}{
+			{title: "Bounded", start: "a", end: "c", expected: []string{"a", "b/1", "b/2"}},
+			{title: "Unbounded", start: "b/2", expected: []string{"b/2", "c"}},
+			{title: "Limit", start: "a", end: "c", limit: 1, expected: []string{"a"}},
+			{title: "Empty", start: "b/3", end: "c"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				keys, err := s.Range(tt.start, tt.end, tt.limit)
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, keys)
+			})
+		}
+	})
+
 	t.Run("Dump", func(t *testing.T) {
 		err := s.Set("key", "value")
 		require.NoError(t, err)
@@ -63,3 +121,140 @@ func TestStore(t *testing.T) {
 		require.ErrorIs(t, err, pebble.ErrNotFound)
 	})
 }
+
+func TestStoreCollections(t *testing.T) {
+	t.Parallel()
+	s := persisted.New(t.TempDir())
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		// Act
+		created, err := s.HashSet("hash", "b", "1")
+		require.NoError(t, err)
+		_, err = s.HashSet("hash", "a", "2")
+		require.NoError(t, err)
+		updated, err := s.HashSet("hash", "b", "3")
+		require.NoError(t, err)
+		value, getErr := s.HashGet("hash", "b")
+		_, missingErr := s.HashGet("hash", "c")
+		fields, err := s.HashGetAll("hash")
+		require.NoError(t, err)
+		deleted, err := s.DeleteMember(store.TypeHash, "hash", "a")
+		require.NoError(t, err)
+		deletedAgain, err := s.DeleteMember(store.TypeHash, "hash", "a")
+		require.NoError(t, err)
+		typ, typeErr := s.CollectionType("hash")
+
+		// Assert
+		require.True(t, created)
+		require.False(t, updated)
+		require.NoError(t, getErr)
+		require.Equal(t, "3", value)
+		require.ErrorIs(t, missingErr, store.ErrNotFound)
+		require.Equal(t, []store.KeyValue{{Key: "a", Value: "2"}, {Key: "b", Value: "3"}}, fields)
+		require.True(t, deleted)
+		require.False(t, deletedAgain)
+		require.NoError(t, typeErr)
+		require.Equal(t, store.TypeHash, typ)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		// Act
+		added, err := s.SetAdd("set", "b")
+		require.NoError(t, err)
+		_, err = s.SetAdd("set", "a")
+		require.NoError(t, err)
+		addedAgain, err := s.SetAdd("set", "b")
+		require.NoError(t, err)
+		members, err := s.SetMembers("set")
+
+		// Assert
+		require.True(t, added)
+		require.False(t, addedAgain)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, members)
+	})
+
+	t.Run("Sorted set", func(t *testing.T) {
+		// Arrange
+		for _, member := range []store.ScoredMember{
+			{Member: "c", Score: 2.5},
+			{Member: "b", Score: -1},
+			{Member: "a", Score: 2.5},
+			{Member: "d", Score: math.Inf(-1)},
+			{Member: "e", Score: 10},
+		} {
+			added, err := s.SortedSetAdd("zset", member.Member, member.Score)
+			require.NoError(t, err)
+			require.True(t, added)
+		}
+
+		// Act
+		added, err := s.SortedSetAdd("zset", "e", 0)
+		require.NoError(t, err)
+		all, err := s.SortedSetRangeByScore("zset", math.Inf(-1), math.Inf(1), 0)
+		require.NoError(t, err)
+		bounded, err := s.SortedSetRangeByScore("zset", -1, 2.5, 2)
+		require.NoError(t, err)
+		deleted, err := s.DeleteMember(store.TypeSortedSet, "zset", "a")
+		require.NoError(t, err)
+		remaining, err := s.SortedSetRangeByScore("zset", 2, 3, 0)
+
+		// Assert
+		require.False(t, added, "the score is updated")
+		require.Equal(t, []store.ScoredMember{
+			{Member: "d", Score: math.Inf(-1)},
+			{Member: "b", Score: -1},
+			{Member: "e", Score: 0},
+			{Member: "a", Score: 2.5},
+			{Member: "c", Score: 2.5},
+		}, all)
+		require.Equal(t, []store.ScoredMember{{Member: "b", Score: -1}, {Member: "e", Score: 0}}, bounded)
+		require.True(t, deleted)
+		require.NoError(t, err)
+		require.Equal(t, []store.ScoredMember{{Member: "c", Score: 2.5}}, remaining)
+	})
+
+	t.Run("Collections are not keys", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, s.Set("key", "value"))
+
+		// Act
+		keys, err := s.Range("", "", 0)
+		require.NoError(t, err)
+		_, err = s.Get("set")
+
+		// Assert
+		require.Equal(t, []string{"key"}, keys)
+		require.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("Collection keys", func(t *testing.T) {
+		// Act
+		all, err := s.CollectionKeys("", "")
+		require.NoError(t, err)
+		bounded, err := s.CollectionKeys("hash", "set")
+
+		// Assert
+		require.ElementsMatch(t, []string{"hash", "set", "zset"}, all)
+		require.NoError(t, err)
+		require.Equal(t, []string{"hash"}, bounded)
+	})
+
+	t.Run("Delete a collection", func(t *testing.T) {
+		// Act
+		deleted, err := s.DeleteCollection("zset")
+		require.NoError(t, err)
+		deletedAgain, err := s.DeleteCollection("zset")
+		require.NoError(t, err)
+		typ, err := s.CollectionType("zset")
+
+		// Assert
+		require.True(t, deleted)
+		require.False(t, deletedAgain)
+		require.NoError(t, err)
+		require.Equal(t, store.TypeNone, typ)
+	})
+}
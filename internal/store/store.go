@@ -1,7 +1,131 @@
 package store
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrStaleRead is returned when the local state does not meet the consistency
+// requirements of a read. The read can be retried on another node.
+var ErrStaleRead = errors.New("stale read")
+
+// ErrNoLeader is returned when a write cannot be applied because the cluster
+// has no leader. The write can be retried once a leader is elected.
+var ErrNoLeader = errors.New("no leader")
+
+// ErrNotFound is returned when a key does not exist or is expired.
+var ErrNotFound = errors.New("not found")
+
+// ErrNotInteger is returned when incrementing a value which is not a 64-bit
+// integer.
+var ErrNotInteger = errors.New("value is not an integer")
+
+// ErrOverflow is returned when an increment overflows a 64-bit integer.
+var ErrOverflow = errors.New("increment would overflow")
+
+// ErrOutOfRange is returned when an increment is out of its bounds.
+var ErrOutOfRange = errors.New("increment out of range")
+
+// ErrWrongType is returned when a collection command is applied to a key
+// holding another type, or an increment to the key of a collection.
+var ErrWrongType = errors.New("key holds the wrong type")
+
+// ErrReservedKey is returned when writing a key with the reserved prefix.
+var ErrReservedKey = errors.New("key has the reserved prefix")
+
+// ReservedPrefix is the key prefix reserved by the stores for their metadata,
+// such as the records of the snapshots which are not keys. The writes of the
+// keys with this prefix are rejected with ErrReservedKey.
+const ReservedPrefix = "\x00"
+
+// CollectionPrefix is the reserved key prefix of the entries of the
+// collections in the storers. The keys with this prefix are not strings.
+const CollectionPrefix = ReservedPrefix + "collection/"
+
+// Type is the type of the value of a key.
+type Type int
+
+const (
+	// TypeNone is the type of a missing key.
+	TypeNone Type = iota
+	TypeString
+	TypeHash
+	TypeSet
+	TypeSortedSet
+)
+
+// String returns the name of the type, like the Redis TYPE command.
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeHash:
+		return "hash"
+	case TypeSet:
+		return "set"
+	case TypeSortedSet:
+		return "zset"
+	default:
+		return "none"
+	}
+}
+
+// ReadOptions are the consistency requirements of a read.
+type ReadOptions struct {
+	// MaxStaleness is the maximum staleness of the local state of a follower.
+	// Zero serves the local state, whatever its staleness.
+	MaxStaleness time.Duration
+	// MinIndex is the consistency token of the read: the Raft index which must
+	// be applied by the node before serving it.
+	MinIndex uint64
+	// Linearizable serves the read on the leader only, once the entries
+	// committed before the read are applied and the leadership is confirmed by
+	// a quorum.
+	Linearizable bool
+	// Timeout is the maximum duration to wait for MinIndex or a linearizable
+	// read. Zero uses the default timeout of the store.
+	Timeout time.Duration
+}
+
+// KeyValue is a key and its value.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// ScoredMember is a member of a sorted set and its score.
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// SetOptions are the condition and the expiration of a write.
+type SetOptions struct {
+	// IfAbsent only writes the keys if none of them exists.
+	IfAbsent bool
+	// IfExists only writes the keys if all of them exist.
+	IfExists bool
+	// TTL is the duration after which the keys expire. Zero never expires
+	// them.
+	TTL time.Duration
+}
+
+// IncrementOptions are the initial value and the bounds of an increment.
+type IncrementOptions struct {
+	// Initial is the value of a missing key, before the delta is added.
+	Initial int64
+	// Min and Max are the bounds of the new value, if set.
+	Min, Max *int64
+}
+
 type Store interface {
-	Get(key string) (string, error)
-	Set(key string, value string) error
-	Delete(key string) error
+	// Get gets the value of a key from the local state.
+	Get(key string, opts ReadOptions) (string, error)
+	// Set sets the value of a key and returns the Raft index of the write.
+	Set(key string, value string) (uint64, error)
+	// Delete deletes a key and returns the Raft index of the write.
+	Delete(key string) (uint64, error)
+	// Increment adds delta to the integer value of a key, and returns the Raft
+	// index of the write and the new value.
+	Increment(key string, delta int64, opts IncrementOptions) (uint64, int64, error)
 }
@@ -0,0 +1,362 @@
+//go:build linearizability
+
+package testcluster_test
+
+import (
+	"context"
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/testcluster"
+	"distributed-kv/pkg/client"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anishathalye/porcupine"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+var (
+	linearizabilityDuration = flag.Duration(
+		"linearizability.duration",
+		30*time.Second,
+		"Duration of the workload of the linearizability test",
+	)
+	linearizabilityClients = flag.Int(
+		"linearizability.clients",
+		8,
+		"Number of concurrent clients of the linearizability test",
+	)
+)
+
+const (
+	initialValue   = "init"
+	opTimeout      = 2 * time.Second
+	failureBackoff = 100 * time.Millisecond
+	checkTimeout   = 5 * time.Minute
+	minSuccessfuls = 100
+)
+
+var keys = []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+// kvOp is the kind of an operation of the history.
+type kvOp int
+
+const (
+	opGet kvOp = iota
+	opSet
+	opCAS
+)
+
+// kvInput is the input of an operation of the history.
+type kvInput struct {
+	op    kvOp
+	key   string
+	value string
+	// expected is the value compared by a compare-and-set.
+	expected string
+}
+
+// kvOutput is the output of an operation of the history.
+type kvOutput struct {
+	value string
+	// swapped is true if a compare-and-set succeeded.
+	swapped bool
+	// unknown is true if the outcome of a write is unknown.
+	unknown bool
+}
+
+// kvModel is the specification of a register per key. The keys are checked
+// independently.
+var kvModel = porcupine.Model{
+	Partition: func(history []porcupine.Operation) [][]porcupine.Operation {
+		byKey := make(map[string][]porcupine.Operation)
+		for _, op := range history {
+			key := op.Input.(kvInput).key
+			byKey[key] = append(byKey[key], op)
+		}
+		partitions := make([][]porcupine.Operation, 0, len(byKey))
+		for _, ops := range byKey {
+			partitions = append(partitions, ops)
+		}
+		return partitions
+	},
+	Init: func() interface{} {
+		return initialValue
+	},
+	Step: func(state, input, output interface{}) (bool, interface{}) {
+		in, out := input.(kvInput), output.(kvOutput)
+		switch in.op {
+		case opSet:
+			return true, in.value
+		case opCAS:
+			// A compare-and-set of unknown outcome which took effect
+			// succeeded if the value matched. If it did not take effect, it
+			// is linearized after the other operations.
+			if state.(string) == in.expected {
+				return out.swapped || out.unknown, in.value
+			}
+			return !out.swapped, state
+		default:
+			return out.value == state.(string), state
+		}
+	},
+	DescribeOperation: func(input, output interface{}) string {
+		in, out := input.(kvInput), output.(kvOutput)
+		switch in.op {
+		case opSet:
+			return fmt.Sprintf("set(%q, %q)", in.key, in.value)
+		case opCAS:
+			result := fmt.Sprint(out.swapped)
+			if out.unknown {
+				result = "unknown"
+			}
+			return fmt.Sprintf("cas(%q, %q, %q) -> %s", in.key, in.expected, in.value, result)
+		default:
+			return fmt.Sprintf("get(%q) -> %q", in.key, out.value)
+		}
+	},
+}
+
+// history records the operations of the clients.
+type history struct {
+	mu    sync.Mutex
+	start time.Time
+	ops   []porcupine.Operation
+	// clients is the number of client IDs. A client gets a new ID after an
+	// ambiguous operation, which never returns.
+	clients atomic.Int64
+	// successfuls is the number of successful operations.
+	successfuls atomic.Int64
+	// ambiguous is the number of failed writes, which may have been applied.
+	ambiguous atomic.Int64
+}
+
+func (h *history) now() int64 {
+	return time.Since(h.start).Nanoseconds()
+}
+
+func (h *history) record(op porcupine.Operation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ops = append(h.ops, op)
+}
+
+// runClient runs random operations until the context is done. The
+// compare-and-sets are etcd transactions comparing the value.
+func runClient(ctx context.Context, c *client.Client, etcd *clientv3.Client, h *history) {
+	id := int(h.clients.Add(1) - 1)
+	// seen is the last value of each key seen by the client, which is expected
+	// by its compare-and-sets.
+	seen := make(map[string]string, len(keys))
+	for seq := 0; ctx.Err() == nil; seq++ {
+		input := kvInput{op: kvOp(rand.IntN(3)), key: keys[rand.IntN(len(keys))]}
+		if input.op != opGet {
+			input.value = fmt.Sprintf("%d-%d", id, seq)
+		}
+		if input.op == opCAS {
+			input.expected = initialValue
+			if value, ok := seen[input.key]; ok {
+				input.expected = value
+			}
+		}
+		opCtx, cancel := context.WithTimeout(ctx, opTimeout)
+		call := h.now()
+		var output kvOutput
+		var err error
+		switch input.op {
+		case opSet:
+			_, err = c.Set(opCtx, input.key, input.value)
+		case opCAS:
+			var res *clientv3.TxnResponse
+			res, err = etcd.Txn(opCtx).
+				If(clientv3.Compare(clientv3.Value(input.key), "=", input.expected)).
+				Then(clientv3.OpPut(input.key, input.value)).
+				Commit()
+			if err == nil {
+				output.swapped = res.Succeeded
+			}
+		default:
+			output.value, err = c.Get(opCtx, input.key, client.WithLinearizable())
+		}
+		ret := h.now()
+		cancel()
+
+		var dialErr *net.OpError
+		switch {
+		case err == nil:
+			h.successfuls.Add(1)
+			switch {
+			case input.op == opGet:
+				seen[input.key] = output.value
+			case input.op == opSet || output.swapped:
+				seen[input.key] = input.value
+			}
+		case errors.As(err, &dialErr) && dialErr.Op == "dial":
+			// The request was never sent.
+			sleep(ctx, failureBackoff)
+			continue
+		case input.op != opGet:
+			// The write may have been applied: it may take effect at any time
+			// after its call.
+			ret = math.MaxInt64
+			output.unknown = true
+			h.ambiguous.Add(1)
+		default:
+			// A failed read has no effect.
+			sleep(ctx, failureBackoff)
+			continue
+		}
+		h.record(porcupine.Operation{
+			ClientId: id,
+			Input:    input,
+			Call:     call,
+			Output:   output,
+			Return:   ret,
+		})
+		if ret == math.MaxInt64 {
+			id = int(h.clients.Add(1) - 1)
+			// Back off, so that an unavailable cluster does not flood the history
+			// with ambiguous writes, which are expensive to check.
+			sleep(ctx, failureBackoff)
+		}
+	}
+}
+
+// runNemesis injects leader kills and partitions until the context is done.
+func runNemesis(ctx context.Context, t *testing.T, c *testcluster.Cluster) {
+	faults := []func(leader int){
+		func(leader int) {
+			t.Logf("nemesis: kill leader %s", c.ID(leader))
+			require.NoError(t, c.Kill(leader))
+			sleep(ctx, time.Second)
+			require.NoError(t, c.Restart(leader))
+		},
+		func(leader int) {
+			t.Logf("nemesis: isolate leader %s", c.ID(leader))
+			c.Partition(leader)
+			sleep(ctx, 2*time.Second)
+		},
+		func(int) {
+			minority := rand.Perm(c.Size())[:(c.Size()-1)/2]
+			t.Logf("nemesis: isolate %v", minority)
+			c.Partition(minority...)
+			sleep(ctx, 2*time.Second)
+		},
+		func(leader int) {
+			t.Logf("nemesis: leader %s cannot send to the followers", c.ID(leader))
+			for i := range c.Size() {
+				if i != leader {
+					c.Faults(leader).SetFaults(
+						c.PeerAddress(i),
+						distributed.Faults{Partitioned: true},
+					)
+				}
+			}
+			sleep(ctx, 2*time.Second)
+		},
+		func(leader int) {
+			t.Logf("nemesis: slow and lossy links from leader %s", c.ID(leader))
+			for i := range c.Size() {
+				if i != leader {
+					c.Faults(leader).SetFaults(c.PeerAddress(i), distributed.Faults{
+						Delay:    10 * time.Millisecond,
+						Jitter:   50 * time.Millisecond,
+						DropRate: 0.01,
+					})
+				}
+			}
+			sleep(ctx, 2*time.Second)
+		},
+	}
+	for ctx.Err() == nil {
+		sleep(ctx, time.Duration(500+rand.IntN(1500))*time.Millisecond)
+		leader, err := c.Leader()
+		if err != nil {
+			continue
+		}
+		faults[rand.IntN(len(faults))](leader)
+		c.Heal()
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// TestLinearizability runs concurrent clients against a cluster while
+// injecting failures, and checks that the history of the operations is
+// linearizable. The workload is made of Set, compare-and-set and linearizable
+// Get.
+//
+// It is opt-in: go test -tags=linearizability ./internal/testcluster/
+func TestLinearizability(t *testing.T) {
+	// Arrange
+	c := testcluster.New(t, 5)
+	ctx := context.Background()
+	seed, err := c.Client(0)
+	require.NoError(t, err)
+	for _, key := range keys {
+		_, err := seed.Set(ctx, key, initialValue)
+		require.NoError(t, err)
+	}
+	h := &history{start: time.Now()}
+
+	// Act
+	workloadCtx, cancel := context.WithTimeout(ctx, *linearizabilityDuration)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := range *linearizabilityClients {
+		cl, err := c.Client(i%c.Size(), client.WithMaxRetries(0))
+		require.NoError(t, err)
+		etcd, err := clientv3.New(clientv3.Config{
+			Endpoints:   []string{c.Server(i % c.Size()).ClientAddress()},
+			DialTimeout: opTimeout,
+			Logger:      zap.NewNop(),
+		})
+		require.NoError(t, err)
+		defer etcd.Close()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runClient(workloadCtx, cl, etcd, h)
+		}()
+	}
+	runNemesis(workloadCtx, t, c)
+	wg.Wait()
+
+	// Assert
+	t.Logf(
+		"%d operations, %d successful, %d ambiguous",
+		len(h.ops),
+		h.successfuls.Load(),
+		h.ambiguous.Load(),
+	)
+	require.GreaterOrEqual(t, h.successfuls.Load(), int64(minSuccessfuls))
+	result, info := porcupine.CheckOperationsVerbose(kvModel, h.ops, checkTimeout)
+	if result == porcupine.Unknown {
+		t.Fatalf(
+			"linearizability check timed out after %s, shorten the workload",
+			checkTimeout,
+		)
+	}
+	if result == porcupine.Illegal {
+		f, err := os.CreateTemp("", "dkv-linearizability-*.html")
+		require.NoError(t, err)
+		defer f.Close()
+		require.NoError(t, porcupine.Visualize(kvModel, info, f))
+		t.Fatalf("history is not linearizable, see %s", f.Name())
+	}
+}
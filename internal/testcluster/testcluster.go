@@ -0,0 +1,340 @@
+// Package testcluster starts in-process clusters of embedded nodes, with real
+// RPC handlers, for tests.
+//
+//	c := testcluster.New(t, 3)
+//	leader, err := c.WaitForLeader(5 * time.Second)
+//	c.Partition(leader)
+package testcluster
+
+import (
+	"context"
+	"crypto/tls"
+	"distributed-kv/internal/store/distributed"
+	internaltls "distributed-kv/internal/tls"
+	"distributed-kv/pkg/client"
+	"distributed-kv/pkg/server"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	joinInterval = 100 * time.Millisecond
+	pollInterval = 20 * time.Millisecond
+	startTimeout = 10 * time.Second
+)
+
+// ErrNoLeader is returned when no leader is recognized by a quorum.
+var ErrNoLeader = errors.New("no leader")
+
+type Options struct {
+	tls       bool
+	configure func(i int, config *server.Config)
+}
+
+type Option func(*Options)
+
+// WithTLS secures the peer and the client traffic with certificates generated
+// for the cluster.
+func WithTLS() Option {
+	return func(o *Options) {
+		o.tls = true
+	}
+}
+
+// WithConfig customizes the configuration of the i-th node.
+func WithConfig(configure func(i int, config *server.Config)) Option {
+	return func(o *Options) {
+		o.configure = configure
+	}
+}
+
+func applyOptions(opts []Option) Options {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// Cluster is a cluster of embedded nodes listening on ephemeral ports.
+type Cluster struct {
+	mu      sync.Mutex
+	configs []server.Config
+	servers []*server.Server
+	// faults inject the network faults of each node. They are kept when the
+	// nodes are restarted.
+	faults []*distributed.FaultInjector
+	// clientTLSConfig is the TLS configuration of the clients, if TLS is
+	// enabled.
+	clientTLSConfig *tls.Config
+
+	Options
+}
+
+// New starts a cluster of size nodes, and waits for all the nodes to join it.
+// The cluster is stopped at the end of the test.
+func New(t testing.TB, size int, opts ...Option) *Cluster {
+	t.Helper()
+
+	c := &Cluster{
+		configs: make([]server.Config, size),
+		servers: make([]*server.Server, size),
+		faults:  make([]*distributed.FaultInjector, size),
+		Options: applyOptions(opts),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, c.Close())
+	})
+
+	var ca *certificateAuthority
+	if c.tls {
+		var err error
+		ca, err = newCertificateAuthority(t.TempDir())
+		require.NoError(t, err)
+		certFile, keyFile, err := ca.issue("client")
+		require.NoError(t, err)
+		c.clientTLSConfig, err = internaltls.SetupClientTLSConfig(certFile, keyFile, ca.CAFile)
+		require.NoError(t, err)
+	}
+
+	peers := make([]server.Peer, size)
+	nodes := make(map[raft.ServerID]string, size)
+	clientAddrs := make([]string, size)
+	for i := range size {
+		id := raft.ServerID(fmt.Sprintf("node%d", i))
+		peers[i] = server.Peer{ID: id, Address: raft.ServerAddress(getRandomAddress(t))}
+		clientAddrs[i] = getRandomAddress(t)
+		nodes[id] = clientAddrs[i]
+	}
+	for i, peer := range peers {
+		c.faults[i] = distributed.NewFaultInjector()
+		config := server.Config{
+			Name:                string(peer.ID),
+			ListenPeerAddress:   string(peer.Address),
+			ListenClientAddress: clientAddrs[i],
+			AdvertiseNodes:      nodes,
+			InitialCluster:      peers,
+			InitialClusterState: server.ClusterStateNew,
+			JoinInterval:        joinInterval,
+			RaftConfig:          raftConfig(),
+			StreamLayer:         c.faults[i].Wrap,
+			DataDir:             t.TempDir(),
+		}
+		if ca != nil {
+			certFile, keyFile, err := ca.issue(string(peer.ID))
+			require.NoError(t, err)
+			config.CertFile, config.KeyFile, config.TrustedCAFile = certFile, keyFile, ca.CAFile
+			config.PeerCertFile, config.PeerKeyFile, config.PeerTrustedCAFile = certFile, keyFile, ca.CAFile
+		}
+		if c.configure != nil {
+			c.configure(i, &config)
+		}
+		c.configs[i] = config
+	}
+
+	for i := range size {
+		require.NoError(t, c.Restart(i))
+	}
+	require.NoError(t, c.waitForMembers(size, startTimeout))
+	return c
+}
+
+// raftConfig returns a Raft configuration with short timeouts, so that the
+// failures are detected quickly.
+func raftConfig() *raft.Config {
+	config := raft.DefaultConfig()
+	config.HeartbeatTimeout = 300 * time.Millisecond
+	config.ElectionTimeout = 300 * time.Millisecond
+	config.LeaderLeaseTimeout = 150 * time.Millisecond
+	config.CommitTimeout = 10 * time.Millisecond
+	return config
+}
+
+func getRandomAddress(t testing.TB) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+	return net.JoinHostPort("localhost", port)
+}
+
+// Size returns the number of nodes of the cluster, including the killed ones.
+func (c *Cluster) Size() int {
+	return len(c.configs)
+}
+
+// ID returns the ID of the i-th node.
+func (c *Cluster) ID(i int) raft.ServerID {
+	return raft.ServerID(c.configs[i].Name)
+}
+
+// Server returns the i-th node, or nil if it is killed.
+func (c *Cluster) Server(i int) *server.Server {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.servers[i]
+}
+
+// Client returns a client whose seed is the i-th node. The other members are
+// discovered, so that the writes are routed to the leader.
+func (c *Cluster) Client(i int, opts ...client.Option) (*client.Client, error) {
+	if c.clientTLSConfig != nil {
+		opts = append([]client.Option{client.WithTLSConfig(c.clientTLSConfig)}, opts...)
+	}
+	return client.New([]string{c.configs[i].ListenClientAddress}, opts...)
+}
+
+// Kill stops the i-th node. Its data directory is kept.
+func (c *Cluster) Kill(i int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	srv := c.servers[i]
+	if srv == nil {
+		return nil
+	}
+	c.servers[i] = nil
+	return srv.Stop()
+}
+
+// Restart starts the i-th node if it is killed.
+func (c *Cluster) Restart(i int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.servers[i] != nil {
+		return nil
+	}
+	srv := server.New(c.configs[i])
+	if err := srv.Start(context.Background()); err != nil {
+		return err
+	}
+	c.servers[i] = srv
+	return nil
+}
+
+// PeerAddress returns the Raft address of the i-th node.
+func (c *Cluster) PeerAddress(i int) raft.ServerAddress {
+	return raft.ServerAddress(c.configs[i].ListenPeerAddress)
+}
+
+// Faults returns the fault injector of the connections dialed by the i-th node,
+// for example to delay or drop its RPCs, or to partition it asymmetrically:
+//
+//	c.Faults(0).SetFaults(c.PeerAddress(1), distributed.Faults{Partitioned: true})
+func (c *Cluster) Faults(i int) *distributed.FaultInjector {
+	return c.faults[i]
+}
+
+// Partition isolates the given nodes from the other nodes. The nodes of each
+// side can still reach each other.
+func (c *Cluster) Partition(nodes ...int) {
+	partitioned := distributed.Faults{Partitioned: true}
+	for i := range c.configs {
+		if slices.Contains(nodes, i) {
+			continue
+		}
+		for _, j := range nodes {
+			c.faults[i].SetFaults(c.PeerAddress(j), partitioned)
+			c.faults[j].SetFaults(c.PeerAddress(i), partitioned)
+		}
+	}
+}
+
+// Heal removes the partitions and the other faults.
+func (c *Cluster) Heal() {
+	for _, faults := range c.faults {
+		faults.Clear()
+	}
+}
+
+// Leader returns the node which is the leader, recognized by a quorum of the
+// nodes it can reach.
+func (c *Cluster) Leader() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, srv := range c.servers {
+		if srv == nil || !srv.Store().IsLeader() {
+			continue
+		}
+		votes := 0
+		for j, peer := range c.servers {
+			if peer == nil || !c.connected(i, j) {
+				continue
+			}
+			if _, id := peer.Store().GetLeader(); id == c.ID(i) {
+				votes++
+			}
+		}
+		if votes > len(c.servers)/2 {
+			return i, nil
+		}
+	}
+	return -1, ErrNoLeader
+}
+
+// WaitForLeader waits for a leader recognized by a quorum, and returns it.
+func (c *Cluster) WaitForLeader(timeout time.Duration) (int, error) {
+	var leader int
+	err := poll(timeout, func() (err error) {
+		leader, err = c.Leader()
+		return err
+	})
+	return leader, err
+}
+
+// waitForMembers waits for the leader to have the given number of members.
+func (c *Cluster) waitForMembers(members int, timeout time.Duration) error {
+	return poll(timeout, func() error {
+		leader, err := c.Leader()
+		if err != nil {
+			return err
+		}
+		servers, err := c.Server(leader).Store().GetServers()
+		if err != nil {
+			return err
+		}
+		if len(servers) != members {
+			return fmt.Errorf("%d members out of %d", len(servers), members)
+		}
+		return nil
+	})
+}
+
+// connected returns true if the i-th and j-th nodes can reach each other.
+func (c *Cluster) connected(i, j int) bool {
+	return !c.faults[i].Faults(c.PeerAddress(j)).Partitioned &&
+		!c.faults[j].Faults(c.PeerAddress(i)).Partitioned
+}
+
+// Close stops all the nodes.
+func (c *Cluster) Close() error {
+	var errs []error
+	for i := range c.configs {
+		errs = append(errs, c.Kill(i))
+	}
+	return errors.Join(errs...)
+}
+
+// poll calls fn until it succeeds or the timeout expires, and returns its last
+// error.
+func poll(timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := fn()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(pollInterval)
+	}
+}
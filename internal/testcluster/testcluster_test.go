@@ -0,0 +1,92 @@
+package testcluster_test
+
+import (
+	"context"
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/testcluster"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 10 * time.Second
+
+func TestCluster(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title string
+		opts  []testcluster.Option
+	}{
+		{
+			title: "Plaintext",
+		},
+		{
+			title: "TLS",
+			opts:  []testcluster.Option{testcluster.WithTLS()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			c := testcluster.New(t, 3, tt.opts...)
+			ctx := context.Background()
+			leader, err := c.WaitForLeader(timeout)
+			require.NoError(t, err)
+			follower := (leader + 1) % c.Size()
+			cl, err := c.Client(follower)
+			require.NoError(t, err)
+			_, err = cl.Set(ctx, "key", "value")
+			require.NoError(t, err)
+
+			// Act
+			c.Partition(leader)
+			newLeader, err := c.WaitForLeader(timeout)
+			require.NoError(t, err)
+			_, err = cl.Set(ctx, "key", "new value")
+			require.NoError(t, err)
+			require.NoError(t, c.Kill(newLeader))
+			c.Heal()
+			require.NoError(t, c.Restart(newLeader))
+			_, err = c.WaitForLeader(timeout)
+			require.NoError(t, err)
+
+			// Assert
+			require.NotEqual(t, leader, newLeader)
+			value, err := cl.Get(ctx, "key")
+			require.NoError(t, err)
+			require.Equal(t, "new value", value)
+		})
+	}
+}
+
+func TestClusterAsymmetricPartition(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	c := testcluster.New(t, 3)
+	ctx := context.Background()
+	leader, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	cl, err := c.Client(leader)
+	require.NoError(t, err)
+
+	// Act
+	// The leader receives the RPCs of the followers, but cannot send its own.
+	for i := range c.Size() {
+		if i != leader {
+			c.Faults(leader).SetFaults(c.PeerAddress(i), distributed.Faults{Partitioned: true})
+		}
+	}
+	newLeader, err := c.WaitForLeader(timeout)
+	require.NoError(t, err)
+	_, err = cl.Set(ctx, "key", "value")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEqual(t, leader, newLeader)
+}
@@ -2,7 +2,10 @@
 
 package mockdistributed
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	store "distributed-kv/internal/store"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Storer is an autogenerated mock type for the Storer type
 type Storer struct {
@@ -49,6 +52,121 @@ func (_c *Storer_Clear_Call) RunAndReturn(run func()) *Storer_Clear_Call {
 	return _c
 }
 
+// CollectionKeys provides a mock function with given fields: start, end
+func (_m *Storer) CollectionKeys(start string, end string) ([]string, error) {
+	ret := _m.Called(start, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CollectionKeys")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
+		return rf(start, end)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_CollectionKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CollectionKeys'
+type Storer_CollectionKeys_Call struct {
+	*mock.Call
+}
+
+// CollectionKeys is a helper method to define mock.On call
+//   - start string
+//   - end string
+func (_e *Storer_Expecter) CollectionKeys(start interface{}, end interface{}) *Storer_CollectionKeys_Call {
+	return &Storer_CollectionKeys_Call{Call: _e.mock.On("CollectionKeys", start, end)}
+}
+
+func (_c *Storer_CollectionKeys_Call) Run(run func(start string, end string)) *Storer_CollectionKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_CollectionKeys_Call) Return(_a0 []string, _a1 error) *Storer_CollectionKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_CollectionKeys_Call) RunAndReturn(run func(string, string) ([]string, error)) *Storer_CollectionKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CollectionType provides a mock function with given fields: key
+func (_m *Storer) CollectionType(key string) (store.Type, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CollectionType")
+	}
+
+	var r0 store.Type
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (store.Type, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) store.Type); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(store.Type)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_CollectionType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CollectionType'
+type Storer_CollectionType_Call struct {
+	*mock.Call
+}
+
+// CollectionType is a helper method to define mock.On call
+//   - key string
+func (_e *Storer_Expecter) CollectionType(key interface{}) *Storer_CollectionType_Call {
+	return &Storer_CollectionType_Call{Call: _e.mock.On("CollectionType", key)}
+}
+
+func (_c *Storer_CollectionType_Call) Run(run func(key string)) *Storer_CollectionType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_CollectionType_Call) Return(_a0 store.Type, _a1 error) *Storer_CollectionType_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_CollectionType_Call) RunAndReturn(run func(string) (store.Type, error)) *Storer_CollectionType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Delete provides a mock function with given fields: key
 func (_m *Storer) Delete(key string) error {
 	ret := _m.Called(key)
@@ -95,6 +213,120 @@ func (_c *Storer_Delete_Call) RunAndReturn(run func(string) error) *Storer_Delet
 	return _c
 }
 
+// DeleteCollection provides a mock function with given fields: key
+func (_m *Storer) DeleteCollection(key string) (bool, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCollection")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_DeleteCollection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCollection'
+type Storer_DeleteCollection_Call struct {
+	*mock.Call
+}
+
+// DeleteCollection is a helper method to define mock.On call
+//   - key string
+func (_e *Storer_Expecter) DeleteCollection(key interface{}) *Storer_DeleteCollection_Call {
+	return &Storer_DeleteCollection_Call{Call: _e.mock.On("DeleteCollection", key)}
+}
+
+func (_c *Storer_DeleteCollection_Call) Run(run func(key string)) *Storer_DeleteCollection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_DeleteCollection_Call) Return(_a0 bool, _a1 error) *Storer_DeleteCollection_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_DeleteCollection_Call) RunAndReturn(run func(string) (bool, error)) *Storer_DeleteCollection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMember provides a mock function with given fields: t, key, member
+func (_m *Storer) DeleteMember(t store.Type, key string, member string) (bool, error) {
+	ret := _m.Called(t, key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(store.Type, string, string) (bool, error)); ok {
+		return rf(t, key, member)
+	}
+	if rf, ok := ret.Get(0).(func(store.Type, string, string) bool); ok {
+		r0 = rf(t, key, member)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(store.Type, string, string) error); ok {
+		r1 = rf(t, key, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_DeleteMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMember'
+type Storer_DeleteMember_Call struct {
+	*mock.Call
+}
+
+// DeleteMember is a helper method to define mock.On call
+//   - t store.Type
+//   - key string
+//   - member string
+func (_e *Storer_Expecter) DeleteMember(t interface{}, key interface{}, member interface{}) *Storer_DeleteMember_Call {
+	return &Storer_DeleteMember_Call{Call: _e.mock.On("DeleteMember", t, key, member)}
+}
+
+func (_c *Storer_DeleteMember_Call) Run(run func(t store.Type, key string, member string)) *Storer_DeleteMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(store.Type), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_DeleteMember_Call) Return(_a0 bool, _a1 error) *Storer_DeleteMember_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_DeleteMember_Call) RunAndReturn(run func(store.Type, string, string) (bool, error)) *Storer_DeleteMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Dump provides a mock function with given fields:
 func (_m *Storer) Dump() map[string]string {
 	ret := _m.Called()
@@ -198,6 +430,239 @@ func (_c *Storer_Get_Call) RunAndReturn(run func(string) (string, error)) *Store
 	return _c
 }
 
+// HashGet provides a mock function with given fields: key, field
+func (_m *Storer) HashGet(key string, field string) (string, error) {
+	ret := _m.Called(key, field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashGet")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(key, field)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(key, field)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, field)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_HashGet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashGet'
+type Storer_HashGet_Call struct {
+	*mock.Call
+}
+
+// HashGet is a helper method to define mock.On call
+//   - key string
+//   - field string
+func (_e *Storer_Expecter) HashGet(key interface{}, field interface{}) *Storer_HashGet_Call {
+	return &Storer_HashGet_Call{Call: _e.mock.On("HashGet", key, field)}
+}
+
+func (_c *Storer_HashGet_Call) Run(run func(key string, field string)) *Storer_HashGet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_HashGet_Call) Return(_a0 string, _a1 error) *Storer_HashGet_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_HashGet_Call) RunAndReturn(run func(string, string) (string, error)) *Storer_HashGet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HashGetAll provides a mock function with given fields: key
+func (_m *Storer) HashGetAll(key string) ([]store.KeyValue, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashGetAll")
+	}
+
+	var r0 []store.KeyValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]store.KeyValue, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) []store.KeyValue); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.KeyValue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_HashGetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashGetAll'
+type Storer_HashGetAll_Call struct {
+	*mock.Call
+}
+
+// HashGetAll is a helper method to define mock.On call
+//   - key string
+func (_e *Storer_Expecter) HashGetAll(key interface{}) *Storer_HashGetAll_Call {
+	return &Storer_HashGetAll_Call{Call: _e.mock.On("HashGetAll", key)}
+}
+
+func (_c *Storer_HashGetAll_Call) Run(run func(key string)) *Storer_HashGetAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_HashGetAll_Call) Return(_a0 []store.KeyValue, _a1 error) *Storer_HashGetAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_HashGetAll_Call) RunAndReturn(run func(string) ([]store.KeyValue, error)) *Storer_HashGetAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HashSet provides a mock function with given fields: key, field, value
+func (_m *Storer) HashSet(key string, field string, value string) (bool, error) {
+	ret := _m.Called(key, field, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashSet")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (bool, error)); ok {
+		return rf(key, field, value)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(key, field, value)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(key, field, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_HashSet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashSet'
+type Storer_HashSet_Call struct {
+	*mock.Call
+}
+
+// HashSet is a helper method to define mock.On call
+//   - key string
+//   - field string
+//   - value string
+func (_e *Storer_Expecter) HashSet(key interface{}, field interface{}, value interface{}) *Storer_HashSet_Call {
+	return &Storer_HashSet_Call{Call: _e.mock.On("HashSet", key, field, value)}
+}
+
+func (_c *Storer_HashSet_Call) Run(run func(key string, field string, value string)) *Storer_HashSet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_HashSet_Call) Return(_a0 bool, _a1 error) *Storer_HashSet_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_HashSet_Call) RunAndReturn(run func(string, string, string) (bool, error)) *Storer_HashSet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Range provides a mock function with given fields: start, end, limit
+func (_m *Storer) Range(start string, end string, limit int) ([]string, error) {
+	ret := _m.Called(start, end, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Range")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) ([]string, error)); ok {
+		return rf(start, end, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) []string); ok {
+		r0 = rf(start, end, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(start, end, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_Range_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Range'
+type Storer_Range_Call struct {
+	*mock.Call
+}
+
+// Range is a helper method to define mock.On call
+//   - start string
+//   - end string
+//   - limit int
+func (_e *Storer_Expecter) Range(start interface{}, end interface{}, limit interface{}) *Storer_Range_Call {
+	return &Storer_Range_Call{Call: _e.mock.On("Range", start, end, limit)}
+}
+
+func (_c *Storer_Range_Call) Run(run func(start string, end string, limit int)) *Storer_Range_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Storer_Range_Call) Return(_a0 []string, _a1 error) *Storer_Range_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_Range_Call) RunAndReturn(run func(string, string, int) ([]string, error)) *Storer_Range_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Set provides a mock function with given fields: key, value
 func (_m *Storer) Set(key string, value string) error {
 	ret := _m.Called(key, value)
@@ -245,6 +710,240 @@ func (_c *Storer_Set_Call) RunAndReturn(run func(string, string) error) *Storer_
 	return _c
 }
 
+// SetAdd provides a mock function with given fields: key, member
+func (_m *Storer) SetAdd(key string, member string) (bool, error) {
+	ret := _m.Called(key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAdd")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
+		return rf(key, member)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(key, member)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_SetAdd_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAdd'
+type Storer_SetAdd_Call struct {
+	*mock.Call
+}
+
+// SetAdd is a helper method to define mock.On call
+//   - key string
+//   - member string
+func (_e *Storer_Expecter) SetAdd(key interface{}, member interface{}) *Storer_SetAdd_Call {
+	return &Storer_SetAdd_Call{Call: _e.mock.On("SetAdd", key, member)}
+}
+
+func (_c *Storer_SetAdd_Call) Run(run func(key string, member string)) *Storer_SetAdd_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_SetAdd_Call) Return(_a0 bool, _a1 error) *Storer_SetAdd_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_SetAdd_Call) RunAndReturn(run func(string, string) (bool, error)) *Storer_SetAdd_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMembers provides a mock function with given fields: key
+func (_m *Storer) SetMembers(key string) ([]string, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMembers")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_SetMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMembers'
+type Storer_SetMembers_Call struct {
+	*mock.Call
+}
+
+// SetMembers is a helper method to define mock.On call
+//   - key string
+func (_e *Storer_Expecter) SetMembers(key interface{}) *Storer_SetMembers_Call {
+	return &Storer_SetMembers_Call{Call: _e.mock.On("SetMembers", key)}
+}
+
+func (_c *Storer_SetMembers_Call) Run(run func(key string)) *Storer_SetMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Storer_SetMembers_Call) Return(_a0 []string, _a1 error) *Storer_SetMembers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_SetMembers_Call) RunAndReturn(run func(string) ([]string, error)) *Storer_SetMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SortedSetAdd provides a mock function with given fields: key, member, score
+func (_m *Storer) SortedSetAdd(key string, member string, score float64) (bool, error) {
+	ret := _m.Called(key, member, score)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SortedSetAdd")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, float64) (bool, error)); ok {
+		return rf(key, member, score)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, float64) bool); ok {
+		r0 = rf(key, member, score)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, float64) error); ok {
+		r1 = rf(key, member, score)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_SortedSetAdd_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SortedSetAdd'
+type Storer_SortedSetAdd_Call struct {
+	*mock.Call
+}
+
+// SortedSetAdd is a helper method to define mock.On call
+//   - key string
+//   - member string
+//   - score float64
+func (_e *Storer_Expecter) SortedSetAdd(key interface{}, member interface{}, score interface{}) *Storer_SortedSetAdd_Call {
+	return &Storer_SortedSetAdd_Call{Call: _e.mock.On("SortedSetAdd", key, member, score)}
+}
+
+func (_c *Storer_SortedSetAdd_Call) Run(run func(key string, member string, score float64)) *Storer_SortedSetAdd_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(float64))
+	})
+	return _c
+}
+
+func (_c *Storer_SortedSetAdd_Call) Return(_a0 bool, _a1 error) *Storer_SortedSetAdd_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_SortedSetAdd_Call) RunAndReturn(run func(string, string, float64) (bool, error)) *Storer_SortedSetAdd_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SortedSetRangeByScore provides a mock function with given fields: key, minimum, maximum, limit
+func (_m *Storer) SortedSetRangeByScore(key string, minimum float64, maximum float64, limit int) ([]store.ScoredMember, error) {
+	ret := _m.Called(key, minimum, maximum, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SortedSetRangeByScore")
+	}
+
+	var r0 []store.ScoredMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, float64, float64, int) ([]store.ScoredMember, error)); ok {
+		return rf(key, minimum, maximum, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64, float64, int) []store.ScoredMember); ok {
+		r0 = rf(key, minimum, maximum, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.ScoredMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64, float64, int) error); ok {
+		r1 = rf(key, minimum, maximum, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storer_SortedSetRangeByScore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SortedSetRangeByScore'
+type Storer_SortedSetRangeByScore_Call struct {
+	*mock.Call
+}
+
+// SortedSetRangeByScore is a helper method to define mock.On call
+//   - key string
+//   - minimum float64
+//   - maximum float64
+//   - limit int
+func (_e *Storer_Expecter) SortedSetRangeByScore(key interface{}, minimum interface{}, maximum interface{}, limit interface{}) *Storer_SortedSetRangeByScore_Call {
+	return &Storer_SortedSetRangeByScore_Call{Call: _e.mock.On("SortedSetRangeByScore", key, minimum, maximum, limit)}
+}
+
+func (_c *Storer_SortedSetRangeByScore_Call) Run(run func(key string, minimum float64, maximum float64, limit int)) *Storer_SortedSetRangeByScore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(float64), args[2].(float64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Storer_SortedSetRangeByScore_Call) Return(_a0 []store.ScoredMember, _a1 error) *Storer_SortedSetRangeByScore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storer_SortedSetRangeByScore_Call) RunAndReturn(run func(string, float64, float64, int) ([]store.ScoredMember, error)) *Storer_SortedSetRangeByScore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewStorer creates a new instance of Storer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStorer(t interface {
@@ -2,7 +2,10 @@
 
 package mockstore
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	store "distributed-kv/internal/store"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Store is an autogenerated mock type for the Store type
 type Store struct {
@@ -18,21 +21,31 @@ func (_m *Store) EXPECT() *Store_Expecter {
 }
 
 // Delete provides a mock function with given fields: key
-func (_m *Store) Delete(key string) error {
+func (_m *Store) Delete(key string) (uint64, error) {
 	ret := _m.Called(key)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (uint64, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) uint64); ok {
 		r0 = rf(key)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(uint64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Store_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
@@ -53,19 +66,19 @@ func (_c *Store_Delete_Call) Run(run func(key string)) *Store_Delete_Call {
 	return _c
 }
 
-func (_c *Store_Delete_Call) Return(_a0 error) *Store_Delete_Call {
-	_c.Call.Return(_a0)
+func (_c *Store_Delete_Call) Return(_a0 uint64, _a1 error) *Store_Delete_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Store_Delete_Call) RunAndReturn(run func(string) error) *Store_Delete_Call {
+func (_c *Store_Delete_Call) RunAndReturn(run func(string) (uint64, error)) *Store_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function with given fields: key
-func (_m *Store) Get(key string) (string, error) {
-	ret := _m.Called(key)
+// Get provides a mock function with given fields: key, opts
+func (_m *Store) Get(key string, opts store.ReadOptions) (string, error) {
+	ret := _m.Called(key, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
@@ -73,17 +86,17 @@ func (_m *Store) Get(key string) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(key)
+	if rf, ok := ret.Get(0).(func(string, store.ReadOptions) (string, error)); ok {
+		return rf(key, opts)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(key)
+	if rf, ok := ret.Get(0).(func(string, store.ReadOptions) string); ok {
+		r0 = rf(key, opts)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(key)
+	if rf, ok := ret.Get(1).(func(string, store.ReadOptions) error); ok {
+		r1 = rf(key, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -98,13 +111,14 @@ type Store_Get_Call struct {
 
 // Get is a helper method to define mock.On call
 //   - key string
-func (_e *Store_Expecter) Get(key interface{}) *Store_Get_Call {
-	return &Store_Get_Call{Call: _e.mock.On("Get", key)}
+//   - opts store.ReadOptions
+func (_e *Store_Expecter) Get(key interface{}, opts interface{}) *Store_Get_Call {
+	return &Store_Get_Call{Call: _e.mock.On("Get", key, opts)}
 }
 
-func (_c *Store_Get_Call) Run(run func(key string)) *Store_Get_Call {
+func (_c *Store_Get_Call) Run(run func(key string, opts store.ReadOptions)) *Store_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(store.ReadOptions))
 	})
 	return _c
 }
@@ -114,27 +128,102 @@ func (_c *Store_Get_Call) Return(_a0 string, _a1 error) *Store_Get_Call {
 	return _c
 }
 
-func (_c *Store_Get_Call) RunAndReturn(run func(string) (string, error)) *Store_Get_Call {
+func (_c *Store_Get_Call) RunAndReturn(run func(string, store.ReadOptions) (string, error)) *Store_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Increment provides a mock function with given fields: key, delta, opts
+func (_m *Store) Increment(key string, delta int64, opts store.IncrementOptions) (uint64, int64, error) {
+	ret := _m.Called(key, delta, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Increment")
+	}
+
+	var r0 uint64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, int64, store.IncrementOptions) (uint64, int64, error)); ok {
+		return rf(key, delta, opts)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, store.IncrementOptions) uint64); ok {
+		r0 = rf(key, delta, opts)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, store.IncrementOptions) int64); ok {
+		r1 = rf(key, delta, opts)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, int64, store.IncrementOptions) error); ok {
+		r2 = rf(key, delta, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Store_Increment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Increment'
+type Store_Increment_Call struct {
+	*mock.Call
+}
+
+// Increment is a helper method to define mock.On call
+//   - key string
+//   - delta int64
+//   - opts store.IncrementOptions
+func (_e *Store_Expecter) Increment(key interface{}, delta interface{}, opts interface{}) *Store_Increment_Call {
+	return &Store_Increment_Call{Call: _e.mock.On("Increment", key, delta, opts)}
+}
+
+func (_c *Store_Increment_Call) Run(run func(key string, delta int64, opts store.IncrementOptions)) *Store_Increment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int64), args[2].(store.IncrementOptions))
+	})
+	return _c
+}
+
+func (_c *Store_Increment_Call) Return(_a0 uint64, _a1 int64, _a2 error) *Store_Increment_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Store_Increment_Call) RunAndReturn(run func(string, int64, store.IncrementOptions) (uint64, int64, error)) *Store_Increment_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Set provides a mock function with given fields: key, value
-func (_m *Store) Set(key string, value string) error {
+func (_m *Store) Set(key string, value string) (uint64, error) {
 	ret := _m.Called(key, value)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Set")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (uint64, error)); ok {
+		return rf(key, value)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) uint64); ok {
 		r0 = rf(key, value)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(uint64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Store_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
@@ -156,12 +245,12 @@ func (_c *Store_Set_Call) Run(run func(key string, value string)) *Store_Set_Cal
 	return _c
 }
 
-func (_c *Store_Set_Call) Return(_a0 error) *Store_Set_Call {
-	_c.Call.Return(_a0)
+func (_c *Store_Set_Call) Return(_a0 uint64, _a1 error) *Store_Set_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Store_Set_Call) RunAndReturn(run func(string, string) error) *Store_Set_Call {
+func (_c *Store_Set_Call) RunAndReturn(run func(string, string) (uint64, error)) *Store_Set_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,583 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"golang.org/x/net/http2"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultMinBackoff = 50 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// ErrNoEndpoint is returned when a client is created without endpoint.
+var ErrNoEndpoint = errors.New("no endpoint")
+
+// Client is a client of a cluster.
+//
+// The members of the cluster are discovered from the endpoints. The writes and
+// the administrative operations are routed to the leader, and the reads are
+// spread across the members. The operations are retried with backoff on leader
+// changes and transport errors. The consistency tokens of the writes are
+// tracked by a Session, so that the reads observe them.
+//
+// A Client is safe for concurrent use.
+type Client struct {
+	scheme string
+	seeds  []string
+
+	mu        sync.Mutex
+	endpoints []string
+	leader    string
+	next      int
+	nodes     map[string]*node
+
+	Options
+}
+
+// node are the API clients of a member.
+type node struct {
+	dkv        dkvv1connect.DkvAPIClient
+	membership dkvv1connect.MembershipAPIClient
+	admin      dkvv1connect.AdminAPIClient
+	lock       dkvv1connect.LockAPIClient
+	queue      dkvv1connect.QueueAPIClient
+}
+
+// Options are the options of a Client.
+type Options struct {
+	httpClient     connect.HTTPClient
+	tlsConfig      *tls.Config
+	session        *Session
+	maxRetries     int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	connectOptions []connect.ClientOption
+}
+
+// Option configures a Client.
+type Option func(*Options)
+
+// WithHTTPClient sets the HTTP client. By default, an HTTP/2 client is used,
+// over TLS if a TLS configuration is set.
+func WithHTTPClient(client connect.HTTPClient) Option {
+	return func(o *Options) {
+		o.httpClient = client
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the default HTTP client. The
+// endpoints without scheme are reached over HTTPS.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *Options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithSession sets the session tracking the consistency tokens, which can be
+// shared by multiple clients. By default, each client has its own session.
+func WithSession(session *Session) Option {
+	return func(o *Options) {
+		o.session = session
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries of an operation.
+func WithMaxRetries(retries int) Option {
+	return func(o *Options) {
+		o.maxRetries = retries
+	}
+}
+
+// WithBackoff sets the minimum and maximum delay between two retries. The delay
+// doubles at each retry.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(o *Options) {
+		o.minBackoff = minBackoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithConnectOptions adds options to the Connect clients. The gRPC protocol is
+// used by default.
+func WithConnectOptions(opts ...connect.ClientOption) Option {
+	return func(o *Options) {
+		o.connectOptions = append(o.connectOptions, opts...)
+	}
+}
+
+func applyOptions(opts []Option) Options {
+	o := Options{
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.session == nil {
+		o.session = &Session{}
+	}
+	if o.httpClient == nil {
+		o.httpClient = newHTTPClient(o.tlsConfig)
+	}
+	return o
+}
+
+// New creates a client of the cluster reachable at the endpoints.
+//
+// An endpoint is either an URL or an address, which is reached over HTTPS if a
+// TLS configuration is set. The discovered members are reached with the scheme
+// of the first endpoint.
+func New(endpoints []string, opts ...Option) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	c := &Client{
+		nodes:   make(map[string]*node),
+		Options: applyOptions(opts),
+	}
+	defaultScheme := "http://"
+	if c.tlsConfig != nil {
+		defaultScheme = "https://"
+	}
+	for _, endpoint := range endpoints {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = defaultScheme + endpoint
+		}
+		c.seeds = append(c.seeds, endpoint)
+	}
+	c.scheme = c.seeds[0][:strings.Index(c.seeds[0], "://")+3]
+	c.endpoints = c.seeds
+	return c, nil
+}
+
+func newHTTPClient(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				conn, err := d.DialContext(ctx, network, addr)
+				if tlsConfig != nil {
+					serverName, _, serr := net.SplitHostPort(addr)
+					if serr != nil {
+						serverName = addr
+					}
+					tlsConfig := tlsConfig.Clone()
+					tlsConfig.ServerName = serverName
+					return tls.Client(conn, tlsConfig), err
+				}
+				return conn, err
+			},
+		},
+	}
+}
+
+// Session returns the session tracking the consistency tokens of the client.
+func (c *Client) Session() *Session {
+	return c.session
+}
+
+// Discover refreshes the members and the leader of the cluster, from the first
+// endpoint which responds.
+func (c *Client) Discover(ctx context.Context) error {
+	var errs []error
+	for _, endpoint := range c.candidates() {
+		res, err := c.node(endpoint).membership.GetServers(
+			ctx,
+			connect.NewRequest(&dkvv1.GetServersRequest{}),
+		)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.update(res.Msg.GetServers())
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// candidates returns the known endpoints, followed by the seeds.
+func (c *Client) candidates() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	candidates := make([]string, 0, len(c.endpoints)+len(c.seeds))
+	seen := make(map[string]bool)
+	for _, endpoints := range [][]string{c.endpoints, c.seeds} {
+		for _, endpoint := range endpoints {
+			if !seen[endpoint] {
+				seen[endpoint] = true
+				candidates = append(candidates, endpoint)
+			}
+		}
+	}
+	return candidates
+}
+
+func (c *Client) update(servers []*dkvv1.Server) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	endpoints := make([]string, 0, len(servers))
+	leader := ""
+	for _, server := range servers {
+		if server.GetRpcAddress() == "" {
+			continue
+		}
+		endpoint := c.scheme + server.GetRpcAddress()
+		endpoints = append(endpoints, endpoint)
+		if server.GetIsLeader() {
+			leader = endpoint
+		}
+	}
+	// Without advertised addresses, the seeds are the only known endpoints.
+	if len(endpoints) > 0 {
+		c.endpoints = endpoints
+	}
+	c.leader = leader
+}
+
+func (c *Client) node(endpoint string) *node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, ok := c.nodes[endpoint]
+	if !ok {
+		opts := append([]connect.ClientOption{connect.WithGRPC()}, c.connectOptions...)
+		opts = append(opts, connect.WithInterceptors(c.session))
+		n = &node{
+			dkv:        dkvv1connect.NewDkvAPIClient(c.httpClient, endpoint, opts...),
+			membership: dkvv1connect.NewMembershipAPIClient(c.httpClient, endpoint, opts...),
+			admin:      dkvv1connect.NewAdminAPIClient(c.httpClient, endpoint, opts...),
+			lock:       dkvv1connect.NewLockAPIClient(c.httpClient, endpoint, opts...),
+			queue:      dkvv1connect.NewQueueAPIClient(c.httpClient, endpoint, opts...),
+		}
+		c.nodes[endpoint] = n
+	}
+	return n
+}
+
+// member returns the API clients of the member reached at the endpoint, which
+// is either an URL or an address.
+func (c *Client) member(endpoint string) *node {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = c.scheme + endpoint
+	}
+	return c.node(endpoint)
+}
+
+// pick returns the endpoint of the leader, or the next endpoint.
+func (c *Client) pick(ctx context.Context, leader bool) string {
+	c.mu.Lock()
+	known := c.leader
+	c.mu.Unlock()
+	if leader && known == "" {
+		// The error is ignored: any member forwards the writes to the leader.
+		_ = c.Discover(ctx)
+		c.mu.Lock()
+		known = c.leader
+		c.mu.Unlock()
+	}
+	if leader && known != "" {
+		return known
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	endpoint := c.endpoints[c.next%len(c.endpoints)]
+	c.next++
+	return endpoint
+}
+
+// forget forgets the leader if it is the endpoint, so that it is discovered
+// again.
+func (c *Client) forget(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.leader == endpoint {
+		c.leader = ""
+	}
+}
+
+// do calls fn on a member, and retries the retryable errors with backoff.
+func (c *Client) do(ctx context.Context, leader bool, fn func(*node) error) error {
+	return c.retry(ctx, leader, IsRetryable, fn)
+}
+
+// retry calls fn on a member, and retries the errors for which retryable
+// returns true with backoff.
+func (c *Client) retry(
+	ctx context.Context,
+	leader bool,
+	retryable func(error) bool,
+	fn func(*node) error,
+) error {
+	backoff := c.minBackoff
+	for attempt := 0; ; attempt++ {
+		endpoint := c.pick(ctx, leader)
+		err := fn(c.node(endpoint))
+		if err == nil || !retryable(err) {
+			return err
+		}
+		c.forget(endpoint)
+		if attempt >= c.maxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, c.maxBackoff)
+	}
+}
+
+// IsRetryable returns true if the error is caused by a leader change or a
+// transport error, which means the operation can be retried.
+func IsRetryable(err error) bool {
+	return connect.CodeOf(err) == connect.CodeUnavailable
+}
+
+// notApplied returns true if a write failed without being applied: the server
+// replied UNAVAILABLE, or the member could not be dialed. Other transport
+// errors may happen after the write is applied.
+func notApplied(err error) bool {
+	if !IsRetryable(err) {
+		return false
+	}
+	if connect.IsWireError(err) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// ReadOption configures a read.
+type ReadOption func(*dkvv1.GetRequest)
+
+// WithMaxStaleness sets the maximum staleness of a read served by a follower.
+func WithMaxStaleness(maxStaleness time.Duration) ReadOption {
+	return func(req *dkvv1.GetRequest) {
+		req.MaxStaleness = durationpb.New(maxStaleness)
+	}
+}
+
+// WithMinIndex sets the consistency token of a read, in addition to the token
+// of the session.
+func WithMinIndex(index uint64) ReadOption {
+	return func(req *dkvv1.GetRequest) {
+		req.MinIndex = index
+	}
+}
+
+// WithLinearizable serves the read on the leader, once it confirmed its
+// leadership and applied the entries committed before the read.
+func WithLinearizable() ReadOption {
+	return func(req *dkvv1.GetRequest) {
+		req.Linearizable = true
+	}
+}
+
+// Get gets the value of a key.
+func (c *Client) Get(ctx context.Context, key string, opts ...ReadOption) (value string, err error) {
+	req := &dkvv1.GetRequest{Key: key}
+	for _, opt := range opts {
+		opt(req)
+	}
+	err = c.do(ctx, req.GetLinearizable(), func(n *node) error {
+		res, err := n.dkv.Get(ctx, connect.NewRequest(req))
+		if err != nil {
+			return err
+		}
+		value = res.Msg.GetValue()
+		return nil
+	})
+	return value, err
+}
+
+// Set sets the value of a key and returns the Raft index of the write.
+func (c *Client) Set(ctx context.Context, key string, value string) (index uint64, err error) {
+	err = c.do(ctx, true, func(n *node) error {
+		res, err := n.dkv.Set(ctx, connect.NewRequest(&dkvv1.SetRequest{
+			Key:   key,
+			Value: value,
+		}))
+		if err != nil {
+			return err
+		}
+		index = res.Msg.GetIndex()
+		return nil
+	})
+	return index, err
+}
+
+// Delete deletes a key and returns the Raft index of the write.
+func (c *Client) Delete(ctx context.Context, key string) (index uint64, err error) {
+	err = c.do(ctx, true, func(n *node) error {
+		res, err := n.dkv.Delete(ctx, connect.NewRequest(&dkvv1.DeleteRequest{
+			Key: key,
+		}))
+		if err != nil {
+			return err
+		}
+		index = res.Msg.GetIndex()
+		return nil
+	})
+	return index, err
+}
+
+// IncrementOption configures an increment.
+type IncrementOption func(*dkvv1.IncrementRequest)
+
+// WithInitial sets the value of a missing key, before the delta is added. It is
+// 0 by default.
+func WithInitial(initial int64) IncrementOption {
+	return func(req *dkvv1.IncrementRequest) {
+		req.Initial = initial
+	}
+}
+
+// WithMin fails the increment if the new value is lower than the minimum.
+func WithMin(minimum int64) IncrementOption {
+	return func(req *dkvv1.IncrementRequest) {
+		req.Min = &minimum
+	}
+}
+
+// WithMax fails the increment if the new value is greater than the maximum.
+func WithMax(maximum int64) IncrementOption {
+	return func(req *dkvv1.IncrementRequest) {
+		req.Max = &maximum
+	}
+}
+
+// Increment adds delta to the signed 64-bit integer value of a key atomically,
+// and returns the new value.
+//
+// The increment is not idempotent, so it is only retried if it was not
+// applied. An error whose outcome is unknown, such as a connection lost during
+// the increment, is returned.
+func (c *Client) Increment(
+	ctx context.Context,
+	key string,
+	delta int64,
+	opts ...IncrementOption,
+) (value int64, err error) {
+	req := &dkvv1.IncrementRequest{Key: key, Delta: delta}
+	for _, opt := range opts {
+		opt(req)
+	}
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.dkv.Increment(ctx, connect.NewRequest(req))
+		if err != nil {
+			return err
+		}
+		value = res.Msg.GetValue()
+		return nil
+	})
+	return value, err
+}
+
+// GetServers returns the members of the cluster.
+func (c *Client) GetServers(ctx context.Context) (res *dkvv1.GetServersResponse, err error) {
+	err = c.do(ctx, false, func(n *node) error {
+		r, err := n.membership.GetServers(ctx, connect.NewRequest(&dkvv1.GetServersRequest{}))
+		if err != nil {
+			return err
+		}
+		res = r.Msg
+		return nil
+	})
+	return res, err
+}
+
+// JoinServer adds a server to the cluster. If set, the cluster token must
+// match the token of the cluster.
+func (c *Client) JoinServer(ctx context.Context, id, address, clusterToken string) error {
+	return c.do(ctx, true, func(n *node) error {
+		_, err := n.membership.JoinServer(ctx, connect.NewRequest(&dkvv1.JoinServerRequest{
+			Id:           id,
+			Address:      address,
+			ClusterToken: clusterToken,
+		}))
+		return err
+	})
+}
+
+// LeaveServer removes a server from the cluster.
+func (c *Client) LeaveServer(ctx context.Context, id string) error {
+	return c.do(ctx, true, func(n *node) error {
+		_, err := n.membership.LeaveServer(ctx, connect.NewRequest(&dkvv1.LeaveServerRequest{
+			Id: id,
+		}))
+		return err
+	})
+}
+
+// GetClusterHealth returns the health of the members, as seen by the leader.
+func (c *Client) GetClusterHealth(ctx context.Context) (res *dkvv1.GetClusterHealthResponse, err error) {
+	err = c.do(ctx, true, func(n *node) error {
+		r, err := n.membership.GetClusterHealth(
+			ctx,
+			connect.NewRequest(&dkvv1.GetClusterHealthRequest{}),
+		)
+		if err != nil {
+			return err
+		}
+		res = r.Msg
+		return nil
+	})
+	return res, err
+}
+
+// Snapshot writes a point-in-time backup of the leader to w.
+//
+// The snapshot is only retried if nothing was written to w.
+func (c *Client) Snapshot(ctx context.Context, w io.Writer) error {
+	return c.do(ctx, true, func(n *node) error {
+		stream, err := n.admin.Snapshot(ctx, connect.NewRequest(&dkvv1.SnapshotRequest{}))
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		written := false
+		for stream.Receive() {
+			written = true
+			if _, err := w.Write(stream.Msg().GetChunk()); err != nil {
+				return err
+			}
+		}
+		if err := stream.Err(); err != nil && written {
+			return connect.NewError(connect.CodeAborted, err)
+		} else if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// SetFaults injects network faults on the Raft connections dialed by a member
+// to the peer of the request. The member is reached at the endpoint, and must
+// have the fault injection enabled. The call is not retried on other members.
+func (c *Client) SetFaults(ctx context.Context, endpoint string, req *dkvv1.SetFaultsRequest) error {
+	_, err := c.member(endpoint).admin.SetFaults(ctx, connect.NewRequest(req))
+	return err
+}
+
+// ClearFaults removes the network faults injected on a member.
+func (c *Client) ClearFaults(ctx context.Context, endpoint string) error {
+	_, err := c.member(endpoint).admin.ClearFaults(ctx, connect.NewRequest(&dkvv1.ClearFaultsRequest{}))
+	return err
+}
@@ -0,0 +1,257 @@
+package client_test
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/testcluster"
+	"distributed-kv/pkg/client"
+	"distributed-kv/pkg/server"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster is a cluster whose members only accept the writes on the leader.
+type fakeCluster struct {
+	mu      sync.Mutex
+	leader  int
+	urls    []string
+	writes  []int
+	clients []*http.Client
+}
+
+func newFakeCluster(t *testing.T, members int) *fakeCluster {
+	t.Helper()
+
+	c := &fakeCluster{writes: make([]int, members)}
+	for i := 0; i < members; i++ {
+		m := &fakeMember{cluster: c, id: i}
+		mux := http.NewServeMux()
+		mux.Handle(dkvv1connect.NewDkvAPIHandler(m))
+		mux.Handle(dkvv1connect.NewMembershipAPIHandler(m))
+		srv := httptest.NewUnstartedServer(mux)
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+		c.urls = append(c.urls, srv.URL)
+		c.clients = append(c.clients, srv.Client())
+	}
+	return c
+}
+
+func (c *fakeCluster) setLeader(leader int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leader = leader
+}
+
+func (c *fakeCluster) writesOf(member int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writes[member]
+}
+
+type fakeMember struct {
+	dkvv1connect.UnimplementedDkvAPIHandler
+	dkvv1connect.UnimplementedMembershipAPIHandler
+	cluster *fakeCluster
+	id      int
+}
+
+func (m *fakeMember) Set(
+	context.Context,
+	*connect.Request[dkvv1.SetRequest],
+) (*connect.Response[dkvv1.SetResponse], error) {
+	m.cluster.mu.Lock()
+	defer m.cluster.mu.Unlock()
+	if m.cluster.leader != m.id {
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("not leader"))
+	}
+	m.cluster.writes[m.id]++
+	return connect.NewResponse(&dkvv1.SetResponse{Index: 10}), nil
+}
+
+func (m *fakeMember) Increment(
+	_ context.Context,
+	req *connect.Request[dkvv1.IncrementRequest],
+) (*connect.Response[dkvv1.IncrementResponse], error) {
+	m.cluster.mu.Lock()
+	defer m.cluster.mu.Unlock()
+	if m.cluster.leader != m.id {
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("not leader"))
+	}
+	m.cluster.writes[m.id]++
+	if req.Msg.GetKey() == "unknown" {
+		return nil, connect.NewError(connect.CodeUnknown, errors.New("leadership lost"))
+	}
+	return connect.NewResponse(&dkvv1.IncrementResponse{Value: req.Msg.GetDelta(), Index: 11}), nil
+}
+
+func (m *fakeMember) Get(
+	_ context.Context,
+	req *connect.Request[dkvv1.GetRequest],
+) (*connect.Response[dkvv1.GetResponse], error) {
+	if req.Msg.GetKey() == "missing" {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("not found"))
+	}
+	return connect.NewResponse(&dkvv1.GetResponse{Value: "value"}), nil
+}
+
+func (m *fakeMember) GetServers(
+	context.Context,
+	*connect.Request[dkvv1.GetServersRequest],
+) (*connect.Response[dkvv1.GetServersResponse], error) {
+	m.cluster.mu.Lock()
+	defer m.cluster.mu.Unlock()
+	res := &dkvv1.GetServersResponse{}
+	for i, url := range m.cluster.urls {
+		res.Servers = append(res.Servers, &dkvv1.Server{
+			RpcAddress: strings.TrimPrefix(url, "https://"),
+			IsLeader:   i == m.cluster.leader,
+		})
+	}
+	return connect.NewResponse(res), nil
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cluster := newFakeCluster(t, 2)
+	cluster.setLeader(1)
+	c, err := client.New(
+		cluster.urls[:1],
+		client.WithHTTPClient(cluster.clients[0]),
+		client.WithBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Route the writes to the leader", func(t *testing.T) {
+		// Act
+		index, err := c.Set(ctx, "key", "value")
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), index)
+		require.Equal(t, 1, cluster.writesOf(1))
+		require.Equal(t, uint64(10), c.Session().Index())
+	})
+
+	t.Run("Retry the writes on leader change", func(t *testing.T) {
+		// Arrange
+		cluster.setLeader(0)
+
+		// Act
+		_, err := c.Set(ctx, "key", "value")
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 1, cluster.writesOf(0))
+	})
+
+	t.Run("Return the non retryable errors", func(t *testing.T) {
+		// Act
+		value, err := c.Get(ctx, "key")
+		_, missingErr := c.Get(ctx, "missing")
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "value", value)
+		require.Equal(t, connect.CodeNotFound, connect.CodeOf(missingErr))
+	})
+
+	t.Run("Retry the increments which were not applied", func(t *testing.T) {
+		// Arrange
+		cluster.setLeader(1)
+
+		// Act
+		value, err := c.Increment(ctx, "counter", 2, client.WithInitial(1), client.WithMax(10))
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, int64(2), value)
+		require.Equal(t, 2, cluster.writesOf(1))
+		require.Equal(t, uint64(11), c.Session().Index())
+	})
+
+	t.Run("Do not retry the increments whose outcome is unknown", func(t *testing.T) {
+		// Act
+		_, err := c.Increment(ctx, "unknown", 1)
+
+		// Assert
+		require.Equal(t, connect.CodeUnknown, connect.CodeOf(err))
+		require.Equal(t, 3, cluster.writesOf(1))
+	})
+
+	t.Run("Give up after the maximum retries", func(t *testing.T) {
+		// Arrange
+		cluster.setLeader(-1)
+
+		// Act
+		_, err := c.Set(ctx, "key", "value")
+
+		// Assert
+		require.True(t, client.IsRetryable(err))
+	})
+}
+
+func TestNewWithoutEndpoint(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := client.New(nil)
+
+	// Assert
+	require.ErrorIs(t, err, client.ErrNoEndpoint)
+}
+
+func TestClientFaults(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cluster := testcluster.New(t, 3, testcluster.WithConfig(func(_ int, config *server.Config) {
+		config.FaultInjection = true
+	}))
+	leader, err := cluster.WaitForLeader(timeout)
+	require.NoError(t, err)
+	c, err := cluster.Client(leader)
+	require.NoError(t, err)
+	ctx := context.Background()
+	endpoint := cluster.Server(leader).ClientAddress()
+
+	// Act
+	// The leader cannot send its RPCs to the followers.
+	for i := range cluster.Size() {
+		if i != leader {
+			err := c.SetFaults(ctx, endpoint, &dkvv1.SetFaultsRequest{
+				PeerAddress: string(cluster.PeerAddress(i)),
+				Partitioned: true,
+			})
+			require.NoError(t, err)
+		}
+	}
+	elected := func() bool {
+		for i := range cluster.Size() {
+			if i != leader && cluster.Server(i).Store().IsLeader() {
+				return true
+			}
+		}
+		return false
+	}
+	require.Eventually(t, elected, timeout, 100*time.Millisecond, "a follower is elected")
+	clearErr := c.ClearFaults(ctx, endpoint)
+	invalidErr := c.SetFaults(ctx, endpoint, &dkvv1.SetFaultsRequest{})
+
+	// Assert
+	require.NoError(t, clearErr)
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(invalidErr))
+}
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"errors"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DefaultLeaseTTL is the TTL of the leases acquired without TTL.
+const DefaultLeaseTTL = time.Minute
+
+// ErrLeaseLost is returned by Lease.Err when a lease expired before it was
+// released, for example because the cluster was unreachable for its TTL.
+var ErrLeaseLost = errors.New("lease lost")
+
+// Lease is a lock or a leadership held by the client. It is kept alive in the
+// background until it is released or lost.
+type Lease struct {
+	// Key is the key of the lease.
+	Key string
+	// Revision is the fencing token of the holder, which increases with each
+	// new holder. The resources guarded by the lease should reject the
+	// requests with an older token.
+	Revision int64
+	// Value is the value of a candidate.
+	Value string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Done returns a channel closed when the lease is released or lost.
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns ErrLeaseLost if the lease was lost, and nil otherwise.
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Lock waits until the lock of the name is acquired, and returns its lease.
+// The waiters are served in FIFO order. A TTL of zero is DefaultLeaseTTL.
+//
+// Like Increment, the lock is only retried if it was not applied.
+func (c *Client) Lock(ctx context.Context, name string, ttl time.Duration) (lease *Lease, err error) {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.lock.Lock(ctx, connect.NewRequest(&dkvv1.LockRequest{
+			Name: name,
+			Ttl:  durationpb.New(ttl),
+		}))
+		if err != nil {
+			return err
+		}
+		lease = c.hold(res.Msg.GetLease(), ttl)
+		return nil
+	})
+	return lease, err
+}
+
+// Unlock releases a lock. ErrLeaseLost is returned if it was lost.
+func (c *Client) Unlock(ctx context.Context, lease *Lease) error {
+	return c.release(ctx, lease, func(n *node) error {
+		_, err := n.lock.Unlock(ctx, connect.NewRequest(&dkvv1.UnlockRequest{
+			Key:      lease.Key,
+			Revision: lease.Revision,
+		}))
+		return err
+	})
+}
+
+// Campaign waits until the candidate with the value is elected leader of the
+// election of the name, and returns its lease. The candidates are elected in
+// FIFO order. A TTL of zero is DefaultLeaseTTL.
+//
+// Like Increment, the campaign is only retried if it was not applied.
+func (c *Client) Campaign(
+	ctx context.Context,
+	name, value string,
+	ttl time.Duration,
+) (lease *Lease, err error) {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.lock.Campaign(ctx, connect.NewRequest(&dkvv1.CampaignRequest{
+			Name:  name,
+			Value: value,
+			Ttl:   durationpb.New(ttl),
+		}))
+		if err != nil {
+			return err
+		}
+		lease = c.hold(res.Msg.GetLease(), ttl)
+		return nil
+	})
+	return lease, err
+}
+
+// Resign releases a leadership. ErrLeaseLost is returned if it was lost.
+func (c *Client) Resign(ctx context.Context, lease *Lease) error {
+	return c.release(ctx, lease, func(n *node) error {
+		_, err := n.lock.Resign(ctx, connect.NewRequest(&dkvv1.ResignRequest{
+			Key:      lease.Key,
+			Revision: lease.Revision,
+		}))
+		return err
+	})
+}
+
+// Observe calls fn with the leader of the election of the name, or nil if
+// there is none, and then each time it changes, until the context is done or
+// fn fails.
+//
+// The observation is only retried if no leader was observed.
+func (c *Client) Observe(ctx context.Context, name string, fn func(leader *dkvv1.Lease) error) error {
+	return c.do(ctx, false, func(n *node) error {
+		stream, err := n.lock.Observe(ctx, connect.NewRequest(&dkvv1.ObserveRequest{Name: name}))
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		observed := false
+		for stream.Receive() {
+			observed = true
+			if err := fn(stream.Msg().GetLeader()); err != nil {
+				return err
+			}
+		}
+		if err := stream.Err(); err != nil && observed {
+			return connect.NewError(connect.CodeAborted, err)
+		} else if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// hold keeps a lease alive in the background.
+func (c *Client) hold(lease *dkvv1.Lease, ttl time.Duration) *Lease {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &Lease{
+		Key:      lease.GetKey(),
+		Revision: lease.GetRevision(),
+		Value:    lease.GetValue(),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go c.keepAlive(ctx, l, ttl)
+	return l
+}
+
+// keepAlive keeps a lease alive until the context is done or the lease is
+// lost.
+func (c *Client) keepAlive(ctx context.Context, l *Lease, ttl time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	// The server kept the lease alive at most a third of the TTL before it was
+	// acquired.
+	kept := time.Now().Add(-ttl / 3)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		start := time.Now()
+		err := c.do(ctx, true, func(n *node) error {
+			_, err := n.lock.KeepAlive(ctx, connect.NewRequest(&dkvv1.KeepAliveRequest{
+				Key: l.Key,
+				Ttl: durationpb.New(ttl),
+			}))
+			return err
+		})
+		switch {
+		case err == nil:
+			kept = start
+		case ctx.Err() != nil:
+			return
+		case connect.CodeOf(err) == connect.CodeNotFound || time.Since(kept) >= ttl:
+			l.mu.Lock()
+			l.err = ErrLeaseLost
+			l.mu.Unlock()
+			return
+		}
+	}
+}
+
+// release stops keeping a lease alive, and deletes it with fn.
+func (c *Client) release(ctx context.Context, lease *Lease, fn func(*node) error) error {
+	lease.cancel()
+	<-lease.done
+	if err := lease.Err(); err != nil {
+		return err
+	}
+	err := c.do(ctx, true, fn)
+	if connect.CodeOf(err) == connect.CodeNotFound {
+		return ErrLeaseLost
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package client_test
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/internal/testcluster"
+	"distributed-kv/pkg/client"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 10 * time.Second
+
+func TestClientLock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cluster := testcluster.New(t, 3)
+	leader, err := cluster.WaitForLeader(timeout)
+	require.NoError(t, err)
+	c, err := cluster.Client((leader + 1) % cluster.Size())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+
+	t.Run("Lock and Unlock", func(t *testing.T) {
+		// Arrange
+		first, err := c.Lock(ctx, "lock", time.Second)
+		require.NoError(t, err)
+		acquired := make(chan *client.Lease, 1)
+		go func() {
+			lease, err := c.Lock(ctx, "lock", time.Second)
+			if err == nil {
+				acquired <- lease
+			}
+		}()
+
+		// Act
+		time.Sleep(2 * time.Second)
+		held := first.Err()
+		require.NoError(t, c.Unlock(ctx, first))
+		second := <-acquired
+		require.NoError(t, c.Unlock(ctx, second))
+
+		// Assert
+		require.NoError(t, held, "the lease is kept alive beyond its TTL")
+		require.Greater(t, second.Revision, first.Revision)
+		require.ErrorIs(t, c.Unlock(ctx, second), client.ErrLeaseLost)
+	})
+
+	t.Run("Lose an expired lease", func(t *testing.T) {
+		// Arrange
+		lease, err := c.Lock(ctx, "lost", time.Second)
+		require.NoError(t, err)
+		_, err = cluster.Server(leader).Store().Release(lease.Key, lease.Revision)
+		require.NoError(t, err)
+
+		// Act
+		<-lease.Done()
+
+		// Assert
+		require.ErrorIs(t, lease.Err(), client.ErrLeaseLost)
+	})
+
+	t.Run("Campaign and Observe", func(t *testing.T) {
+		// Arrange
+		observeCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		leaders := make(chan *dkvv1.Lease, 10)
+		go func() {
+			_ = c.Observe(observeCtx, "election", func(leader *dkvv1.Lease) error {
+				leaders <- leader
+				return nil
+			})
+		}()
+		require.Nil(t, <-leaders)
+
+		// Act
+		lease, err := c.Campaign(ctx, "election", "node0", time.Minute)
+		require.NoError(t, err)
+		elected := <-leaders
+		require.NoError(t, c.Resign(ctx, lease))
+		resigned := <-leaders
+
+		// Assert
+		require.Equal(t, "node0", elected.GetValue())
+		require.Equal(t, lease.Revision, elected.GetRevision())
+		require.Nil(t, resigned)
+	})
+
+	t.Run("Invalid TTL", func(t *testing.T) {
+		// Act
+		_, err := c.Lock(ctx, "invalid", time.Millisecond)
+
+		// Assert
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrClaimExpired is returned when acknowledging a message whose claim expired
+// and was requeued, or which was already acknowledged.
+var ErrClaimExpired = errors.New("claim expired")
+
+// Enqueue appends a message to a queue, and returns its ID. The IDs are Raft
+// indexes, so they give the order of the queue.
+//
+// Like Increment, the enqueue is only retried if it was not applied.
+func (c *Client) Enqueue(ctx context.Context, queue, body string) (id uint64, err error) {
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.queue.Enqueue(ctx, connect.NewRequest(&dkvv1.EnqueueRequest{
+			Queue: queue,
+			Body:  body,
+		}))
+		if err != nil {
+			return err
+		}
+		id = res.Msg.GetIndex()
+		return nil
+	})
+	return id, err
+}
+
+// Dequeue claims the first message of a queue for the visibility timeout, and
+// returns it. The message must be acknowledged with Ack before the claim
+// expires, otherwise it is delivered again. A visibility timeout of zero is
+// the default of the server.
+//
+// If the queue is empty, Dequeue waits at most the wait for a message, and
+// returns nil if there is none.
+//
+// Like Increment, the dequeue is only retried if it was not applied.
+func (c *Client) Dequeue(
+	ctx context.Context,
+	queue string,
+	visibilityTimeout, wait time.Duration,
+) (message *dkvv1.QueueMessage, err error) {
+	req := &dkvv1.DequeueRequest{Queue: queue, Wait: durationpb.New(wait)}
+	if visibilityTimeout > 0 {
+		req.VisibilityTimeout = durationpb.New(visibilityTimeout)
+	}
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.queue.Dequeue(ctx, connect.NewRequest(req))
+		if err != nil {
+			return err
+		}
+		message = res.Msg.GetMessage()
+		return nil
+	})
+	return message, err
+}
+
+// Ack deletes a claimed message and returns the Raft index of the write.
+// ErrClaimExpired is returned if the message was requeued or acknowledged.
+func (c *Client) Ack(ctx context.Context, queue string, message *dkvv1.QueueMessage) (index uint64, err error) {
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.queue.Ack(ctx, connect.NewRequest(&dkvv1.AckRequest{
+			Queue: queue,
+			Id:    message.GetId(),
+			Claim: message.GetClaim(),
+		}))
+		if err != nil {
+			return err
+		}
+		index = res.Msg.GetIndex()
+		return nil
+	})
+	return index, claimError(err)
+}
+
+// Nack requeues a claimed message at its position, so that it is delivered
+// again, and returns the Raft index of the write. ErrClaimExpired is returned
+// if the message was requeued or acknowledged.
+func (c *Client) Nack(ctx context.Context, queue string, message *dkvv1.QueueMessage) (index uint64, err error) {
+	err = c.retry(ctx, true, notApplied, func(n *node) error {
+		res, err := n.queue.Nack(ctx, connect.NewRequest(&dkvv1.NackRequest{
+			Queue: queue,
+			Id:    message.GetId(),
+			Claim: message.GetClaim(),
+		}))
+		if err != nil {
+			return err
+		}
+		index = res.Msg.GetIndex()
+		return nil
+	})
+	return index, claimError(err)
+}
+
+// Peek returns at most limit messages of a queue which are not claimed, without
+// claiming them, and the numbers of ready and claimed messages. A limit of zero
+// returns one message.
+func (c *Client) Peek(
+	ctx context.Context,
+	queue string,
+	limit int,
+) (messages []*dkvv1.QueueMessage, ready, claimed int64, err error) {
+	err = c.do(ctx, false, func(n *node) error {
+		res, err := n.queue.Peek(ctx, connect.NewRequest(&dkvv1.PeekRequest{
+			Queue: queue,
+			Limit: int32(min(limit, 1<<31-1)),
+		}))
+		if err != nil {
+			return err
+		}
+		messages = res.Msg.GetMessages()
+		ready, claimed = res.Msg.GetReady(), res.Msg.GetClaimed()
+		return nil
+	})
+	return messages, ready, claimed, err
+}
+
+// claimError converts the error of an expired claim to ErrClaimExpired.
+func claimError(err error) error {
+	if connect.CodeOf(err) == connect.CodeFailedPrecondition {
+		return ErrClaimExpired
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"context"
+	"distributed-kv/internal/testcluster"
+	"distributed-kv/pkg/client"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientQueue(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cluster := testcluster.New(t, 3)
+	leader, err := cluster.WaitForLeader(timeout)
+	require.NoError(t, err)
+	c, err := cluster.Client((leader + 1) % cluster.Size())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+
+	t.Run("Enqueue, Dequeue and Ack", func(t *testing.T) {
+		// Arrange
+		first, err := c.Enqueue(ctx, "jobs", "a")
+		require.NoError(t, err)
+		second, err := c.Enqueue(ctx, "jobs", "b")
+		require.NoError(t, err)
+
+		// Act
+		message, err := c.Dequeue(ctx, "jobs", time.Minute, 0)
+		require.NoError(t, err)
+		peeked, ready, claimed, peekErr := c.Peek(ctx, "jobs", 10)
+		_, ackErr := c.Ack(ctx, "jobs", message)
+
+		// Assert
+		require.Less(t, first, second)
+		require.Equal(t, int64(first), message.GetId())
+		require.Equal(t, "a", message.GetBody())
+		require.NoError(t, peekErr)
+		require.Len(t, peeked, 1)
+		require.Equal(t, "b", peeked[0].GetBody())
+		require.Equal(t, int64(1), ready)
+		require.Equal(t, int64(1), claimed)
+		require.NoError(t, ackErr)
+		_, err = c.Ack(ctx, "jobs", message)
+		require.ErrorIs(t, err, client.ErrClaimExpired)
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		// Arrange
+		_, err := c.Enqueue(ctx, "nacked", "a")
+		require.NoError(t, err)
+		message, err := c.Dequeue(ctx, "nacked", time.Minute, 0)
+		require.NoError(t, err)
+
+		// Act
+		_, err = c.Nack(ctx, "nacked", message)
+		require.NoError(t, err)
+		redelivered, dequeueErr := c.Dequeue(ctx, "nacked", time.Minute, 0)
+
+		// Assert
+		require.NoError(t, dequeueErr)
+		require.Equal(t, message.GetId(), redelivered.GetId())
+		require.Equal(t, int64(2), redelivered.GetDeliveries())
+	})
+
+	t.Run("Dequeue an empty queue", func(t *testing.T) {
+		// Act
+		start := time.Now()
+		message, err := c.Dequeue(ctx, "empty", time.Minute, 500*time.Millisecond)
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, message)
+		require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Missing queue", func(t *testing.T) {
+		// Act
+		_, err := c.Dequeue(ctx, "", time.Minute, 0)
+
+		// Assert
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,77 @@
+// Package client is the Go client of the distributed key-value store.
+package client
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"sync/atomic"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ connect.Interceptor = (*Session)(nil)
+
+// Session tracks the consistency tokens of the writes, so that the reads of
+// the session observe them (read-your-writes), whichever node serves them.
+//
+// Use it as an interceptor of the DkvAPI clients:
+//
+//	session := &client.Session{}
+//	c := dkvv1connect.NewDkvAPIClient(http, url, connect.WithInterceptors(session))
+type Session struct {
+	index atomic.Uint64
+}
+
+// Index returns the consistency token of the session: the highest Raft index
+// of its writes.
+func (s *Session) Index() uint64 {
+	return s.index.Load()
+}
+
+// Observe records the consistency token of a write.
+func (s *Session) Observe(index uint64) {
+	for {
+		current := s.index.Load()
+		if index <= current || s.index.CompareAndSwap(current, index) {
+			return
+		}
+	}
+}
+
+// WrapUnary sets the consistency token of the reads, and records the tokens
+// of the write responses.
+func (s *Session) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		switch read := req.(type) {
+		case *connect.Request[dkvv1.GetRequest]:
+			if index := s.Index(); read.Msg.GetMinIndex() < index {
+				msg := proto.Clone(read.Msg).(*dkvv1.GetRequest)
+				msg.MinIndex = index
+				read.Msg = msg
+			}
+		case *connect.Request[dkvv1.PeekRequest]:
+			if index := s.Index(); read.Msg.GetMinIndex() < index {
+				msg := proto.Clone(read.Msg).(*dkvv1.PeekRequest)
+				msg.MinIndex = index
+				read.Msg = msg
+			}
+		}
+		res, err := next(ctx, req)
+		if err != nil {
+			return res, err
+		}
+		if write, ok := res.Any().(interface{ GetIndex() uint64 }); ok {
+			s.Observe(write.GetIndex())
+		}
+		return res, nil
+	}
+}
+
+func (s *Session) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (s *Session) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
@@ -0,0 +1,88 @@
+package client_test
+
+import (
+	"context"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode is a node which returns the given index on writes, and records the
+// consistency token of the reads.
+type fakeNode struct {
+	dkvv1connect.UnimplementedDkvAPIHandler
+	index    uint64
+	minIndex uint64
+}
+
+func (n *fakeNode) Set(
+	context.Context,
+	*connect.Request[dkvv1.SetRequest],
+) (*connect.Response[dkvv1.SetResponse], error) {
+	return connect.NewResponse(&dkvv1.SetResponse{Index: n.index}), nil
+}
+
+func (n *fakeNode) Delete(
+	context.Context,
+	*connect.Request[dkvv1.DeleteRequest],
+) (*connect.Response[dkvv1.DeleteResponse], error) {
+	return connect.NewResponse(&dkvv1.DeleteResponse{Index: n.index}), nil
+}
+
+func (n *fakeNode) Get(
+	_ context.Context,
+	req *connect.Request[dkvv1.GetRequest],
+) (*connect.Response[dkvv1.GetResponse], error) {
+	n.minIndex = req.Msg.GetMinIndex()
+	return connect.NewResponse(&dkvv1.GetResponse{}), nil
+}
+
+func newFakeNode(t *testing.T, node *fakeNode) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle(dkvv1connect.NewDkvAPIHandler(node))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	writer := &fakeNode{index: 42}
+	reader := &fakeNode{index: 7}
+	session := &client.Session{}
+	writerClient := dkvv1connect.NewDkvAPIClient(
+		http.DefaultClient,
+		newFakeNode(t, writer),
+		connect.WithInterceptors(session),
+	)
+	readerClient := dkvv1connect.NewDkvAPIClient(
+		http.DefaultClient,
+		newFakeNode(t, reader),
+		connect.WithInterceptors(session),
+	)
+	ctx := context.Background()
+	req := &dkvv1.GetRequest{Key: "key"}
+
+	// Act
+	_, err := writerClient.Set(ctx, connect.NewRequest(&dkvv1.SetRequest{Key: "key"}))
+	require.NoError(t, err)
+	_, err = readerClient.Delete(ctx, connect.NewRequest(&dkvv1.DeleteRequest{Key: "other"}))
+	require.NoError(t, err)
+	_, err = readerClient.Get(ctx, connect.NewRequest(req))
+	require.NoError(t, err)
+
+	// Assert
+	require.Equal(t, uint64(42), session.Index())
+	require.Equal(t, uint64(42), reader.minIndex)
+	require.Zero(t, req.GetMinIndex(), "the request of the caller is not modified")
+}
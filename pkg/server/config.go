@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+const (
+	// ClusterStateNew bootstraps a new cluster from the first node of the
+	// initial cluster.
+	ClusterStateNew = "new"
+	// ClusterStateExisting joins an existing cluster.
+	ClusterStateExisting = "existing"
+)
+
+// Peer is a node of the initial cluster.
+type Peer struct {
+	ID      raft.ServerID
+	Address raft.ServerAddress
+}
+
+// ParsePeers parses a list of peers in the form id=address.
+func ParsePeers(values []string) ([]Peer, error) {
+	peers := make([]Peer, 0, len(values))
+	for _, value := range values {
+		id, addr, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid initial cluster configuration: %s", value)
+		}
+		peers = append(peers, Peer{ID: raft.ServerID(id), Address: raft.ServerAddress(addr)})
+	}
+	return peers, nil
+}
+
+// Config is the configuration of a node.
+type Config struct {
+	// Name is the unique name of the node.
+	Name string
+	// ListenPeerAddress is the address to listen on for peer traffic.
+	ListenPeerAddress string
+	// ListenClientAddress is the address to listen on for client traffic.
+	ListenClientAddress string
+	// ListenRedisAddress is the address to listen on for Redis clients, with
+	// the client TLS configuration. Empty disables the Redis protocol.
+	ListenRedisAddress string
+	// MultiplexPeer tunnels the peer traffic through the client address and
+	// the client TLS configuration instead of the peer address.
+	MultiplexPeer bool
+	// AdvertiseNodes are the RPC addresses of the nodes, by ID.
+	AdvertiseNodes map[raft.ServerID]string
+
+	// InitialCluster are the Raft addresses of the nodes of the initial
+	// cluster. The first node bootstraps the cluster.
+	InitialCluster []Peer
+	// InitialClusterState is ClusterStateNew or ClusterStateExisting.
+	InitialClusterState string
+	// InitialClusterToken identifies the cluster. Peers with another token are
+	// rejected.
+	InitialClusterToken string
+	// ForceNewCluster forces a new cluster with this node as the only member.
+	ForceNewCluster bool
+
+	// DiscoveryMode is the discovery of the peers in addition to the initial
+	// cluster: static (default), srv or dns.
+	DiscoveryMode string
+	// DiscoverySRV is the domain name of the SRV records of the peers.
+	DiscoverySRV string
+	// DiscoveryDNSPattern is the address pattern of the peers, where {i} is the
+	// ordinal of the peer.
+	DiscoveryDNSPattern string
+	// DiscoveryDNSMaxPeers is the number of ordinals resolved by the dns
+	// discovery.
+	DiscoveryDNSMaxPeers int
+	// DiscoveryRemoveAfter removes the members which have not been discovered
+	// for this duration. Disabled if 0.
+	DiscoveryRemoveAfter time.Duration
+	// DiscoveryTrustPeers accepts the peer identities of the discovered peers,
+	// which trusts the DNS answers: whoever controls them can add trusted peer
+	// certificates. Otherwise, only the members and PeerAllowedIdentities are
+	// accepted.
+	DiscoveryTrustPeers bool
+	// JoinInterval is the interval between two discoveries of the peers to
+	// join. Defaults to 5s.
+	JoinInterval time.Duration
+
+	// DeadMemberTimeout removes the members which have been unreachable from
+	// the leader for this duration. Disabled if 0.
+	DeadMemberTimeout time.Duration
+	// MinQuorum is the minimum number of voters kept when removing dead or
+	// vanished members.
+	MinQuorum int
+	// ServerStabilizationTime joins the new servers as non-voters, promoted
+	// once healthy for this duration. Disabled if 0.
+	ServerStabilizationTime time.Duration
+	// Zone is the zone of the node. The leader keeps one voter per zone.
+	Zone string
+	// MaxReadLag is the maximum number of committed entries not applied by a
+	// follower serving a read with a maximum staleness.
+	MaxReadLag uint64
+	// RaftConfig is the base Raft configuration. Defaults to
+	// raft.DefaultConfig().
+	RaftConfig *raft.Config
+	// StreamLayer, if set, wraps the stream layer of the Raft transport, for
+	// example to inject network faults in tests.
+	StreamLayer func(raft.StreamLayer) raft.StreamLayer
+	// FaultInjection enables the debug RPCs injecting network faults in the
+	// Raft transport. It must not be enabled in production.
+	FaultInjection bool
+	// FaultInjectionSeed seeds the random faults, so that they can be
+	// reproduced. A random seed is used if 0. The seed is logged.
+	FaultInjectionSeed uint64
+
+	// PeerCertFile, PeerKeyFile and PeerTrustedCAFile are the TLS files of the
+	// peer traffic.
+	PeerCertFile      string
+	PeerKeyFile       string
+	PeerTrustedCAFile string
+	// PeerAllowedIdentities are additional peer identities (CN or SAN) to
+	// accept.
+	PeerAllowedIdentities []string
+
+	// CertFile, KeyFile and TrustedCAFile are the TLS files of the client
+	// traffic.
+	CertFile      string
+	KeyFile       string
+	TrustedCAFile string
+
+	// DataDir is the path to the data directory.
+	DataDir string
+
+	// AuditLogFile is the path to the audit log file. Disabled if empty.
+	AuditLogFile string
+	// AuditLogMaxSize is the maximum size in bytes of the audit log file before
+	// rotation.
+	AuditLogMaxSize int64
+	// AuditLogMaxBackups is the maximum number of rotated audit log files.
+	AuditLogMaxBackups int
+
+	// BackupDir is the directory where the leader stores scheduled snapshots.
+	// Disabled if empty.
+	BackupDir string
+	// BackupInterval is the interval between scheduled snapshots. It must be
+	// positive if BackupDir is set.
+	BackupInterval time.Duration
+	// BackupRetention is the number of scheduled snapshots to keep.
+	BackupRetention int
+}
+
+// peers returns the Raft addresses of the initial cluster, by ID.
+func (c *Config) peers() map[raft.ServerID]raft.ServerAddress {
+	peers := make(map[raft.ServerID]raft.ServerAddress, len(c.InitialCluster))
+	for _, peer := range c.InitialCluster {
+		peers[peer.ID] = peer.Address
+	}
+	return peers
+}
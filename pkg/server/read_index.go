@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	dkvv1 "distributed-kv/gen/dkv/v1"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/store/distributed"
+	internaltls "distributed-kv/internal/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/raft"
+	"golang.org/x/net/http2"
+)
+
+// leaderAPI calls the API of the leader from a follower.
+type leaderAPI struct {
+	nodes  map[raft.ServerID]string
+	scheme string
+	client *http.Client
+}
+
+// newLeaderAPI returns a client of the API of the advertised nodes. Over TLS,
+// the node presents its own certificate.
+func newLeaderAPI(config *Config, useTLS bool) (*leaderAPI, error) {
+	var tlsConfig *tls.Config
+	if useTLS {
+		var err error
+		tlsConfig, err = internaltls.SetupClientTLSConfig(
+			config.CertFile,
+			config.KeyFile,
+			config.TrustedCAFile,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	a := &leaderAPI{
+		nodes:  config.AdvertiseNodes,
+		scheme: "http://",
+		client: &http.Client{
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					var d net.Dialer
+					conn, err := d.DialContext(ctx, network, addr)
+					if err != nil || tlsConfig == nil {
+						return conn, err
+					}
+					serverName, _, err := net.SplitHostPort(addr)
+					if err != nil {
+						serverName = addr
+					}
+					tlsConfig := tlsConfig.Clone()
+					tlsConfig.ServerName = serverName
+					return tls.Client(conn, tlsConfig), nil
+				},
+			},
+		},
+	}
+	if useTLS {
+		a.scheme = "https://"
+	}
+	return a, nil
+}
+
+// readIndex gets the read index of the leader, at its advertised address.
+// distributed.ErrNoLeaderAPI is returned if the leader is not advertised.
+func (a *leaderAPI) readIndex(id raft.ServerID, timeout time.Duration) (uint64, error) {
+	addr, ok := a.nodes[id]
+	if !ok || addr == "" {
+		return 0, distributed.ErrNoLeaderAPI
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	membership := dkvv1connect.NewMembershipAPIClient(a.client, a.scheme+addr, connect.WithGRPC())
+	res, err := membership.GetReadIndex(ctx, connect.NewRequest(&dkvv1.GetReadIndexRequest{}))
+	if connect.CodeOf(err) == connect.CodeUnavailable {
+		// The node is no longer the leader, or cannot confirm its leadership.
+		return 0, fmt.Errorf("%w: %w", raft.ErrNotLeader, err)
+	} else if err != nil {
+		return 0, err
+	}
+	return res.Msg.GetIndex(), nil
+}
+
+// close closes the idle connections to the leader.
+func (a *leaderAPI) close() {
+	a.client.CloseIdleConnections()
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"distributed-kv/internal/backup"
+	"distributed-kv/internal/store/distributed"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// Restore restores the data directory of the node from a snapshot file. The
+// node is restored as the single member of a new cluster.
+func Restore(config Config, path string) error {
+	i := slices.IndexFunc(config.InitialCluster, func(p Peer) bool {
+		return string(p.ID) == config.Name
+	})
+	if i < 0 {
+		return fmt.Errorf("node %s is not part of the initial cluster", config.Name)
+	}
+	info, snapshot, err := backup.Open(path)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+	if err := distributed.Restore(
+		config.DataDir,
+		config.InitialCluster[i].ID,
+		config.InitialCluster[i].Address,
+		info.Index,
+		info.Term,
+		snapshot,
+	); err != nil {
+		return err
+	}
+	slog.Info(
+		"snapshot restored",
+		"index", info.Index,
+		"term", info.Term,
+		"keys", info.Keys,
+		"data-dir", config.DataDir,
+	)
+	return nil
+}
@@ -0,0 +1,633 @@
+// Package server is an embeddable node of the distributed key-value store.
+//
+//	srv := server.New(config)
+//	if err := srv.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer srv.Stop()
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"distributed-kv/gen/dkv/v1/dkvv1connect"
+	"distributed-kv/internal/api"
+	"distributed-kv/internal/audit"
+	"distributed-kv/internal/backup"
+	"distributed-kv/internal/discovery"
+	"distributed-kv/internal/etcd"
+	"distributed-kv/internal/mux"
+	"distributed-kv/internal/redis"
+	"distributed-kv/internal/rest"
+	"distributed-kv/internal/store/distributed"
+	"distributed-kv/internal/store/persisted"
+	internaltls "distributed-kv/internal/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	defaultJoinInterval = 5 * time.Second
+	shutdownTimeout     = 10 * time.Second
+)
+
+// ErrStarted is returned when starting a server twice.
+var ErrStarted = errors.New("server already started")
+
+// Server is a node of the distributed key-value store: the Raft store and
+// the RPC APIs.
+type Server struct {
+	config Config
+
+	mu        sync.Mutex
+	listener  *trackingListener
+	storer    *persisted.Store
+	store     *distributed.Store
+	auditSink *audit.FileSink
+	faults    *distributed.FaultInjector
+	leaderAPI *leaderAPI
+	http      *http.Server
+	etcd      *etcd.Server
+	rest      *rest.Handler
+	lock      *api.LockAPIHandler
+	queue     *api.QueueAPIHandler
+	redis     *redis.Server
+	redisAddr net.Addr
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	errCh     chan error
+	stopOnce  sync.Once
+}
+
+// New returns a server which is not started.
+func New(config Config) *Server {
+	return &Server{
+		config: config,
+		errCh:  make(chan error, 1),
+	}
+}
+
+// Start listens, opens the store and serves the APIs in the background.
+//
+// The server is stopped if Start fails.
+func (s *Server) Start(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return ErrStarted
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			_ = s.stop()
+		}
+	}()
+	if s.config.BackupDir != "" && s.config.BackupInterval <= 0 {
+		return fmt.Errorf("%w: %s", backup.ErrInvalidInterval, s.config.BackupInterval)
+	}
+
+	// TLS configurations
+	var tlsConfig *tls.Config
+	if s.config.CertFile != "" && s.config.KeyFile != "" {
+		tlsConfig, err = internaltls.SetupServerTLSConfig(
+			s.config.CertFile,
+			s.config.KeyFile,
+			s.config.TrustedCAFile,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := net.Listen("tcp", s.config.ListenClientAddress)
+	if err != nil {
+		return err
+	}
+	s.listener = newTrackingListener(root)
+	var l net.Listener = s.listener
+
+	storeOpts, l, err := s.peerOptions(l, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	// The followers get the read index of the leader through its API.
+	if len(s.config.AdvertiseNodes) > 0 {
+		s.leaderAPI, err = newLeaderAPI(&s.config, tlsConfig != nil)
+		if err != nil {
+			return err
+		}
+		storeOpts = append(storeOpts, distributed.WithLeaderReadIndex(s.leaderAPI.readIndex))
+	}
+
+	// Audit
+	var auditSink audit.Sink
+	if s.config.AuditLogFile != "" {
+		s.auditSink, err = audit.NewFileSink(
+			s.config.AuditLogFile,
+			s.config.AuditLogMaxSize,
+			s.config.AuditLogMaxBackups,
+		)
+		if err != nil {
+			return err
+		}
+		auditSink = s.auditSink
+	}
+
+	// Autopilot
+	// The minimum quorum also guards the removal of the vanished peers.
+	if s.config.DeadMemberTimeout > 0 || s.config.ServerStabilizationTime > 0 ||
+		s.config.Zone != "" || s.config.DiscoveryRemoveAfter > 0 {
+		storeOpts = append(storeOpts, distributed.WithAutopilot(distributed.AutopilotConfig{
+			DeadMemberTimeout:       s.config.DeadMemberTimeout,
+			MinQuorum:               s.config.MinQuorum,
+			ServerStabilizationTime: s.config.ServerStabilizationTime,
+			OnEvent: func(e distributed.Event) {
+				slog.Warn(
+					"autopilot event",
+					"type", e.Type,
+					"id", e.ServerID,
+					"index", e.Index,
+					"message", e.Message,
+				)
+				if auditSink != nil {
+					auditSink.Record(audit.NewEntry(
+						"autopilot", string(e.Type), string(e.ServerID), e.Index, nil,
+					))
+				}
+			},
+		}))
+	}
+	if s.config.RaftConfig != nil {
+		// The Raft configuration is modified by the store.
+		raftConfig := *s.config.RaftConfig
+		storeOpts = append(storeOpts, distributed.WithRaftConfig(&raftConfig))
+	}
+	if s.config.FaultInjection {
+		s.faults = distributed.NewFaultInjector()
+		// The seed is logged, so that the random faults can be reproduced.
+		seed := s.config.FaultInjectionSeed
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+		s.faults.Seed(seed)
+		slog.Warn("fault injection enabled", "seed", seed)
+	}
+	if s.config.StreamLayer != nil || s.faults != nil {
+		storeOpts = append(storeOpts, distributed.WithStreamLayer(s.wrapStreamLayer))
+	}
+
+	// Store configuration
+	s.storer = persisted.New(s.config.DataDir)
+	if err := s.openStore(ctx, storeOpts); err != nil {
+		return err
+	}
+
+	// Backups
+	if s.config.BackupDir != "" {
+		scheduler := &backup.Scheduler{
+			Source:      s.store,
+			Destination: &backup.LocalDestination{Dir: s.config.BackupDir},
+			Interval:    s.config.BackupInterval,
+			Retention:   s.config.BackupRetention,
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := scheduler.Run(ctx); err != nil {
+				slog.Error("backup scheduler failed", "error", err)
+			}
+		}()
+	}
+
+	// Routes
+	r := http.NewServeMux()
+	r.Handle("/metrics", promhttp.Handler())
+	r.Handle(dkvv1connect.NewDkvAPIHandler(&api.DkvAPIHandler{
+		Store: s.store,
+		Audit: auditSink,
+	}))
+	r.Handle(dkvv1connect.NewMembershipAPIHandler(&api.MembershipAPIHandler{
+		AdvertiseNodes: s.config.AdvertiseNodes,
+		Store:          s.store,
+		Audit:          auditSink,
+	}))
+	r.Handle(dkvv1connect.NewAdminAPIHandler(&api.AdminAPIHandler{
+		Store:  s.store,
+		Audit:  auditSink,
+		Faults: s.faults,
+	}))
+	s.lock = &api.LockAPIHandler{Store: s.store, Audit: auditSink}
+	r.Handle(dkvv1connect.NewLockAPIHandler(s.lock))
+	s.queue = &api.QueueAPIHandler{Store: s.store, Audit: auditSink}
+	r.Handle(dkvv1connect.NewQueueAPIHandler(s.queue))
+	s.rest = &rest.Handler{Store: s.store, Audit: auditSink}
+	r.Handle(rest.Path, s.rest)
+	s.etcd = &etcd.Server{Store: s.store, Audit: auditSink}
+	etcdServer := etcd.NewGRPCServer(s.etcd)
+	for _, path := range etcd.Paths {
+		r.Handle(path, etcdServer)
+	}
+
+	// Redis protocol
+	if s.config.ListenRedisAddress != "" {
+		if err := s.serveRedis(tlsConfig, auditSink); err != nil {
+			return err
+		}
+	}
+
+	// Start the server
+	slog.Info("server listening", "address", s.listener.Addr())
+	s.http = &http.Server{
+		BaseContext: func(_ net.Listener) context.Context { return ctx },
+		ConnContext: api.ConnContext,
+		Handler:     h2c.NewHandler(r, &http2.Server{}),
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.http.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+			s.errCh <- err
+		}
+	}()
+	return nil
+}
+
+// Stop stops serving the APIs and shuts down the store. It can be called
+// several times.
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop()
+}
+
+func (s *Server) stop() (err error) {
+	s.stopOnce.Do(func() {
+		if s.cancel != nil {
+			s.cancel()
+		}
+		if s.http != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			_ = s.http.Shutdown(ctx)
+			cancel()
+			slog.Warn("server shutdown")
+		}
+		if s.listener != nil {
+			_ = s.listener.Close()
+			s.listener.closeConns()
+		}
+		if s.etcd != nil {
+			s.etcd.Close()
+		}
+		if s.rest != nil {
+			s.rest.Close()
+		}
+		if s.lock != nil {
+			s.lock.Close()
+		}
+		if s.queue != nil {
+			s.queue.Close()
+		}
+		if s.redis != nil {
+			_ = s.redis.Close()
+		}
+		// The background goroutines use the store until they return.
+		s.wg.Wait()
+		if s.store != nil {
+			if err = s.store.Shutdown(); err != nil {
+				slog.Error("failed to shutdown store", "error", err)
+			}
+			slog.Warn("store shutdown")
+		}
+		if s.storer != nil {
+			_ = s.storer.Close()
+		}
+		if s.leaderAPI != nil {
+			s.leaderAPI.close()
+		}
+		if s.auditSink != nil {
+			_ = s.auditSink.Close()
+		}
+	})
+	return err
+}
+
+// Err returns a channel receiving the error which stopped serving the APIs.
+func (s *Server) Err() <-chan error {
+	return s.errCh
+}
+
+// ClientAddress returns the address of the client listener, which is useful
+// when listening on an ephemeral port. It is empty if the server is not
+// started.
+func (s *Server) ClientAddress() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// RedisAddress returns the address of the Redis listener. It is empty if the
+// server is not started or the Redis protocol is disabled.
+func (s *Server) RedisAddress() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.redisAddr == nil {
+		return ""
+	}
+	return s.redisAddr.String()
+}
+
+// Store returns the Raft store of the server. It is nil if the server is not
+// started.
+func (s *Server) Store() *distributed.Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store
+}
+
+// serveRedis serves the Redis protocol in the background.
+func (s *Server) serveRedis(tlsConfig *tls.Config, auditSink audit.Sink) error {
+	l, err := net.Listen("tcp", s.config.ListenRedisAddress)
+	if err != nil {
+		return err
+	}
+	s.redisAddr = l.Addr()
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	s.redis = &redis.Server{Store: s.store, Audit: auditSink}
+	slog.Info("redis listening", "address", s.redisAddr)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.redis.Serve(l); !errors.Is(err, redis.ErrServerClosed) {
+			// The error of the APIs may already be reported.
+			select {
+			case s.errCh <- err:
+			default:
+			}
+		}
+	}()
+	return nil
+}
+
+// wrapStreamLayer wraps the stream layer of the Raft transport with the
+// configured wrapper and the fault injector.
+func (s *Server) wrapStreamLayer(layer raft.StreamLayer) raft.StreamLayer {
+	if s.config.StreamLayer != nil {
+		layer = s.config.StreamLayer(layer)
+	}
+	if s.faults != nil {
+		layer = s.faults.Wrap(layer)
+	}
+	return layer
+}
+
+// peerOptions configures the peer traffic, either multiplexed on the client
+// listener or on its own listener. It returns the listener of the APIs.
+func (s *Server) peerOptions(
+	l net.Listener,
+	tlsConfig *tls.Config,
+) ([]distributed.StoreOption, net.Listener, error) {
+	storeOpts := []distributed.StoreOption{}
+	if s.config.MultiplexPeer {
+		// Peer traffic shares the client listener and the client TLS configuration.
+		m := mux.New(l, tlsConfig)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := m.Serve(); err != nil {
+				slog.Error("multiplexer stopped", "error", err)
+			}
+		}()
+		storeOpts = append(storeOpts, distributed.WithMux(m))
+		if tlsConfig != nil {
+			peerClientTLSConfig, err := internaltls.SetupClientTLSConfig(
+				s.config.CertFile,
+				s.config.KeyFile,
+				s.config.TrustedCAFile,
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+			storeOpts = append(storeOpts, distributed.WithClientTLSConfig(peerClientTLSConfig))
+		}
+		return storeOpts, m.HTTPListener(), nil
+	}
+
+	if s.config.PeerCertFile != "" && s.config.PeerKeyFile != "" {
+		peerTLSConfig, err := internaltls.SetupServerTLSConfig(
+			s.config.PeerCertFile,
+			s.config.PeerKeyFile,
+			s.config.PeerTrustedCAFile,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		storeOpts = append(storeOpts, distributed.WithServerTLSConfig(peerTLSConfig))
+	}
+
+	if (s.config.PeerCertFile != "" && s.config.PeerKeyFile != "") ||
+		s.config.PeerTrustedCAFile != "" {
+		peerClientTLSConfig, err := internaltls.SetupClientTLSConfig(
+			s.config.PeerCertFile,
+			s.config.PeerKeyFile,
+			s.config.PeerTrustedCAFile,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		storeOpts = append(storeOpts, distributed.WithClientTLSConfig(peerClientTLSConfig))
+	}
+
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return storeOpts, l, nil
+}
+
+// openStore opens the Raft store, bootstraps the cluster if this node is the
+// first node of a new cluster, and joins the peers in the background.
+func (s *Server) openStore(ctx context.Context, storeOpts []distributed.StoreOption) error {
+	// Bootstrap
+	if len(s.config.InitialCluster) == 0 {
+		return errors.New("invalid initial cluster configuration (no nodes)")
+	}
+	bootstrapNode := s.config.InitialCluster[0].ID
+	advertizedPeers := s.config.peers()
+	discoverer, err := s.newDiscoverer(advertizedPeers)
+	if err != nil {
+		return err
+	}
+	name := raft.ServerID(s.config.Name)
+	advertizedAddr, ok := advertizedPeers[name]
+	if !ok {
+		// The local node may only be known by the discovery.
+		peers, err := discoverer.Discover(ctx)
+		if err != nil {
+			return err
+		}
+		if advertizedAddr, ok = peers[name]; !ok {
+			return fmt.Errorf("node %s is neither in the initial cluster nor discovered", name)
+		}
+	}
+	// The initial cluster is trusted so that the nodes can be joined by the leader.
+	allowedPeers := append([]string{}, s.config.PeerAllowedIdentities...)
+	allowedPeers = append(allowedPeers, peerIdentities(advertizedPeers)...)
+	storeOpts = append(
+		storeOpts,
+		distributed.WithAllowedPeers(allowedPeers...),
+		distributed.WithForceNewCluster(s.config.ForceNewCluster),
+		distributed.WithClusterToken(s.config.InitialClusterToken),
+		distributed.WithZone(s.config.Zone),
+		distributed.WithMaxReadLag(s.config.MaxReadLag),
+	)
+
+	dstore := distributed.NewStore(
+		s.config.DataDir,
+		s.config.ListenPeerAddress,
+		s.config.Name,
+		advertizedAddr,
+		s.storer,
+		storeOpts...,
+	)
+
+	bootstrap := s.config.InitialClusterState == ClusterStateNew && bootstrapNode == name
+	if err := dstore.Open(bootstrap); err != nil {
+		return err
+	}
+	s.store = dstore
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.joinPeers(ctx, discoverer)
+	}()
+	return nil
+}
+
+// joinPeers periodically joins the discovered peers, and removes the vanished
+// ones, while this node is the leader.
+func (s *Server) joinPeers(ctx context.Context, discoverer discovery.Discoverer) {
+	name := raft.ServerID(s.config.Name)
+	interval := s.config.JoinInterval
+	if interval <= 0 {
+		interval = defaultJoinInterval
+	}
+	tracker := &discovery.Tracker{RemoveAfter: s.config.DiscoveryRemoveAfter}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.store.ShutdownCh():
+			slog.Error("stopped joining peers due to store shutdown")
+			return
+		case <-ticker.C:
+			peers, err := discoverer.Discover(ctx)
+			if err != nil {
+				slog.Error("failed to discover peers", "error", err)
+				continue
+			}
+			// The discovered peers are only trusted if the DNS answers are
+			// trusted, so that they can be joined by the leader.
+			if s.config.DiscoveryTrustPeers {
+				s.store.AllowPeers(peerIdentities(peers)...)
+			}
+
+			leaderAddr, leaderID := s.store.GetLeader()
+			if leaderAddr == "" {
+				slog.Error("no leader")
+				continue
+			}
+			// Not leader
+			if leaderID != name {
+				continue
+			}
+			members, err := s.store.GetServers()
+			if err != nil {
+				slog.Error("failed to get servers", "error", err)
+				continue
+			}
+			join, remove := tracker.Changes(time.Now(), name, members, peers)
+			for id, addr := range join {
+				slog.Info("request peer to join", "id", id, "addr", addr)
+				if _, err := s.store.Join(id, addr); err != nil {
+					slog.Error("failed to join peer", "id", id, "addr", addr, "error", err)
+				}
+			}
+			for _, id := range remove {
+				slog.Warn("removing vanished peer", "id", id, "after", s.config.DiscoveryRemoveAfter)
+				_, err := s.store.LeaveKeepingQuorum(id)
+				if errors.Is(err, distributed.ErrMinQuorum) {
+					slog.Warn("vanished peer not removed to keep the minimum quorum", "id", id)
+				} else if err != nil {
+					slog.Error("failed to remove peer", "id", id, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// newDiscoverer returns the discoverer of the peers, including the initial
+// cluster.
+func (s *Server) newDiscoverer(
+	initialPeers map[raft.ServerID]raft.ServerAddress,
+) (discovery.Discoverer, error) {
+	static := discovery.Static(initialPeers)
+	switch s.config.DiscoveryMode {
+	case "", "static":
+		return static, nil
+	case "srv":
+		if s.config.DiscoverySRV == "" {
+			return nil, errors.New("the srv discovery requires a domain name")
+		}
+		return discovery.Multi{static, &discovery.SRV{
+			Resolver: net.DefaultResolver,
+			Name:     s.config.DiscoverySRV,
+		}}, nil
+	case "dns":
+		if !strings.Contains(s.config.DiscoveryDNSPattern, discovery.IndexPlaceholder) {
+			return nil, fmt.Errorf(
+				"the dns discovery pattern must contain %s",
+				discovery.IndexPlaceholder,
+			)
+		}
+		return discovery.Multi{static, &discovery.Headless{
+			Resolver: net.DefaultResolver,
+			Pattern:  s.config.DiscoveryDNSPattern,
+			MaxPeers: s.config.DiscoveryDNSMaxPeers,
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown discovery mode: %s", s.config.DiscoveryMode)
+	}
+}
+
+// peerIdentities returns the IDs and the hosts of the peers.
+func peerIdentities(peers map[raft.ServerID]raft.ServerAddress) []string {
+	identities := make([]string, 0, 2*len(peers))
+	for id, addr := range peers {
+		host, _, err := net.SplitHostPort(string(addr))
+		if err != nil {
+			host = string(addr)
+		}
+		identities = append(identities, string(id), host)
+	}
+	return identities
+}
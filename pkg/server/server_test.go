@@ -0,0 +1,127 @@
+package server_test
+
+import (
+	"context"
+	"distributed-kv/internal/backup"
+	"distributed-kv/pkg/client"
+	"distributed-kv/pkg/server"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func getRandomAddress(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+	return net.JoinHostPort("localhost", port)
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	peerAddr := getRandomAddress(t)
+	srv := server.New(server.Config{
+		Name:                "node1",
+		ListenPeerAddress:   peerAddr,
+		ListenClientAddress: "localhost:0",
+		InitialCluster: []server.Peer{
+			{ID: "node1", Address: raft.ServerAddress(peerAddr)},
+		},
+		InitialClusterState: server.ClusterStateNew,
+		DataDir:             t.TempDir(),
+	})
+	ctx := context.Background()
+
+	// Act
+	err := srv.Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, srv.Stop())
+	})
+
+	// Assert
+	require.ErrorIs(t, srv.Start(ctx), server.ErrStarted)
+	_, err = srv.Store().WaitForLeader(5 * time.Second)
+	require.NoError(t, err)
+	c, err := client.New([]string{srv.ClientAddress()})
+	require.NoError(t, err)
+	_, err = c.Set(ctx, "key", "value")
+	require.NoError(t, err)
+	value, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+}
+
+func TestServerBackupInterval(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	peerAddr := getRandomAddress(t)
+	srv := server.New(server.Config{
+		Name:                "node1",
+		ListenPeerAddress:   peerAddr,
+		ListenClientAddress: "localhost:0",
+		InitialCluster: []server.Peer{
+			{ID: "node1", Address: raft.ServerAddress(peerAddr)},
+		},
+		InitialClusterState: server.ClusterStateNew,
+		DataDir:             t.TempDir(),
+		BackupDir:           t.TempDir(),
+	})
+
+	// Act
+	err := srv.Start(context.Background())
+
+	// Assert
+	require.ErrorIs(t, err, backup.ErrInvalidInterval)
+}
+
+func TestParsePeers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title    string
+		values   []string
+		expected []server.Peer
+		isError  bool
+	}{
+		{
+			title:  "Keep the order of the peers",
+			values: []string{"node2=localhost:2380", "node1=localhost:2381"},
+			expected: []server.Peer{
+				{ID: "node2", Address: "localhost:2380"},
+				{ID: "node1", Address: "localhost:2381"},
+			},
+		},
+		{
+			title:   "Reject a peer without address",
+			values:  []string{"node1"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Act
+			peers, err := server.ParsePeers(tt.values)
+
+			// Assert
+			if tt.isError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, peers)
+			}
+		})
+	}
+}